@@ -0,0 +1,379 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/migrations"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/services"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// errUsage makes run print the usage text and exit with status 2.
+var errUsage = errors.New("usage")
+
+// userSummary is what the admin tool shows for a user; the Spotify refresh token itself is never printed.
+type userSummary struct {
+	Sub                    string `json:"sub"`
+	Username               string `json:"username,omitempty"`
+	HasSpotifyRefreshToken bool   `json:"has_spotify_refresh_token"`
+}
+
+func summarizeUser(user *models.User) userSummary {
+	return userSummary{Sub: user.Sub, Username: user.Username, HasSpotifyRefreshToken: user.SpotifyRefreshToken != ""}
+}
+
+func findUser(ctx context.Context, e *env, sub string) (*models.User, error) {
+	user, err := e.userDAO.FindBySub(ctx, sub)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("no user with sub %q", sub)
+	}
+	return user, err
+}
+
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// parseFlags parses a subcommand's flags and checks it got exactly nArgs positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string, nArgs int) error {
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if fs.NArg() != nArgs {
+		return errUsage
+	}
+	return nil
+}
+
+func userCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("user", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	user, err := findUser(ctx, e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	return writeJSON(os.Stdout, summarizeUser(user))
+}
+
+func selectionsCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("selections", flag.ContinueOnError)
+	month := fs.String("month", "", "only list selections for this month (YYYY-MM)")
+	asJSON := fs.Bool("json", false, "print full selections as JSON")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	sub := fs.Arg(0)
+
+	var selections []*models.UserSelection
+	var err error
+	if *month != "" {
+		selections, err = e.userSelectionDAO.ListByUserAndMonth(ctx, sub, *month)
+	} else {
+		selections, err = e.userSelectionDAO.ListByUser(ctx, sub)
+	}
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		return writeJSON(os.Stdout, selections)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tMONTH\tROLE\tTYPE\tSPOTIFY ID\tNAME")
+	for _, s := range selections {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", s.ID.Hex(), s.MonthYear, s.SelectionRole, s.ItemType, s.SpotifyItemID, s.ItemName)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%d selection(s)\n", len(selections))
+	return nil
+}
+
+func refreshItemsCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("refresh-items", flag.ContinueOnError)
+	itemType := fs.String("type", "", "item type of the given IDs: track, album or artist")
+	sub := fs.String("user", "", "refresh every item referenced by this user's selections")
+	stale := fs.Duration("stale", 0, "refresh items of -type last fetched longer ago than this")
+	limit := fs.Int64("limit", 500, "with -stale, refresh at most this many items")
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	trackDAO := dao.NewSpotifyTrackDAO(e.client, e.config.MongoDBName, dao.SpotifyTracksCollection, e.logger)
+	albumDAO := dao.NewSpotifyAlbumDAO(e.client, e.config.MongoDBName, dao.SpotifyAlbumsCollection, e.logger)
+	artistDAO := dao.NewSpotifyArtistDAO(e.client, e.config.MongoDBName, dao.SpotifyArtistsCollection, e.logger)
+
+	// Group the IDs to refresh by item type
+	idsByType := map[string][]string{}
+	switch {
+	case *sub != "" && fs.NArg() == 0:
+		selections, err := e.userSelectionDAO.ListByUser(ctx, *sub)
+		if err != nil {
+			return err
+		}
+		seen := map[string]bool{}
+		for _, s := range selections {
+			if key := s.ItemType + ":" + s.SpotifyItemID; !seen[key] {
+				seen[key] = true
+				idsByType[s.ItemType] = append(idsByType[s.ItemType], s.SpotifyItemID)
+			}
+		}
+	case *sub == "" && *stale > 0 && fs.NArg() == 0:
+		ids, err := staleItemIDs(ctx, trackDAO, albumDAO, artistDAO, *itemType, time.Now().Add(-*stale), *limit)
+		if err != nil {
+			return err
+		}
+		idsByType[*itemType] = ids
+	case *sub == "" && *stale == 0 && fs.NArg() > 0 && (*itemType == "track" || *itemType == "album" || *itemType == "artist"):
+		idsByType[*itemType] = fs.Args()
+	default:
+		return errUsage
+	}
+
+	spotifyService := services.NewSpotifyService(e.config.SpotifyClientID, e.config.SpotifyClientSecret, e.logger)
+	token, err := spotifyService.ClientCredentialsToken(ctx)
+	if err != nil {
+		return err
+	}
+	client := utils.CreateTemporarySpotifyClient(ctx, token)
+	audioFeatures, err := services.NewAudioFeaturesProvider(e.config.AudioFeaturesProvider, e.config.AudioFeaturesFixture)
+	if err != nil {
+		return err
+	}
+	syncService := services.NewSpotifySyncService(
+		trackDAO, albumDAO, artistDAO,
+		dao.NewSpotifyRelatedArtistsDAO(e.client, e.config.MongoDBName, dao.SpotifyRelatedArtistsCollection, e.logger),
+		dao.NewSpotifyArtistTopTracksDAO(e.client, e.config.MongoDBName, dao.SpotifyArtistTopTracksCollection, e.logger),
+		audioFeatures,
+		e.logger,
+	)
+
+	failed := 0
+	for itemType, ids := range idsByType {
+		items, failures := syncService.SyncItems(ctx, ids, itemType, client)
+		fmt.Printf("%s: refreshed %d, failed %d\n", itemType, len(items), len(failures))
+		for id, err := range failures {
+			fmt.Printf("  %s: %v\n", id, err)
+		}
+		failed += len(failures)
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d item(s) could not be refreshed", failed)
+	}
+	return nil
+}
+
+// staleItemIDs lists the Spotify IDs of up to limit cached items of itemType last fetched before fetchedBefore.
+func staleItemIDs(ctx context.Context, trackDAO dao.SpotifyTrackDAO, albumDAO dao.SpotifyAlbumDAO, artistDAO dao.SpotifyArtistDAO,
+	itemType string, fetchedBefore time.Time, limit int64) ([]string, error) {
+	var ids []string
+	switch itemType {
+	case "track":
+		items, err := trackDAO.ListStale(ctx, fetchedBefore, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			ids = append(ids, item.SpotifyID)
+		}
+	case "album":
+		items, err := albumDAO.ListStale(ctx, fetchedBefore, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			ids = append(ids, item.SpotifyID)
+		}
+	case "artist":
+		items, err := artistDAO.ListStale(ctx, fetchedBefore, limit)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			ids = append(ids, item.SpotifyID)
+		}
+	default:
+		return nil, errUsage
+	}
+	return ids, nil
+}
+
+func gcCacheCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("gc-cache", flag.ContinueOnError)
+	grace := fs.Duration("grace", e.config.CacheGCGrace, "only delete items not fetched for this long")
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	gc := services.NewCacheGCService(
+		dao.NewSpotifyTrackDAO(e.client, e.config.MongoDBName, dao.SpotifyTracksCollection, e.logger),
+		dao.NewSpotifyAlbumDAO(e.client, e.config.MongoDBName, dao.SpotifyAlbumsCollection, e.logger),
+		dao.NewSpotifyArtistDAO(e.client, e.config.MongoDBName, dao.SpotifyArtistsCollection, e.logger),
+		dao.NewSpotifyRelatedArtistsDAO(e.client, e.config.MongoDBName, dao.SpotifyRelatedArtistsCollection, e.logger),
+		dao.NewSpotifyArtistTopTracksDAO(e.client, e.config.MongoDBName, dao.SpotifyArtistTopTracksCollection, e.logger),
+		*grace, e.logger,
+	)
+	deleted, err := gc.Collect(ctx)
+	collections := make([]string, 0, len(deleted))
+	for collection := range deleted {
+		collections = append(collections, collection)
+	}
+	sort.Strings(collections)
+	for _, collection := range collections {
+		fmt.Printf("%s: deleted %d\n", collection, deleted[collection])
+	}
+	return err
+}
+
+func reindexCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("reindex", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	if err := migrations.EnsureIndexes(ctx, e.db); err != nil {
+		return err
+	}
+	fmt.Println("indexes ensured")
+	return nil
+}
+
+// userExport is the JSON document written by the export command.
+type userExport struct {
+	ExportedAt time.Time               `json:"exported_at"`
+	User       userSummary             `json:"user"`
+	Selections []*models.UserSelection `json:"selections"`
+}
+
+func exportCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	output := fs.String("o", "", "write to this file instead of stdout")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	user, err := findUser(ctx, e, fs.Arg(0))
+	if err != nil {
+		return err
+	}
+	selections, err := e.userSelectionDAO.ListByUser(ctx, user.Sub)
+	if err != nil {
+		return err
+	}
+
+	w := io.Writer(os.Stdout)
+	if *output != "" {
+		f, err := os.OpenFile(*output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600) // Personal data: owner-only
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := writeJSON(w, userExport{ExportedAt: time.Now().UTC(), User: summarizeUser(user), Selections: selections}); err != nil {
+		return err
+	}
+	if *output != "" {
+		fmt.Fprintf(os.Stderr, "exported %d selection(s) to %s\n", len(selections), *output)
+	}
+	return nil
+}
+
+func deleteUserCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("delete-user", flag.ContinueOnError)
+	yes := fs.Bool("yes", false, "confirm the deletion")
+	if err := parseFlags(fs, args, 1); err != nil {
+		return err
+	}
+	sub := fs.Arg(0)
+	if !*yes {
+		return fmt.Errorf("refusing to delete %q without -yes (consider running export first)", sub)
+	}
+
+	// Selections first, so a failure part-way never leaves selections without an owner record to find them by
+	deletedSelections, err := e.userSelectionDAO.DeleteByUser(ctx, sub)
+	if err != nil {
+		return err
+	}
+	deletedKeys, err := e.idempotencyDAO.DeleteByUser(ctx, sub)
+	if err != nil {
+		return err
+	}
+	userDeleted := true
+	if err := e.userDAO.Delete(ctx, sub); errors.Is(err, mongo.ErrNoDocuments) {
+		userDeleted = false
+	} else if err != nil {
+		return err
+	}
+	fmt.Printf("deleted user: %t, selections: %d, idempotency keys: %d\n", userDeleted, deletedSelections, deletedKeys)
+	return nil
+}
+
+// collectionStats is the subset of $collStats storageStats we report.
+type collectionStats struct {
+	StorageStats struct {
+		Count          int64 `bson:"count"`
+		Size           int64 `bson:"size"`
+		StorageSize    int64 `bson:"storageSize"`
+		NIndexes       int   `bson:"nindexes"`
+		TotalIndexSize int64 `bson:"totalIndexSize"`
+	} `bson:"storageStats"`
+}
+
+func statsCommand(ctx context.Context, e *env, args []string) error {
+	fs := flag.NewFlagSet("stats", flag.ContinueOnError)
+	if err := parseFlags(fs, args, 0); err != nil {
+		return err
+	}
+	names, err := e.db.ListCollectionNames(ctx, bson.M{"type": "collection"})
+	if err != nil {
+		return fmt.Errorf("listing collections: %w", err)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(w, "COLLECTION\tDOCUMENTS\tDATA\tSTORAGE\tINDEXES\tINDEX SIZE\t")
+	for _, name := range names {
+		cursor, err := e.db.Collection(name).Aggregate(ctx, bson.A{bson.M{"$collStats": bson.M{"storageStats": bson.M{}}}})
+		if err != nil {
+			return fmt.Errorf("reading stats for %s: %w", name, err)
+		}
+		var stats []collectionStats
+		if err := cursor.All(ctx, &stats); err != nil || len(stats) == 0 {
+			return fmt.Errorf("decoding stats for %s: %v", name, err)
+		}
+		s := stats[0].StorageStats
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%d\t%s\t\n", name, s.Count, formatBytes(s.Size), formatBytes(s.StorageSize), s.NIndexes, formatBytes(s.TotalIndexSize))
+	}
+	return w.Flush()
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), strings.ToUpper("kmgtpe")[exp])
+}
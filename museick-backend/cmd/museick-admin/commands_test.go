@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeUserDAO, fakeSelectionDAO and fakeIdempotencyDAO implement what the user commands call;
+// the rest is left to the nil embedded interfaces. calls records the deletions in order.
+type fakeUserDAO struct {
+	dao.UserDAO
+	users map[string]*models.User
+	calls *[]string
+}
+
+func (f *fakeUserDAO) FindBySub(ctx context.Context, sub string) (*models.User, error) {
+	if user, ok := f.users[sub]; ok {
+		return user, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeUserDAO) Delete(ctx context.Context, sub string) error {
+	*f.calls = append(*f.calls, "user")
+	if _, ok := f.users[sub]; !ok {
+		return mongo.ErrNoDocuments
+	}
+	delete(f.users, sub)
+	return nil
+}
+
+type fakeSelectionDAO struct {
+	dao.UserSelectionDAO
+	selections []*models.UserSelection
+	deleteErr  error
+	calls      *[]string
+}
+
+func (f *fakeSelectionDAO) ListByUser(ctx context.Context, userID string) ([]*models.UserSelection, error) {
+	var found []*models.UserSelection
+	for _, s := range f.selections {
+		if s.UserID == userID {
+			found = append(found, s)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeSelectionDAO) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	*f.calls = append(*f.calls, "selections")
+	if f.deleteErr != nil {
+		return 0, f.deleteErr
+	}
+	var kept []*models.UserSelection
+	for _, s := range f.selections {
+		if s.UserID != userID {
+			kept = append(kept, s)
+		}
+	}
+	deleted := int64(len(f.selections) - len(kept))
+	f.selections = kept
+	return deleted, nil
+}
+
+type fakeIdempotencyDAO struct {
+	dao.IdempotencyDAO
+	calls *[]string
+}
+
+func (f *fakeIdempotencyDAO) DeleteByUser(ctx context.Context, userSub string) (int64, error) {
+	*f.calls = append(*f.calls, "idempotency_keys")
+	return 1, nil
+}
+
+func newTestEnv(calls *[]string) *env {
+	return &env{
+		userDAO: &fakeUserDAO{calls: calls, users: map[string]*models.User{
+			"user_1": {Sub: "user_1", Username: "ada", SpotifyRefreshToken: "AQB-secret-refresh"},
+		}},
+		userSelectionDAO: &fakeSelectionDAO{calls: calls, selections: []*models.UserSelection{
+			{UserID: "user_1", SpotifyItemID: "t1", MonthYear: "2025-03"},
+			{UserID: "user_1", SpotifyItemID: "t2", MonthYear: "2025-04"},
+			{UserID: "user_2", SpotifyItemID: "t3", MonthYear: "2025-03"},
+		}},
+		idempotencyDAO: &fakeIdempotencyDAO{calls: calls},
+	}
+}
+
+func TestDeleteUserCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		deleteErr error
+		wantErr   string
+		wantCalls []string
+	}{
+		{name: "no sub", args: []string{"-yes"}, wantErr: errUsage.Error()},
+		{name: "without confirmation", args: []string{"user_1"}, wantErr: "without -yes"},
+		{name: "confirmed", args: []string{"-yes", "user_1"}, wantCalls: []string{"selections", "idempotency_keys", "user"}},
+		{name: "unknown user still clears their data", args: []string{"-yes", "user_9"}, wantCalls: []string{"selections", "idempotency_keys", "user"}},
+		{name: "stops at the first failure", args: []string{"-yes", "user_1"}, deleteErr: errors.New("mongo down"), wantErr: "mongo down", wantCalls: []string{"selections"}},
+	}
+	for _, tt := range tests {
+		var calls []string
+		e := newTestEnv(&calls)
+		e.userSelectionDAO.(*fakeSelectionDAO).deleteErr = tt.deleteErr
+		err := deleteUserCommand(context.Background(), e, tt.args)
+		if (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: deleteUserCommand(%q) = %v, want error containing %q", tt.name, tt.args, err, tt.wantErr)
+		}
+		if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+			t.Errorf("%s: deleted %v, want %v", tt.name, calls, tt.wantCalls)
+		}
+	}
+}
+
+func TestExportCommand(t *testing.T) {
+	var calls []string
+	e := newTestEnv(&calls)
+	path := filepath.Join(t.TempDir(), "export.json")
+	if err := exportCommand(context.Background(), e, []string{"-o", path, "user_1"}); err != nil {
+		t.Fatalf("exportCommand() = %v", err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("export file mode = %o, want 600", perm)
+	}
+	written, _ := os.ReadFile(path)
+	if strings.Contains(string(written), "AQB-secret-refresh") {
+		t.Errorf("export contains the Spotify refresh token: %s", written)
+	}
+	var export struct {
+		User       userSummary              `json:"user"`
+		Selections []map[string]interface{} `json:"selections"`
+	}
+	if err := json.Unmarshal(written, &export); err != nil {
+		t.Fatalf("export is not JSON: %v", err)
+	}
+	if export.User != (userSummary{Sub: "user_1", Username: "ada", HasSpotifyRefreshToken: true}) || len(export.Selections) != 2 {
+		t.Errorf("exported %+v with %d selections, want user_1 with 2", export.User, len(export.Selections))
+	}
+
+	if err := exportCommand(context.Background(), e, []string{"-o", path, "user_9"}); err == nil || !strings.Contains(err.Error(), `no user with sub "user_9"`) {
+		t.Errorf("exportCommand(unknown user) = %v, want no user with sub", err)
+	}
+}
+
+func TestParseFlags(t *testing.T) {
+	tests := []struct {
+		args    []string
+		nArgs   int
+		wantErr bool
+	}{
+		{[]string{"user_1"}, 1, false},
+		{[]string{"-month", "2025-03", "user_1"}, 1, false},
+		{nil, 0, false},
+		{nil, 1, true},
+		{[]string{"user_1", "user_2"}, 1, true},
+		{[]string{"-unknown", "user_1"}, 1, true},
+	}
+	for _, tt := range tests {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.String("month", "", "")
+		if err := parseFlags(fs, tt.args, tt.nArgs); (err != nil) != tt.wantErr || (err != nil && err != errUsage) {
+			t.Errorf("parseFlags(%q, %d) = %v, want usage error %v", tt.args, tt.nArgs, err, tt.wantErr)
+		}
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1024, "1.0 KiB"},
+		{1536, "1.5 KiB"},
+		{5 * 1024 * 1024, "5.0 MiB"},
+		{3 * 1024 * 1024 * 1024, "3.0 GiB"},
+	}
+	for _, tt := range tests {
+		if got := formatBytes(tt.n); got != tt.want {
+			t.Errorf("formatBytes(%d) = %q, want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestRunUsage(t *testing.T) {
+	for _, args := range [][]string{nil, {"frobnicate"}, {"-bogus-flag"}} {
+		if code := run(args); code != 2 {
+			t.Errorf("run(%q) = %d, want 2", args, code)
+		}
+	}
+}
@@ -0,0 +1,126 @@
+// Command museick-admin is an operator tool for support tasks that would otherwise mean
+// querying MongoDB by hand. It reads the same app.env / environment configuration as the server.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/initializers"
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const usage = `Usage: museick-admin [-config DIR] [-log-level LEVEL] <command> [flags] [args]
+
+Commands:
+  user <sub>                          Show a user
+  selections [-month YYYY-MM] [-json] <sub>
+                                      List a user's selections
+  refresh-items -type TYPE <id>...    Re-fetch Spotify items into the cache (TYPE is track, album or artist)
+  refresh-items -user <sub>           Re-fetch every item referenced by a user's selections
+  refresh-items -type TYPE -stale DURATION [-limit N]
+                                      Re-fetch the cached items of TYPE last fetched longer ago than DURATION
+  gc-cache [-grace DURATION]          Delete cached Spotify data no selection references (default grace CACHE_GC_GRACE)
+  reindex                             Re-create all indexes (idempotent)
+  export [-o FILE] <sub>              Export a user and their selections as JSON
+  delete-user -yes <sub>              Delete a user, their selections and idempotency keys
+  stats                               Print document counts and sizes per collection
+`
+
+// env holds the connections and DAOs shared by every command.
+type env struct {
+	config           *initializers.Config
+	logger           *slog.Logger
+	client           *mongo.Client
+	db               *mongo.Database
+	userDAO          dao.UserDAO
+	userSelectionDAO dao.UserSelectionDAO
+	idempotencyDAO   dao.IdempotencyDAO
+}
+
+type command func(ctx context.Context, e *env, args []string) error
+
+var commands = map[string]command{
+	"user":          userCommand,
+	"selections":    selectionsCommand,
+	"refresh-items": refreshItemsCommand,
+	"gc-cache":      gcCacheCommand,
+	"reindex":       reindexCommand,
+	"export":        exportCommand,
+	"delete-user":   deleteUserCommand,
+	"stats":         statsCommand,
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	global := flag.NewFlagSet("museick-admin", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(os.Stderr, usage) }
+	configDir := global.String("config", ".", "directory containing app.env")
+	logLevel := global.String("log-level", "warn", "log level for diagnostics written to stderr")
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return 2
+	}
+	cmd, ok := commands[global.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", global.Arg(0))
+		global.Usage()
+		return 2
+	}
+
+	// Diagnostics go to stderr so command output on stdout can be piped
+	logger, err := logging.New(logging.Options{Level: *logLevel, Output: os.Stderr})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not configure logging:", err)
+		return 2
+	}
+	slog.SetDefault(logger)
+	if err := initializers.LoadConfig(*configDir); err != nil {
+		logger.Error("could not load configuration", "error", err)
+		return 1
+	}
+	config := initializers.GetConfig()
+
+	client, err := initializers.NewMongoConnection(config)
+	if err != nil {
+		logger.Error("could not connect to MongoDB", "error", err)
+		return 1
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = client.Disconnect(ctx)
+	}()
+
+	e := &env{
+		config:           config,
+		logger:           logger,
+		client:           client,
+		db:               client.Database(config.MongoDBName),
+		userDAO:          dao.NewUserDAO(client, config.MongoDBName, dao.UsersCollection, logger),
+		userSelectionDAO: dao.NewUserSelectionDAO(client, config.MongoDBName, dao.UserSelectionsCollection, logger),
+		idempotencyDAO:   dao.NewIdempotencyDAO(client, config.MongoDBName, dao.IdempotencyKeysCollection, logger),
+	}
+
+	if err := cmd(context.Background(), e, global.Args()[1:]); err != nil {
+		if err == errUsage {
+			global.Usage()
+			return 2
+		}
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+	return 0
+}
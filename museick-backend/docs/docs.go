@@ -24,24 +24,47 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/api/selections": {
-            "post": {
+        "/": {
+            "get": {
+                "description": "Confirms the API is reachable.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "API welcome",
+                "responses": {
+                    "200": {
+                        "description": "Welcome message",
+                        "schema": {
+                            "$ref": "#/definitions/models.WelcomeResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/artists/{id}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Adds a Spotify item (song, album, artist) to the user's candidate list (Muse or Ick) for a specific month.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Returns an artist (including followers) with its related artists and top tracks in a market, from the cache.\nAnything missing or stale is fetched from Spotify with the caller's token. Related artists and top tracks are\nbest effort: they come back empty when Spotify can't provide them.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "selections"
+                    "artists"
                 ],
-                "summary": "Add a selection candidate",
+                "summary": "Get artist details",
                 "parameters": [
                     {
                         "type": "string",
@@ -51,67 +74,81 @@ const docTemplate = `{
                         "required": true
                     },
                     {
-                        "description": "Candidate Selection Data",
-                        "name": "selection",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/models.CreateSelectionRequest"
-                        }
+                        "type": "string",
+                        "description": "Spotify access token, needed when the artist is not cached or is stale",
+                        "name": "X-Spotify-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spotify artist ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "default": "US",
+                        "description": "ISO 3166-1 alpha-2 country code for top tracks",
+                        "name": "market",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Selection already existed",
-                        "schema": {
-                            "$ref": "#/definitions/models.UserSelection"
-                        }
-                    },
-                    "201": {
-                        "description": "Selection candidate created successfully",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.UserSelection"
+                            "$ref": "#/definitions/models.ArtistDetailsResponse"
                         }
                     },
                     "400": {
-                        "description": "Invalid input format or data",
+                        "description": "Invalid market or missing Spotify token",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Artist not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/selections/{id}": {
-            "put": {
+        "/api/journal/search": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Updates the role (e.g., candidate to selected) or notes of a specific selection. Handles demotion of previous selection if needed.",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "Full-text search over the user's selection notes and the track, album and artist names of their selections. Results are ranked by relevance and include highlighted snippets.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "selections"
+                    "journal"
                 ],
-                "summary": "Update a selection's role or notes",
+                "summary": "Search selection notes",
                 "parameters": [
                     {
                         "type": "string",
@@ -122,74 +159,114 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Selection ID (MongoDB ObjectID)",
-                        "name": "id",
-                        "in": "path",
+                        "description": "Search query (MongoDB text search syntax: quoted phrases and -negation are supported)",
+                        "name": "q",
+                        "in": "query",
                         "required": true
                     },
                     {
-                        "description": "Fields to update (selection_role and/or notes)",
-                        "name": "selection",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/handlers.UpdateSelectionRequest"
-                        }
+                        "type": "integer",
+                        "example": 2024,
+                        "description": "Restrict results to a calendar year",
+                        "name": "year",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "Selection updated successfully",
+                        "description": "Matching selections, most relevant first",
                         "schema": {
-                            "$ref": "#/definitions/models.UserSelection"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.JournalSearchHit"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Invalid input format, data, or ID",
+                        "description": "Missing or invalid query or year",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden (selection does not belong to user)",
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/journal/tags": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Lists every mood tag the user has applied to a selection, with usage counts, most used first.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "journal"
+                ],
+                "summary": "List mood tags",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Mood tags with counts",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MoodTagCount"
+                            }
                         }
                     },
-                    "404": {
-                        "description": "Selection not found",
+                    "401": {
+                        "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/journal/tags/{tag}": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Deletes a specific user selection (candidate or selected).",
+                "description": "Retrieves the user's selections carrying a mood tag, newest month first.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "selections"
+                    "journal"
                 ],
-                "summary": "Delete a selection",
+                "summary": "List selections by mood tag",
                 "parameters": [
                     {
                         "type": "string",
@@ -200,64 +277,151 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "description": "Selection ID (MongoDB ObjectID)",
-                        "name": "id",
+                        "description": "Mood tag (case-insensitive)",
+                        "name": "tag",
                         "in": "path",
                         "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2024,
+                        "description": "Restrict results to a calendar year",
+                        "name": "year",
+                        "in": "query"
                     }
                 ],
                 "responses": {
-                    "204": {
-                        "description": "Selection deleted successfully"
+                    "200": {
+                        "description": "Tagged selections",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserSelection"
+                            }
+                        }
                     },
                     "400": {
-                        "description": "Invalid ID format",
+                        "description": "Invalid tag or year",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
-                    "403": {
-                        "description": "Forbidden (selection does not belong to user)",
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/playlists": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Creates a Spotify playlist in the user's account from a year's Muse or Ick selections, optionally including candidates.\nTracks are checked against the user's market: unavailable ones are skipped and relinked ones are added as their\nplayable copy. The response lists both.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "playlists"
+                ],
+                "summary": "Create a yearly playlist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spotify access token of the user the playlist is created for",
+                        "name": "X-Spotify-Token",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Year, mode (muse or ick) and whether to include candidates",
+                        "name": "playlist",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.CreatePlaylistRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Playlist created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatePlaylistResponse"
                         }
                     },
-                    "404": {
-                        "description": "Selection not found",
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
-                        "description": "Internal server error",
+                        "description": "Playlist could not be created",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
         },
-        "/api/selections/{monthYear}": {
-            "get": {
+        "/api/selections": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieves all selections (candidates and selected) for the authenticated user for a specific month.",
+                "description": "Adds a Spotify item (song, album, artist) to the user's candidate list (Muse or Ick) for a specific month.",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "selections"
                 ],
-                "summary": "List selections by month",
+                "summary": "Add a selection candidate",
                 "parameters": [
                     {
                         "type": "string",
@@ -268,139 +432,2587 @@ const docTemplate = `{
                     },
                     {
                         "type": "string",
-                        "format": "YYYY-MM",
-                        "example": "2024-07",
-                        "description": "Month and Year (YYYY-MM)",
-                        "name": "monthYear",
-                        "in": "path",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spotify access token, used to verify the item exists",
+                        "name": "X-Spotify-Token",
+                        "in": "header",
                         "required": true
+                    },
+                    {
+                        "description": "Candidate Selection Data",
+                        "name": "selection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CreateSelectionRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "List of selections",
+                        "description": "Selection already existed",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.UserSelection"
-                            }
+                            "$ref": "#/definitions/models.UserSelection"
+                        }
+                    },
+                    "201": {
+                        "description": "Selection candidate created successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSelection"
                         }
                     },
                     "400": {
-                        "description": "Invalid monthYear format",
+                        "description": "Invalid input format or data",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "401": {
                         "description": "Unauthorized",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     },
                     "500": {
                         "description": "Internal server error",
                         "schema": {
-                            "$ref": "#/definitions/gin.H"
+                            "$ref": "#/definitions/models.ErrorResponse"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "gin.H": {
-            "type": "object",
-            "additionalProperties": {}
         },
-        "handlers.UpdateSelectionRequest": {
-            "type": "object",
-            "properties": {
-                "notes": {
-                    "description": "Pointer",
+        "/api/selections/batch": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Applies a list of create, update and delete operations in one request. Created items are verified against Spotify in bulk, and all writes are applied in a single bulk write. Each operation reports its own status, so some may fail while others succeed.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "selections"
+                ],
+                "summary": "Apply several selection changes at once",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spotify access token (required when the batch contains create operations)",
+                        "name": "X-Spotify-Token",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Operations to apply (max 100)",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchSelectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "All operations succeeded",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchSelectionResponse"
+                        }
+                    },
+                    "207": {
+                        "description": "Some operations failed; see per-operation results",
+                        "schema": {
+                            "$ref": "#/definitions/models.BatchSelectionResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/selections/carry-over": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Copies the user's candidates from a source month into a target month without re-verifying them against Spotify. Items already in the target month are skipped. Optionally links each copy to the selection it came from.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "selections"
+                ],
+                "summary": "Carry candidates over to another month",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "description": "Source and target months, candidate roles to copy, and whether to link copies to their origin",
+                        "name": "carryOver",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CarryOverRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Copied and skipped candidates",
+                        "schema": {
+                            "$ref": "#/definitions/models.CarryOverResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid months or roles",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/selections/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Updates the role (e.g., candidate to selected), notes, mood tags or listening context of a specific selection. Handles demotion of previous selection if needed. Replaced notes are kept in notes_history.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "selections"
+                ],
+                "summary": "Update a selection's role or journal entry",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Selection ID (MongoDB ObjectID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Fields to update (selection_role, notes, mood_tags and/or listening_context)",
+                        "name": "selection",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UpdateSelectionRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Selection updated successfully",
+                        "schema": {
+                            "$ref": "#/definitions/models.UserSelection"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid input format, data, or ID",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (selection does not belong to user)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Selection not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Deletes a specific user selection (candidate or selected).",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "selections"
+                ],
+                "summary": "Delete a selection",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Makes retries safe: a repeat with the same key and request replays the first response",
+                        "name": "Idempotency-Key",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Selection ID (MongoDB ObjectID)",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "Selection deleted successfully"
+                    },
+                    "400": {
+                        "description": "Invalid ID format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "Forbidden (selection does not belong to user)",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Selection not found",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/selections/{monthYear}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Retrieves all selections (candidates and selected) for the authenticated user for a specific month.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "selections"
+                ],
+                "summary": "List selections by month",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "format": "YYYY-MM",
+                        "example": "2024-07",
+                        "description": "Month and Year (YYYY-MM)",
+                        "name": "monthYear",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "List of selections",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.UserSelection"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid monthYear format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spotify/exchange-code": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Completes the Spotify PKCE flow: exchanges the authorization code for tokens, stores the refresh token against the user and returns the access token.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spotify"
+                ],
+                "summary": "Exchange a Spotify authorization code",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "description": "Authorization code and PKCE verifier",
+                        "name": "exchange",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.SpotifyCodeExchangeRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Spotify access token",
+                        "schema": {
+                            "$ref": "#/definitions/models.SpotifyTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Spotify token exchange failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/spotify/refresh-token": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Uses the refresh token stored for the user to obtain a new Spotify access token. A rotated refresh token is stored automatically.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "spotify"
+                ],
+                "summary": "Refresh the Spotify access token",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "New Spotify access token",
+                        "schema": {
+                            "$ref": "#/definitions/models.SpotifyTokenResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "No refresh token stored; the user must reconnect Spotify",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Spotify token refresh failed",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/audio-features/{year}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares the distributions of valence, energy, danceability, tempo and acousticness between the tracks\nthe user picked as Muses and as Icks in a year, with the difference of the means per feature.\nCandidates count too unless selected_only is set. Each recording counts once per group, and tracks\nwithout cached audio features are counted but left out of the distributions.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Compare the audio features of Muses and Icks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2024,
+                        "description": "Calendar year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the monthly Muse and Ick picks, not candidates",
+                        "name": "selected_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MoodAnalyticsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid year",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/compare": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares the user's picks across 2 to 10 years: per-year summaries, artists picked in more than one year,\nhow each genre's share drifted from the first year to the last, items picked as a Muse in several years,\nIcks that later became Muses (redemptions) and the reverse, and runs of consecutive months in one role\nthat cross New Year. Copies of the same recording or release count as one item.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Compare several years of picks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "example": "2024,2025",
+                        "description": "Comma-separated calendar years",
+                        "name": "years",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the monthly Muse and Ick picks, not candidates",
+                        "name": "selected_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.YearComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid years",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/genres/{year}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Attributes every pick of the year (artists directly, tracks and albums through their credited artists) to\nits artists' genres and reports the genre mix month by month and over the year. Each pick's weight of 1\nis split evenly across its genres. Also lists the most picked artists, and the artists picked both as a\nMuse and as an Ick. Items and artists missing from the cache are synced when a Spotify token is sent.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Chart a year's picks by genre and artist",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Spotify access token, used to sync items and artists missing from the cache",
+                        "name": "X-Spotify-Token",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2024,
+                        "description": "Calendar year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the monthly Muse and Ick picks, not candidates",
+                        "name": "selected_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.GenreTimelineResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid year",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/release-eras/{year}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Groups the tracks and albums the user picked in a year by the decade and year they were released (tracks\nuse their album's release date), counts how many were new releases rather than catalog, and names the\noldest and newest Muse. Dates may be reported to the year, month or day; a pick whose gap to its\nrelease can't be told from a year-only date is counted as undetermined. Picks without a cached\nrelease date count towards picks only.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Break down a year's picks by release era",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "example": 2024,
+                        "description": "Calendar year",
+                        "name": "year",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the monthly Muse and Ick picks, not candidates",
+                        "name": "selected_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.ReleaseEraResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid year",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/stats/retrospective": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Compares every year the user has picks in, with the same breakdown as /api/stats/compare.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "stats"
+                ],
+                "summary": "Look back over every year of picks",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only count the monthly Muse and Ick picks, not candidates",
+                        "name": "selected_only",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.YearComparisonResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid selected_only",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/users/sync": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "Ensures the Clerk user behind the token has a Museick user record, creating it on first sign-in.",
+                "tags": [
+                    "users"
+                ],
+                "summary": "Sync the signed-in user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Bearer token",
+                        "name": "Authorization",
+                        "in": "header",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "204": {
+                        "description": "User exists"
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/db_health": {
+            "get": {
+                "description": "Runs only the MongoDB ping check. Use /readyz instead.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "MongoDB health (deprecated)",
+                "deprecated": true,
+                "responses": {
+                    "200": {
+                        "description": "MongoDB connection healthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    },
+                    "500": {
+                        "description": "MongoDB connection unhealthy",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/healthz": {
+            "get": {
+                "description": "Reports that the process is running and serving HTTP. Does not check dependencies, so a database outage will not get the process restarted.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Liveness probe",
+                "responses": {
+                    "200": {
+                        "description": "Process is alive",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "string"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/media/{hash}": {
+            "get": {
+                "description": "Serves an album or artist image mirrored from Spotify, by the mirror_hash on a cached image.\nWithout size, the original is returned as Spotify served it. With size, a square thumbnail is\nreturned as JPEG, or as WebP with format=webp. Responses are immutable and cached for a year.",
+                "produces": [
+                    "image/jpeg",
+                    "image/png",
+                    "image/webp"
+                ],
+                "tags": [
+                    "media"
+                ],
+                "summary": "Get a mirrored image",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Image hash (hex SHA-256)",
+                        "name": "hash",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            64,
+                            160,
+                            300
+                        ],
+                        "type": "integer",
+                        "description": "Thumbnail edge in pixels",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "jpeg",
+                            "webp"
+                        ],
+                        "type": "string",
+                        "description": "Thumbnail format",
+                        "name": "format",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "The image",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "304": {
+                        "description": "Not modified"
+                    },
+                    "400": {
+                        "description": "Invalid hash, size or format",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "Image not mirrored",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal server error",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/ping": {
+            "get": {
+                "description": "Returns \"pong\". Kept for the Railway and Docker health checks.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Ping",
+                "responses": {
+                    "200": {
+                        "description": "pong",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "429": {
+                        "description": "Rate limited",
+                        "schema": {
+                            "$ref": "#/definitions/models.ErrorResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/readyz": {
+            "get": {
+                "description": "Checks MongoDB connectivity, the presence of required indexes and (from a cached background probe) Spotify token endpoint reachability. Fails while the server is shutting down so traffic drains away.",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "health"
+                ],
+                "summary": "Readiness probe",
+                "responses": {
+                    "200": {
+                        "description": "All dependencies are ready",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    },
+                    "503": {
+                        "description": "At least one dependency is failing, or the server is shutting down",
+                        "schema": {
+                            "$ref": "#/definitions/health.Report"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "health.CheckResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "latency_ms": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "health.Report": {
+            "type": "object",
+            "properties": {
+                "checks": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/health.CheckResult"
+                    }
+                },
+                "status": {
+                    "type": "string",
+                    "example": "ok"
+                }
+            }
+        },
+        "models.ArtistDetailsResponse": {
+            "type": "object",
+            "properties": {
+                "artist": {
+                    "$ref": "#/definitions/models.SpotifyArtist"
+                },
+                "market": {
+                    "type": "string",
+                    "example": "US"
+                },
+                "related_artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SpotifyArtist"
+                    }
+                },
+                "top_tracks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SpotifyTrack"
+                    }
+                }
+            }
+        },
+        "models.ArtistRollup": {
+            "type": "object",
+            "properties": {
+                "by_item_type": {
+                    "description": "Picks per item type: track, album or artist",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "genres": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "icks": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "muses": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rick Astley"
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "spotify_id": {
+                    "type": "string",
+                    "example": "0gxyHStUsqpMadRV0Di1Qt"
+                }
+            }
+        },
+        "models.AudioFeatures": {
+            "type": "object",
+            "properties": {
+                "acousticness": {
+                    "type": "number"
+                },
+                "danceability": {
+                    "type": "number"
+                },
+                "energy": {
+                    "type": "number"
+                },
+                "fetched_at": {
+                    "type": "integer"
+                },
+                "source": {
+                    "description": "Provider the features came from: spotify or fixture",
+                    "type": "string"
+                },
+                "tempo": {
+                    "type": "number"
+                },
+                "valence": {
+                    "type": "number"
+                }
+            }
+        },
+        "models.BatchSelectionOperation": {
+            "type": "object",
+            "required": [
+                "op"
+            ],
+            "properties": {
+                "id": {
+                    "description": "Update/Delete: selection ID",
+                    "type": "string"
+                },
+                "item_type": {
+                    "description": "Create: \"track\", \"album\", or \"artist\"",
+                    "type": "string"
+                },
+                "listening_context": {
+                    "$ref": "#/definitions/models.ListeningContext"
+                },
+                "month_year": {
+                    "description": "Create: \"YYYY-MM\"",
+                    "type": "string"
+                },
+                "mood_tags": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "notes": {
+                    "type": "string"
+                },
+                "op": {
+                    "type": "string",
+                    "enum": [
+                        "create",
+                        "update",
+                        "delete"
+                    ]
+                },
+                "selection_role": {
+                    "description": "Create (candidate roles only) or Update",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SelectionRole"
+                        }
+                    ]
+                },
+                "spotify_item_id": {
+                    "description": "Create",
+                    "type": "string"
+                }
+            }
+        },
+        "models.BatchSelectionRequest": {
+            "type": "object",
+            "required": [
+                "operations"
+            ],
+            "properties": {
+                "operations": {
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/models.BatchSelectionOperation"
+                    }
+                }
+            }
+        },
+        "models.BatchSelectionResponse": {
+            "type": "object",
+            "properties": {
+                "failed": {
+                    "type": "integer"
+                },
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.BatchSelectionResult"
+                    }
+                },
+                "succeeded": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.BatchSelectionResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "index": {
+                    "type": "integer"
+                },
+                "op": {
+                    "type": "string"
+                },
+                "selection": {
+                    "$ref": "#/definitions/models.UserSelection"
+                },
+                "status": {
+                    "description": "HTTP status the equivalent single request would have returned",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.CarryOverRequest": {
+            "type": "object",
+            "required": [
+                "source_month",
+                "target_month"
+            ],
+            "properties": {
+                "link_origin": {
+                    "description": "Record the source selection ID on each copy",
+                    "type": "boolean"
+                },
+                "roles": {
+                    "description": "Candidate roles to copy; defaults to both",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SelectionRole"
+                    }
+                },
+                "source_month": {
+                    "description": "\"YYYY-MM\"",
+                    "type": "string"
+                },
+                "target_month": {
+                    "description": "\"YYYY-MM\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CarryOverResponse": {
+            "type": "object",
+            "properties": {
+                "copied": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.UserSelection"
+                    }
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.CarryOverSkip"
+                    }
+                }
+            }
+        },
+        "models.CarryOverSkip": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                },
+                "source_selection_id": {
+                    "type": "string"
+                },
+                "spotify_item_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreatePlaylistRequest": {
+            "type": "object",
+            "required": [
+                "mode",
+                "year"
+            ],
+            "properties": {
+                "include_candidates": {
+                    "description": "Also add candidates, not just the monthly selections",
+                    "type": "boolean"
+                },
+                "mode": {
+                    "type": "string",
+                    "enum": [
+                        "muse",
+                        "ick"
+                    ]
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "models.CreatePlaylistResponse": {
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "Tracks added to the playlist",
+                    "type": "integer",
+                    "example": 12
+                },
+                "market": {
+                    "description": "Market track availability was checked for",
+                    "type": "string",
+                    "example": "GB"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "Playlist created successfully"
+                },
+                "skipped": {
+                    "description": "Tracks left out, with the reason",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistTrackSkip"
+                    }
+                },
+                "substituted": {
+                    "description": "Tracks replaced by a playable relinked copy",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.PlaylistTrackSubstitution"
+                    }
+                },
+                "url": {
+                    "description": "Spotify URL of the new playlist",
+                    "type": "string"
+                }
+            }
+        },
+        "models.CreateSelectionRequest": {
+            "type": "object",
+            "required": [
+                "item_type",
+                "month_year",
+                "selection_role",
+                "spotify_item_id"
+            ],
+            "properties": {
+                "item_type": {
+                    "description": "\"track\", \"album\", or \"artist\"",
+                    "type": "string",
+                    "enum": [
+                        "track",
+                        "album",
+                        "artist"
+                    ]
+                },
+                "listening_context": {
+                    "description": "Optional",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ListeningContext"
+                        }
+                    ]
+                },
+                "month_year": {
+                    "description": "\"YYYY-MM\"",
+                    "type": "string",
+                    "example": "2024-07"
+                },
+                "mood_tags": {
+                    "description": "Optional",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "notes": {
+                    "description": "Optional (markdown)",
+                    "type": "string"
+                },
+                "selection_role": {
+                    "description": "\"muse_candidate\" or \"ick_candidate\"",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SelectionRole"
+                        }
+                    ]
+                },
+                "spotify_item_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ErrorResponse": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string",
+                    "example": "Selection not found"
+                }
+            }
+        },
+        "models.FeatureDistribution": {
+            "type": "object",
+            "properties": {
+                "histogram": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.HistogramBin"
+                    }
+                },
+                "max": {
+                    "type": "number",
+                    "example": 0.97
+                },
+                "mean": {
+                    "type": "number",
+                    "example": 0.62
+                },
+                "median": {
+                    "type": "number",
+                    "example": 0.64
+                },
+                "min": {
+                    "type": "number",
+                    "example": 0.12
+                },
+                "std_dev": {
+                    "type": "number",
+                    "example": 0.18
+                }
+            }
+        },
+        "models.FollowersObject": {
+            "type": "object",
+            "properties": {
+                "href": {
+                    "description": "Can be null",
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "models.GenreDrift": {
+            "type": "object",
+            "properties": {
+                "change": {
+                    "description": "Share in the last year minus share in the first",
+                    "type": "number",
+                    "example": 0.08
+                },
+                "genre": {
+                    "type": "string",
+                    "example": "hyperpop"
+                },
+                "shares": {
+                    "description": "Every compared year, oldest first, 0 when absent",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.YearShare"
+                    }
+                }
+            }
+        },
+        "models.GenreMonth": {
+            "type": "object",
+            "properties": {
+                "genres": {
+                    "description": "Largest share first, the top 10",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenreShare"
+                    }
+                },
+                "month_year": {
+                    "type": "string",
+                    "example": "2024-03"
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 14
+                },
+                "with_genres": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.GenreShare": {
+            "type": "object",
+            "properties": {
+                "genre": {
+                    "type": "string",
+                    "example": "indie rock"
+                },
+                "share": {
+                    "description": "Weight over the picks with genres",
+                    "type": "number",
+                    "example": 0.21
+                },
+                "weight": {
+                    "type": "number",
+                    "example": 2.5
+                }
+            }
+        },
+        "models.GenreTimelineResponse": {
+            "type": "object",
+            "properties": {
+                "months": {
+                    "description": "January to December, empty months included",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenreMonth"
+                    }
+                },
+                "muse_and_ick": {
+                    "description": "Artists with both Muse and Ick picks",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ArtistRollup"
+                    }
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 96
+                },
+                "selected_only": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "top_artists": {
+                    "description": "Most picks first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ArtistRollup"
+                    }
+                },
+                "top_genres": {
+                    "description": "Over the whole year",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenreShare"
+                    }
+                },
+                "unresolved": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "models.HistogramBin": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "from": {
+                    "type": "number",
+                    "example": 0.5
+                },
+                "to": {
+                    "type": "number",
+                    "example": 0.6
+                }
+            }
+        },
+        "models.ImageObject": {
+            "type": "object",
+            "properties": {
+                "height": {
+                    "description": "Pointer to handle null",
+                    "type": "integer"
+                },
+                "mirror_hash": {
+                    "type": "string"
+                },
+                "url": {
+                    "type": "string"
+                },
+                "width": {
+                    "description": "Pointer to handle null",
+                    "type": "integer"
+                }
+            }
+        },
+        "models.JournalSearchHighlight": {
+            "type": "object",
+            "properties": {
+                "field": {
+                    "description": "\"notes\", \"item_name\", \"album_name\" or \"artist_names\"",
+                    "type": "string"
+                },
+                "snippet": {
+                    "description": "HTML-escaped text with \u003cmark\u003e...\u003c/mark\u003e around matches",
+                    "type": "string"
+                }
+            }
+        },
+        "models.JournalSearchHit": {
+            "type": "object",
+            "properties": {
+                "highlights": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.JournalSearchHighlight"
+                    }
+                },
+                "score": {
+                    "type": "number"
+                },
+                "selection": {
+                    "$ref": "#/definitions/models.UserSelection"
+                }
+            }
+        },
+        "models.LinkedTrack": {
+            "type": "object",
+            "properties": {
+                "external_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "id": {
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.ListeningContext": {
+            "type": "object",
+            "properties": {
+                "activity": {
+                    "description": "e.g., \"Running\", \"Cooking\"",
+                    "type": "string"
+                },
+                "companions": {
+                    "description": "Who the user was with",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "location": {
+                    "description": "e.g., \"Road trip to Cornwall\"",
+                    "type": "string"
+                }
+            }
+        },
+        "models.MoodAnalyticsResponse": {
+            "type": "object",
+            "properties": {
+                "differences": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "number"
+                    }
+                },
+                "icks": {
+                    "$ref": "#/definitions/models.MoodGroupStats"
+                },
+                "muses": {
+                    "$ref": "#/definitions/models.MoodGroupStats"
+                },
+                "selected_only": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "models.MoodGroupStats": {
+            "type": "object",
+            "properties": {
+                "features": {
+                    "description": "By feature name; empty when no track has features",
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.FeatureDistribution"
+                    }
+                },
+                "tracks": {
+                    "description": "Distinct recordings selected in the group",
+                    "type": "integer",
+                    "example": 14
+                },
+                "with_features": {
+                    "description": "Of those, how many have audio features cached",
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.MoodTagCount": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "tag": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.NewVsCatalogStats": {
+            "type": "object",
+            "properties": {
+                "catalog": {
+                    "type": "integer",
+                    "example": 15
+                },
+                "median_gap_months": {
+                    "description": "Over picks with month or day precision",
+                    "type": "number",
+                    "example": 30
+                },
+                "new_releases": {
+                    "type": "integer",
+                    "example": 8
+                },
+                "undetermined": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "window_months": {
+                    "type": "integer",
+                    "example": 12
+                }
+            }
+        },
+        "models.NotesRevision": {
+            "type": "object",
+            "properties": {
+                "edited_at": {
+                    "description": "When this version was replaced",
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "notes": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlaylistTrackSkip": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string",
+                    "example": "not available in GB"
+                },
+                "spotify_item_id": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.PlaylistTrackSubstitution": {
+            "type": "object",
+            "properties": {
+                "added_id": {
+                    "description": "Playable copy that was added instead",
+                    "type": "string"
+                },
+                "spotify_item_id": {
+                    "description": "Track the user selected",
+                    "type": "string"
+                }
+            }
+        },
+        "models.RecurringArtist": {
+            "type": "object",
+            "properties": {
+                "icks": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "muses": {
+                    "type": "integer",
+                    "example": 6
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Rick Astley"
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "spotify_id": {
+                    "type": "string",
+                    "example": "0gxyHStUsqpMadRV0Di1Qt"
+                },
+                "years": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.ReleaseBucket": {
+            "type": "object",
+            "properties": {
+                "icks": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "label": {
+                    "type": "string",
+                    "example": "1990s"
+                },
+                "muses": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "start": {
+                    "description": "First year of the decade, or the release year",
+                    "type": "integer",
+                    "example": 1990
+                }
+            }
+        },
+        "models.ReleaseEraPick": {
+            "type": "object",
+            "properties": {
+                "artist_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "item_name": {
+                    "type": "string",
+                    "example": "Never Gonna Give You Up"
+                },
+                "item_type": {
+                    "type": "string",
+                    "example": "track"
+                },
+                "month_year": {
+                    "type": "string",
+                    "example": "2024-03"
+                },
+                "release_date": {
+                    "type": "string",
+                    "example": "1987-07-27"
+                },
+                "release_date_precision": {
+                    "type": "string",
+                    "example": "day"
+                },
+                "selection_id": {
+                    "type": "string",
+                    "example": "6634f0c2a1b2c3d4e5f60718"
+                },
+                "selection_role": {
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SelectionRole"
+                        }
+                    ],
+                    "example": "muse_selected"
+                },
+                "spotify_item_id": {
+                    "type": "string",
+                    "example": "4uLU6hMCjMI75M1A2tKUQC"
+                }
+            }
+        },
+        "models.ReleaseEraResponse": {
+            "type": "object",
+            "properties": {
+                "by_decade": {
+                    "description": "Oldest decade first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReleaseBucket"
+                    }
+                },
+                "by_release_year": {
+                    "description": "Oldest year first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ReleaseBucket"
+                    }
+                },
+                "new_vs_catalog": {
+                    "$ref": "#/definitions/models.NewVsCatalogStats"
+                },
+                "newest_muse": {
+                    "$ref": "#/definitions/models.ReleaseEraPick"
+                },
+                "oldest_muse": {
+                    "$ref": "#/definitions/models.ReleaseEraPick"
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 27
+                },
+                "selected_only": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "with_release_date": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "models.RepeatMuse": {
+            "type": "object",
+            "properties": {
+                "artist_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "item_name": {
+                    "type": "string",
+                    "example": "Never Gonna Give You Up"
+                },
+                "item_type": {
+                    "type": "string",
+                    "example": "track"
+                },
+                "spotify_item_id": {
+                    "type": "string",
+                    "example": "4uLU6hMCjMI75M1A2tKUQC"
+                },
+                "years": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.Restrictions": {
+            "type": "object",
+            "properties": {
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.RoleChange": {
+            "type": "object",
+            "properties": {
+                "artist_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from_month": {
+                    "description": "First month in the earlier role",
+                    "type": "string",
+                    "example": "2023-04"
+                },
+                "item_name": {
+                    "type": "string",
+                    "example": "Never Gonna Give You Up"
+                },
+                "item_type": {
+                    "type": "string",
+                    "example": "track"
+                },
+                "spotify_item_id": {
+                    "type": "string",
+                    "example": "4uLU6hMCjMI75M1A2tKUQC"
+                },
+                "to_month": {
+                    "description": "First month in the later role, in a later year",
+                    "type": "string",
+                    "example": "2024-09"
+                }
+            }
+        },
+        "models.SelectionRole": {
+            "type": "string",
+            "enum": [
+                "muse_candidate",
+                "ick_candidate",
+                "muse_selected",
+                "ick_selected"
+            ],
+            "x-enum-varnames": [
+                "RoleMuseCandidate",
+                "RoleIckCandidate",
+                "RoleMuseSelected",
+                "RoleIckSelected"
+            ]
+        },
+        "models.SelectionStreak": {
+            "type": "object",
+            "properties": {
+                "artist_names": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "from": {
+                    "type": "string",
+                    "example": "2024-11"
+                },
+                "item_name": {
+                    "type": "string",
+                    "example": "Never Gonna Give You Up"
+                },
+                "item_type": {
+                    "type": "string",
+                    "example": "track"
+                },
+                "months": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "muse",
+                        "ick"
+                    ],
+                    "example": "muse"
+                },
+                "spotify_item_id": {
+                    "type": "string",
+                    "example": "4uLU6hMCjMI75M1A2tKUQC"
+                },
+                "to": {
+                    "type": "string",
+                    "example": "2025-02"
+                }
+            }
+        },
+        "models.SimplifiedAlbum": {
+            "type": "object",
+            "properties": {
+                "album_type": {
                     "type": "string"
                 },
-                "selection_role": {
-                    "description": "Pointer to distinguish between not provided and empty string",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/models.SelectionRole"
-                        }
-                    ]
+                "artists": {
+                    "description": "Simplified artist objects",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SimplifiedArtist"
+                    }
+                },
+                "available_markets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "external_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Spotify Album ID",
+                    "type": "string"
+                },
+                "images": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageObject"
+                    }
+                },
+                "name": {
+                    "type": "string"
+                },
+                "release_date": {
+                    "type": "string"
+                },
+                "release_date_precision": {
+                    "type": "string"
+                },
+                "restrictions": {
+                    "$ref": "#/definitions/models.Restrictions"
+                },
+                "total_tracks": {
+                    "type": "integer"
+                },
+                "type": {
+                    "description": "\"album\"",
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
                 }
             }
         },
-        "models.CreateSelectionRequest": {
+        "models.SimplifiedArtist": {
+            "type": "object",
+            "properties": {
+                "external_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "type": "string"
+                },
+                "id": {
+                    "description": "Spotify Artist ID",
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "type": {
+                    "description": "\"artist\"",
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SpotifyArtist": {
+            "type": "object",
+            "properties": {
+                "external_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "followers": {
+                    "$ref": "#/definitions/models.FollowersObject"
+                },
+                "genres": {
+                    "description": "Often requires fetching full artist",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "href": {
+                    "type": "string"
+                },
+                "images": {
+                    "description": "Often requires fetching full artist",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.ImageObject"
+                    }
+                },
+                "last_fetched_at": {
+                    "description": "Track when we last updated this from Spotify",
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "description": "Often requires fetching full artist",
+                    "type": "integer"
+                },
+                "spotify_id": {
+                    "description": "Use Spotify ID as the document ID",
+                    "type": "string"
+                },
+                "type": {
+                    "description": "\"artist\"",
+                    "type": "string"
+                },
+                "uri": {
+                    "type": "string"
+                }
+            }
+        },
+        "models.SpotifyCodeExchangeRequest": {
             "type": "object",
             "required": [
-                "item_type",
-                "month_year",
-                "selection_role",
-                "spotify_item_id"
+                "code",
+                "code_verifier"
             ],
             "properties": {
-                "item_type": {
-                    "description": "\"track\", \"album\", or \"artist\"",
+                "code": {
+                    "description": "Authorization code from Spotify's redirect",
                     "type": "string"
                 },
-                "month_year": {
-                    "description": "\"YYYY-MM\"",
+                "code_verifier": {
+                    "description": "PKCE verifier the frontend generated the challenge from",
+                    "type": "string"
+                }
+            }
+        },
+        "models.SpotifyTokenResponse": {
+            "type": "object",
+            "properties": {
+                "access_token": {
                     "type": "string"
                 },
-                "notes": {
-                    "description": "Optional",
+                "expires_in": {
+                    "description": "Seconds",
+                    "type": "integer"
+                },
+                "scope": {
                     "type": "string"
                 },
-                "selection_role": {
-                    "description": "\"muse_candidate\" or \"ick_candidate\"",
+                "token_type": {
+                    "type": "string",
+                    "example": "Bearer"
+                }
+            }
+        },
+        "models.SpotifyTrack": {
+            "type": "object",
+            "properties": {
+                "album": {
+                    "$ref": "#/definitions/models.SimplifiedAlbum"
+                },
+                "artists": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SimplifiedArtist"
+                    }
+                },
+                "audio_features": {
+                    "description": "Set during sync; a sync that can't fetch features keeps the cached ones",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/models.SelectionRole"
+                            "$ref": "#/definitions/models.AudioFeatures"
                         }
                     ]
                 },
-                "spotify_item_id": {
+                "available_markets": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "canonical_id": {
+                    "description": "Groups copies of the same recording, see TrackCanonicalID",
+                    "type": "string"
+                },
+                "disc_number": {
+                    "type": "integer"
+                },
+                "duration_ms": {
+                    "type": "integer"
+                },
+                "explicit": {
+                    "type": "boolean"
+                },
+                "external_ids": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "external_urls": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
+                "is_local": {
+                    "description": "Local file added to a playlist, not a catalogue track",
+                    "type": "boolean"
+                },
+                "is_playable": {
+                    "description": "IsPlayable and LinkedFrom are only reported when the track is fetched for a market (see Market).\nThe shared cache is fetched without one and relies on AvailableMarkets instead.",
+                    "type": "boolean"
+                },
+                "last_fetched_at": {
+                    "type": "integer"
+                },
+                "linked_from": {
+                    "description": "Original track when Spotify relinked to a playable copy",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.LinkedTrack"
+                        }
+                    ]
+                },
+                "market": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "popularity": {
+                    "type": "integer"
+                },
+                "preview_url": {
+                    "type": "string"
+                },
+                "spotify_id": {
+                    "description": "Use Spotify ID as the document ID",
+                    "type": "string"
+                },
+                "track_number": {
+                    "type": "integer"
+                },
+                "type": {
+                    "type": "string"
+                },
+                "uri": {
                     "type": "string"
                 }
             }
         },
-        "models.SelectionRole": {
-            "type": "string",
-            "enum": [
-                "muse_candidate",
-                "ick_candidate",
-                "muse_selected",
-                "ick_selected"
-            ],
-            "x-enum-varnames": [
-                "RoleMuseCandidate",
-                "RoleIckCandidate",
-                "RoleMuseSelected",
-                "RoleIckSelected"
-            ]
+        "models.UpdateSelectionRequest": {
+            "type": "object",
+            "properties": {
+                "listening_context": {
+                    "description": "Pointer; an empty object clears the context",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ListeningContext"
+                        }
+                    ]
+                },
+                "mood_tags": {
+                    "description": "Pointer; an empty list clears the tags",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "notes": {
+                    "description": "Pointer",
+                    "type": "string"
+                },
+                "selection_role": {
+                    "description": "Pointer to distinguish between not provided and empty string",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.SelectionRole"
+                        }
+                    ]
+                }
+            }
         },
         "models.UserSelection": {
             "type": "object",
             "properties": {
                 "added_at": {
                     "description": "When the user first added this item for this role/month",
-                    "type": "integer"
+                    "type": "string",
+                    "format": "date-time"
+                },
+                "album_name": {
+                    "description": "Album name (tracks only)",
+                    "type": "string"
+                },
+                "artist_names": {
+                    "description": "Names of the credited artists",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "canonical_id": {
+                    "description": "Denormalized canonical ID of the item, so copies of the same recording or release count as one (see GroupKey)",
+                    "type": "string",
+                    "example": "isrc:GBAYE0601498"
+                },
+                "carried_over_from": {
+                    "description": "Set when this selection was created by carrying a candidate over from another month",
+                    "type": "string"
                 },
                 "id": {
+                    "type": "string",
+                    "example": "6650f1c2a4b7e8d9c0a1b2c3"
+                },
+                "item_name": {
+                    "description": "Denormalized from the Spotify cache at creation time so notes and metadata can share one text index",
                     "type": "string"
                 },
                 "item_type": {
                     "description": "\"track\", \"album\", or \"artist\"",
-                    "type": "string"
+                    "type": "string",
+                    "enum": [
+                        "track",
+                        "album",
+                        "artist"
+                    ]
+                },
+                "listening_context": {
+                    "description": "Optional \"where/with whom\" context",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/models.ListeningContext"
+                        }
+                    ]
                 },
                 "month_year": {
                     "description": "Format: \"YYYY-MM\", e.g., \"2024-07\"",
-                    "type": "string"
+                    "type": "string",
+                    "example": "2024-07"
+                },
+                "mood_tags": {
+                    "description": "Journal fields",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
                 "notes": {
-                    "description": "Optional user notes",
+                    "description": "Optional user story (markdown)",
                     "type": "string"
                 },
+                "notes_history": {
+                    "description": "Previous versions of Notes, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.NotesRevision"
+                    }
+                },
                 "selection_role": {
                     "description": "\"muse_candidate\", \"ick_candidate\", \"muse_selected\", \"ick_selected\"",
                     "allOf": [
@@ -415,13 +3027,132 @@ const docTemplate = `{
                 },
                 "updated_at": {
                     "description": "When the selection was last modified",
-                    "type": "integer"
+                    "type": "string",
+                    "format": "date-time"
                 },
                 "user_id": {
                     "description": "Clerk User ID (sub)",
                     "type": "string"
                 }
             }
+        },
+        "models.WelcomeResponse": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string",
+                    "example": "Welcome to Museick API"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "success"
+                }
+            }
+        },
+        "models.YearComparisonResponse": {
+            "type": "object",
+            "properties": {
+                "falls_from_grace": {
+                    "description": "Muses that became Icks",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RoleChange"
+                    }
+                },
+                "genre_drift": {
+                    "description": "Largest change first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenreDrift"
+                    }
+                },
+                "recurring_artists": {
+                    "description": "Most years first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RecurringArtist"
+                    }
+                },
+                "redemptions": {
+                    "description": "Icks that became Muses",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RoleChange"
+                    }
+                },
+                "repeat_muses": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.RepeatMuse"
+                    }
+                },
+                "selected_only": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "streaks": {
+                    "description": "Longest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.SelectionStreak"
+                    }
+                },
+                "summaries": {
+                    "description": "One per year, oldest first",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.YearSummary"
+                    }
+                },
+                "years": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.YearShare": {
+            "type": "object",
+            "properties": {
+                "share": {
+                    "type": "number",
+                    "example": 0.12
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
+        },
+        "models.YearSummary": {
+            "type": "object",
+            "properties": {
+                "icks": {
+                    "description": "A pick that was both counts in Muses and Icks",
+                    "type": "integer",
+                    "example": 36
+                },
+                "muses": {
+                    "type": "integer",
+                    "example": 50
+                },
+                "picks": {
+                    "type": "integer",
+                    "example": 84
+                },
+                "top_genres": {
+                    "description": "Largest share first, the top 5",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.GenreShare"
+                    }
+                },
+                "year": {
+                    "type": "integer",
+                    "example": 2024
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -437,7 +3168,7 @@ const docTemplate = `{
 // SwaggerInfo holds exported Swagger Info so clients can modify it
 var SwaggerInfo = &swag.Spec{
 	Version:          "1.0",
-	Host:             "localhost:8080",
+	Host:             "",
 	BasePath:         "/",
 	Schemes:          []string{"http", "https"},
 	Title:            "Museick API",
@@ -1,7 +1,10 @@
 package initializers
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -9,14 +12,45 @@ import (
 // Config holds all configuration for the application.
 // The values are read by viper from a config file or environment variables.
 type Config struct {
+	AppEnv string `mapstructure:"APP_ENV"` // Profile: dev, test or prod (default dev)
+
 	MongoHost     string `mapstructure:"MONGO_HOST"`
 	MongoPort     string `mapstructure:"MONGO_PORT"`
 	MongoDBName   string `mapstructure:"MONGO_DB_NAME"`
 	MongoUser     string `mapstructure:"MONGO_USER"`
 	MongoPassword string `mapstructure:"MONGO_PASSWORD"`
 
-	ServerPort   string   `mapstructure:"PORT"`
-	ClientOrigin []string `mapstructure:"CLIENT_ORIGIN"` // Frontend URL(s) for CORS
+	// MONGO_URI (mongodb:// or mongodb+srv://) takes precedence over host, port, user and password.
+	// The settings below override the matching URI options when set.
+	MongoURI                    string        `mapstructure:"MONGO_URI"`
+	MongoAuthSource             string        `mapstructure:"MONGO_AUTH_SOURCE"`              // Used with the discrete fields (default admin)
+	MongoReplicaSet             string        `mapstructure:"MONGO_REPLICA_SET"`              // Replica set name (required for transactions)
+	MongoReadPreference         string        `mapstructure:"MONGO_READ_PREFERENCE"`          // primary, primaryPreferred, secondary, secondaryPreferred or nearest
+	MongoTLS                    bool          `mapstructure:"MONGO_TLS"`                      // Enable TLS (implied by mongodb+srv URIs)
+	MongoTLSCAFile              string        `mapstructure:"MONGO_TLS_CA_FILE"`              // PEM bundle of CAs to trust
+	MongoTLSCertificateKeyFile  string        `mapstructure:"MONGO_TLS_CERTIFICATE_KEY_FILE"` // PEM client certificate and key, for x.509 auth
+	MongoTLSInsecure            bool          `mapstructure:"MONGO_TLS_INSECURE"`             // Skip certificate verification (development only)
+	MongoMaxPoolSize            uint64        `mapstructure:"MONGO_MAX_POOL_SIZE"`            // Driver default 100
+	MongoMinPoolSize            uint64        `mapstructure:"MONGO_MIN_POOL_SIZE"`            // Driver default 0
+	MongoMaxConnIdleTime        time.Duration `mapstructure:"MONGO_MAX_CONN_IDLE_TIME"`       // e.g. 5m (driver default: no limit)
+	MongoConnectTimeout         time.Duration `mapstructure:"MONGO_CONNECT_TIMEOUT"`          // Default 10s
+	MongoServerSelectionTimeout time.Duration `mapstructure:"MONGO_SERVER_SELECTION_TIMEOUT"` // Default 10s
+	MongoRetryWrites            *bool         `mapstructure:"MONGO_RETRY_WRITES"`             // Driver default true
+
+	ServerPort   string   `mapstructure:"PORT"`          // Default 8080
+	ClientOrigin []string `mapstructure:"CLIENT_ORIGIN"` // Frontend origin(s) for CORS, comma-separated (dev default http://localhost:5173)
+	// Reverse proxies (IPs or CIDRs, comma-separated) whose X-Forwarded-For is believed when resolving the client IP
+	// for per-IP rate limits and logs. Default none: the peer address is the client.
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+
+	// HTTP server timeouts, as durations such as 30s or 2m
+	HTTPReadHeaderTimeout time.Duration `mapstructure:"HTTP_READ_HEADER_TIMEOUT"` // Default 10s
+	HTTPReadTimeout       time.Duration `mapstructure:"HTTP_READ_TIMEOUT"`        // Default 30s
+	HTTPWriteTimeout      time.Duration `mapstructure:"HTTP_WRITE_TIMEOUT"`       // Default 60s
+	HTTPIdleTimeout       time.Duration `mapstructure:"HTTP_IDLE_TIMEOUT"`        // Default 2m
+	ShutdownTimeout       time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`         // Time allowed to drain requests and stop workers (default 15s)
+	// How long /readyz fails before the listener closes on shutdown, so load balancers notice first (default 5s, 0 in test)
+	ShutdownReadinessDelay time.Duration `mapstructure:"SHUTDOWN_READINESS_DELAY"`
 
 	ClerkSecretKey   string `mapstructure:"CLERK_SECRET_KEY"`
 	ClerkFrontendAPI string `mapstructure:"CLERK_FRONTEND_API"`
@@ -24,75 +58,189 @@ type Config struct {
 	SpotifyClientID     string `mapstructure:"SPOTIFY_CLIENT_ID"`
 	SpotifyClientSecret string `mapstructure:"SPOTIFY_CLIENT_SECRET"`
 	SpotifyRedirectURL  string `mapstructure:"SPOTIFY_REDIRECT_URL"` // URL Spotify redirects to after auth
+
+	// How old a cached Spotify item may get before it is re-fetched (default 24h)
+	CacheRefreshThreshold time.Duration `mapstructure:"CACHE_REFRESH_THRESHOLD"`
+	// Background removal of cached Spotify items no selection references (default on, every 24h,
+	// for items not re-fetched for 720h; off in test)
+	CacheGCEnabled  bool          `mapstructure:"CACHE_GC_ENABLED"`
+	CacheGCInterval time.Duration `mapstructure:"CACHE_GC_INTERVAL"`
+	CacheGCGrace    time.Duration `mapstructure:"CACHE_GC_GRACE"`
+	// Mirroring of cached album and artist images, served from /media/:hash (default on every 10m; off in test)
+	MediaStore          string        `mapstructure:"MEDIA_STORE"` // gridfs or filesystem (default gridfs)
+	MediaDir            string        `mapstructure:"MEDIA_DIR"`   // Directory for the filesystem store (default media)
+	MediaMirrorEnabled  bool          `mapstructure:"MEDIA_MIRROR_ENABLED"`
+	MediaMirrorInterval time.Duration `mapstructure:"MEDIA_MIRROR_INTERVAL"`
+
+	AudioFeaturesProvider string `mapstructure:"AUDIO_FEATURES_PROVIDER"` // spotify, fixture or none (default none)
+	AudioFeaturesFixture  string `mapstructure:"AUDIO_FEATURES_FIXTURE"`  // JSON file of track ID -> features, for the fixture provider
+
+	LogLevel  string `mapstructure:"LOG_LEVEL"`  // debug, info, warn or error (default info, warn in test)
+	LogFormat string `mapstructure:"LOG_FORMAT"` // text or json (default text, json in prod)
+
+	TracingExporter     string  `mapstructure:"TRACING_EXPORTER"`      // none, stdout, file or otlp (default none)
+	TracingServiceName  string  `mapstructure:"TRACING_SERVICE_NAME"`  // Default museick-backend
+	TracingSampleRatio  float64 `mapstructure:"TRACING_SAMPLE_RATIO"`  // 0 < ratio <= 1 (default 1)
+	TracingFilePath     string  `mapstructure:"TRACING_FILE_PATH"`     // Output for the file exporter
+	TracingOTLPEndpoint string  `mapstructure:"TRACING_OTLP_ENDPOINT"` // host:port of an OTLP/HTTP collector (default localhost:4318)
+	TracingOTLPInsecure bool    `mapstructure:"TRACING_OTLP_INSECURE"` // Send OTLP over plain HTTP
+
+	RateLimitStore   string `mapstructure:"RATE_LIMIT_STORE"`   // memory or mongo (default memory; use mongo when running several instances)
+	RateLimitPublic  string `mapstructure:"RATE_LIMIT_PUBLIC"`  // Per IP on public routes, e.g. 60/m (default 120/m, "off" disables; off in test)
+	RateLimitAPI     string `mapstructure:"RATE_LIMIT_API"`     // Per user on /api routes (default 300/m)
+	RateLimitSpotify string `mapstructure:"RATE_LIMIT_SPOTIFY"` // Per user on routes that call Spotify (default 30/m,10)
+
+	IdempotencyTTL time.Duration `mapstructure:"IDEMPOTENCY_TTL"` // How long Idempotency-Key responses are kept (default 24h)
+
+	MigrateOnStart bool `mapstructure:"MIGRATE_ON_START"` // Apply pending schema migrations before serving (default false, true in test)
 }
 
 // Global config variable
 var config Config
 
 // LoadConfig reads configuration from file or environment variables.
+// Values come, from highest to lowest precedence, from environment variables, app.<profile>.env,
+// app.env, the profile's defaults and finally the base defaults. LoadConfig only reports errors
+// reading the configuration; call Config.Validate before relying on it.
 func LoadConfig(path string) (err error) {
-	// log.Println("--- Checking ENV VARS directly via os.Getenv ---")
-	// log.Printf("os.Getenv(\"MONGO_HOST\"): [%s]", os.Getenv("MONGO_HOST"))
-	// log.Printf("os.Getenv(\"PORT\"): [%s]", os.Getenv("PORT"))
-	// log.Println("--- End direct ENV VAR check ---")
-
 	viper.AddConfigPath(path)  // Path to look for the config file in
 	viper.SetConfigType("env") // REQUIRED if the config file does not have the extension in the name
 	viper.SetConfigName("app") // Name of config file (without extension)
 
 	viper.AutomaticEnv() // Read Env variables
 
+	// Bind every key explicitly so environment variables are picked up even when app.env
+	// doesn't mention them (AutomaticEnv alone only applies to keys viper already knows)
+	for _, key := range configKeys() {
+		if err := viper.BindEnv(key); err != nil {
+			return fmt.Errorf("binding %s: %w", key, err)
+		}
+	}
+
 	err = viper.ReadInConfig() // Find and read the config file
 	if err != nil {
-		// Handle errors reading the config file
-		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			// Config file not found; ignore error if desired and rely solely on ENV vars
-			log.Println("Config file 'app.env' not found, relying on environment variables.")
-
-			// Explicitly bind environment variables if file not found
-			// This ensures viper knows about the keys even without a file
-			keys := []string{
-				"MONGO_HOST", "MONGO_PORT", "MONGO_DB_NAME", "MONGO_USER", "MONGO_PASSWORD",
-				"PORT", "CLIENT_ORIGIN",
-				"CLERK_SECRET_KEY", "CLERK_FRONTEND_API",
-				"SPOTIFY_CLIENT_ID", "SPOTIFY_CLIENT_SECRET", "SPOTIFY_REDIRECT_URL",
-			}
-			for _, key := range keys {
-				if bindErr := viper.BindEnv(key); bindErr != nil {
-					log.Printf("BindEnv error for %s: %v", key, bindErr)
-					// Make bind error fatal as there are no optional vars
-					return bindErr
-				}
-			}
-			err = nil // Reset error since file not found is acceptable here
-		} else {
+		if _, ok := err.(viper.ConfigFileNotFoundError); !ok {
 			// Config file was found but another error was produced
-			log.Printf("Error reading config file: %s", err)
-			return
+			return fmt.Errorf("reading app.env: %w", err)
 		}
+		// Config file not found; rely solely on ENV vars
+		slog.Info("config file 'app.env' not found, relying on environment variables")
 	}
 
-	err = viper.Unmarshal(&config) // Unmarshal config into struct
-	if err != nil {
-		log.Printf("!!! Viper Unmarshal Error: %v", err)
-	} else {
-		// Optional: Log loaded config (be careful with secrets)
-		log.Printf("--- Config loaded via Viper ---")
-		log.Printf("MongoHost: [%s]", config.MongoHost)
-		log.Printf("MongoDBName: [%s]", config.MongoDBName)
-		log.Printf("ServerPort: [%s]", config.ServerPort)
-		// Log the slice of origins
-		log.Printf("ClientOrigin: %v", config.ClientOrigin) // Use %v for slice
-		log.Printf("ClerkFrontendAPI: [%s]", config.ClerkFrontendAPI)
-		// Avoid logging secrets directly
-		log.Printf("SpotifyClientID: [%s]", config.SpotifyClientID)
-		log.Printf("SpotifyRedirectURL: [%s]", config.SpotifyRedirectURL)
-		// log.Printf("SpotifyClientSecret: [REDACTED]")
-		// log.Printf("ClerkSecretKey: [REDACTED]")
-		log.Printf("--- End Config ---")
+	// The profile can come from the environment or app.env, so it is only known after reading the file
+	profile := strings.ToLower(strings.TrimSpace(viper.GetString("APP_ENV")))
+	if profile == "" {
+		profile = ProfileDev
+	}
+	defaults, ok := profileDefaults[profile]
+	if !ok {
+		return fmt.Errorf("APP_ENV: unknown profile %q, expected %s, %s or %s", profile, ProfileDev, ProfileTest, ProfileProd)
+	}
+	viper.Set("APP_ENV", profile)
+	for key, value := range baseDefaults {
+		viper.SetDefault(key, value)
+	}
+	for key, value := range defaults {
+		viper.SetDefault(key, value)
+	}
+
+	// Optional per-profile overrides, e.g. app.prod.env
+	viper.SetConfigName("app." + profile)
+	if mergeErr := viper.MergeInConfig(); mergeErr != nil {
+		if _, ok := mergeErr.(viper.ConfigFileNotFoundError); !ok {
+			return fmt.Errorf("reading app.%s.env: %w", profile, mergeErr)
+		}
 	}
 
-	return // Return unmarshal error if any
+	if err = viper.Unmarshal(&config); err != nil { // Unmarshal config into struct
+		return fmt.Errorf("decoding configuration: %w", err)
+	}
+	config.normalize()
+	return nil
+}
+
+// normalize tidies list values.
+func (c *Config) normalize() {
+	// List values may arrive as one comma-separated string or as a list depending on their source
+	var origins []string
+	for _, value := range c.ClientOrigin {
+		for _, origin := range strings.Split(value, ",") {
+			if origin = strings.TrimRight(strings.TrimSpace(origin), "/"); origin != "" {
+				origins = append(origins, origin)
+			}
+		}
+	}
+	c.ClientOrigin = origins
+	var proxies []string
+	for _, value := range c.TrustedProxies {
+		for _, proxy := range strings.Split(value, ",") {
+			if proxy = strings.TrimSpace(proxy); proxy != "" {
+				proxies = append(proxies, proxy)
+			}
+		}
+	}
+	c.TrustedProxies = proxies
+}
+
+// LogValue implements slog.LogValuer so the config can be logged as a group.
+// Secrets (passwords, client secrets, keys) are deliberately left out.
+func (c Config) LogValue() slog.Value {
+	retryWrites := "default"
+	if c.MongoRetryWrites != nil {
+		retryWrites = fmt.Sprint(*c.MongoRetryWrites)
+	}
+	return slog.GroupValue(
+		slog.String("app_env", c.AppEnv),
+		slog.String("mongo_uri", RedactMongoURI(c.MongoURI)),
+		slog.String("mongo_host", c.MongoHost),
+		slog.String("mongo_port", c.MongoPort),
+		slog.String("mongo_db_name", c.MongoDBName),
+		slog.String("mongo_replica_set", c.MongoReplicaSet),
+		slog.String("mongo_read_preference", c.MongoReadPreference),
+		slog.Bool("mongo_tls", c.MongoTLS),
+		slog.String("mongo_tls_ca_file", c.MongoTLSCAFile),
+		slog.Bool("mongo_tls_insecure", c.MongoTLSInsecure),
+		slog.Uint64("mongo_max_pool_size", c.MongoMaxPoolSize),
+		slog.Uint64("mongo_min_pool_size", c.MongoMinPoolSize),
+		slog.Duration("mongo_connect_timeout", c.MongoConnectTimeout),
+		slog.Duration("mongo_server_selection_timeout", c.MongoServerSelectionTimeout),
+		slog.String("mongo_retry_writes", retryWrites),
+		slog.String("server_port", c.ServerPort),
+		slog.Any("client_origin", c.ClientOrigin),
+		slog.Any("trusted_proxies", c.TrustedProxies),
+		slog.Duration("http_read_header_timeout", c.HTTPReadHeaderTimeout),
+		slog.Duration("http_read_timeout", c.HTTPReadTimeout),
+		slog.Duration("http_write_timeout", c.HTTPWriteTimeout),
+		slog.Duration("http_idle_timeout", c.HTTPIdleTimeout),
+		slog.Duration("shutdown_timeout", c.ShutdownTimeout),
+		slog.Duration("shutdown_readiness_delay", c.ShutdownReadinessDelay),
+		slog.String("clerk_frontend_api", c.ClerkFrontendAPI),
+		slog.String("spotify_client_id", c.SpotifyClientID),
+		slog.String("spotify_redirect_url", c.SpotifyRedirectURL),
+		slog.Duration("cache_refresh_threshold", c.CacheRefreshThreshold),
+		slog.Bool("cache_gc_enabled", c.CacheGCEnabled),
+		slog.Duration("cache_gc_interval", c.CacheGCInterval),
+		slog.Duration("cache_gc_grace", c.CacheGCGrace),
+		slog.String("media_store", c.MediaStore),
+		slog.String("media_dir", c.MediaDir),
+		slog.Bool("media_mirror_enabled", c.MediaMirrorEnabled),
+		slog.Duration("media_mirror_interval", c.MediaMirrorInterval),
+		slog.String("audio_features_provider", c.AudioFeaturesProvider),
+		slog.String("audio_features_fixture", c.AudioFeaturesFixture),
+		slog.String("log_level", c.LogLevel),
+		slog.String("log_format", c.LogFormat),
+		slog.String("tracing_exporter", c.TracingExporter),
+		slog.String("tracing_service_name", c.TracingServiceName),
+		slog.Float64("tracing_sample_ratio", c.TracingSampleRatio),
+		slog.String("tracing_file_path", c.TracingFilePath),
+		slog.String("tracing_otlp_endpoint", c.TracingOTLPEndpoint),
+		slog.String("rate_limit_store", c.RateLimitStore),
+		slog.String("rate_limit_public", c.RateLimitPublic),
+		slog.String("rate_limit_api", c.RateLimitAPI),
+		slog.String("rate_limit_spotify", c.RateLimitSpotify),
+		slog.Duration("idempotency_ttl", c.IdempotencyTTL),
+		slog.Bool("migrate_on_start", c.MigrateOnStart),
+	)
 }
 
 // GetConfig returns the loaded configuration.
@@ -2,40 +2,214 @@ package initializers
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
-	"log"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"go.opentelemetry.io/contrib/instrumentation/go.mongodb.org/mongo-driver/mongo/otelmongo"
+)
+
+// Defaults applied when neither the config nor MONGO_URI sets a timeout.
+const (
+	defaultMongoConnectTimeout         = 10 * time.Second
+	defaultMongoServerSelectionTimeout = 10 * time.Second
 )
 
 // NewMongoConnection establishes a connection to MongoDB using the provided configuration.
 func NewMongoConnection(cfg *Config) (*mongo.Client, error) {
-	// Construct MongoDB connection URI
-	uri := fmt.Sprintf("mongodb://%s:%s@%s:%s/?authSource=admin",
-		cfg.MongoUser, cfg.MongoPassword, cfg.MongoHost, cfg.MongoPort,
-	)
-
-	// Set client options
-	clientOpts := options.Client().ApplyURI(uri)
+	clientOpts, err := MongoClientOptions(cfg)
+	if err != nil {
+		return nil, err
+	}
+	clientOpts.SetMonitor(chainCommandMonitors(
+		metrics.NewMongoCommandMonitor(),                                   // Command timings for /metrics
+		otelmongo.NewMonitor(otelmongo.WithCommandAttributeDisabled(true)), // A span per command; bodies are left out as they hold user notes
+	))
 
-	// Connect to MongoDB with a timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Connecting is lazy, so allow the ping enough time to select a server and open a connection
+	ctx, cancel := context.WithTimeout(context.Background(), *clientOpts.ServerSelectionTimeout+*clientOpts.ConnectTimeout)
 	defer cancel()
 
 	client, err := mongo.Connect(ctx, clientOpts)
 	if err != nil {
-		return nil, fmt.Errorf("failed to connect to MongoDB: %w", err)
+		return nil, fmt.Errorf("failed to connect to MongoDB at %s: %w", RedactMongoURI(clientOpts.GetURI()), err)
 	}
 
 	// Ping the primary to verify the connection.
 	if err := client.Ping(ctx, nil); err != nil {
 		// Close the client if ping fails
 		client.Disconnect(context.Background()) // Use a background context for disconnect
-		return nil, fmt.Errorf("Mongo ping failed: %w", err)
+		return nil, fmt.Errorf("Mongo ping failed for %s: %w", RedactMongoURI(clientOpts.GetURI()), err)
 	}
 
-	log.Println("✅ Connected to MongoDB")
 	return client, nil
 }
+
+// MongoClientOptions validates the MongoDB settings and turns them into driver options.
+// MONGO_URI is used as-is when set; otherwise a URI is built from the discrete host, port and credential fields.
+// Explicit settings (TLS, pool sizes, timeouts, ...) are applied on top and win over the URI's own options.
+func MongoClientOptions(cfg *Config) (*options.ClientOptions, error) {
+	uri := cfg.MongoURI
+	if uri == "" {
+		if cfg.MongoHost == "" {
+			return nil, errors.New("MongoDB is not configured: set MONGO_URI or MONGO_HOST")
+		}
+		u := url.URL{Scheme: "mongodb", Host: cfg.MongoHost, Path: "/"}
+		if cfg.MongoPort != "" {
+			u.Host = net.JoinHostPort(cfg.MongoHost, cfg.MongoPort)
+		}
+		if cfg.MongoUser != "" {
+			// url.UserPassword escapes credentials containing reserved characters like @ or /
+			u.User = url.UserPassword(cfg.MongoUser, cfg.MongoPassword)
+			authSource := cfg.MongoAuthSource
+			if authSource == "" {
+				authSource = "admin"
+			}
+			u.RawQuery = url.Values{"authSource": {authSource}}.Encode()
+		}
+		uri = u.String()
+	} else if !strings.HasPrefix(uri, "mongodb://") && !strings.HasPrefix(uri, "mongodb+srv://") {
+		return nil, fmt.Errorf("MONGO_URI must start with mongodb:// or mongodb+srv:// (got %s)", RedactMongoURI(uri))
+	}
+
+	clientOpts := options.Client().ApplyURI(uri)
+	if clientOpts.AppName == nil {
+		clientOpts.SetAppName("museick-backend")
+	}
+	if cfg.MongoReplicaSet != "" {
+		clientOpts.SetReplicaSet(cfg.MongoReplicaSet)
+	}
+	if cfg.MongoReadPreference != "" {
+		mode, err := readpref.ModeFromString(cfg.MongoReadPreference)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGO_READ_PREFERENCE %q: %w", cfg.MongoReadPreference, err)
+		}
+		rp, err := readpref.New(mode)
+		if err != nil {
+			return nil, fmt.Errorf("invalid MONGO_READ_PREFERENCE %q: %w", cfg.MongoReadPreference, err)
+		}
+		clientOpts.SetReadPreference(rp)
+	}
+
+	if cfg.MongoTLS || cfg.MongoTLSCAFile != "" || cfg.MongoTLSCertificateKeyFile != "" || cfg.MongoTLSInsecure {
+		tlsConfig, err := mongoTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		clientOpts.SetTLSConfig(tlsConfig)
+	}
+
+	if cfg.MongoMaxPoolSize > 0 {
+		clientOpts.SetMaxPoolSize(cfg.MongoMaxPoolSize)
+	}
+	if cfg.MongoMinPoolSize > 0 {
+		clientOpts.SetMinPoolSize(cfg.MongoMinPoolSize)
+	}
+	if clientOpts.MaxPoolSize != nil && *clientOpts.MaxPoolSize > 0 && clientOpts.MinPoolSize != nil && *clientOpts.MinPoolSize > *clientOpts.MaxPoolSize {
+		return nil, fmt.Errorf("MongoDB min pool size (%d) exceeds max pool size (%d)", *clientOpts.MinPoolSize, *clientOpts.MaxPoolSize)
+	}
+	if cfg.MongoMaxConnIdleTime > 0 {
+		clientOpts.SetMaxConnIdleTime(cfg.MongoMaxConnIdleTime)
+	}
+	if cfg.MongoConnectTimeout > 0 {
+		clientOpts.SetConnectTimeout(cfg.MongoConnectTimeout)
+	} else if clientOpts.ConnectTimeout == nil {
+		clientOpts.SetConnectTimeout(defaultMongoConnectTimeout)
+	}
+	if cfg.MongoServerSelectionTimeout > 0 {
+		clientOpts.SetServerSelectionTimeout(cfg.MongoServerSelectionTimeout)
+	} else if clientOpts.ServerSelectionTimeout == nil {
+		clientOpts.SetServerSelectionTimeout(defaultMongoServerSelectionTimeout)
+	}
+	if cfg.MongoRetryWrites != nil {
+		clientOpts.SetRetryWrites(*cfg.MongoRetryWrites)
+	}
+
+	// Catches malformed URIs and conflicting options before we try to connect
+	if err := clientOpts.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid MongoDB configuration for %s: %w", RedactMongoURI(uri), err)
+	}
+	return clientOpts, nil
+}
+
+// mongoTLSConfig builds the TLS configuration from the CA and client certificate files.
+func mongoTLSConfig(cfg *Config) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12, InsecureSkipVerify: cfg.MongoTLSInsecure}
+	if cfg.MongoTLSCAFile != "" {
+		pem, err := os.ReadFile(cfg.MongoTLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("reading MONGO_TLS_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("MONGO_TLS_CA_FILE %s contains no PEM certificates", cfg.MongoTLSCAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+	if cfg.MongoTLSCertificateKeyFile != "" {
+		// The file holds both the certificate and its private key, as mongosh's --tlsCertificateKeyFile expects
+		cert, err := tls.LoadX509KeyPair(cfg.MongoTLSCertificateKeyFile, cfg.MongoTLSCertificateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("loading MONGO_TLS_CERTIFICATE_KEY_FILE: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// Matches the password in a URI's userinfo ("user:password@"); the userinfo ends at the last @ before the path.
+// Any scheme matches, so a URI rejected for its scheme is still redacted in the error.
+var mongoURIPasswordRegex = regexp.MustCompile(`^([A-Za-z][A-Za-z0-9+.-]*://[^:/@]*:)[^/?]*@`)
+
+// Matches password-bearing URI options such as tlsCertificateKeyFilePassword.
+var mongoURIPasswordOptionRegex = regexp.MustCompile(`(?i)([?&][a-z]*password=)[^&]*`)
+
+// RedactMongoURI hides the password and any password-like query options in a MongoDB URI so it can be logged.
+func RedactMongoURI(uri string) string {
+	if uri == "" {
+		return ""
+	}
+	uri = mongoURIPasswordRegex.ReplaceAllString(uri, "${1}"+logging.Redacted+"@")
+	uri = mongoURIPasswordOptionRegex.ReplaceAllString(uri, "${1}"+logging.Redacted)
+	return logging.RedactString(uri)
+}
+
+// chainCommandMonitors fans each command event out to several monitors, since the driver accepts only one.
+func chainCommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, m := range monitors {
+				if m.Started != nil {
+					m.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, m := range monitors {
+				if m.Succeeded != nil {
+					m.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, m := range monitors {
+				if m.Failed != nil {
+					m.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
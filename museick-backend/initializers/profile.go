@@ -0,0 +1,131 @@
+package initializers
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+)
+
+// Profiles selected with APP_ENV.
+const (
+	ProfileDev  = "dev"  // Local development (default)
+	ProfileTest = "test" // Automated tests and CI
+	ProfileProd = "prod" // Deployed environments; validation is stricter
+)
+
+// baseDefaults apply to every profile.
+var baseDefaults = map[string]interface{}{
+	"PORT":                     "8080",
+	"HTTP_READ_HEADER_TIMEOUT": 10 * time.Second,
+	"HTTP_READ_TIMEOUT":        30 * time.Second,
+	"HTTP_WRITE_TIMEOUT":       60 * time.Second,
+	"HTTP_IDLE_TIMEOUT":        2 * time.Minute,
+	"SHUTDOWN_TIMEOUT":         15 * time.Second,
+	"SHUTDOWN_READINESS_DELAY": 5 * time.Second,
+	"IDEMPOTENCY_TTL":          24 * time.Hour,
+	"CACHE_REFRESH_THRESHOLD":  24 * time.Hour,
+	"CACHE_GC_ENABLED":         true,
+	"CACHE_GC_INTERVAL":        24 * time.Hour,
+	"CACHE_GC_GRACE":           30 * 24 * time.Hour,
+	"MEDIA_STORE":              "gridfs",
+	"MEDIA_DIR":                "media",
+	"MEDIA_MIRROR_ENABLED":     true,
+	"MEDIA_MIRROR_INTERVAL":    10 * time.Minute,
+	"AUDIO_FEATURES_PROVIDER":  "none", // Spotify only serves audio features to apps registered before November 2024
+	"LOG_LEVEL":                "info",
+	"LOG_FORMAT":               "text",
+	// Rate limits are on unless explicitly set to "off"
+	"RATE_LIMIT_STORE":   "memory",
+	"RATE_LIMIT_PUBLIC":  "120/m",
+	"RATE_LIMIT_API":     "300/m",
+	"RATE_LIMIT_SPOTIFY": "30/m,10",
+}
+
+// profileDefaults override baseDefaults for each profile. Anything set in app.env or the environment still wins.
+var profileDefaults = map[string]map[string]interface{}{
+	ProfileDev: {
+		"CLIENT_ORIGIN": "http://localhost:5173", // Vite dev server
+	},
+	ProfileTest: {
+		"LOG_LEVEL":          "warn",
+		"RATE_LIMIT_PUBLIC":  "off",
+		"RATE_LIMIT_API":     "off",
+		"RATE_LIMIT_SPOTIFY": "off",
+		"MIGRATE_ON_START":   true,
+		"CACHE_GC_ENABLED":   false,
+		// Tests don't reach Spotify's CDN
+		"MEDIA_MIRROR_ENABLED": false,
+		// No load balancer to wait for
+		"SHUTDOWN_READINESS_DELAY": time.Duration(0),
+	},
+	ProfileProd: {
+		"LOG_FORMAT": "json",
+	},
+}
+
+// configKeys lists the environment variable of every Config field, in declaration order.
+func configKeys() []string {
+	t := reflect.TypeOf(Config{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// PrintConfig writes the effective configuration as KEY=value lines, with secrets masked.
+// Unset secrets are printed empty so it is still clear they are missing.
+func PrintConfig(w io.Writer, c *Config) error {
+	if _, err := fmt.Fprintf(w, "# museick-backend configuration (profile %s)\n", c.AppEnv); err != nil {
+		return err
+	}
+	v := reflect.ValueOf(*c)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := t.Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		value := formatConfigValue(v.Field(i))
+		switch {
+		case key == "MONGO_URI":
+			value = RedactMongoURI(value)
+		case logging.IsSensitiveKey(key) && value != "":
+			value = logging.Redacted
+		}
+		if _, err := fmt.Fprintf(w, "%s=%s\n", key, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatConfigValue(v reflect.Value) string {
+	switch value := v.Interface().(type) {
+	case []string:
+		return strings.Join(value, ",")
+	case *bool:
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(*value)
+	case time.Duration:
+		if value == 0 {
+			return ""
+		}
+		return value.String()
+	case uint64:
+		if value == 0 {
+			return ""
+		}
+		return fmt.Sprint(value)
+	default:
+		return fmt.Sprint(value)
+	}
+}
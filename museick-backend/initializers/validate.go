@@ -0,0 +1,168 @@
+package initializers
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+	"github.com/seven7een/museick/museick-backend/internal/ratelimit"
+)
+
+// Validate checks the configuration the server needs before it starts, so a missing secret or a
+// malformed URL is reported at startup instead of on the first request that needs it.
+// Every problem is reported, one per line, rather than only the first.
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+	require := func(key, value string) {
+		if strings.TrimSpace(value) == "" {
+			fail(key, "is required")
+		}
+	}
+	prod := c.AppEnv == ProfileProd
+
+	// MongoDB
+	require("MONGO_DB_NAME", c.MongoDBName)
+	if _, err := MongoClientOptions(c); err != nil {
+		errs = append(errs, err)
+	}
+	if prod && c.MongoTLSInsecure {
+		fail("MONGO_TLS_INSECURE", "must not be enabled in %s", ProfileProd)
+	}
+
+	// HTTP server
+	if port, err := strconv.Atoi(c.ServerPort); err != nil || port < 1 || port > 65535 {
+		fail("PORT", "must be a port number between 1 and 65535, got %q", c.ServerPort)
+	}
+	if len(c.ClientOrigin) == 0 {
+		fail("CLIENT_ORIGIN", "is required")
+	}
+	for _, origin := range c.ClientOrigin {
+		if err := validateOrigin(origin, prod); err != nil {
+			fail("CLIENT_ORIGIN", "%v", err)
+		}
+	}
+	for _, proxy := range c.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("TRUSTED_PROXIES", "%q is not an IP address or CIDR range", proxy)
+			}
+		}
+	}
+	for key, d := range map[string]time.Duration{
+		"HTTP_READ_HEADER_TIMEOUT": c.HTTPReadHeaderTimeout,
+		"HTTP_READ_TIMEOUT":        c.HTTPReadTimeout,
+		"HTTP_WRITE_TIMEOUT":       c.HTTPWriteTimeout,
+		"HTTP_IDLE_TIMEOUT":        c.HTTPIdleTimeout,
+		"SHUTDOWN_TIMEOUT":         c.ShutdownTimeout,
+		"CACHE_REFRESH_THRESHOLD":  c.CacheRefreshThreshold,
+		"CACHE_GC_INTERVAL":        c.CacheGCInterval,
+		"CACHE_GC_GRACE":           c.CacheGCGrace,
+		"MEDIA_MIRROR_INTERVAL":    c.MediaMirrorInterval,
+		"IDEMPOTENCY_TTL":          c.IdempotencyTTL,
+	} {
+		if d <= 0 {
+			fail(key, "must be a positive duration such as 30s or 5m, got %s", d)
+		}
+	}
+	if c.ShutdownReadinessDelay < 0 {
+		fail("SHUTDOWN_READINESS_DELAY", "must not be negative, got %s", c.ShutdownReadinessDelay)
+	}
+	if c.HTTPReadTimeout > 0 && c.HTTPReadHeaderTimeout > c.HTTPReadTimeout {
+		fail("HTTP_READ_HEADER_TIMEOUT", "must not exceed HTTP_READ_TIMEOUT (%s)", c.HTTPReadTimeout)
+	}
+
+	// Media
+	switch c.MediaStore {
+	case "gridfs":
+	case "filesystem":
+		require("MEDIA_DIR", c.MediaDir)
+	default:
+		fail("MEDIA_STORE", "must be gridfs or filesystem, got %q", c.MediaStore)
+	}
+
+	// Audio features
+	switch c.AudioFeaturesProvider {
+	case "spotify", "none":
+	case "fixture":
+		require("AUDIO_FEATURES_FIXTURE", c.AudioFeaturesFixture)
+	default:
+		fail("AUDIO_FEATURES_PROVIDER", "must be spotify, fixture or none, got %q", c.AudioFeaturesProvider)
+	}
+
+	// Auth providers
+	require("CLERK_SECRET_KEY", c.ClerkSecretKey)
+	require("SPOTIFY_CLIENT_ID", c.SpotifyClientID)
+	require("SPOTIFY_CLIENT_SECRET", c.SpotifyClientSecret)
+	if c.SpotifyRedirectURL == "" {
+		fail("SPOTIFY_REDIRECT_URL", "is required")
+	} else if err := validateAbsoluteURL(c.SpotifyRedirectURL, prod); err != nil {
+		fail("SPOTIFY_REDIRECT_URL", "%v", err)
+	}
+
+	// Logging, tracing and rate limiting
+	if _, err := logging.ParseLevel(c.LogLevel); err != nil {
+		fail("LOG_LEVEL", "%v", err)
+	}
+	if format := strings.ToLower(c.LogFormat); format != "" && format != "text" && format != "json" {
+		fail("LOG_FORMAT", "must be text or json, got %q", c.LogFormat)
+	}
+	switch strings.ToLower(c.TracingExporter) {
+	case "", "none", "stdout", "otlp":
+	case "file":
+		require("TRACING_FILE_PATH", c.TracingFilePath)
+	default:
+		fail("TRACING_EXPORTER", "must be none, stdout, file or otlp, got %q", c.TracingExporter)
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		fail("TRACING_SAMPLE_RATIO", "must be between 0 and 1, got %g", c.TracingSampleRatio)
+	}
+	if c.RateLimitStore != "memory" && c.RateLimitStore != "mongo" {
+		fail("RATE_LIMIT_STORE", "must be memory or mongo, got %q", c.RateLimitStore)
+	}
+	for key, value := range map[string]string{
+		"RATE_LIMIT_PUBLIC":  c.RateLimitPublic,
+		"RATE_LIMIT_API":     c.RateLimitAPI,
+		"RATE_LIMIT_SPOTIFY": c.RateLimitSpotify,
+	} {
+		if _, err := ratelimit.ParseLimit(value); err != nil {
+			fail(key, "%v", err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// validateOrigin checks a CORS origin is a bare scheme://host[:port], as browsers send it.
+func validateOrigin(origin string, requireHTTPS bool) error {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an http(s) origin", origin)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return fmt.Errorf("%q must be an origin without a path, query or credentials", origin)
+	}
+	if requireHTTPS && u.Scheme != "https" {
+		return fmt.Errorf("%q must use https in %s", origin, ProfileProd)
+	}
+	return nil
+}
+
+// validateAbsoluteURL checks value is an absolute http(s) URL.
+func validateAbsoluteURL(value string, requireHTTPS bool) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%q is not an absolute http(s) URL", value)
+	}
+	if requireHTTPS && u.Scheme != "https" {
+		return fmt.Errorf("%q must use https in %s", value, ProfileProd)
+	}
+	return nil
+}
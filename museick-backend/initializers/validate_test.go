@@ -0,0 +1,112 @@
+package initializers
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig returns a configuration that passes Validate, for tests to break one field at a time.
+func validConfig() Config {
+	return Config{
+		AppEnv:                 ProfileDev,
+		MongoHost:              "localhost",
+		MongoPort:              "27017",
+		MongoDBName:            "museick",
+		ServerPort:             "8080",
+		ClientOrigin:           []string{"http://localhost:5173"},
+		HTTPReadHeaderTimeout:  10 * time.Second,
+		HTTPReadTimeout:        30 * time.Second,
+		HTTPWriteTimeout:       60 * time.Second,
+		HTTPIdleTimeout:        2 * time.Minute,
+		ShutdownTimeout:        15 * time.Second,
+		ShutdownReadinessDelay: 5 * time.Second,
+		ClerkSecretKey:         "sk_test",
+		SpotifyClientID:        "client",
+		SpotifyClientSecret:    "secret",
+		SpotifyRedirectURL:     "http://localhost:5173/callback",
+		CacheRefreshThreshold:  24 * time.Hour,
+		CacheGCInterval:        24 * time.Hour,
+		CacheGCGrace:           720 * time.Hour,
+		MediaStore:             "gridfs",
+		MediaMirrorInterval:    10 * time.Minute,
+		AudioFeaturesProvider:  "none",
+		LogLevel:               "info",
+		LogFormat:              "text",
+		TracingSampleRatio:     1,
+		RateLimitStore:         "memory",
+		RateLimitPublic:        "120/m",
+		RateLimitAPI:           "300/m",
+		RateLimitSpotify:       "30/m,10",
+		IdempotencyTTL:         24 * time.Hour,
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(c *Config)
+		wantErr []string // Substrings the error must contain; none means the config is valid
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"trusted proxies", func(c *Config) { c.TrustedProxies = []string{"10.0.0.1", "172.16.0.0/12", "::1"} }, nil},
+		{"zero readiness delay", func(c *Config) { c.ShutdownReadinessDelay = 0 }, nil},
+		{"missing database", func(c *Config) { c.MongoDBName = " " }, []string{"MONGO_DB_NAME: is required"}},
+		{"no MongoDB host", func(c *Config) { c.MongoHost = "" }, []string{"set MONGO_URI or MONGO_HOST"}},
+		{"bad MongoDB URI", func(c *Config) { c.MongoURI = "postgres://db" }, []string{"MONGO_URI must start with mongodb://"}},
+		{"insecure TLS in prod", func(c *Config) {
+			c.AppEnv = ProfileProd
+			c.ClientOrigin = []string{"https://museick.app"}
+			c.SpotifyRedirectURL = "https://museick.app/callback"
+			c.MongoTLSInsecure = true
+		}, []string{"MONGO_TLS_INSECURE: must not be enabled in prod"}},
+		{"bad port", func(c *Config) { c.ServerPort = "80a" }, []string{"PORT: must be a port number"}},
+		{"no origin", func(c *Config) { c.ClientOrigin = nil }, []string{"CLIENT_ORIGIN: is required"}},
+		{"origin with path", func(c *Config) { c.ClientOrigin = []string{"http://localhost:5173/app"} }, []string{"must be an origin without a path"}},
+		{"http origin in prod", func(c *Config) {
+			c.AppEnv = ProfileProd
+			c.SpotifyRedirectURL = "https://museick.app/callback"
+		}, []string{`CLIENT_ORIGIN: "http://localhost:5173" must use https in prod`}},
+		{"bad trusted proxy", func(c *Config) { c.TrustedProxies = []string{"10.0.0.0/33"} }, []string{"TRUSTED_PROXIES"}},
+		{"zero timeout", func(c *Config) { c.HTTPWriteTimeout = 0 }, []string{"HTTP_WRITE_TIMEOUT: must be a positive duration"}},
+		{"zero idempotency TTL", func(c *Config) { c.IdempotencyTTL = 0 }, []string{"IDEMPOTENCY_TTL: must be a positive duration"}},
+		{"negative readiness delay", func(c *Config) { c.ShutdownReadinessDelay = -time.Second }, []string{"SHUTDOWN_READINESS_DELAY: must not be negative"}},
+		{"header timeout above read timeout", func(c *Config) { c.HTTPReadHeaderTimeout = time.Minute }, []string{"HTTP_READ_HEADER_TIMEOUT: must not exceed HTTP_READ_TIMEOUT"}},
+		{"filesystem media without a directory", func(c *Config) { c.MediaStore = "filesystem" }, []string{"MEDIA_DIR: is required"}},
+		{"unknown media store", func(c *Config) { c.MediaStore = "s3" }, []string{"MEDIA_STORE: must be gridfs or filesystem"}},
+		{"fixture without a file", func(c *Config) { c.AudioFeaturesProvider = "fixture" }, []string{"AUDIO_FEATURES_FIXTURE: is required"}},
+		{"unknown audio features provider", func(c *Config) { c.AudioFeaturesProvider = "echonest" }, []string{"AUDIO_FEATURES_PROVIDER"}},
+		{"relative redirect URL", func(c *Config) { c.SpotifyRedirectURL = "/callback" }, []string{"SPOTIFY_REDIRECT_URL"}},
+		{"bad log level", func(c *Config) { c.LogLevel = "loud" }, []string{"LOG_LEVEL"}},
+		{"bad sample ratio", func(c *Config) { c.TracingSampleRatio = 1.5 }, []string{"TRACING_SAMPLE_RATIO"}},
+		{"file exporter without a path", func(c *Config) { c.TracingExporter = "file" }, []string{"TRACING_FILE_PATH: is required"}},
+		{"unknown rate limit store", func(c *Config) { c.RateLimitStore = "redis" }, []string{"RATE_LIMIT_STORE"}},
+		{"bad rate limit", func(c *Config) { c.RateLimitSpotify = "30/d" }, []string{"RATE_LIMIT_SPOTIFY: invalid rate limit unit"}},
+		{"every problem is reported", func(c *Config) {
+			c.ClerkSecretKey = ""
+			c.SpotifyClientID = ""
+			c.RateLimitAPI = "fast"
+		}, []string{"CLERK_SECRET_KEY: is required", "SPOTIFY_CLIENT_ID: is required", "RATE_LIMIT_API"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := validConfig()
+			tt.mutate(&c)
+			err := c.Validate()
+			if len(tt.wantErr) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() = %v, want nil", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() = nil, want errors containing %q", tt.wantErr)
+			}
+			for _, want := range tt.wantErr {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("Validate() = %v, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
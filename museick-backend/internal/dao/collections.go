@@ -0,0 +1,17 @@
+package dao
+
+// Collection names used by the DAOs and by schema migrations.
+const (
+	UsersCollection                  = "users"
+	UserSelectionsCollection         = "user_selections"
+	SpotifyTracksCollection          = "spotify_tracks"
+	SpotifyAlbumsCollection          = "spotify_albums"
+	SpotifyArtistsCollection         = "spotify_artists"
+	SpotifyRelatedArtistsCollection  = "spotify_related_artists"
+	SpotifyArtistTopTracksCollection = "spotify_artist_top_tracks"
+	IdempotencyKeysCollection        = "idempotency_keys"
+	MediaAssetsCollection            = "media_assets"
+	RateLimitsCollection             = "rate_limits" // Token buckets when RATE_LIMIT_STORE is mongo
+	// GridFS bucket holding mirrored images when MEDIA_STORE is gridfs (media.files and media.chunks)
+	MediaBucket = "media"
+)
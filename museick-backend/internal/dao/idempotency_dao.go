@@ -0,0 +1,152 @@
+package dao
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Index names for the idempotency_keys collection.
+const (
+	IdempotencyKeyIndexName    = "user_sub_1_key_1"
+	IdempotencyExpiryIndexName = "expires_at_ttl"
+)
+
+// ErrIdempotencyLeaseLost is returned by Complete when the reservation's lease ran out and another request
+// took the key over, so this request's response is not stored.
+var ErrIdempotencyLeaseLost = errors.New("idempotency key was taken over by another request")
+
+// How many times Reserve retries when the key is released or expires between its insert and its lookup.
+const maxIdempotencyReserveAttempts = 3
+
+// IdempotencyDAO defines data access for stored Idempotency-Key responses.
+type IdempotencyDAO interface {
+	// Reserve inserts an in-progress record. If the user already used the key, the existing record is returned
+	// and nothing is inserted; a nil record means the caller owns the key and must Complete or Release it.
+	// An in-progress record for the same request whose lease (LockedUntil) has passed is taken over instead.
+	Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error)
+	// Complete and Release only act on the reservation identified by token (the record's Token), so a request
+	// whose key was taken over cannot store its response or delete the new owner's reservation.
+	Complete(ctx context.Context, userSub, key string, token primitive.ObjectID, status int, contentType string, body []byte) error
+	Release(ctx context.Context, userSub, key string, token primitive.ObjectID) error
+	// DeleteByUser removes every stored key for the user and returns how many were deleted.
+	DeleteByUser(ctx context.Context, userSub string) (int64, error)
+}
+
+// idempotencyDAOImpl implements IdempotencyDAO using MongoDB.
+type idempotencyDAOImpl struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewIdempotencyDAO creates a new IdempotencyDAO.
+func NewIdempotencyDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) IdempotencyDAO {
+	logger = logger.With("component", "idempotency_dao")
+	collection := client.Database(dbName).Collection(collectionName)
+	// The unique key index and the TTL index are created by schema migrations (see internal/migrations)
+	return &idempotencyDAOImpl{collection: collection, logger: logger}
+}
+
+// Reserve claims the key for the record's user, or returns the record already holding it.
+func (dao *idempotencyDAOImpl) Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	for attempt := 0; attempt < maxIdempotencyReserveAttempts; attempt++ {
+		_, err := dao.collection.InsertOne(ctx, record)
+		if err == nil {
+			return nil, nil
+		}
+		if !mongo.IsDuplicateKeyError(err) {
+			dao.logger.ErrorContext(ctx, "error reserving idempotency key", "error", err)
+			return nil, fmt.Errorf("error reserving idempotency key: %w", err)
+		}
+
+		// The request holding the key crashed or outlived its lease before it could Complete or Release; a retry
+		// of the same request takes the key over. The conditions keep two retries from both winning it, and the
+		// new token stops the previous holder from completing or releasing it.
+		takeover := bson.M{
+			"user_sub":     record.UserSub,
+			"key":          record.Key,
+			"fingerprint":  record.Fingerprint,
+			"state":        models.IdempotencyInProgress,
+			"locked_until": bson.M{"$lt": record.CreatedAt},
+		}
+		update := bson.M{"$set": bson.M{
+			"token":        record.Token,
+			"created_at":   record.CreatedAt,
+			"expires_at":   record.ExpiresAt,
+			"locked_until": record.LockedUntil,
+		}}
+		result, err := dao.collection.UpdateOne(ctx, takeover, update)
+		if err != nil {
+			dao.logger.ErrorContext(ctx, "error taking over idempotency key", "error", err)
+			return nil, fmt.Errorf("error taking over idempotency key: %w", err)
+		}
+		if result.MatchedCount == 1 {
+			dao.logger.WarnContext(ctx, "took over abandoned idempotency key")
+			return nil, nil
+		}
+
+		var existing models.IdempotencyRecord
+		err = dao.collection.FindOne(ctx, bson.M{"user_sub": record.UserSub, "key": record.Key}).Decode(&existing)
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			continue // Released or expired between the insert and the lookup; claim it again
+		}
+		if err != nil {
+			dao.logger.ErrorContext(ctx, "error finding idempotency record", "error", err)
+			return nil, fmt.Errorf("error finding idempotency record: %w", err)
+		}
+		return &existing, nil
+	}
+	dao.logger.ErrorContext(ctx, "idempotency key kept disappearing while reserving it", "attempts", maxIdempotencyReserveAttempts)
+	return nil, fmt.Errorf("error reserving idempotency key: released %d times while reserving", maxIdempotencyReserveAttempts)
+}
+
+// Complete stores the response for an in-progress key so later requests replay it.
+// Returns ErrIdempotencyLeaseLost if the reservation no longer holds the key.
+func (dao *idempotencyDAOImpl) Complete(ctx context.Context, userSub, key string, token primitive.ObjectID, status int, contentType string, body []byte) error {
+	filter := bson.M{"user_sub": userSub, "key": key, "token": token, "state": models.IdempotencyInProgress}
+	update := bson.M{
+		"$set": bson.M{
+			"state":        models.IdempotencyCompleted,
+			"status":       status,
+			"content_type": contentType,
+			"body":         body,
+		},
+		"$unset": bson.M{"locked_until": ""},
+	}
+	result, err := dao.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error storing idempotent response", "error", err)
+		return fmt.Errorf("error storing idempotent response: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return ErrIdempotencyLeaseLost
+	}
+	return nil
+}
+
+// Release deletes an in-progress key so the client can retry the request. A reservation that was taken over
+// is left alone.
+func (dao *idempotencyDAOImpl) Release(ctx context.Context, userSub, key string, token primitive.ObjectID) error {
+	filter := bson.M{"user_sub": userSub, "key": key, "token": token, "state": models.IdempotencyInProgress}
+	if _, err := dao.collection.DeleteOne(ctx, filter); err != nil {
+		dao.logger.ErrorContext(ctx, "error releasing idempotency key", "error", err)
+		return fmt.Errorf("error releasing idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteByUser removes every idempotency record for the user.
+func (dao *idempotencyDAOImpl) DeleteByUser(ctx context.Context, userSub string) (int64, error) {
+	result, err := dao.collection.DeleteMany(ctx, bson.M{"user_sub": userSub})
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting user's idempotency keys", "error", err)
+		return 0, fmt.Errorf("error deleting idempotency keys: %w", err)
+	}
+	return result.DeletedCount, nil
+}
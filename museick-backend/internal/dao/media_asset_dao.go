@@ -0,0 +1,92 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MediaAssetSourceIndexName indexes media assets by the Spotify URLs they were downloaded from.
+const MediaAssetSourceIndexName = "source_urls_1"
+
+// MediaAssetDAO defines the interface for mirrored image records.
+type MediaAssetDAO interface {
+	// GetByHash finds an asset by content hash. Returns mongo.ErrNoDocuments if it was never mirrored.
+	GetByHash(ctx context.Context, hash string) (*models.MediaAsset, error)
+	// GetBySourceURL finds the asset downloaded from a URL. Returns mongo.ErrNoDocuments if the URL was never mirrored.
+	GetBySourceURL(ctx context.Context, sourceURL string) (*models.MediaAsset, error)
+	// Upsert inserts the asset, or adds sourceURL to the existing asset with the same hash.
+	Upsert(ctx context.Context, asset *models.MediaAsset, sourceURL string) error
+}
+
+type mediaAssetDAOImpl struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewMediaAssetDAO creates a new instance of MediaAssetDAO.
+func NewMediaAssetDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) MediaAssetDAO {
+	collection := client.Database(dbName).Collection(collectionName)
+	logger = logger.With("component", "media_asset_dao")
+	logger.Info("initializing MediaAssetDAO", "database", dbName, "collection", collectionName)
+	return &mediaAssetDAOImpl{collection: collection, logger: logger}
+}
+
+// GetByHash finds an asset by content hash (_id).
+func (dao *mediaAssetDAOImpl) GetByHash(ctx context.Context, hash string) (*models.MediaAsset, error) {
+	return dao.findOne(ctx, bson.M{"_id": hash})
+}
+
+// GetBySourceURL finds the asset downloaded from sourceURL.
+func (dao *mediaAssetDAOImpl) GetBySourceURL(ctx context.Context, sourceURL string) (*models.MediaAsset, error) {
+	return dao.findOne(ctx, bson.M{"source_urls": sourceURL})
+}
+
+func (dao *mediaAssetDAOImpl) findOne(ctx context.Context, filter bson.M) (*models.MediaAsset, error) {
+	var asset models.MediaAsset
+	err := dao.collection.FindOne(ctx, filter).Decode(&asset)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, mongo.ErrNoDocuments
+		}
+		dao.logger.ErrorContext(ctx, "error finding media asset", "filter", filter, "error", err)
+		return nil, fmt.Errorf("error finding media asset: %w", err)
+	}
+	return &asset, nil
+}
+
+// Upsert stores the asset under its hash. When the hash is already known (the same image behind another URL,
+// or two instances mirroring at once) only sourceURL is added to it.
+func (dao *mediaAssetDAOImpl) Upsert(ctx context.Context, asset *models.MediaAsset, sourceURL string) error {
+	if asset.Hash == "" {
+		return fmt.Errorf("hash cannot be empty for upsert")
+	}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"content_type": asset.ContentType,
+			"width":        asset.Width,
+			"height":       asset.Height,
+			"size":         asset.Size,
+			"variants":     asset.Variants,
+			"created_at":   primitive.NewDateTimeFromTime(time.Now()),
+		},
+		"$addToSet": bson.M{"source_urls": sourceURL},
+	}
+	_, err := dao.collection.UpdateOne(ctx, bson.M{"_id": asset.Hash}, update, options.Update().SetUpsert(true))
+	if mongo.IsDuplicateKeyError(err) {
+		// Lost an upsert race for the same hash; the retry updates the winner's document
+		_, err = dao.collection.UpdateOne(ctx, bson.M{"_id": asset.Hash}, update)
+	}
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error upserting media asset", "hash", asset.Hash, "error", err)
+		return fmt.Errorf("error upserting media asset: %w", err)
+	}
+	return nil
+}
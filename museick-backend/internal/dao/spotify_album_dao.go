@@ -3,7 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/models"
@@ -17,17 +17,32 @@ import (
 type SpotifyAlbumDAO interface {
 	Upsert(ctx context.Context, album *models.SpotifyAlbum) error
 	GetByID(ctx context.Context, spotifyID string) (*models.SpotifyAlbum, error)
+	// GetByIDs finds the albums with the given Spotify IDs in one query. Missing IDs are simply absent from the result.
+	GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyAlbum, error)
+	// BulkUpsert upserts several albums with a single BulkWrite.
+	BulkUpsert(ctx context.Context, albums []*models.SpotifyAlbum) error
+	// ListStale returns up to limit albums last fetched before fetchedBefore, oldest first. A limit of 0 means no limit.
+	ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyAlbum, error)
+	// DeleteUnreferenced deletes albums last fetched before fetchedBefore that no user selection references,
+	// directly or as the album of a selected track.
+	DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error)
+	// ListUnmirroredImages returns up to limit albums with _id after afterID, in _id order, that have images not mirrored yet.
+	ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyAlbum, error)
+	// SetImageMirrors records the mirrored copy of a album's images, by image URL (URL -> media hash).
+	SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error
 }
 
 type spotifyAlbumDAOImpl struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
 // NewSpotifyAlbumDAO creates a new instance of SpotifyAlbumDAO.
-func NewSpotifyAlbumDAO(client *mongo.Client, dbName string, collectionName string) SpotifyAlbumDAO {
+func NewSpotifyAlbumDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) SpotifyAlbumDAO {
 	collection := client.Database(dbName).Collection(collectionName)
-	log.Printf("Initializing SpotifyAlbumDAO with collection: %s.%s", dbName, collectionName)
-	return &spotifyAlbumDAOImpl{collection: collection}
+	logger = logger.With("component", "spotify_album_dao")
+	logger.Info("initializing SpotifyAlbumDAO", "database", dbName, "collection", collectionName)
+	return &spotifyAlbumDAOImpl{collection: collection, logger: logger}
 }
 
 // Upsert inserts a new album document or updates an existing one based on SpotifyID (_id).
@@ -43,16 +58,16 @@ func (dao *spotifyAlbumDAOImpl) Upsert(ctx context.Context, album *models.Spotif
 
 	result, err := dao.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		log.Printf("Error upserting album with SpotifyID '%s': %v\n", album.SpotifyID, err)
+		dao.logger.ErrorContext(ctx, "error upserting album", "spotify_id", album.SpotifyID, "error", err)
 		return fmt.Errorf("error upserting album: %w", err)
 	}
 
 	if result.UpsertedCount > 0 {
-		log.Printf("Inserted new album with SpotifyID: %s", album.SpotifyID)
+		dao.logger.DebugContext(ctx, "inserted new album", "spotify_id", album.SpotifyID)
 	} else if result.ModifiedCount > 0 {
-		log.Printf("Updated existing album with SpotifyID: %s", album.SpotifyID)
+		dao.logger.DebugContext(ctx, "updated existing album", "spotify_id", album.SpotifyID)
 	} else if result.MatchedCount > 0 {
-		log.Printf("Album with SpotifyID %s already up-to-date.", album.SpotifyID)
+		dao.logger.DebugContext(ctx, "album already up-to-date", "spotify_id", album.SpotifyID)
 	}
 
 	return nil
@@ -69,9 +84,79 @@ func (dao *spotifyAlbumDAOImpl) GetByID(ctx context.Context, spotifyID string) (
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Printf("Error finding album by SpotifyID '%s': %v\n", spotifyID, err)
+		dao.logger.ErrorContext(ctx, "error finding album", "spotify_id", spotifyID, "error", err)
 		return nil, fmt.Errorf("error finding album: %w", err)
 	}
 
 	return &album, nil
 }
+
+// GetByIDs finds the albums with the given Spotify IDs (_id) in one query.
+func (dao *spotifyAlbumDAOImpl) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyAlbum, error) {
+	albums := []*models.SpotifyAlbum{}
+	if len(spotifyIDs) == 0 {
+		return albums, nil
+	}
+	if err := findByIDs(ctx, dao.collection, spotifyIDs, &albums); err != nil {
+		dao.logger.ErrorContext(ctx, "error finding albums", "count", len(spotifyIDs), "error", err)
+		return nil, fmt.Errorf("error finding albums: %w", err)
+	}
+	return albums, nil
+}
+
+// BulkUpsert inserts or updates several albums based on SpotifyID (_id) with a single BulkWrite.
+func (dao *spotifyAlbumDAOImpl) BulkUpsert(ctx context.Context, albums []*models.SpotifyAlbum) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	ids := make([]string, len(albums))
+	docs := make([]interface{}, len(albums))
+	for i, album := range albums {
+		album.LastFetchedAt = now
+		ids[i], docs[i] = album.SpotifyID, album
+	}
+	if err := bulkUpsertByID(ctx, dao.collection, ids, docs); err != nil {
+		dao.logger.ErrorContext(ctx, "error bulk upserting albums", "count", len(albums), "error", err)
+		return fmt.Errorf("error bulk upserting albums: %w", err)
+	}
+	dao.logger.DebugContext(ctx, "bulk upserted albums", "count", len(albums))
+	return nil
+}
+
+// ListStale returns up to limit albums last fetched before fetchedBefore, oldest first.
+func (dao *spotifyAlbumDAOImpl) ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyAlbum, error) {
+	albums := []*models.SpotifyAlbum{}
+	if err := findStale(ctx, dao.collection, fetchedBefore, limit, &albums); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing stale albums", "error", err)
+		return nil, fmt.Errorf("error listing stale albums: %w", err)
+	}
+	return albums, nil
+}
+
+// DeleteUnreferenced deletes albums last fetched before fetchedBefore that no user selection references,
+// directly or as the album of a selected track.
+func (dao *spotifyAlbumDAOImpl) DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	deleted, err := deleteUnreferenced(ctx, dao.collection, "album", "_id", fetchedBefore, albumCredits...)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting unreferenced albums", "error", err)
+		return deleted, fmt.Errorf("error deleting unreferenced albums: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListUnmirroredImages returns up to limit albums after afterID whose images are not all mirrored yet.
+func (dao *spotifyAlbumDAOImpl) ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyAlbum, error) {
+	albums := []*models.SpotifyAlbum{}
+	if err := findUnmirrored(ctx, dao.collection, "images", afterID, limit, &albums); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing albums with unmirrored images", "error", err)
+		return nil, fmt.Errorf("error listing albums with unmirrored images: %w", err)
+	}
+	return albums, nil
+}
+
+// SetImageMirrors sets mirror_hash on the album's images with the given URLs.
+func (dao *spotifyAlbumDAOImpl) SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error {
+	if err := setImageMirrors(ctx, dao.collection, spotifyID, "images", mirrors); err != nil {
+		dao.logger.ErrorContext(ctx, "error setting album image mirrors", "spotify_id", spotifyID, "error", err)
+		return fmt.Errorf("error setting album image mirrors: %w", err)
+	}
+	return nil
+}
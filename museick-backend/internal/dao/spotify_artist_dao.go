@@ -3,7 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/models"
@@ -17,17 +17,32 @@ import (
 type SpotifyArtistDAO interface {
 	Upsert(ctx context.Context, artist *models.SpotifyArtist) error
 	GetByID(ctx context.Context, spotifyID string) (*models.SpotifyArtist, error)
+	// GetByIDs finds the artists with the given Spotify IDs in one query. Missing IDs are simply absent from the result.
+	GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyArtist, error)
+	// BulkUpsert upserts several artists with a single BulkWrite.
+	BulkUpsert(ctx context.Context, artists []*models.SpotifyArtist) error
+	// ListStale returns up to limit artists last fetched before fetchedBefore, oldest first. A limit of 0 means no limit.
+	ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyArtist, error)
+	// DeleteUnreferenced deletes artists last fetched before fetchedBefore that no user selection references,
+	// directly or through a selected track or album that credits them.
+	DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error)
+	// ListUnmirroredImages returns up to limit artists with _id after afterID, in _id order, that have images not mirrored yet.
+	ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyArtist, error)
+	// SetImageMirrors records the mirrored copy of a artist's images, by image URL (URL -> media hash).
+	SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error
 }
 
 type spotifyArtistDAOImpl struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
 // NewSpotifyArtistDAO creates a new instance of SpotifyArtistDAO.
-func NewSpotifyArtistDAO(client *mongo.Client, dbName string, collectionName string) SpotifyArtistDAO {
+func NewSpotifyArtistDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) SpotifyArtistDAO {
 	collection := client.Database(dbName).Collection(collectionName)
-	log.Printf("Initializing SpotifyArtistDAO with collection: %s.%s", dbName, collectionName)
-	return &spotifyArtistDAOImpl{collection: collection}
+	logger = logger.With("component", "spotify_artist_dao")
+	logger.Info("initializing SpotifyArtistDAO", "database", dbName, "collection", collectionName)
+	return &spotifyArtistDAOImpl{collection: collection, logger: logger}
 }
 
 // Upsert inserts a new artist document or updates an existing one based on SpotifyID (_id).
@@ -43,16 +58,16 @@ func (dao *spotifyArtistDAOImpl) Upsert(ctx context.Context, artist *models.Spot
 
 	result, err := dao.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		log.Printf("Error upserting artist with SpotifyID '%s': %v\n", artist.SpotifyID, err)
+		dao.logger.ErrorContext(ctx, "error upserting artist", "spotify_id", artist.SpotifyID, "error", err)
 		return fmt.Errorf("error upserting artist: %w", err)
 	}
 
 	if result.UpsertedCount > 0 {
-		log.Printf("Inserted new artist with SpotifyID: %s", artist.SpotifyID)
+		dao.logger.DebugContext(ctx, "inserted new artist", "spotify_id", artist.SpotifyID)
 	} else if result.ModifiedCount > 0 {
-		log.Printf("Updated existing artist with SpotifyID: %s", artist.SpotifyID)
+		dao.logger.DebugContext(ctx, "updated existing artist", "spotify_id", artist.SpotifyID)
 	} else if result.MatchedCount > 0 {
-		log.Printf("Artist with SpotifyID %s already up-to-date.", artist.SpotifyID)
+		dao.logger.DebugContext(ctx, "artist already up-to-date", "spotify_id", artist.SpotifyID)
 	}
 
 	return nil
@@ -69,9 +84,79 @@ func (dao *spotifyArtistDAOImpl) GetByID(ctx context.Context, spotifyID string)
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Printf("Error finding artist by SpotifyID '%s': %v\n", spotifyID, err)
+		dao.logger.ErrorContext(ctx, "error finding artist", "spotify_id", spotifyID, "error", err)
 		return nil, fmt.Errorf("error finding artist: %w", err)
 	}
 
 	return &artist, nil
 }
+
+// GetByIDs finds the artists with the given Spotify IDs (_id) in one query.
+func (dao *spotifyArtistDAOImpl) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyArtist, error) {
+	artists := []*models.SpotifyArtist{}
+	if len(spotifyIDs) == 0 {
+		return artists, nil
+	}
+	if err := findByIDs(ctx, dao.collection, spotifyIDs, &artists); err != nil {
+		dao.logger.ErrorContext(ctx, "error finding artists", "count", len(spotifyIDs), "error", err)
+		return nil, fmt.Errorf("error finding artists: %w", err)
+	}
+	return artists, nil
+}
+
+// BulkUpsert inserts or updates several artists based on SpotifyID (_id) with a single BulkWrite.
+func (dao *spotifyArtistDAOImpl) BulkUpsert(ctx context.Context, artists []*models.SpotifyArtist) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	ids := make([]string, len(artists))
+	docs := make([]interface{}, len(artists))
+	for i, artist := range artists {
+		artist.LastFetchedAt = now
+		ids[i], docs[i] = artist.SpotifyID, artist
+	}
+	if err := bulkUpsertByID(ctx, dao.collection, ids, docs); err != nil {
+		dao.logger.ErrorContext(ctx, "error bulk upserting artists", "count", len(artists), "error", err)
+		return fmt.Errorf("error bulk upserting artists: %w", err)
+	}
+	dao.logger.DebugContext(ctx, "bulk upserted artists", "count", len(artists))
+	return nil
+}
+
+// ListStale returns up to limit artists last fetched before fetchedBefore, oldest first.
+func (dao *spotifyArtistDAOImpl) ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyArtist, error) {
+	artists := []*models.SpotifyArtist{}
+	if err := findStale(ctx, dao.collection, fetchedBefore, limit, &artists); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing stale artists", "error", err)
+		return nil, fmt.Errorf("error listing stale artists: %w", err)
+	}
+	return artists, nil
+}
+
+// DeleteUnreferenced deletes artists last fetched before fetchedBefore that no user selection references,
+// directly or through a selected track or album that credits them.
+func (dao *spotifyArtistDAOImpl) DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	deleted, err := deleteUnreferenced(ctx, dao.collection, "artist", "_id", fetchedBefore, artistCredits...)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting unreferenced artists", "error", err)
+		return deleted, fmt.Errorf("error deleting unreferenced artists: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListUnmirroredImages returns up to limit artists after afterID whose images are not all mirrored yet.
+func (dao *spotifyArtistDAOImpl) ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyArtist, error) {
+	artists := []*models.SpotifyArtist{}
+	if err := findUnmirrored(ctx, dao.collection, "images", afterID, limit, &artists); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing artists with unmirrored images", "error", err)
+		return nil, fmt.Errorf("error listing artists with unmirrored images: %w", err)
+	}
+	return artists, nil
+}
+
+// SetImageMirrors sets mirror_hash on the artist's images with the given URLs.
+func (dao *spotifyArtistDAOImpl) SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error {
+	if err := setImageMirrors(ctx, dao.collection, spotifyID, "images", mirrors); err != nil {
+		dao.logger.ErrorContext(ctx, "error setting artist image mirrors", "spotify_id", spotifyID, "error", err)
+		return fmt.Errorf("error setting artist image mirrors: %w", err)
+	}
+	return nil
+}
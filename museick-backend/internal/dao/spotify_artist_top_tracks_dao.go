@@ -0,0 +1,83 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SpotifyArtistTopTracksDAO defines the interface for per-market top-tracks cache operations.
+type SpotifyArtistTopTracksDAO interface {
+	Upsert(ctx context.Context, topTracks *models.SpotifyArtistTopTracks) error
+	Get(ctx context.Context, artistID string, market string) (*models.SpotifyArtistTopTracks, error)
+	// DeleteUnreferenced deletes entries last fetched before fetchedBefore for artists no user selection references.
+	DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error)
+}
+
+type spotifyArtistTopTracksDAOImpl struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewSpotifyArtistTopTracksDAO creates a new instance of SpotifyArtistTopTracksDAO.
+func NewSpotifyArtistTopTracksDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) SpotifyArtistTopTracksDAO {
+	collection := client.Database(dbName).Collection(collectionName)
+	logger = logger.With("component", "spotify_artist_top_tracks_dao")
+	logger.Info("initializing SpotifyArtistTopTracksDAO", "database", dbName, "collection", collectionName)
+	return &spotifyArtistTopTracksDAOImpl{collection: collection, logger: logger}
+}
+
+// Upsert replaces the top tracks stored for an artist in a market.
+func (dao *spotifyArtistTopTracksDAOImpl) Upsert(ctx context.Context, topTracks *models.SpotifyArtistTopTracks) error {
+	if topTracks.ArtistID == "" || topTracks.Market == "" {
+		return fmt.Errorf("ArtistID and Market cannot be empty for upsert")
+	}
+	topTracks.ID = models.ArtistTopTracksID(topTracks.ArtistID, topTracks.Market)
+	topTracks.LastFetchedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	filter := bson.M{"_id": topTracks.ID}
+	update := bson.M{"$set": topTracks}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := dao.collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		dao.logger.ErrorContext(ctx, "error upserting artist top tracks", "artist_id", topTracks.ArtistID, "market", topTracks.Market, "error", err)
+		return fmt.Errorf("error upserting artist top tracks: %w", err)
+	}
+	dao.logger.DebugContext(ctx, "upserted artist top tracks", "artist_id", topTracks.ArtistID, "market", topTracks.Market, "count", len(topTracks.Tracks))
+	return nil
+}
+
+// Get finds the top tracks stored for an artist in a market.
+// Returns mongo.ErrNoDocuments if they have not been cached yet.
+func (dao *spotifyArtistTopTracksDAOImpl) Get(ctx context.Context, artistID string, market string) (*models.SpotifyArtistTopTracks, error) {
+	var topTracks models.SpotifyArtistTopTracks
+	filter := bson.M{"_id": models.ArtistTopTracksID(artistID, market)}
+
+	err := dao.collection.FindOne(ctx, filter).Decode(&topTracks)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, mongo.ErrNoDocuments
+		}
+		dao.logger.ErrorContext(ctx, "error finding artist top tracks", "artist_id", artistID, "market", market, "error", err)
+		return nil, fmt.Errorf("error finding artist top tracks: %w", err)
+	}
+
+	return &topTracks, nil
+}
+
+// DeleteUnreferenced deletes entries last fetched before fetchedBefore for artists no user selection references.
+func (dao *spotifyArtistTopTracksDAOImpl) DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	deleted, err := deleteUnreferenced(ctx, dao.collection, "artist", "artist_id", fetchedBefore)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting unreferenced artist top tracks", "error", err)
+		return deleted, fmt.Errorf("error deleting unreferenced artist top tracks: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,202 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SelectionItemIndexName is the user_selections index on (item_type, spotify_item_id) that lets the
+// cache garbage collector check whether anyone still references a cached item.
+const SelectionItemIndexName = "item_type_1_spotify_item_id_1"
+
+// Indexes on the artists and album credited in cached tracks and albums, so the garbage collector can keep
+// the artists and albums of selected tracks and albums.
+const (
+	CacheArtistCreditIndexName = "artists.id_1"
+	CacheAlbumCreditIndexName  = "album.id_1"
+)
+
+// cacheCredit is a reference to a cached item from another cached collection: documents in collection,
+// which selections reference with itemType, name the item in field.
+type cacheCredit struct {
+	collection string
+	itemType   string
+	field      string
+}
+
+// The cached documents that credit artists and albums. A track or album someone selected keeps its artists
+// (for genres and names in the stats), and a selected track keeps its album.
+var (
+	artistCredits = []cacheCredit{
+		{collection: SpotifyTracksCollection, itemType: "track", field: "artists.id"},
+		{collection: SpotifyAlbumsCollection, itemType: "album", field: "artists.id"},
+	}
+	albumCredits = []cacheCredit{
+		{collection: SpotifyTracksCollection, itemType: "track", field: "album.id"},
+	}
+)
+
+// How many IDs go into each DeleteMany when garbage collecting.
+const deleteBatchSize = 1000
+
+// Shared implementation of the batch operations on the Spotify cache collections. Every cache
+// collection is keyed by Spotify ID (_id) and stamps last_fetched_at on write.
+
+// bulkUpsertByID replaces-or-inserts each document under its ID in a single unordered BulkWrite.
+func bulkUpsertByID(ctx context.Context, collection *mongo.Collection, ids []string, docs []interface{}) error {
+	if len(docs) == 0 {
+		return nil
+	}
+	writes := make([]mongo.WriteModel, len(docs))
+	for i, doc := range docs {
+		if ids[i] == "" {
+			return fmt.Errorf("SpotifyID cannot be empty for upsert")
+		}
+		writes[i] = mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": ids[i]}).
+			SetUpdate(bson.M{"$set": doc}).
+			SetUpsert(true)
+	}
+	_, err := collection.BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false))
+	return err
+}
+
+// findByIDs decodes the documents with the given IDs into results (a pointer to a slice).
+func findByIDs(ctx context.Context, collection *mongo.Collection, ids []string, results interface{}) error {
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+// findStale decodes up to limit documents last fetched before fetchedBefore, oldest first, into results.
+func findStale(ctx context.Context, collection *mongo.Collection, fetchedBefore time.Time, limit int64, results interface{}) error {
+	opts := options.Find().SetSort(bson.D{{Key: "last_fetched_at", Value: 1}})
+	if limit > 0 {
+		opts.SetLimit(limit)
+	}
+	cursor, err := collection.Find(ctx, bson.M{"last_fetched_at": bson.M{"$lt": primitive.NewDateTimeFromTime(fetchedBefore)}}, opts)
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+// deleteUnreferenced deletes documents last fetched before fetchedBefore whose idField (the Spotify ID
+// they belong to) no user selection of itemType references, and that no cached document in credits
+// names while being selected itself. It returns how many were deleted.
+// Items are re-checked against fetchedBefore on delete, so one re-fetched in the meantime (because
+// somebody just selected it) survives.
+func deleteUnreferenced(ctx context.Context, collection *mongo.Collection, itemType, idField string, fetchedBefore time.Time, credits ...cacheCredit) (int64, error) {
+	cutoff := primitive.NewDateTimeFromTime(fetchedBefore)
+	// selected looks up (at most) one selection of itemType referencing localField into "references"
+	selected := func(itemType, localField string) bson.D {
+		return bson.D{{Key: "$lookup", Value: bson.M{
+			"from":         UserSelectionsCollection,
+			"localField":   localField,
+			"foreignField": "spotify_item_id",
+			"pipeline": bson.A{
+				bson.M{"$match": bson.M{"item_type": itemType}},
+				bson.M{"$limit": 1},
+				bson.M{"$project": bson.M{"_id": 1}},
+			},
+			"as": "references",
+		}}}
+	}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"last_fetched_at": bson.M{"$lt": cutoff}}}},
+		selected(itemType, idField),
+		{{Key: "$match", Value: bson.M{"references": bson.M{"$size": 0}}}},
+	}
+	for _, credit := range credits {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{
+				"from":         credit.collection,
+				"localField":   idField,
+				"foreignField": credit.field,
+				"pipeline": bson.A{
+					bson.M{"$project": bson.M{"_id": 1}},
+					selected(credit.itemType, "_id"),
+					bson.M{"$match": bson.M{"references.0": bson.M{"$exists": true}}},
+					bson.M{"$limit": 1},
+					bson.M{"$project": bson.M{"_id": 1}},
+				},
+				"as": "references",
+			}}},
+			bson.D{{Key: "$match", Value: bson.M{"references": bson.M{"$size": 0}}}},
+		)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$project", Value: bson.M{"_id": 1}}})
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, err
+	}
+	var unreferenced []struct {
+		ID string `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &unreferenced); err != nil {
+		return 0, err
+	}
+	if len(unreferenced) == 0 {
+		return 0, nil
+	}
+
+	var deleted int64
+	for start := 0; start < len(unreferenced); start += deleteBatchSize {
+		end := start + deleteBatchSize
+		if end > len(unreferenced) {
+			end = len(unreferenced)
+		}
+		ids := make([]string, 0, end-start)
+		for _, doc := range unreferenced[start:end] {
+			ids = append(ids, doc.ID)
+		}
+		result, err := collection.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}, "last_fetched_at": bson.M{"$lt": cutoff}})
+		if err != nil {
+			return deleted, err
+		}
+		deleted += result.DeletedCount
+	}
+	return deleted, nil
+}
+
+// findUnmirrored decodes up to limit documents with _id after afterID, in _id order, that have an image under
+// imagesField not mirrored yet into results. Paging by _id lets a caller walk past images that keep failing.
+func findUnmirrored(ctx context.Context, collection *mongo.Collection, imagesField, afterID string, limit int64, results interface{}) error {
+	filter := bson.M{
+		"_id":       bson.M{"$gt": afterID},
+		imagesField: bson.M{"$elemMatch": bson.M{"mirror_hash": bson.M{"$in": bson.A{nil, ""}}}},
+	}
+	cursor, err := collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}).SetLimit(limit))
+	if err != nil {
+		return err
+	}
+	return cursor.All(ctx, results)
+}
+
+// setImageMirrors sets mirror_hash on the images under imagesField of one document, by image URL (URL -> hash).
+// Images not in mirrors, or replaced by a sync in the meantime, are left alone.
+func setImageMirrors(ctx context.Context, collection *mongo.Collection, id, imagesField string, mirrors map[string]string) error {
+	if len(mirrors) == 0 {
+		return nil
+	}
+	set := bson.M{}
+	arrayFilters := make([]interface{}, 0, len(mirrors))
+	i := 0
+	for url, hash := range mirrors {
+		name := fmt.Sprintf("img%d", i)
+		set[imagesField+".$["+name+"].mirror_hash"] = hash
+		arrayFilters = append(arrayFilters, bson.M{name + ".url": url})
+		i++
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+	_, err := collection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": set}, opts)
+	return err
+}
@@ -0,0 +1,82 @@
+package dao
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SpotifyRelatedArtistsDAO defines the interface for related-artists cache operations.
+type SpotifyRelatedArtistsDAO interface {
+	Upsert(ctx context.Context, related *models.SpotifyRelatedArtists) error
+	GetByArtistID(ctx context.Context, artistID string) (*models.SpotifyRelatedArtists, error)
+	// DeleteUnreferenced deletes entries last fetched before fetchedBefore for artists no user selection references.
+	DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error)
+}
+
+type spotifyRelatedArtistsDAOImpl struct {
+	collection *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewSpotifyRelatedArtistsDAO creates a new instance of SpotifyRelatedArtistsDAO.
+func NewSpotifyRelatedArtistsDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) SpotifyRelatedArtistsDAO {
+	collection := client.Database(dbName).Collection(collectionName)
+	logger = logger.With("component", "spotify_related_artists_dao")
+	logger.Info("initializing SpotifyRelatedArtistsDAO", "database", dbName, "collection", collectionName)
+	return &spotifyRelatedArtistsDAOImpl{collection: collection, logger: logger}
+}
+
+// Upsert replaces the related artists stored for an artist (_id).
+func (dao *spotifyRelatedArtistsDAOImpl) Upsert(ctx context.Context, related *models.SpotifyRelatedArtists) error {
+	if related.ArtistID == "" {
+		return fmt.Errorf("ArtistID cannot be empty for upsert")
+	}
+	related.LastFetchedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	filter := bson.M{"_id": related.ArtistID}
+	update := bson.M{"$set": related}
+	opts := options.Update().SetUpsert(true)
+
+	if _, err := dao.collection.UpdateOne(ctx, filter, update, opts); err != nil {
+		dao.logger.ErrorContext(ctx, "error upserting related artists", "artist_id", related.ArtistID, "error", err)
+		return fmt.Errorf("error upserting related artists: %w", err)
+	}
+	dao.logger.DebugContext(ctx, "upserted related artists", "artist_id", related.ArtistID, "count", len(related.Artists))
+	return nil
+}
+
+// GetByArtistID finds the related artists stored for an artist.
+// Returns mongo.ErrNoDocuments if they have not been cached yet.
+func (dao *spotifyRelatedArtistsDAOImpl) GetByArtistID(ctx context.Context, artistID string) (*models.SpotifyRelatedArtists, error) {
+	var related models.SpotifyRelatedArtists
+	filter := bson.M{"_id": artistID}
+
+	err := dao.collection.FindOne(ctx, filter).Decode(&related)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, mongo.ErrNoDocuments
+		}
+		dao.logger.ErrorContext(ctx, "error finding related artists", "artist_id", artistID, "error", err)
+		return nil, fmt.Errorf("error finding related artists: %w", err)
+	}
+
+	return &related, nil
+}
+
+// DeleteUnreferenced deletes entries last fetched before fetchedBefore for artists no user selection references.
+func (dao *spotifyRelatedArtistsDAOImpl) DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	deleted, err := deleteUnreferenced(ctx, dao.collection, "artist", "_id", fetchedBefore)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting unreferenced related artists", "error", err)
+		return deleted, fmt.Errorf("error deleting unreferenced related artists: %w", err)
+	}
+	return deleted, nil
+}
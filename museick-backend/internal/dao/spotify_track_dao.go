@@ -3,7 +3,7 @@ package dao
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/models"
@@ -17,19 +17,33 @@ import (
 type SpotifyTrackDAO interface {
 	Upsert(ctx context.Context, track *models.SpotifyTrack) error
 	GetByID(ctx context.Context, spotifyID string) (*models.SpotifyTrack, error)
+	// GetByIDs finds the tracks with the given Spotify IDs in one query. Missing IDs are simply absent from the result.
+	GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyTrack, error)
+	// BulkUpsert upserts several tracks with a single BulkWrite.
+	BulkUpsert(ctx context.Context, tracks []*models.SpotifyTrack) error
+	// ListStale returns up to limit tracks last fetched before fetchedBefore, oldest first. A limit of 0 means no limit.
+	ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyTrack, error)
+	// DeleteUnreferenced deletes tracks last fetched before fetchedBefore that no user selection references.
+	DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error)
+	// ListUnmirroredImages returns up to limit tracks with _id after afterID, in _id order, that have images (the album art embedded in the track) not mirrored yet.
+	ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyTrack, error)
+	// SetImageMirrors records the mirrored copy of a track's images, by image URL (URL -> media hash).
+	SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error
 }
 
 type spotifyTrackDAOImpl struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
 // NewSpotifyTrackDAO creates a new instance of SpotifyTrackDAO.
-func NewSpotifyTrackDAO(client *mongo.Client, dbName string, collectionName string) SpotifyTrackDAO {
+func NewSpotifyTrackDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) SpotifyTrackDAO {
 	collection := client.Database(dbName).Collection(collectionName)
 	// Optional: Create index on _id if not default, though it usually is.
 	// Ensure TTL index if you want old tracks to expire (consider implications)
-	log.Printf("Initializing SpotifyTrackDAO with collection: %s.%s", dbName, collectionName)
-	return &spotifyTrackDAOImpl{collection: collection}
+	logger = logger.With("component", "spotify_track_dao")
+	logger.Info("initializing SpotifyTrackDAO", "database", dbName, "collection", collectionName)
+	return &spotifyTrackDAOImpl{collection: collection, logger: logger}
 }
 
 // Upsert inserts a new track document or updates an existing one based on SpotifyID (_id).
@@ -45,16 +59,16 @@ func (dao *spotifyTrackDAOImpl) Upsert(ctx context.Context, track *models.Spotif
 
 	result, err := dao.collection.UpdateOne(ctx, filter, update, opts)
 	if err != nil {
-		log.Printf("Error upserting track with SpotifyID '%s': %v\n", track.SpotifyID, err)
+		dao.logger.ErrorContext(ctx, "error upserting track", "spotify_id", track.SpotifyID, "error", err)
 		return fmt.Errorf("error upserting track: %w", err)
 	}
 
 	if result.UpsertedCount > 0 {
-		log.Printf("Inserted new track with SpotifyID: %s", track.SpotifyID)
+		dao.logger.DebugContext(ctx, "inserted new track", "spotify_id", track.SpotifyID)
 	} else if result.ModifiedCount > 0 {
-		log.Printf("Updated existing track with SpotifyID: %s", track.SpotifyID)
+		dao.logger.DebugContext(ctx, "updated existing track", "spotify_id", track.SpotifyID)
 	} else if result.MatchedCount > 0 {
-		log.Printf("Track with SpotifyID %s already up-to-date.", track.SpotifyID)
+		dao.logger.DebugContext(ctx, "track already up-to-date", "spotify_id", track.SpotifyID)
 	}
 
 	return nil
@@ -71,9 +85,78 @@ func (dao *spotifyTrackDAOImpl) GetByID(ctx context.Context, spotifyID string) (
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments // Return specific error
 		}
-		log.Printf("Error finding track by SpotifyID '%s': %v\n", spotifyID, err)
+		dao.logger.ErrorContext(ctx, "error finding track", "spotify_id", spotifyID, "error", err)
 		return nil, fmt.Errorf("error finding track: %w", err)
 	}
 
 	return &track, nil
 }
+
+// GetByIDs finds the tracks with the given Spotify IDs (_id) in one query.
+func (dao *spotifyTrackDAOImpl) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyTrack, error) {
+	tracks := []*models.SpotifyTrack{}
+	if len(spotifyIDs) == 0 {
+		return tracks, nil
+	}
+	if err := findByIDs(ctx, dao.collection, spotifyIDs, &tracks); err != nil {
+		dao.logger.ErrorContext(ctx, "error finding tracks", "count", len(spotifyIDs), "error", err)
+		return nil, fmt.Errorf("error finding tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// BulkUpsert inserts or updates several tracks based on SpotifyID (_id) with a single BulkWrite.
+func (dao *spotifyTrackDAOImpl) BulkUpsert(ctx context.Context, tracks []*models.SpotifyTrack) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	ids := make([]string, len(tracks))
+	docs := make([]interface{}, len(tracks))
+	for i, track := range tracks {
+		track.LastFetchedAt = now
+		ids[i], docs[i] = track.SpotifyID, track
+	}
+	if err := bulkUpsertByID(ctx, dao.collection, ids, docs); err != nil {
+		dao.logger.ErrorContext(ctx, "error bulk upserting tracks", "count", len(tracks), "error", err)
+		return fmt.Errorf("error bulk upserting tracks: %w", err)
+	}
+	dao.logger.DebugContext(ctx, "bulk upserted tracks", "count", len(tracks))
+	return nil
+}
+
+// ListStale returns up to limit tracks last fetched before fetchedBefore, oldest first.
+func (dao *spotifyTrackDAOImpl) ListStale(ctx context.Context, fetchedBefore time.Time, limit int64) ([]*models.SpotifyTrack, error) {
+	tracks := []*models.SpotifyTrack{}
+	if err := findStale(ctx, dao.collection, fetchedBefore, limit, &tracks); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing stale tracks", "error", err)
+		return nil, fmt.Errorf("error listing stale tracks: %w", err)
+	}
+	return tracks, nil
+}
+
+// DeleteUnreferenced deletes tracks last fetched before fetchedBefore that no user selection references.
+func (dao *spotifyTrackDAOImpl) DeleteUnreferenced(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+	deleted, err := deleteUnreferenced(ctx, dao.collection, "track", "_id", fetchedBefore)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting unreferenced tracks", "error", err)
+		return deleted, fmt.Errorf("error deleting unreferenced tracks: %w", err)
+	}
+	return deleted, nil
+}
+
+// ListUnmirroredImages returns up to limit tracks after afterID whose images are not all mirrored yet.
+func (dao *spotifyTrackDAOImpl) ListUnmirroredImages(ctx context.Context, afterID string, limit int64) ([]*models.SpotifyTrack, error) {
+	tracks := []*models.SpotifyTrack{}
+	if err := findUnmirrored(ctx, dao.collection, "album.images", afterID, limit, &tracks); err != nil {
+		dao.logger.ErrorContext(ctx, "error listing tracks with unmirrored images", "error", err)
+		return nil, fmt.Errorf("error listing tracks with unmirrored images: %w", err)
+	}
+	return tracks, nil
+}
+
+// SetImageMirrors sets mirror_hash on the track's images (the album art embedded in the track) with the given URLs.
+func (dao *spotifyTrackDAOImpl) SetImageMirrors(ctx context.Context, spotifyID string, mirrors map[string]string) error {
+	if err := setImageMirrors(ctx, dao.collection, spotifyID, "album.images", mirrors); err != nil {
+		dao.logger.ErrorContext(ctx, "error setting track image mirrors", "spotify_id", spotifyID, "error", err)
+		return fmt.Errorf("error setting track image mirrors: %w", err)
+	}
+	return nil
+}
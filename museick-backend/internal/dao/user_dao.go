@@ -3,44 +3,38 @@ package dao
 import (
 	"context"
 	"fmt"
-	"log"
+	"log/slog"
 
 	"github.com/seven7een/museick/museick-backend/internal/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+// UserSubIndexName is the unique index on users.sub (MongoDB's default name for it).
+const UserSubIndexName = "sub_1"
+
 // UserDAO defines the interface for user data access operations.
 type UserDAO interface {
 	FindBySub(ctx context.Context, sub string) (*models.User, error)
 	Create(ctx context.Context, user *models.User) error
 	UpdateRefreshToken(ctx context.Context, sub string, refreshToken string) error
-	// TODO: Add other methods like Delete if needed
+	UpdateSpotifyMarket(ctx context.Context, sub string, market string) error
+	// Delete removes the user. Returns mongo.ErrNoDocuments if the user is not found.
+	Delete(ctx context.Context, sub string) error
 }
 
 // userDAOImpl implements the UserDAO interface using MongoDB.
 type userDAOImpl struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
 // NewUserDAO creates a new instance of UserDAO.
-func NewUserDAO(client *mongo.Client, dbName string, collectionName string) UserDAO {
+func NewUserDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) UserDAO {
+	logger = logger.With("component", "user_dao")
 	collection := client.Database(dbName).Collection(collectionName)
-	// Create index on 'sub' field for efficient lookups if it doesn't exist
-	indexModel := mongo.IndexModel{
-		Keys:    bson.M{"sub": 1},
-		Options: options.Index().SetUnique(true),
-	}
-	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
-	if err != nil {
-		// Log the error but don't necessarily fail startup, maybe index exists
-		// TODO: Check for exists error
-		log.Printf("⚠️ Could not create index on users collection 'sub' field: %v\n", err)
-	} else {
-		log.Println("✅ Index on users collection 'sub' field ensured.")
-	}
-	return &userDAOImpl{collection: collection}
+	// The unique index on sub is created by schema migrations (see internal/migrations)
+	return &userDAOImpl{collection: collection, logger: logger}
 }
 
 // FindBySub finds a user by their Clerk subject ID.
@@ -54,7 +48,7 @@ func (dao *userDAOImpl) FindBySub(ctx context.Context, sub string) (*models.User
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Printf("Error finding user by sub '%s': %v\n", sub, err)
+		dao.logger.ErrorContext(ctx, "error finding user", "sub", sub, "error", err)
 		return nil, fmt.Errorf("error finding user: %w", err)
 	}
 
@@ -68,15 +62,15 @@ func (dao *userDAOImpl) Create(ctx context.Context, user *models.User) error {
 	if err != nil {
 		// Handle potential duplicate key error if index creation failed but constraint exists
 		if mongo.IsDuplicateKeyError(err) {
-			log.Printf("Attempted to create duplicate user with sub '%s'\n", user.Sub)
+			dao.logger.WarnContext(ctx, "attempted to create duplicate user", "sub", user.Sub)
 			// Usually, for sync, finding the existing user is enough, so no error needed here.
 			// If Create is called outside sync, it might return a specific error.
 			return nil // Or a custom duplicate error
 		}
-		log.Printf("Error creating user with sub '%s': %v\n", user.Sub, err)
+		dao.logger.ErrorContext(ctx, "error creating user", "sub", user.Sub, "error", err)
 		return fmt.Errorf("error creating user: %w", err) // Wrap internal error
 	}
-	log.Printf("Successfully created user with sub '%s'\n", user.Sub)
+	dao.logger.InfoContext(ctx, "created user", "sub", user.Sub)
 	return nil
 }
 
@@ -89,15 +83,50 @@ func (dao *userDAOImpl) UpdateRefreshToken(ctx context.Context, sub string, refr
 
 	result, err := dao.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Printf("Error updating refresh token for user sub '%s': %v\n", sub, err)
+		dao.logger.ErrorContext(ctx, "error updating spotify refresh token", "sub", sub, "error", err)
 		return fmt.Errorf("error updating refresh token: %w", err)
 	}
 
 	if result.MatchedCount == 0 {
-		log.Printf("Attempted to update refresh token for non-existent user sub '%s'\n", sub)
+		dao.logger.WarnContext(ctx, "attempted to update spotify refresh token for non-existent user", "sub", sub)
 		return fmt.Errorf("user with sub '%s' not found for refresh token update", sub) // Or return nil if this is acceptable
 	}
 
-	log.Printf("Successfully updated refresh token for user sub '%s'\n", sub)
+	dao.logger.InfoContext(ctx, "updated spotify refresh token", "sub", sub)
+	return nil
+}
+
+// UpdateSpotifyMarket stores the market (country) of the user's Spotify account.
+func (dao *userDAOImpl) UpdateSpotifyMarket(ctx context.Context, sub string, market string) error {
+	filter := bson.M{"sub": sub}
+	update := bson.M{
+		"$set": bson.M{"spotify_market": market},
+	}
+
+	result, err := dao.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error updating spotify market", "sub", sub, "error", err)
+		return fmt.Errorf("error updating spotify market: %w", err)
+	}
+
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("user with sub '%s' not found for spotify market update", sub)
+	}
+
+	dao.logger.DebugContext(ctx, "updated spotify market", "sub", sub, "market", market)
+	return nil
+}
+
+// Delete removes the user identified by their sub.
+func (dao *userDAOImpl) Delete(ctx context.Context, sub string) error {
+	result, err := dao.collection.DeleteOne(ctx, bson.M{"sub": sub})
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting user", "sub", sub, "error", err)
+		return fmt.Errorf("error deleting user: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return mongo.ErrNoDocuments
+	}
+	dao.logger.InfoContext(ctx, "deleted user", "sub", sub)
 	return nil
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/models"
@@ -17,80 +17,101 @@ import (
 // ErrSelectionExists is returned by Create when the selection already exists.
 var ErrSelectionExists = errors.New("selection already exists")
 
+// Index names on the user_selections collection.
+// Names match MongoDB's defaults where the index predates migrations, so existing deployments keep their indexes.
+const (
+	SelectionUniqueIndexName  = "user_id_1_month_year_1_spotify_item_id_1"
+	SelectionMonthIndexName   = "user_id_1_month_year_1"
+	SelectionRoleIndexName    = "user_id_1_month_year_1_selection_role_1"
+	SelectionMoodTagIndexName = "user_id_1_mood_tags_1"
+	JournalTextIndexName      = "journal_text_index"
+)
+
+// SelectionBatchOpType identifies the kind of write in a SelectionBatchOp.
+type SelectionBatchOpType string
+
+const (
+	SelectionBatchCreate SelectionBatchOpType = "create"
+	SelectionBatchUpdate SelectionBatchOpType = "update"
+	SelectionBatchDelete SelectionBatchOpType = "delete"
+)
+
+// SelectionBatchOp is a single write applied by BulkApply.
+type SelectionBatchOp struct {
+	Type          SelectionBatchOpType
+	Selection     *models.UserSelection // Create: the selection to insert (ID and timestamps are set by the DAO)
+	SelectionID   primitive.ObjectID    // Update/Delete: the selection to modify
+	UserID        string                // Update/Delete: the owner, so a stale ID can never touch another user's data
+	Updates       bson.M                // Update: fields to $set
+	NotesRevision *models.NotesRevision // Update: optional previous notes to append to notes_history
+	Demote        *SelectionDemotion    // Update: optional demotion of the item previously holding a selected role, applied only if the update matched
+}
+
+// SelectionDemotion moves whichever other selection holds From (for the same user/month/item type) back to To.
+type SelectionDemotion struct {
+	MonthYear string
+	ItemType  string
+	From      models.SelectionRole
+	To        models.SelectionRole
+}
+
 // UserSelectionDAO defines the interface for user selection data access operations.
 type UserSelectionDAO interface {
 	Create(ctx context.Context, selection *models.UserSelection) (*models.UserSelection, error)
 	// FindByUserMonthSpotifyItem finds a selection based on the unique combination of user, month, and spotify item ID.
 	FindByUserMonthSpotifyItem(ctx context.Context, userID, monthYear, spotifyItemID string) (*models.UserSelection, error)
+	// FindByUserMonthCanonicalID finds a user's selection for a month whose item has the given canonical ID.
+	FindByUserMonthCanonicalID(ctx context.Context, userID, monthYear, canonicalID string) (*models.UserSelection, error)
 	// FindByRole finds selections matching a specific role for a user/month.
 	FindByRole(ctx context.Context, userID, monthYear string, role models.SelectionRole) ([]*models.UserSelection, error)
 	// FindSelected finds the currently selected Muse or Ick for a user/month/itemType.
 	FindSelected(ctx context.Context, userID, monthYear string, role models.SelectionRole, itemType string) (*models.UserSelection, error)
 	Update(ctx context.Context, selectionID primitive.ObjectID, updates bson.M) (*models.UserSelection, error)
+	// UpdateWithNotesRevision applies updates and appends the replaced notes to notes_history, keeping at most maxRevisions entries.
+	UpdateWithNotesRevision(ctx context.Context, selectionID primitive.ObjectID, updates bson.M, revision models.NotesRevision, maxRevisions int) (*models.UserSelection, error)
 	// UpdateRole updates only the role of a selection.
 	UpdateRole(ctx context.Context, selectionID primitive.ObjectID, newRole models.SelectionRole, updatedAt primitive.DateTime) error
 	Delete(ctx context.Context, selectionID primitive.ObjectID) error
 	ListByUserAndMonth(ctx context.Context, userID, monthYear string) ([]*models.UserSelection, error)
+	// ListByUser retrieves all of a user's selections, oldest month first.
+	ListByUser(ctx context.Context, userID string) ([]*models.UserSelection, error)
+	// DeleteByUser removes all of a user's selections and returns how many were deleted.
+	DeleteByUser(ctx context.Context, userID string) (int64, error)
 	// GetByID retrieves a single selection by its MongoDB ObjectID.
 	GetByID(ctx context.Context, selectionID primitive.ObjectID) (*models.UserSelection, error)
 	// GetUserSelectionsForYear retrieves selections for a user for a specific year.
 	GetUserSelectionsForYear(ctx context.Context, userID string, year int, itemType string, roles []string) ([]*models.UserSelection, error)
+	// SearchText runs a full-text search over a user's notes and denormalized item names, ranked by relevance.
+	// A year of 0 searches across all years.
+	SearchText(ctx context.Context, userID, query string, year int, limit int64) ([]*models.JournalSearchResult, error)
+	// FindByIDs retrieves the selections with the given IDs. Missing IDs are simply absent from the result.
+	FindByIDs(ctx context.Context, selectionIDs []primitive.ObjectID) ([]*models.UserSelection, error)
+	// BulkApply runs the operations as a single unordered bulk write, except updates with a demotion, which
+	// are applied one by one afterwards. The returned slice holds one error (or nil) per operation;
+	// duplicate creates report ErrSelectionExists.
+	BulkApply(ctx context.Context, ops []SelectionBatchOp, maxNotesRevisions int) ([]error, error)
+	// ListMoodTags returns each mood tag the user has used along with how many selections carry it.
+	ListMoodTags(ctx context.Context, userID string) ([]*models.MoodTagCount, error)
+	// FindByMoodTag retrieves a user's selections carrying a mood tag, newest month first. A year of 0 searches all years.
+	FindByMoodTag(ctx context.Context, userID, tag string, year int) ([]*models.UserSelection, error)
+	// AggregateYearlyPicks groups a user's Muse and Ick selections into one YearlyPick per item and year, joined with
+	// the cached tracks, albums and artists, ordered by year. No years means every year.
+	AggregateYearlyPicks(ctx context.Context, userID string, years []int, museRoles, ickRoles []string) ([]*models.YearlyPick, error)
 	// TODO: Add methods like ListByUserAndType, etc. if needed
 }
 
 type userSelectionDAOImpl struct {
 	collection *mongo.Collection
+	logger     *slog.Logger
 }
 
 // NewUserSelectionDAO creates a new instance of UserSelectionDAO.
-func NewUserSelectionDAO(client *mongo.Client, dbName string, collectionName string) UserSelectionDAO {
+func NewUserSelectionDAO(client *mongo.Client, dbName string, collectionName string, logger *slog.Logger) UserSelectionDAO {
+	logger = logger.With("component", "user_selection_dao")
 	collection := client.Database(dbName).Collection(collectionName)
-	// Create compound index for efficient lookups and uniqueness constraint - i.e. user selections are a unique ciombination of user, month, and item
-	indexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "user_id", Value: 1},
-			{Key: "month_year", Value: 1},
-			{Key: "spotify_item_id", Value: 1},
-		},
-		Options: options.Index().SetUnique(true), // Ensure a user can only add the same item once per month/item
-	}
-	_, err := collection.Indexes().CreateOne(context.Background(), indexModel)
-	if err != nil {
-		// Log the error but don't necessarily fail startup
-		log.Printf("⚠️ Could not create unique index on user_selections collection: %v\n", err)
-	} else {
-		log.Println("✅ Unique index on user_selections collection ensured.")
-	}
-	// Add index for listing by user and month
-	listIndexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "user_id", Value: 1},
-			{Key: "month_year", Value: 1},
-		},
-	}
-	_, err = collection.Indexes().CreateOne(context.Background(), listIndexModel)
-	if err != nil {
-		log.Printf("⚠️ Could not create list index on user_selections collection: %v\n", err)
-	} else {
-		log.Println("✅ List index on user_selections collection ensured.")
-	}
-	// Add index for finding by role efficiently
-	roleIndexModel := mongo.IndexModel{
-		Keys: bson.D{
-			{Key: "user_id", Value: 1},
-			{Key: "month_year", Value: 1},
-			{Key: "selection_role", Value: 1},
-		},
-	}
-	_, err = collection.Indexes().CreateOne(context.Background(), roleIndexModel)
-	if err != nil {
-		log.Printf("⚠️ Could not create role index on user_selections collection: %v\n", err)
-	} else {
-		log.Println("✅ Role index on user_selections collection ensured.")
-	}
-
-	log.Printf("Initializing UserSelectionDAO with collection: %s.%s", dbName, collectionName)
-	return &userSelectionDAOImpl{collection: collection}
+	// Indexes are created by schema migrations (see internal/migrations)
+	logger.Info("initializing UserSelectionDAO", "database", dbName, "collection", collectionName)
+	return &userSelectionDAOImpl{collection: collection, logger: logger}
 }
 
 // Create inserts a new user selection document.
@@ -106,16 +127,16 @@ func (dao *userSelectionDAOImpl) Create(ctx context.Context, selection *models.U
 	if err != nil {
 		// Handle potential duplicate key error due to unique index
 		if mongo.IsDuplicateKeyError(err) {
-			log.Printf("Duplicate key error for user '%s', month '%s', item '%s'. Selection already exists.",
-				selection.UserID, selection.MonthYear, selection.SpotifyItemID)
+			dao.logger.DebugContext(ctx, "selection already exists",
+				"month_year", selection.MonthYear, "spotify_item_id", selection.SpotifyItemID)
 			// Simply return the sentinel error. The service layer will handle fetching the existing item if needed.
 			return nil, ErrSelectionExists // Return nil item, specific error
 		}
 		// If it wasn't a duplicate key error, return the wrapped error
-		log.Printf("Error creating user selection: %v", err)
+		dao.logger.ErrorContext(ctx, "error creating user selection", "error", err)
 		return nil, fmt.Errorf("error creating selection: %w", err)
 	}
-	log.Printf("Successfully created user selection with ID '%s'\n", selection.ID.Hex())
+	dao.logger.InfoContext(ctx, "created user selection", "selection_id", selection.ID.Hex())
 	return selection, nil
 }
 
@@ -133,7 +154,28 @@ func (dao *userSelectionDAOImpl) FindByUserMonthSpotifyItem(ctx context.Context,
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Printf("Error finding user selection by user/month/item: %v\n", err)
+		dao.logger.ErrorContext(ctx, "error finding user selection by user/month/item", "error", err)
+		return nil, fmt.Errorf("error finding selection: %w", err)
+	}
+	return &selection, nil
+}
+
+// FindByUserMonthCanonicalID finds a user's selection for a month whose item has the given canonical ID,
+// so another Spotify copy of an already selected recording or release can be recognised.
+// Returns mongo.ErrNoDocuments if there is none.
+func (dao *userSelectionDAOImpl) FindByUserMonthCanonicalID(ctx context.Context, userID, monthYear, canonicalID string) (*models.UserSelection, error) {
+	var selection models.UserSelection
+	filter := bson.M{
+		"user_id":      userID,
+		"month_year":   monthYear,
+		"canonical_id": canonicalID,
+	}
+	err := dao.collection.FindOne(ctx, filter).Decode(&selection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, mongo.ErrNoDocuments
+		}
+		dao.logger.ErrorContext(ctx, "error finding user selection by user/month/canonical id", "error", err)
 		return nil, fmt.Errorf("error finding selection: %w", err)
 	}
 	return &selection, nil
@@ -148,14 +190,14 @@ func (dao *userSelectionDAOImpl) FindByRole(ctx context.Context, userID, monthYe
 	}
 	cursor, err := dao.collection.Find(ctx, filter)
 	if err != nil {
-		log.Printf("Error finding selections by role '%s' for user '%s', month '%s': %v\n", role, userID, monthYear, err)
+		dao.logger.ErrorContext(ctx, "error finding selections by role", "role", role, "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("could not retrieve selections by role: %w", err)
 	}
 	defer cursor.Close(ctx)
 
 	var selections []*models.UserSelection
 	if err = cursor.All(ctx, &selections); err != nil {
-		log.Printf("Error decoding selections by role for user '%s', month '%s': %v\n", userID, monthYear, err)
+		dao.logger.ErrorContext(ctx, "error decoding selections by role", "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("could not decode selections by role: %w", err)
 	}
 	if selections == nil {
@@ -183,7 +225,7 @@ func (dao *userSelectionDAOImpl) FindSelected(ctx context.Context, userID, month
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments // Not found is not an error here
 		}
-		log.Printf("Error finding selected item role '%s' for user '%s', month '%s': %v\n", role, userID, monthYear, err)
+		dao.logger.ErrorContext(ctx, "error finding selected item", "role", role, "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("error finding selected item: %w", err)
 	}
 	return &selection, nil
@@ -199,13 +241,39 @@ func (dao *userSelectionDAOImpl) Update(ctx context.Context, selectionID primiti
 	err := dao.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedSelection)
 	if err != nil {
 		if err == mongo.ErrNoDocuments {
-			log.Printf("No user selection found with ID '%s' to update\n", selectionID.Hex())
+			dao.logger.WarnContext(ctx, "no user selection found to update", "selection_id", selectionID.Hex())
+			return nil, mongo.ErrNoDocuments
+		}
+		dao.logger.ErrorContext(ctx, "error updating user selection", "selection_id", selectionID.Hex(), "error", err)
+		return nil, fmt.Errorf("error updating selection: %w", err)
+	}
+	dao.logger.InfoContext(ctx, "updated user selection", "selection_id", selectionID.Hex())
+	return &updatedSelection, nil
+}
+
+// UpdateWithNotesRevision modifies a selection and records the previous notes in a single atomic update.
+func (dao *userSelectionDAOImpl) UpdateWithNotesRevision(ctx context.Context, selectionID primitive.ObjectID, updates bson.M, revision models.NotesRevision, maxRevisions int) (*models.UserSelection, error) {
+	filter := bson.M{"_id": selectionID}
+	update := bson.M{
+		"$set": updates,
+		"$push": bson.M{"notes_history": bson.M{
+			"$each":  []models.NotesRevision{revision},
+			"$slice": -maxRevisions, // Keep only the most recent revisions
+		}},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var updatedSelection models.UserSelection
+	err := dao.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&updatedSelection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			dao.logger.WarnContext(ctx, "no user selection found to update", "selection_id", selectionID.Hex())
 			return nil, mongo.ErrNoDocuments
 		}
-		log.Printf("Error updating user selection with ID '%s': %v\n", selectionID.Hex(), err)
+		dao.logger.ErrorContext(ctx, "error updating user selection", "selection_id", selectionID.Hex(), "error", err)
 		return nil, fmt.Errorf("error updating selection: %w", err)
 	}
-	log.Printf("Successfully updated user selection with ID '%s'\n", selectionID.Hex())
+	dao.logger.InfoContext(ctx, "updated user selection and recorded notes revision", "selection_id", selectionID.Hex())
 	return &updatedSelection, nil
 }
 
@@ -218,14 +286,14 @@ func (dao *userSelectionDAOImpl) UpdateRole(ctx context.Context, selectionID pri
 	}}
 	result, err := dao.collection.UpdateOne(ctx, filter, update)
 	if err != nil {
-		log.Printf("Error updating role for selection ID '%s': %v\n", selectionID.Hex(), err)
+		dao.logger.ErrorContext(ctx, "error updating selection role", "selection_id", selectionID.Hex(), "error", err)
 		return fmt.Errorf("error updating selection role: %w", err)
 	}
 	if result.MatchedCount == 0 {
-		log.Printf("No selection found with ID '%s' to update role\n", selectionID.Hex())
+		dao.logger.WarnContext(ctx, "no selection found to update role", "selection_id", selectionID.Hex())
 		return mongo.ErrNoDocuments
 	}
-	log.Printf("Successfully updated role for selection ID '%s' to '%s'\n", selectionID.Hex(), newRole)
+	dao.logger.InfoContext(ctx, "updated selection role", "selection_id", selectionID.Hex(), "role", newRole)
 	return nil
 }
 
@@ -234,14 +302,14 @@ func (dao *userSelectionDAOImpl) Delete(ctx context.Context, selectionID primiti
 	filter := bson.M{"_id": selectionID}
 	result, err := dao.collection.DeleteOne(ctx, filter)
 	if err != nil {
-		log.Printf("Error deleting user selection with ID '%s': %v\n", selectionID.Hex(), err)
+		dao.logger.ErrorContext(ctx, "error deleting user selection", "selection_id", selectionID.Hex(), "error", err)
 		return fmt.Errorf("error deleting selection: %w", err)
 	}
 	if result.DeletedCount == 0 {
-		log.Printf("No user selection found with ID '%s' to delete\n", selectionID.Hex())
+		dao.logger.WarnContext(ctx, "no user selection found to delete", "selection_id", selectionID.Hex())
 		return mongo.ErrNoDocuments // Indicate not found
 	}
-	log.Printf("Successfully deleted user selection with ID '%s'\n", selectionID.Hex())
+	dao.logger.InfoContext(ctx, "deleted user selection", "selection_id", selectionID.Hex())
 	return nil
 }
 
@@ -250,14 +318,14 @@ func (dao *userSelectionDAOImpl) ListByUserAndMonth(ctx context.Context, userID,
 	filter := bson.M{"user_id": userID, "month_year": monthYear}
 	cursor, err := dao.collection.Find(ctx, filter)
 	if err != nil {
-		log.Printf("Error listing selections for user '%s', month '%s': %v\n", userID, monthYear, err)
+		dao.logger.ErrorContext(ctx, "error listing selections", "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("could not retrieve selections: %w", err)
 	}
 	defer cursor.Close(ctx)
 
 	var selections []*models.UserSelection
 	if err = cursor.All(ctx, &selections); err != nil {
-		log.Printf("Error decoding selections for user '%s', month '%s': %v\n", userID, monthYear, err)
+		dao.logger.ErrorContext(ctx, "error decoding selections", "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("could not decode selections: %w", err)
 	}
 
@@ -269,6 +337,35 @@ func (dao *userSelectionDAOImpl) ListByUserAndMonth(ctx context.Context, userID,
 	return selections, nil
 }
 
+// ListByUser retrieves all selections for a user, sorted by month and then by when they were added.
+func (dao *userSelectionDAOImpl) ListByUser(ctx context.Context, userID string) ([]*models.UserSelection, error) {
+	opts := options.Find().SetSort(bson.D{{Key: "month_year", Value: 1}, {Key: "added_at", Value: 1}})
+	cursor, err := dao.collection.Find(ctx, bson.M{"user_id": userID}, opts)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error listing user's selections", "error", err)
+		return nil, fmt.Errorf("could not retrieve selections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	selections := []*models.UserSelection{}
+	if err = cursor.All(ctx, &selections); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding user's selections", "error", err)
+		return nil, fmt.Errorf("could not decode selections: %w", err)
+	}
+	return selections, nil
+}
+
+// DeleteByUser removes every selection belonging to the user.
+func (dao *userSelectionDAOImpl) DeleteByUser(ctx context.Context, userID string) (int64, error) {
+	result, err := dao.collection.DeleteMany(ctx, bson.M{"user_id": userID})
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error deleting user's selections", "error", err)
+		return 0, fmt.Errorf("error deleting selections: %w", err)
+	}
+	dao.logger.InfoContext(ctx, "deleted user's selections", "count", result.DeletedCount)
+	return result.DeletedCount, nil
+}
+
 // GetByID retrieves a single selection by its MongoDB ObjectID.
 func (dao *userSelectionDAOImpl) GetByID(ctx context.Context, selectionID primitive.ObjectID) (*models.UserSelection, error) {
 	var selection models.UserSelection
@@ -278,7 +375,7 @@ func (dao *userSelectionDAOImpl) GetByID(ctx context.Context, selectionID primit
 		if err == mongo.ErrNoDocuments {
 			return nil, mongo.ErrNoDocuments // Return specific error for not found
 		}
-		log.Printf("Error finding user selection by ID '%s': %v\n", selectionID.Hex(), err)
+		dao.logger.ErrorContext(ctx, "error finding user selection", "selection_id", selectionID.Hex(), "error", err)
 		return nil, fmt.Errorf("error finding selection by ID: %w", err)
 	}
 	return &selection, nil
@@ -315,3 +412,320 @@ func (dao *userSelectionDAOImpl) GetUserSelectionsForYear(ctx context.Context, u
 
 	return selections, nil
 }
+
+// SearchText runs a $text query scoped to a user (and optionally a year), sorted by textScore.
+func (dao *userSelectionDAOImpl) SearchText(ctx context.Context, userID, query string, year int, limit int64) ([]*models.JournalSearchResult, error) {
+	filter := bson.M{
+		"user_id": userID,
+		"$text":   bson.M{"$search": query},
+	}
+	if year != 0 {
+		filter["month_year"] = bson.M{
+			"$gte": fmt.Sprintf("%d-01", year),
+			"$lte": fmt.Sprintf("%d-12", year),
+		}
+	}
+
+	score := bson.M{"$meta": "textScore"}
+	opts := options.Find().
+		SetProjection(bson.M{"score": score}).
+		SetSort(bson.D{{Key: "score", Value: score}, {Key: "month_year", Value: -1}}).
+		SetLimit(limit)
+
+	cursor, err := dao.collection.Find(ctx, filter, opts)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error running text search", "error", err)
+		return nil, fmt.Errorf("error searching selections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []*models.JournalSearchResult
+	if err = cursor.All(ctx, &results); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding text search results", "error", err)
+		return nil, fmt.Errorf("error decoding search results: %w", err)
+	}
+	if results == nil {
+		results = []*models.JournalSearchResult{}
+	}
+	return results, nil
+}
+
+// ListMoodTags aggregates the user's mood tags with usage counts, most used first.
+func (dao *userSelectionDAOImpl) ListMoodTags(ctx context.Context, userID string) ([]*models.MoodTagCount, error) {
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": userID, "mood_tags.0": bson.M{"$exists": true}}}},
+		{{Key: "$unwind", Value: "$mood_tags"}},
+		{{Key: "$group", Value: bson.M{"_id": "$mood_tags", "count": bson.M{"$sum": 1}}}},
+		{{Key: "$sort", Value: bson.D{{Key: "count", Value: -1}, {Key: "_id", Value: 1}}}},
+	}
+	cursor, err := dao.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error aggregating mood tags", "error", err)
+		return nil, fmt.Errorf("could not aggregate mood tags: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tags []*models.MoodTagCount
+	if err = cursor.All(ctx, &tags); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding mood tags", "error", err)
+		return nil, fmt.Errorf("could not decode mood tags: %w", err)
+	}
+	if tags == nil {
+		tags = []*models.MoodTagCount{}
+	}
+	return tags, nil
+}
+
+// FindByMoodTag retrieves a user's selections carrying the given mood tag.
+func (dao *userSelectionDAOImpl) FindByMoodTag(ctx context.Context, userID, tag string, year int) ([]*models.UserSelection, error) {
+	filter := bson.M{"user_id": userID, "mood_tags": tag}
+	if year != 0 {
+		filter["month_year"] = bson.M{
+			"$gte": fmt.Sprintf("%d-01", year),
+			"$lte": fmt.Sprintf("%d-12", year),
+		}
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "month_year", Value: -1}, {Key: "added_at", Value: -1}})
+
+	cursor, err := dao.collection.Find(ctx, filter, opts)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error finding selections by mood tag", "tag", tag, "error", err)
+		return nil, fmt.Errorf("could not retrieve selections by mood tag: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var selections []*models.UserSelection
+	if err = cursor.All(ctx, &selections); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding selections by mood tag", "tag", tag, "error", err)
+		return nil, fmt.Errorf("could not decode selections by mood tag: %w", err)
+	}
+	if selections == nil {
+		selections = []*models.UserSelection{}
+	}
+	return selections, nil
+}
+
+// FindByIDs retrieves all selections whose _id is in selectionIDs.
+func (dao *userSelectionDAOImpl) FindByIDs(ctx context.Context, selectionIDs []primitive.ObjectID) ([]*models.UserSelection, error) {
+	if len(selectionIDs) == 0 {
+		return []*models.UserSelection{}, nil
+	}
+	filter := bson.M{"_id": bson.M{"$in": selectionIDs}}
+	cursor, err := dao.collection.Find(ctx, filter)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error finding selections by ID", "count", len(selectionIDs), "error", err)
+		return nil, fmt.Errorf("could not retrieve selections by ID: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var selections []*models.UserSelection
+	if err = cursor.All(ctx, &selections); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding selections by ID", "error", err)
+		return nil, fmt.Errorf("could not decode selections by ID: %w", err)
+	}
+	if selections == nil {
+		selections = []*models.UserSelection{}
+	}
+	return selections, nil
+}
+
+// BulkApply translates the operations into write models and runs them in one unordered BulkWrite,
+// so a failing operation does not stop the others. Updates that select a Muse or Ick run on their own
+// afterwards: the previous holder is only demoted once the update has matched its selection, so a failed
+// or vanished target never leaves the slot empty.
+func (dao *userSelectionDAOImpl) BulkApply(ctx context.Context, ops []SelectionBatchOp, maxNotesRevisions int) ([]error, error) {
+	opErrors := make([]error, len(ops))
+	if len(ops) == 0 {
+		return opErrors, nil
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var writeModels []mongo.WriteModel
+	var owners []int    // owners[i] is the index in ops of writeModels[i]
+	var selecting []int // Updates with a demotion, applied after the bulk write
+	for i, op := range ops {
+		switch op.Type {
+		case SelectionBatchCreate:
+			op.Selection.ID = primitive.NewObjectID()
+			if op.Selection.AddedAt == 0 {
+				op.Selection.AddedAt = now
+			}
+			op.Selection.UpdatedAt = now
+			writeModels = append(writeModels, mongo.NewInsertOneModel().SetDocument(op.Selection))
+			owners = append(owners, i)
+		case SelectionBatchUpdate:
+			if op.Demote != nil {
+				selecting = append(selecting, i)
+				continue
+			}
+			writeModels = append(writeModels, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": op.SelectionID, "user_id": op.UserID}).
+				SetUpdate(selectionBatchUpdate(op, maxNotesRevisions)))
+			owners = append(owners, i)
+		case SelectionBatchDelete:
+			writeModels = append(writeModels, mongo.NewDeleteOneModel().
+				SetFilter(bson.M{"_id": op.SelectionID, "user_id": op.UserID}))
+			owners = append(owners, i)
+		default:
+			opErrors[i] = fmt.Errorf("unsupported batch operation: %s", op.Type)
+		}
+	}
+
+	if len(writeModels) > 0 {
+		opts := options.BulkWrite().SetOrdered(false)
+		result, err := dao.collection.BulkWrite(ctx, writeModels, opts)
+		if err != nil {
+			var bulkErr mongo.BulkWriteException
+			if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+				dao.logger.ErrorContext(ctx, "error running selection bulk write", "operations", len(ops), "error", err)
+				return nil, fmt.Errorf("error applying selection batch: %w", err)
+			}
+			for _, writeErr := range bulkErr.WriteErrors {
+				owner := owners[writeErr.Index]
+				if writeErr.HasErrorCode(11000) { // Duplicate key on the (user_id, month_year, spotify_item_id) index
+					opErrors[owner] = ErrSelectionExists
+				} else {
+					opErrors[owner] = fmt.Errorf("error applying %s operation: %s", ops[owner].Type, writeErr.Message)
+				}
+			}
+		}
+		if result != nil {
+			dao.logger.InfoContext(ctx, "applied selection batch",
+				"inserted", result.InsertedCount, "modified", result.ModifiedCount, "deleted", result.DeletedCount)
+		}
+	}
+
+	for _, i := range selecting {
+		opErrors[i] = dao.applySelectingUpdate(ctx, ops[i], maxNotesRevisions, now)
+	}
+	return opErrors, nil
+}
+
+// applySelectingUpdate applies an update that gives its selection a selected role, then demotes whichever
+// other selection held that role. Nothing is demoted unless the update matched the selection.
+func (dao *userSelectionDAOImpl) applySelectingUpdate(ctx context.Context, op SelectionBatchOp, maxNotesRevisions int, now primitive.DateTime) error {
+	result, err := dao.collection.UpdateOne(ctx, bson.M{"_id": op.SelectionID, "user_id": op.UserID}, selectionBatchUpdate(op, maxNotesRevisions))
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error applying selecting update in batch", "selection_id", op.SelectionID.Hex(), "error", err)
+		return fmt.Errorf("error applying update operation: %w", err)
+	}
+	if result.MatchedCount != 1 {
+		return fmt.Errorf("selection not found: %w", mongo.ErrNoDocuments)
+	}
+	_, err = dao.collection.UpdateMany(ctx,
+		bson.M{
+			"_id":            bson.M{"$ne": op.SelectionID},
+			"user_id":        op.UserID,
+			"month_year":     op.Demote.MonthYear,
+			"item_type":      op.Demote.ItemType,
+			"selection_role": op.Demote.From,
+		},
+		bson.M{"$set": bson.M{"selection_role": op.Demote.To, "updated_at": now}})
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error demoting previous selection in batch", "selection_id", op.SelectionID.Hex(), "error", err)
+		return fmt.Errorf("selection updated but the previous %s could not be demoted: %w", op.Demote.From, err)
+	}
+	return nil
+}
+
+// selectionBatchUpdate builds the update document for a batch update, appending replaced notes to the history.
+func selectionBatchUpdate(op SelectionBatchOp, maxNotesRevisions int) bson.M {
+	update := bson.M{"$set": op.Updates}
+	if op.NotesRevision != nil {
+		update["$push"] = bson.M{"notes_history": bson.M{
+			"$each":  []models.NotesRevision{*op.NotesRevision},
+			"$slice": -maxNotesRevisions,
+		}}
+	}
+	return update
+}
+
+// AggregateYearlyPicks runs one pipeline over user_selections: it groups selections by year and item (by
+// canonical ID where known, like UserSelection.GroupKey), collects the months in each role, then looks up the
+// credited artists of tracks and albums and the cached artists themselves.
+func (dao *userSelectionDAOImpl) AggregateYearlyPicks(ctx context.Context, userID string, years []int, museRoles, ickRoles []string) ([]*models.YearlyPick, error) {
+	match := bson.M{
+		"user_id":        userID,
+		"selection_role": bson.M{"$in": append(append([]string{}, museRoles...), ickRoles...)},
+	}
+	yearMatch := bson.M{}
+	if len(years) > 0 {
+		minYear, maxYear := years[0], years[0]
+		for _, year := range years {
+			minYear, maxYear = min(minYear, year), max(maxYear, year)
+		}
+		// The range uses the (user_id, month_year) index; the years themselves are matched once parsed
+		match["month_year"] = bson.M{"$gte": fmt.Sprintf("%d-01", minYear), "$lte": fmt.Sprintf("%d-12", maxYear)}
+		yearMatch["year"] = bson.M{"$in": years}
+	}
+	monthIfRole := func(roles []string) bson.M {
+		return bson.M{"$addToSet": bson.M{"$cond": bson.A{bson.M{"$in": bson.A{"$selection_role", roles}}, "$month_year", nil}}}
+	}
+	// The credits of the first matching cached item, or none
+	firstCredits := func(field string) bson.M {
+		return bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$" + field + ".artists", 0}}, bson.A{}}}
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$addFields", Value: bson.M{
+			"year": bson.M{"$toInt": bson.M{"$substrCP": bson.A{"$month_year", 0, 4}}},
+			"group_key": bson.M{"$cond": bson.A{
+				bson.M{"$gt": bson.A{bson.M{"$ifNull": bson.A{"$canonical_id", ""}}, ""}},
+				"$canonical_id",
+				bson.M{"$concat": bson.A{"spotify:", "$item_type", ":", "$spotify_item_id"}},
+			}},
+		}}},
+		{{Key: "$match", Value: yearMatch}},
+		{{Key: "$sort", Value: bson.D{{Key: "month_year", Value: 1}, {Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":             bson.M{"year": "$year", "group_key": "$group_key"},
+			"item_type":       bson.M{"$first": "$item_type"},
+			"spotify_item_id": bson.M{"$first": "$spotify_item_id"},
+			"item_name":       bson.M{"$first": "$item_name"},
+			"artist_names":    bson.M{"$first": "$artist_names"},
+			"muse_months":     monthIfRole(museRoles),
+			"ick_months":      monthIfRole(ickRoles),
+		}}},
+		{{Key: "$lookup", Value: bson.M{"from": SpotifyTracksCollection, "localField": "spotify_item_id", "foreignField": "_id", "as": "track"}}},
+		{{Key: "$lookup", Value: bson.M{"from": SpotifyAlbumsCollection, "localField": "spotify_item_id", "foreignField": "_id", "as": "album"}}},
+		{{Key: "$addFields", Value: bson.M{
+			"artist_credits": bson.M{"$switch": bson.M{
+				"branches": bson.A{
+					bson.M{"case": bson.M{"$eq": bson.A{"$item_type", "track"}}, "then": firstCredits("track")},
+					bson.M{"case": bson.M{"$eq": bson.A{"$item_type", "album"}}, "then": firstCredits("album")},
+					bson.M{"case": bson.M{"$eq": bson.A{"$item_type", "artist"}}, "then": bson.A{bson.M{"id": "$spotify_item_id", "name": "$item_name"}}},
+				},
+				"default": bson.A{},
+			}},
+		}}},
+		{{Key: "$lookup", Value: bson.M{"from": SpotifyArtistsCollection, "localField": "artist_credits.id", "foreignField": "_id", "as": "artists"}}},
+		{{Key: "$project", Value: bson.M{
+			"_id":             0,
+			"year":            "$_id.year",
+			"group_key":       "$_id.group_key",
+			"item_type":       1,
+			"spotify_item_id": 1,
+			"item_name":       1,
+			"artist_names":    1,
+			"muse_months":     bson.M{"$sortArray": bson.M{"input": bson.M{"$setDifference": bson.A{"$muse_months", bson.A{nil}}}, "sortBy": 1}},
+			"ick_months":      bson.M{"$sortArray": bson.M{"input": bson.M{"$setDifference": bson.A{"$ick_months", bson.A{nil}}}, "sortBy": 1}},
+			"artist_credits":  bson.M{"id": 1, "name": 1},
+			"artists":         bson.M{"_id": 1, "name": 1, "genres": 1},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "year", Value: 1}, {Key: "group_key", Value: 1}}}},
+	}
+	cursor, err := dao.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		dao.logger.ErrorContext(ctx, "error aggregating yearly picks", "error", err)
+		return nil, fmt.Errorf("could not aggregate yearly picks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	picks := []*models.YearlyPick{}
+	if err = cursor.All(ctx, &picks); err != nil {
+		dao.logger.ErrorContext(ctx, "error decoding yearly picks", "error", err)
+		return nil, fmt.Errorf("could not decode yearly picks: %w", err)
+	}
+	return picks, nil
+}
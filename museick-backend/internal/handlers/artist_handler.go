@@ -0,0 +1,79 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/services"
+	"github.com/zmb3/spotify/v2"
+)
+
+// marketRegex matches an ISO 3166-1 alpha-2 country code, as Spotify expects for markets.
+var marketRegex = regexp.MustCompile(`^[A-Z]{2}$`)
+
+// ArtistHandler serves cached artist details so the frontend doesn't have to call Spotify itself.
+type ArtistHandler struct {
+	syncService      *services.SpotifySyncService
+	refreshThreshold time.Duration
+	logger           *slog.Logger
+}
+
+// NewArtistHandler creates a new ArtistHandler. Cached data older than refreshThreshold is re-fetched from Spotify.
+func NewArtistHandler(syncService *services.SpotifySyncService, refreshThreshold time.Duration, logger *slog.Logger) *ArtistHandler {
+	return &ArtistHandler{
+		syncService:      syncService,
+		refreshThreshold: refreshThreshold,
+		logger:           logger.With("component", "artist_handler"),
+	}
+}
+
+// GetArtist handles GET /api/artists/:id
+// @Summary Get artist details
+// @Description Returns an artist (including followers) with its related artists and top tracks in a market, from the cache.
+// @Description Anything missing or stale is fetched from Spotify with the caller's token. Related artists and top tracks are
+// @Description best effort: they come back empty when Spotify can't provide them.
+// @Tags artists
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param X-Spotify-Token header string false "Spotify access token, needed when the artist is not cached or is stale"
+// @Param id path string true "Spotify artist ID"
+// @Param market query string false "ISO 3166-1 alpha-2 country code for top tracks" default(US)
+// @Success 200 {object} models.ArtistDetailsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid market or missing Spotify token"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 404 {object} models.ErrorResponse "Artist not found"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/artists/{id} [get]
+// @Security BearerAuth
+func (h *ArtistHandler) GetArtist(c *gin.Context) {
+	artistID := c.Param("id")
+	market := strings.ToUpper(c.DefaultQuery("market", services.DefaultMarket))
+	if !marketRegex.MatchString(market) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid market, expected an ISO 3166-1 alpha-2 country code such as US"})
+		return
+	}
+
+	details, err := h.syncService.GetArtistDetails(c.Request.Context(), artistID, market, c.GetHeader("X-Spotify-Token"), h.refreshThreshold)
+	if err != nil {
+		var spotifyErr spotify.Error
+		switch {
+		case errors.As(err, &spotifyErr) && (spotifyErr.Status == http.StatusNotFound || spotifyErr.Status == http.StatusBadRequest):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Artist not found"})
+		case errors.As(err, &spotifyErr) && (spotifyErr.Status == http.StatusUnauthorized || spotifyErr.Status == http.StatusForbidden):
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to authenticate with Spotify. Please ensure your account is linked."})
+		case strings.Contains(err.Error(), "spotify token missing"):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Spotify token is required to fetch this artist"})
+		default:
+			h.logger.ErrorContext(c.Request.Context(), "error getting artist details", "artist_id", artistID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get artist"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, details)
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/health"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+// HealthHandler serves the liveness and readiness probes.
+type HealthHandler struct {
+	checker *health.Checker
+	logger  *slog.Logger
+}
+
+// NewHealthHandler creates a new HealthHandler.
+func NewHealthHandler(checker *health.Checker, logger *slog.Logger) *HealthHandler {
+	return &HealthHandler{checker: checker, logger: logger.With("component", "health_handler")}
+}
+
+// Welcome handles GET /
+// @Summary API welcome
+// @Description Confirms the API is reachable.
+// @Tags health
+// @Produce json
+// @Success 200 {object} models.WelcomeResponse "Welcome message"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Router / [get]
+func Welcome(c *gin.Context) {
+	c.JSON(http.StatusOK, models.WelcomeResponse{Status: "success", Message: "Welcome to Museick API"})
+}
+
+// Ping handles GET /ping
+// @Summary Ping
+// @Description Returns "pong". Kept for the Railway and Docker health checks.
+// @Tags health
+// @Produce json
+// @Success 200 {string} string "pong"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Router /ping [get]
+func Ping(c *gin.Context) {
+	c.JSON(http.StatusOK, "pong")
+}
+
+// Liveness handles GET /healthz
+// @Summary Liveness probe
+// @Description Reports that the process is running and serving HTTP. Does not check dependencies, so a database outage will not get the process restarted.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string "Process is alive"
+// @Router /healthz [get]
+func (h *HealthHandler) Liveness(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": health.StatusOK})
+}
+
+// Readiness handles GET /readyz
+// @Summary Readiness probe
+// @Description Checks MongoDB connectivity, the presence of required indexes and (from a cached background probe) Spotify token endpoint reachability. Fails while the server is shutting down so traffic drains away.
+// @Tags health
+// @Produce json
+// @Success 200 {object} health.Report "All dependencies are ready"
+// @Failure 503 {object} health.Report "At least one dependency is failing, or the server is shutting down"
+// @Router /readyz [get]
+func (h *HealthHandler) Readiness(c *gin.Context) {
+	report, ready := h.checker.Ready(c.Request.Context())
+	if !ready {
+		h.logger.WarnContext(c.Request.Context(), "readiness check failing", "status", report.Status, "checks", report.Checks)
+		c.JSON(http.StatusServiceUnavailable, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// DBHealth handles GET /db_health. Kept for existing monitors; prefer /readyz.
+// @Summary MongoDB health (deprecated)
+// @Description Runs only the MongoDB ping check. Use /readyz instead.
+// @Tags health
+// @Produce json
+// @Success 200 {object} map[string]string "MongoDB connection healthy"
+// @Failure 500 {object} map[string]string "MongoDB connection unhealthy"
+// @Router /db_health [get]
+// @Deprecated
+func (h *HealthHandler) DBHealth(c *gin.Context) {
+	if result := h.checker.Run(c.Request.Context(), CheckMongo); result.Status != health.StatusOK {
+		c.JSON(http.StatusInternalServerError, gin.H{"status": "MongoDB connection unhealthy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "MongoDB connection healthy"})
+}
+
+// Names of the readiness checks registered in main.
+const (
+	CheckMongo        = "mongo"
+	CheckMongoIndexes = "mongo_indexes"
+	CheckSpotify      = "spotify"
+)
@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/services"
+	"github.com/seven7een/museick/museick-backend/middleware"
+)
+
+// JournalHandler handles HTTP requests related to the selection journal (notes, stories and mood tags).
+type JournalHandler struct {
+	journalService *services.JournalService
+	logger         *slog.Logger
+}
+
+// NewJournalHandler creates a new JournalHandler.
+func NewJournalHandler(svc *services.JournalService, logger *slog.Logger) *JournalHandler {
+	return &JournalHandler{journalService: svc, logger: logger.With("component", "journal_handler")}
+}
+
+// SearchJournal handles GET /api/journal/search
+// @Summary Search selection notes
+// @Description Full-text search over the user's selection notes and the track, album and artist names of their selections. Results are ranked by relevance and include highlighted snippets.
+// @Tags journal
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param q query string true "Search query (MongoDB text search syntax: quoted phrases and -negation are supported)"
+// @Param year query int false "Restrict results to a calendar year" Example(2024)
+// @Success 200 {array} models.JournalSearchHit "Matching selections, most relevant first"
+// @Failure 400 {object} models.ErrorResponse "Missing or invalid query or year"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/journal/search [get]
+// @Security BearerAuth
+func (h *JournalHandler) SearchJournal(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+
+	query := c.Query("q")
+	year := 0
+	if yearParam := c.Query("year"); yearParam != "" {
+		parsed, err := strconv.Atoi(yearParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year, expected YYYY"})
+			return
+		}
+		year = parsed
+	}
+
+	hits, err := h.journalService.SearchJournal(c.Request.Context(), userID, query, year)
+	if err != nil {
+		if strings.Contains(err.Error(), "search query") || strings.Contains(err.Error(), "invalid year") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error searching journal", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search journal"})
+		return
+	}
+
+	c.JSON(http.StatusOK, hits)
+}
+
+// ListMoodTags handles GET /api/journal/tags
+// @Summary List mood tags
+// @Description Lists every mood tag the user has applied to a selection, with usage counts, most used first.
+// @Tags journal
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {array} models.MoodTagCount "Mood tags with counts"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/journal/tags [get]
+// @Security BearerAuth
+func (h *JournalHandler) ListMoodTags(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+
+	tags, err := h.journalService.ListMoodTags(c.Request.Context(), userID)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "error listing mood tags", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list mood tags"})
+		return
+	}
+
+	c.JSON(http.StatusOK, tags)
+}
+
+// ListSelectionsByMoodTag handles GET /api/journal/tags/:tag
+// @Summary List selections by mood tag
+// @Description Retrieves the user's selections carrying a mood tag, newest month first.
+// @Tags journal
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param tag path string true "Mood tag (case-insensitive)"
+// @Param year query int false "Restrict results to a calendar year" Example(2024)
+// @Success 200 {array} models.UserSelection "Tagged selections"
+// @Failure 400 {object} models.ErrorResponse "Invalid tag or year"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/journal/tags/{tag} [get]
+// @Security BearerAuth
+func (h *JournalHandler) ListSelectionsByMoodTag(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+
+	tag := c.Param("tag")
+	year := 0
+	if yearParam := c.Query("year"); yearParam != "" {
+		parsed, err := strconv.Atoi(yearParam)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year, expected YYYY"})
+			return
+		}
+		year = parsed
+	}
+
+	selections, err := h.journalService.ListSelectionsByMoodTag(c.Request.Context(), userID, tag, year)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidJournalEntry) || strings.Contains(err.Error(), "invalid year") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error listing selections by mood tag", "tag", tag, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list selections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, selections)
+}
@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/media"
+)
+
+// Mirrored objects are content-addressed and never change, so clients and proxies may keep them for a year.
+const mediaCacheControl = "public, max-age=31536000, immutable"
+
+// MediaHandler serves images mirrored from Spotify's CDN.
+type MediaHandler struct {
+	store  media.Store
+	logger *slog.Logger
+}
+
+// NewMediaHandler creates a new MediaHandler.
+func NewMediaHandler(store media.Store, logger *slog.Logger) *MediaHandler {
+	return &MediaHandler{
+		store:  store,
+		logger: logger.With("component", "media_handler"),
+	}
+}
+
+// GetMedia handles GET /media/:hash
+// @Summary Get a mirrored image
+// @Description Serves an album or artist image mirrored from Spotify, by the mirror_hash on a cached image.
+// @Description Without size, the original is returned as Spotify served it. With size, a square thumbnail is
+// @Description returned as JPEG, or as WebP with format=webp. Responses are immutable and cached for a year.
+// @Tags media
+// @Produce image/jpeg
+// @Produce image/png
+// @Produce image/webp
+// @Param hash path string true "Image hash (hex SHA-256)"
+// @Param size query int false "Thumbnail edge in pixels" Enums(64, 160, 300)
+// @Param format query string false "Thumbnail format" Enums(jpeg, webp)
+// @Success 200 {file} file "The image"
+// @Success 304 "Not modified"
+// @Failure 400 {object} models.ErrorResponse "Invalid hash, size or format"
+// @Failure 404 {object} models.ErrorResponse "Image not mirrored"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /media/{hash} [get]
+func (h *MediaHandler) GetMedia(c *gin.Context) {
+	size := 0
+	if raw := c.Query("size"); raw != "" {
+		var err error
+		if size, err = strconv.Atoi(raw); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid size, expected a number of pixels"})
+			return
+		}
+	}
+	name, err := media.ObjectName(c.Param("hash"), size, c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid media request: " + err.Error()})
+		return
+	}
+
+	data, err := h.store.Get(c.Request.Context(), name)
+	if errors.Is(err, media.ErrNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Image not found"})
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "error reading media object", "name", name, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read image"})
+		return
+	}
+
+	header := c.Writer.Header()
+	header.Set("Content-Type", http.DetectContentType(data))
+	header.Set("Cache-Control", mediaCacheControl)
+	header.Set("ETag", `"`+name+`"`)
+	header.Set("X-Content-Type-Options", "nosniff")
+	// ServeContent answers If-None-Match with 304 and handles Range requests
+	http.ServeContent(c.Writer, c.Request, name, time.Time{}, bytes.NewReader(data))
+}
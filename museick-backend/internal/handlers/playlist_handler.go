@@ -1,30 +1,50 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/models"
 	"github.com/seven7een/museick/museick-backend/internal/services"
 	"github.com/seven7een/museick/museick-backend/middleware"
 )
 
+// PlaylistHandler handles HTTP requests for exporting selections to Spotify playlists.
 type PlaylistHandler struct {
 	playlistService *services.PlaylistService
+	logger          *slog.Logger
 }
 
-func NewPlaylistHandler(playlistService *services.PlaylistService) *PlaylistHandler {
+// NewPlaylistHandler creates a new PlaylistHandler.
+func NewPlaylistHandler(playlistService *services.PlaylistService, logger *slog.Logger) *PlaylistHandler {
 	return &PlaylistHandler{
 		playlistService: playlistService,
+		logger:          logger.With("component", "playlist_handler"),
 	}
 }
 
+// CreatePlaylist handles POST /api/playlists
+// @Summary Create a yearly playlist
+// @Description Creates a Spotify playlist in the user's account from a year's Muse or Ick selections, optionally including candidates.
+// @Description Tracks are checked against the user's market: unavailable ones are skipped and relinked ones are added as their
+// @Description playable copy. The response lists both.
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param X-Spotify-Token header string true "Spotify access token of the user the playlist is created for"
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
+// @Param playlist body models.CreatePlaylistRequest true "Year, mode (muse or ick) and whether to include candidates"
+// @Success 201 {object} models.CreatePlaylistResponse "Playlist created"
+// @Failure 400 {object} models.ErrorResponse "Invalid request format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Playlist could not be created"
+// @Router /api/playlists [post]
+// @Security BearerAuth
 func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
-	var request struct {
-		Year              int    `json:"year" binding:"required"`
-		Mode              string `json:"mode" binding:"required,oneof=muse ick"`
-		IncludeCandidates bool   `json:"include_candidates"`
-	}
+	var request models.CreatePlaylistRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format"})
@@ -34,7 +54,7 @@ func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
 	userID := c.GetString(middleware.ClerkUserIDKey)
 	spotifyToken := c.GetHeader("X-Spotify-Token")
 
-	playlistURL, err := h.playlistService.CreateYearlyPlaylist(
+	response, err := h.playlistService.CreateYearlyPlaylist(
 		c.Request.Context(),
 		userID,
 		spotifyToken,
@@ -44,13 +64,11 @@ func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
 	)
 
 	if err != nil {
-		log.Printf("Error creating playlist: %v", err)
+		h.logger.ErrorContext(c.Request.Context(), "error creating playlist", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create playlist"})
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{
-		"message": "Playlist created successfully",
-		"url":     playlistURL,
-	})
+	response.Message = "Playlist created successfully"
+	c.JSON(http.StatusCreated, response)
 }
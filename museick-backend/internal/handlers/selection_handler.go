@@ -3,7 +3,7 @@ package handlers
 import (
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"strings"
 
@@ -17,11 +17,12 @@ import (
 // SelectionHandler handles HTTP requests related to user selections.
 type SelectionHandler struct {
 	selectionService *services.UserSelectionService
+	logger           *slog.Logger
 }
 
 // NewSelectionHandler creates a new SelectionHandler.
-func NewSelectionHandler(svc *services.UserSelectionService) *SelectionHandler {
-	return &SelectionHandler{selectionService: svc}
+func NewSelectionHandler(svc *services.UserSelectionService, logger *slog.Logger) *SelectionHandler {
+	return &SelectionHandler{selectionService: svc, logger: logger.With("component", "selection_handler")}
 }
 
 // CreateSelection handles POST /api/selections
@@ -31,12 +32,15 @@ func NewSelectionHandler(svc *services.UserSelectionService) *SelectionHandler {
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
-// @Param selection body models.CreateSelectionRequest true "Candidate Selection Data" // Specify package
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
+// @Param X-Spotify-Token header string true "Spotify access token, used to verify the item exists"
+// @Param selection body models.CreateSelectionRequest true "Candidate Selection Data"
 // @Success 201 {object} models.UserSelection "Selection candidate created successfully"
 // @Success 200 {object} models.UserSelection "Selection already existed"
-// @Failure 400 {object} gin.H "Invalid input format or data"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 500 {object} gin.H "Internal server error"
+// @Failure 400 {object} models.ErrorResponse "Invalid input format or data"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/selections [post]
 // @Security BearerAuth
 func (h *SelectionHandler) CreateSelection(c *gin.Context) {
@@ -66,6 +70,10 @@ func (h *SelectionHandler) CreateSelection(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to verify Spotify item. Please ensure your account is linked and try again."})
 			return
 		}
+		if errors.Is(err, services.ErrInvalidJournalEntry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if strings.Contains(err.Error(), "spotify client not configured") || strings.Contains(err.Error(), "user tokens not found") {
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to authenticate with Spotify. Please ensure your account is linked."})
 			return
@@ -85,9 +93,9 @@ func (h *SelectionHandler) CreateSelection(c *gin.Context) {
 // @Param Authorization header string true "Bearer token"
 // @Param monthYear path string true "Month and Year (YYYY-MM)" Format(YYYY-MM) Example(2024-07)
 // @Success 200 {array} models.UserSelection "List of selections"
-// @Failure 400 {object} gin.H "Invalid monthYear format"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 500 {object} gin.H "Internal server error"
+// @Failure 400 {object} models.ErrorResponse "Invalid monthYear format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/selections/{monthYear} [get]
 // @Security BearerAuth
 func (h *SelectionHandler) ListSelectionsByMonth(c *gin.Context) {
@@ -105,7 +113,7 @@ func (h *SelectionHandler) ListSelectionsByMonth(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		log.Printf("Error listing selections for user %s, month %s: %v", userID, monthYear, err)
+		h.logger.ErrorContext(c.Request.Context(), "error listing selections", "month_year", monthYear, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve selections"})
 		return
 	}
@@ -114,27 +122,22 @@ func (h *SelectionHandler) ListSelectionsByMonth(c *gin.Context) {
 	c.JSON(http.StatusOK, selections)
 }
 
-// UpdateSelectionRequest defines the expected JSON body for PUT /api/selections/:id
-type UpdateSelectionRequest struct {
-	Role  *models.SelectionRole `json:"selection_role"` // Pointer to distinguish between not provided and empty string
-	Notes *string               `json:"notes"`          // Pointer
-}
-
 // UpdateSelection handles PUT /api/selections/:id
-// @Summary Update a selection's role or notes
-// @Description Updates the role (e.g., candidate to selected) or notes of a specific selection. Handles demotion of previous selection if needed.
+// @Summary Update a selection's role or journal entry
+// @Description Updates the role (e.g., candidate to selected), notes, mood tags or listening context of a specific selection. Handles demotion of previous selection if needed. Replaced notes are kept in notes_history.
 // @Tags selections
 // @Accept json
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
 // @Param id path string true "Selection ID (MongoDB ObjectID)"
-// @Param selection body UpdateSelectionRequest true "Fields to update (selection_role and/or notes)"
+// @Param selection body models.UpdateSelectionRequest true "Fields to update (selection_role, notes, mood_tags and/or listening_context)"
 // @Success 200 {object} models.UserSelection "Selection updated successfully"
-// @Failure 400 {object} gin.H "Invalid input format, data, or ID"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 403 {object} gin.H "Forbidden (selection does not belong to user)"
-// @Failure 404 {object} gin.H "Selection not found"
-// @Failure 500 {object} gin.H "Internal server error"
+// @Failure 400 {object} models.ErrorResponse "Invalid input format, data, or ID"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden (selection does not belong to user)"
+// @Failure 404 {object} models.ErrorResponse "Selection not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/selections/{id} [put]
 // @Security BearerAuth
 func (h *SelectionHandler) UpdateSelection(c *gin.Context) {
@@ -146,9 +149,9 @@ func (h *SelectionHandler) UpdateSelection(c *gin.Context) {
 
 	selectionID := c.Param("id") // Get selection's MongoDB ID from URL path
 
-	var req UpdateSelectionRequest
+	var req models.UpdateSelectionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON for UpdateSelection: %v", err)
+		h.logger.DebugContext(c.Request.Context(), "error binding JSON for UpdateSelection", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid request body: %s", err.Error())})
 		return
 	}
@@ -166,10 +169,12 @@ func (h *SelectionHandler) UpdateSelection(c *gin.Context) {
 	}
 
 	input := services.UpdateSelectionInput{
-		SelectionID: selectionID,
-		UserID:      userID,        // Pass UserID for authorization check in service
-		Role:        validatedRole, // Pass validated role pointer
-		Notes:       req.Notes,
+		SelectionID:      selectionID,
+		UserID:           userID,        // Pass UserID for authorization check in service
+		Role:             validatedRole, // Pass validated role pointer
+		Notes:            req.Notes,
+		MoodTags:         req.MoodTags,
+		ListeningContext: req.ListeningContext,
 	}
 
 	updatedSelection, err := h.selectionService.UpdateSelection(c.Request.Context(), input)
@@ -185,11 +190,15 @@ func (h *SelectionHandler) UpdateSelection(c *gin.Context) {
 			return
 		}
 		// Check for bad request errors
+		if errors.Is(err, services.ErrInvalidJournalEntry) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		if strings.Contains(err.Error(), "invalid selection ID format") || strings.Contains(err.Error(), "no updates provided") || strings.Contains(err.Error(), "invalid target selection role") {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
-		log.Printf("Error updating selection ID %s for user %s: %v", selectionID, userID, err)
+		h.logger.ErrorContext(c.Request.Context(), "error updating selection", "selection_id", selectionID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update selection"})
 		return
 	}
@@ -203,13 +212,14 @@ func (h *SelectionHandler) UpdateSelection(c *gin.Context) {
 // @Tags selections
 // @Produce json
 // @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
 // @Param id path string true "Selection ID (MongoDB ObjectID)"
 // @Success 204 "Selection deleted successfully"
-// @Failure 400 {object} gin.H "Invalid ID format"
-// @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 403 {object} gin.H "Forbidden (selection does not belong to user)"
-// @Failure 404 {object} gin.H "Selection not found"
-// @Failure 500 {object} gin.H "Internal server error"
+// @Failure 400 {object} models.ErrorResponse "Invalid ID format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 403 {object} models.ErrorResponse "Forbidden (selection does not belong to user)"
+// @Failure 404 {object} models.ErrorResponse "Selection not found"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
 // @Router /api/selections/{id} [delete]
 // @Security BearerAuth
 func (h *SelectionHandler) DeleteSelection(c *gin.Context) {
@@ -236,10 +246,139 @@ func (h *SelectionHandler) DeleteSelection(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Forbidden"})
 			return
 		}
-		log.Printf("Error deleting selection ID %s for user %s: %v", selectionID, userID, err)
+		h.logger.ErrorContext(c.Request.Context(), "error deleting selection", "selection_id", selectionID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete selection"})
 		return
 	}
 
 	c.Status(http.StatusNoContent) // Success, no body
 }
+
+// BatchSelections handles POST /api/selections/batch
+// @Summary Apply several selection changes at once
+// @Description Applies a list of create, update and delete operations in one request. Created items are verified against Spotify in bulk, and all writes are applied in a single bulk write. Each operation reports its own status, so some may fail while others succeed.
+// @Tags selections
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
+// @Param X-Spotify-Token header string false "Spotify access token (required when the batch contains create operations)"
+// @Param batch body models.BatchSelectionRequest true "Operations to apply (max 100)"
+// @Success 200 {object} models.BatchSelectionResponse "All operations succeeded"
+// @Success 207 {object} models.BatchSelectionResponse "Some operations failed; see per-operation results"
+// @Failure 400 {object} models.ErrorResponse "Invalid request format"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/selections/batch [post]
+// @Security BearerAuth
+func (h *SelectionHandler) BatchSelections(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+
+	var request models.BatchSelectionRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	spotifyToken := c.GetHeader("X-Spotify-Token")
+
+	outcomes, err := h.selectionService.ApplyBatch(c.Request.Context(), userID, spotifyToken, request.Operations)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "error applying selection batch", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to apply selection batch"})
+		return
+	}
+
+	response := models.BatchSelectionResponse{Results: make([]models.BatchSelectionResult, len(outcomes))}
+	for i, outcome := range outcomes {
+		result := models.BatchSelectionResult{Index: i, Op: request.Operations[i].Op, Selection: outcome.Selection}
+		switch {
+		case outcome.Err != nil:
+			result.Status = batchErrorStatus(outcome.Err)
+			result.Error = outcome.Err.Error()
+			response.Failed++
+		case outcome.Created:
+			result.Status = http.StatusCreated
+			response.Succeeded++
+		case request.Operations[i].Op == "delete":
+			result.Status = http.StatusNoContent
+			response.Succeeded++
+		default:
+			result.Status = http.StatusOK
+			response.Succeeded++
+		}
+		response.Results[i] = result
+	}
+
+	status := http.StatusOK
+	if response.Failed > 0 {
+		status = http.StatusMultiStatus
+	}
+	c.JSON(status, response)
+}
+
+// batchErrorStatus maps a per-operation service error to the status the single-item endpoints would return.
+func batchErrorStatus(err error) int {
+	msg := err.Error()
+	switch {
+	case errors.Is(err, mongo.ErrNoDocuments) || strings.Contains(msg, "selection not found"):
+		return http.StatusNotFound
+	case strings.Contains(msg, "unauthorized") || strings.Contains(msg, "authorization failed"):
+		return http.StatusForbidden
+	case strings.Contains(msg, "spotify authentication failed") || strings.Contains(msg, "spotify token is required"):
+		return http.StatusUnauthorized
+	case errors.Is(err, services.ErrInvalidJournalEntry),
+		strings.Contains(msg, "invalid"),
+		strings.Contains(msg, "no updates provided"),
+		strings.Contains(msg, "spotify item not found"):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// CarryOverSelections handles POST /api/selections/carry-over
+// @Summary Carry candidates over to another month
+// @Description Copies the user's candidates from a source month into a target month without re-verifying them against Spotify. Items already in the target month are skipped. Optionally links each copy to the selection it came from.
+// @Tags selections
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param Idempotency-Key header string false "Makes retries safe: a repeat with the same key and request replays the first response"
+// @Param carryOver body models.CarryOverRequest true "Source and target months, candidate roles to copy, and whether to link copies to their origin"
+// @Success 200 {object} models.CarryOverResponse "Copied and skipped candidates"
+// @Failure 400 {object} models.ErrorResponse "Invalid months or roles"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/selections/carry-over [post]
+// @Security BearerAuth
+func (h *SelectionHandler) CarryOverSelections(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+
+	var request models.CarryOverRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request format: " + err.Error()})
+		return
+	}
+
+	response, err := h.selectionService.CarryOverCandidates(c.Request.Context(), userID, &request)
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error carrying over selections", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to carry over selections"})
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
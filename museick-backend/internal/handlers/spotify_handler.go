@@ -1,11 +1,13 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
 	"github.com/seven7een/museick/museick-backend/internal/services"
 	"github.com/seven7een/museick/museick-backend/middleware"
 )
@@ -14,53 +16,63 @@ import (
 type SpotifyHandler struct {
 	SpotifyService *services.SpotifyService
 	UserDAO        dao.UserDAO
+	logger         *slog.Logger
 }
 
 // NewSpotifyHandler creates a new instance of SpotifyHandler
-func NewSpotifyHandler(s *services.SpotifyService, u dao.UserDAO) *SpotifyHandler {
+func NewSpotifyHandler(s *services.SpotifyService, u dao.UserDAO, logger *slog.Logger) *SpotifyHandler {
 	return &SpotifyHandler{
 		SpotifyService: s,
 		UserDAO:        u,
+		logger:         logger.With("component", "spotify_handler"),
 	}
 }
 
 // ExchangeCodeForToken handles the exchange of authorization code for token
 // This endpoint MUST be protected by authentication middleware
 // POST /api/spotify/exchange-code
+// @Summary Exchange a Spotify authorization code
+// @Description Completes the Spotify PKCE flow: exchanges the authorization code for tokens, stores the refresh token against the user and returns the access token.
+// @Tags spotify
+// @Accept json
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param exchange body models.SpotifyCodeExchangeRequest true "Authorization code and PKCE verifier"
+// @Success 200 {object} models.SpotifyTokenResponse "Spotify access token"
+// @Failure 400 {object} models.ErrorResponse "Invalid request"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Spotify token exchange failed"
+// @Router /api/spotify/exchange-code [post]
+// @Security BearerAuth
 func (h *SpotifyHandler) ExchangeCodeForToken(c *gin.Context) {
-	var request struct {
-		Code         string `json:"code" binding:"required"`
-		CodeVerifier string `json:"code_verifier" binding:"required"`
-	}
+	var request models.SpotifyCodeExchangeRequest
 
 	if err := c.ShouldBindJSON(&request); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
 		return
 	}
 
-	// Log the incoming request details
-	log.Printf("Received /exchange-code request: Code=%s, Verifier=%s", request.Code, request.CodeVerifier)
-
 	// Get user sub from context (set by auth middleware)
 	userSub, exists := c.Get(middleware.ClerkUserIDKey)
 	if !exists {
-		log.Println("Error: %s not found in context for /exchange-code", middleware.ClerkUserIDKey)
+		h.logger.ErrorContext(c.Request.Context(), "user identifier not found in context", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier not found in token"})
 		return
 	}
 	subString, ok := userSub.(string)
 	if !ok || subString == "" {
-		log.Println("Error: %s in context is not a valid string for /exchange-code", middleware.ClerkUserIDKey)
+		h.logger.ErrorContext(c.Request.Context(), "user identifier in context is invalid or empty", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user identifier in token"})
 		return
 	}
 
 	// TODO: Add rate limiting if necessary
 
-	tokenData, err := h.SpotifyService.ExchangeCodeForToken(request.Code, request.CodeVerifier)
+	tokenData, err := h.SpotifyService.ExchangeCodeForToken(c.Request.Context(), request.Code, request.CodeVerifier)
 	if err != nil {
 		// Log the internal error for debugging
-		log.Printf("Error exchanging Spotify code for user %s: %v", subString, err)
+		h.logger.ErrorContext(c.Request.Context(), "error exchanging spotify code", "error", err)
 		// Return a generic error to the client
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to exchange Spotify token"})
 		return
@@ -70,7 +82,7 @@ func (h *SpotifyHandler) ExchangeCodeForToken(c *gin.Context) {
 	refreshToken, ok := tokenData["refresh_token"].(string)
 	if !ok || refreshToken == "" {
 		// Log error but potentially continue if only access token is needed immediately
-		log.Printf("Warning: No refresh token received from Spotify for user %s", subString)
+		h.logger.WarnContext(c.Request.Context(), "no refresh token received from spotify")
 		// Decide if this is a hard error or just a warning
 		// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to obtain Spotify refresh token"})
 		// return
@@ -79,40 +91,66 @@ func (h *SpotifyHandler) ExchangeCodeForToken(c *gin.Context) {
 		err = h.UserDAO.UpdateRefreshToken(c.Request.Context(), subString, refreshToken)
 		if err != nil {
 			// Log error but don't necessarily fail the request, as access token might still be valid
-			log.Printf("Error storing refresh token for user %s: %v", subString, err)
+			h.logger.ErrorContext(c.Request.Context(), "error storing spotify refresh token", "error", err)
 			// Optionally return an error to the client if storing is critical
 			// c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store Spotify refresh token"})
 			// return
 		} else {
-			log.Printf("Successfully stored refresh token for user %s", subString)
+			h.logger.InfoContext(c.Request.Context(), "stored spotify refresh token")
 		}
 	}
 
-	// Prepare response for the frontend (WITHOUT refresh token)
-	frontendResponse := map[string]interface{}{
-		"access_token": tokenData["access_token"],
-		"expires_in":   tokenData["expires_in"],
-		"scope":        tokenData["scope"],
-		"token_type":   tokenData["token_type"],
+	// Remember the user's market so syncs and playlist exports can check availability for it
+	if accessToken, ok := tokenData["access_token"].(string); ok && accessToken != "" {
+		h.storeUserMarket(c.Request.Context(), subString, accessToken)
 	}
 
-	c.JSON(http.StatusOK, frontendResponse)
+	// Prepare response for the frontend (WITHOUT refresh token)
+	c.JSON(http.StatusOK, frontendTokenResponse(tokenData))
+}
+
+// storeUserMarket looks up the market of the user's Spotify account and stores it. Failures are only
+// logged: the market is refreshed on the next playlist export, and linking Spotify shouldn't fail over it.
+func (h *SpotifyHandler) storeUserMarket(ctx context.Context, sub string, accessToken string) {
+	market, err := h.SpotifyService.UserMarket(ctx, accessToken)
+	if err != nil {
+		h.logger.WarnContext(ctx, "could not get spotify user market", "error", err)
+		return
+	}
+	if market == "" {
+		return
+	}
+	if err := h.UserDAO.UpdateSpotifyMarket(ctx, sub, market); err != nil {
+		h.logger.WarnContext(ctx, "could not store spotify user market", "error", err)
+	}
 }
 
 // RefreshAccessToken handles the refreshing of the access token using the stored refresh token
 // POST /api/spotify/refresh-token
 // This endpoint MUST be protected by authentication middleware
+// @Summary Refresh the Spotify access token
+// @Description Uses the refresh token stored for the user to obtain a new Spotify access token. A rotated refresh token is stored automatically.
+// @Tags spotify
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Success 200 {object} models.SpotifyTokenResponse "New Spotify access token"
+// @Failure 400 {object} models.ErrorResponse "No refresh token stored; the user must reconnect Spotify"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Spotify token refresh failed"
+// @Router /api/spotify/refresh-token [post]
+// @Security BearerAuth
 func (h *SpotifyHandler) RefreshAccessToken(c *gin.Context) {
 	// Get user sub from context (set by auth middleware)
 	userSub, exists := c.Get(middleware.ClerkUserIDKey)
 	if !exists {
-		log.Println("Error: %s not found in context for /refresh-token", middleware.ClerkUserIDKey)
+		h.logger.ErrorContext(c.Request.Context(), "user identifier not found in context", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier not found in token"})
 		return
 	}
 	subString, ok := userSub.(string)
 	if !ok || subString == "" {
-		log.Println("Error: %s in context is not a valid string for /refresh-token", middleware.ClerkUserIDKey)
+		h.logger.ErrorContext(c.Request.Context(), "user identifier in context is invalid or empty", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user identifier in token"})
 		return
 	}
@@ -120,22 +158,21 @@ func (h *SpotifyHandler) RefreshAccessToken(c *gin.Context) {
 	// Retrieve the user's stored refresh token
 	user, err := h.UserDAO.FindBySub(c.Request.Context(), subString)
 	if err != nil {
-		log.Printf("Error finding user %s for refresh token: %v", subString, err)
+		h.logger.ErrorContext(c.Request.Context(), "error finding user for spotify token refresh", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user data"})
 		return
 	}
 	if user == nil || user.SpotifyRefreshToken == "" {
-		log.Printf("No refresh token found for user %s", subString)
+		h.logger.WarnContext(c.Request.Context(), "no spotify refresh token stored for user")
 		c.JSON(http.StatusBadRequest, gin.H{"error": "No Spotify refresh token available for this user. Please reconnect Spotify."})
 		return
 	}
 
-	log.Printf("Attempting to refresh token for user %s", subString)
 	// Use the stored refresh token
-	tokenData, err := h.SpotifyService.RefreshAccessToken(user.SpotifyRefreshToken)
+	tokenData, err := h.SpotifyService.RefreshAccessToken(c.Request.Context(), user.SpotifyRefreshToken)
 	if err != nil {
 		// Log the internal error
-		log.Printf("Error refreshing Spotify token for user %s: %v", subString, err)
+		h.logger.ErrorContext(c.Request.Context(), "error refreshing spotify token", "error", err)
 		// Consider specific handling for Spotify errors (e.g., invalid refresh token)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to refresh Spotify token"})
 		return
@@ -144,22 +181,27 @@ func (h *SpotifyHandler) RefreshAccessToken(c *gin.Context) {
 	// Check if Spotify returned a *new* refresh token (it sometimes does)
 	newRefreshToken, ok := tokenData["refresh_token"].(string)
 	if ok && newRefreshToken != "" && newRefreshToken != user.SpotifyRefreshToken {
-		log.Printf("Received new refresh token for user %s. Updating storage.", subString)
+		h.logger.InfoContext(c.Request.Context(), "received rotated spotify refresh token, updating storage")
 		err = h.UserDAO.UpdateRefreshToken(c.Request.Context(), subString, newRefreshToken)
 		if err != nil {
 			// Log error but proceed with sending the access token
-			log.Printf("Error updating new refresh token for user %s: %v", subString, err)
+			h.logger.ErrorContext(c.Request.Context(), "error storing rotated spotify refresh token", "error", err)
 		}
 	}
 
 	// Prepare response for the frontend (WITHOUT refresh token)
-	frontendResponse := map[string]interface{}{
-		"access_token": tokenData["access_token"],
-		"expires_in":   tokenData["expires_in"],
-		// Scope might not be included in refresh response, handle potential nil
-		"scope":      tokenData["scope"],
-		"token_type": tokenData["token_type"],
-	}
+	c.JSON(http.StatusOK, frontendTokenResponse(tokenData))
+}
 
-	c.JSON(http.StatusOK, frontendResponse)
+// frontendTokenResponse picks the fields the frontend needs out of Spotify's token response.
+// Scope may be missing from refresh responses, in which case it is left empty.
+func frontendTokenResponse(tokenData map[string]interface{}) models.SpotifyTokenResponse {
+	response := models.SpotifyTokenResponse{}
+	response.AccessToken, _ = tokenData["access_token"].(string)
+	response.Scope, _ = tokenData["scope"].(string)
+	response.TokenType, _ = tokenData["token_type"].(string)
+	if expiresIn, ok := tokenData["expires_in"].(float64); ok { // JSON numbers decode as float64
+		response.ExpiresIn = int(expiresIn)
+	}
+	return response
 }
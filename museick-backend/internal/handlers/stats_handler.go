@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/services"
+	"github.com/seven7een/museick/museick-backend/middleware"
+)
+
+// StatsHandler serves analytics over the user's selections.
+type StatsHandler struct {
+	statsService *services.StatsService
+	logger       *slog.Logger
+}
+
+// NewStatsHandler creates a new StatsHandler.
+func NewStatsHandler(svc *services.StatsService, logger *slog.Logger) *StatsHandler {
+	return &StatsHandler{statsService: svc, logger: logger.With("component", "stats_handler")}
+}
+
+// statsYear reads the :year path parameter, writing a 400 and returning false when it isn't a number.
+func statsYear(c *gin.Context) (int, bool) {
+	year, err := strconv.Atoi(c.Param("year"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid year, expected YYYY"})
+		return 0, false
+	}
+	return year, true
+}
+
+// statsSelectedOnly reads the optional selected_only query parameter, writing a 400 and returning false when it isn't a bool.
+func statsSelectedOnly(c *gin.Context) (bool, bool) {
+	raw := c.Query("selected_only")
+	if raw == "" {
+		return false, true
+	}
+	selectedOnly, err := strconv.ParseBool(raw)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid selected_only, expected true or false"})
+		return false, false
+	}
+	return selectedOnly, true
+}
+
+// GetAudioFeatureStats handles GET /api/stats/audio-features/:year
+// @Summary Compare the audio features of Muses and Icks
+// @Description Compares the distributions of valence, energy, danceability, tempo and acousticness between the tracks
+// @Description the user picked as Muses and as Icks in a year, with the difference of the means per feature.
+// @Description Candidates count too unless selected_only is set. Each recording counts once per group, and tracks
+// @Description without cached audio features are counted but left out of the distributions.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param year path int true "Calendar year" Example(2024)
+// @Param selected_only query bool false "Only count the monthly Muse and Ick picks, not candidates"
+// @Success 200 {object} models.MoodAnalyticsResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid year"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/stats/audio-features/{year} [get]
+// @Security BearerAuth
+func (h *StatsHandler) GetAudioFeatureStats(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+	year, ok := statsYear(c)
+	if !ok {
+		return
+	}
+	selectedOnly, ok := statsSelectedOnly(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.AudioFeatureStats(c.Request.Context(), userID, year, selectedOnly)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatsRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error computing audio feature stats", "year", year, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetReleaseEraStats handles GET /api/stats/release-eras/:year
+// @Summary Break down a year's picks by release era
+// @Description Groups the tracks and albums the user picked in a year by the decade and year they were released (tracks
+// @Description use their album's release date), counts how many were new releases rather than catalog, and names the
+// @Description oldest and newest Muse. Dates may be reported to the year, month or day; a pick whose gap to its
+// @Description release can't be told from a year-only date is counted as undetermined. Picks without a cached
+// @Description release date count towards picks only.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param year path int true "Calendar year" Example(2024)
+// @Param selected_only query bool false "Only count the monthly Muse and Ick picks, not candidates"
+// @Success 200 {object} models.ReleaseEraResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid year"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/stats/release-eras/{year} [get]
+// @Security BearerAuth
+func (h *StatsHandler) GetReleaseEraStats(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+	year, ok := statsYear(c)
+	if !ok {
+		return
+	}
+	selectedOnly, ok := statsSelectedOnly(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.ReleaseEraStats(c.Request.Context(), userID, year, selectedOnly)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatsRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error computing release era stats", "year", year, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetGenreStats handles GET /api/stats/genres/:year
+// @Summary Chart a year's picks by genre and artist
+// @Description Attributes every pick of the year (artists directly, tracks and albums through their credited artists) to
+// @Description its artists' genres and reports the genre mix month by month and over the year. Each pick's weight of 1
+// @Description is split evenly across its genres. Also lists the most picked artists, and the artists picked both as a
+// @Description Muse and as an Ick. Items and artists missing from the cache are synced when a Spotify token is sent.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param X-Spotify-Token header string false "Spotify access token, used to sync items and artists missing from the cache"
+// @Param year path int true "Calendar year" Example(2024)
+// @Param selected_only query bool false "Only count the monthly Muse and Ick picks, not candidates"
+// @Success 200 {object} models.GenreTimelineResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid year"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 429 {object} models.ErrorResponse "Rate limited"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/stats/genres/{year} [get]
+// @Security BearerAuth
+func (h *StatsHandler) GetGenreStats(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+	year, ok := statsYear(c)
+	if !ok {
+		return
+	}
+	selectedOnly, ok := statsSelectedOnly(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.GenreTimeline(c.Request.Context(), userID, year, selectedOnly, c.GetHeader("X-Spotify-Token"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatsRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error computing genre stats", "year", year, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetYearComparison handles GET /api/stats/compare
+// @Summary Compare several years of picks
+// @Description Compares the user's picks across 2 to 10 years: per-year summaries, artists picked in more than one year,
+// @Description how each genre's share drifted from the first year to the last, items picked as a Muse in several years,
+// @Description Icks that later became Muses (redemptions) and the reverse, and runs of consecutive months in one role
+// @Description that cross New Year. Copies of the same recording or release count as one item.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param years query string true "Comma-separated calendar years" Example(2024,2025)
+// @Param selected_only query bool false "Only count the monthly Muse and Ick picks, not candidates"
+// @Success 200 {object} models.YearComparisonResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid years"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/stats/compare [get]
+// @Security BearerAuth
+func (h *StatsHandler) GetYearComparison(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+	var years []int
+	for _, raw := range strings.Split(c.Query("years"), ",") {
+		year, err := strconv.Atoi(strings.TrimSpace(raw))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid years, expected a comma-separated list such as 2024,2025"})
+			return
+		}
+		years = append(years, year)
+	}
+	selectedOnly, ok := statsSelectedOnly(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.CompareYears(c.Request.Context(), userID, years, selectedOnly)
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidStatsRequest) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		h.logger.ErrorContext(c.Request.Context(), "error comparing years", "years", years, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// GetRetrospective handles GET /api/stats/retrospective
+// @Summary Look back over every year of picks
+// @Description Compares every year the user has picks in, with the same breakdown as /api/stats/compare.
+// @Tags stats
+// @Produce json
+// @Param Authorization header string true "Bearer token"
+// @Param selected_only query bool false "Only count the monthly Muse and Ick picks, not candidates"
+// @Success 200 {object} models.YearComparisonResponse
+// @Failure 400 {object} models.ErrorResponse "Invalid selected_only"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/stats/retrospective [get]
+// @Security BearerAuth
+func (h *StatsHandler) GetRetrospective(c *gin.Context) {
+	userID := c.GetString(middleware.ClerkUserIDKey)
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User identifier missing"})
+		return
+	}
+	selectedOnly, ok := statsSelectedOnly(c)
+	if !ok {
+		return
+	}
+
+	stats, err := h.statsService.Retrospective(c.Request.Context(), userID, selectedOnly)
+	if err != nil {
+		h.logger.ErrorContext(c.Request.Context(), "error computing retrospective", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
@@ -1,7 +1,7 @@
 package handlers
 
 import (
-	"log"
+	"log/slog"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -12,28 +12,38 @@ import (
 // UserHandler handles HTTP requests related to users.
 type UserHandler struct {
 	userService services.UserService
+	logger      *slog.Logger
 }
 
 // NewUserHandler creates a new UserHandler.
-func NewUserHandler(userService services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService services.UserService, logger *slog.Logger) *UserHandler {
+	return &UserHandler{userService: userService, logger: logger.With("component", "user_handler")}
 }
 
 // SyncUser handles the POST /api/users/sync request.
 // It retrieves the user ID (sub) from the context (set by middleware)
 // and calls the user service to ensure the user exists in the database.
+// @Summary Sync the signed-in user
+// @Description Ensures the Clerk user behind the token has a Museick user record, creating it on first sign-in.
+// @Tags users
+// @Param Authorization header string true "Bearer token"
+// @Success 204 "User exists"
+// @Failure 401 {object} models.ErrorResponse "Unauthorized"
+// @Failure 500 {object} models.ErrorResponse "Internal server error"
+// @Router /api/users/sync [post]
+// @Security BearerAuth
 func (h *UserHandler) SyncUser(c *gin.Context) {
 	// Retrieve user ID (sub) from context set by AuthenticateClerkJWT middleware
 	userIDValue, exists := c.Get(middleware.ClerkUserIDKey)
 	if !exists {
-		log.Println("Error in SyncUser handler: '%s' not found in context.", middleware.ClerkUserIDKey) // Update log
+		h.logger.ErrorContext(c.Request.Context(), "user identifier not found in context", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User identifier missing"})
 		return
 	}
 
 	userID, ok := userIDValue.(string)
 	if !ok || userID == "" {
-		log.Println("Error in SyncUser handler: Invalid %s type or empty in context.", middleware.ClerkUserIDKey) // Update log
+		h.logger.ErrorContext(c.Request.Context(), "user identifier in context is invalid or empty", "key", middleware.ClerkUserIDKey)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid user identifier"})
 		return
 	}
@@ -42,7 +52,7 @@ func (h *UserHandler) SyncUser(c *gin.Context) {
 	err := h.userService.SyncUser(c.Request.Context(), userID)
 	if err != nil {
 		// Log the service error
-		log.Printf("Error syncing user with sub '%s': %v\n", userID, err)
+		h.logger.ErrorContext(c.Request.Context(), "error syncing user", "error", err)
 		// Return a generic server error to the client
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to synchronize user data"})
 		return
@@ -0,0 +1,158 @@
+// Package health implements the dependency checks behind the readiness probe.
+package health
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Check reports whether a dependency is usable. It should respect ctx's deadline.
+type Check func(ctx context.Context) error
+
+// Status values used in reports.
+const (
+	StatusOK           = "ok"
+	StatusFailing      = "failing"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckResult is the outcome of one check.
+type CheckResult struct {
+	Status    string `json:"status" example:"ok"`
+	LatencyMS int64  `json:"latency_ms" example:"3"`
+	Error     string `json:"error,omitempty"`
+}
+
+// Report is the readiness response body.
+type Report struct {
+	Status string                 `json:"status" example:"ok"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// Checker runs the registered readiness checks.
+type Checker struct {
+	mu           sync.RWMutex
+	checks       map[string]Check
+	timeout      time.Duration
+	shuttingDown atomic.Bool
+}
+
+// NewChecker creates a Checker whose checks each get the given timeout.
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{checks: make(map[string]Check), timeout: timeout}
+}
+
+// Register adds a named check.
+func (c *Checker) Register(name string, check Check) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks[name] = check
+}
+
+// SetShuttingDown makes readiness fail so load balancers stop routing new traffic while requests drain.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs every check concurrently and reports whether all passed.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+	c.mu.RLock()
+	names := make([]string, 0, len(c.checks))
+	for name := range c.checks {
+		names = append(names, name)
+	}
+	c.mu.RUnlock()
+	sort.Strings(names)
+
+	results := make([]CheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = c.Run(ctx, name)
+		}(i, name)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: make(map[string]CheckResult, len(names))}
+	ready := true
+	for i, name := range names {
+		report.Checks[name] = results[i]
+		if results[i].Status != StatusOK {
+			ready = false
+		}
+	}
+	if c.shuttingDown.Load() {
+		report.Status = StatusShuttingDown
+		return report, false
+	}
+	if !ready {
+		report.Status = StatusFailing
+	}
+	return report, ready
+}
+
+// Run executes a single named check.
+func (c *Checker) Run(ctx context.Context, name string) CheckResult {
+	c.mu.RLock()
+	check, ok := c.checks[name]
+	c.mu.RUnlock()
+	if !ok {
+		return CheckResult{Status: StatusFailing, Error: "unknown check"}
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	start := time.Now()
+	err := check(checkCtx)
+	result := CheckResult{Status: StatusOK, LatencyMS: time.Since(start).Milliseconds()}
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+	return result
+}
+
+// MongoPing checks that the primary answers a ping.
+func MongoPing(client *mongo.Client) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx, nil)
+	}
+}
+
+// MongoIndexes checks that each collection has the named indexes the application relies on
+// (e.g. the unique selection index that prevents duplicates).
+func MongoIndexes(db *mongo.Database, required map[string][]string) Check {
+	return func(ctx context.Context) error {
+		for collection, names := range required {
+			cursor, err := db.Collection(collection).Indexes().List(ctx)
+			if err != nil {
+				return fmt.Errorf("listing indexes on %s: %w", collection, err)
+			}
+			var indexes []bson.M
+			if err := cursor.All(ctx, &indexes); err != nil {
+				return fmt.Errorf("decoding indexes on %s: %w", collection, err)
+			}
+			present := make(map[string]bool, len(indexes))
+			for _, index := range indexes {
+				if name, ok := index["name"].(string); ok {
+					present[name] = true
+				}
+			}
+			for _, name := range names {
+				if !present[name] {
+					return fmt.Errorf("index %s missing on %s", name, collection)
+				}
+			}
+		}
+		return nil
+	}
+}
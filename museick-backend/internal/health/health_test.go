@@ -0,0 +1,111 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckerReady(t *testing.T) {
+	ok := func(ctx context.Context) error { return nil }
+	failing := func(ctx context.Context) error { return errors.New("connection refused") }
+	slow := func(ctx context.Context) error {
+		<-ctx.Done() // Only the checker's timeout ends it
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name         string
+		checks       map[string]Check
+		shuttingDown bool
+		wantReady    bool
+		wantStatus   string
+		wantChecks   map[string]string // Check name to its status
+	}{
+		{"no checks", nil, false, true, StatusOK, map[string]string{}},
+		{"all passing", map[string]Check{"mongo": ok, "spotify": ok}, false, true, StatusOK, map[string]string{"mongo": StatusOK, "spotify": StatusOK}},
+		{"one failing", map[string]Check{"mongo": ok, "spotify": failing}, false, false, StatusFailing, map[string]string{"mongo": StatusOK, "spotify": StatusFailing}},
+		{"check times out", map[string]Check{"mongo": slow}, false, false, StatusFailing, map[string]string{"mongo": StatusFailing}},
+		{"shutting down", map[string]Check{"mongo": ok}, true, false, StatusShuttingDown, map[string]string{"mongo": StatusOK}},
+	}
+	for _, tt := range tests {
+		checker := NewChecker(20 * time.Millisecond)
+		for name, check := range tt.checks {
+			checker.Register(name, check)
+		}
+		if tt.shuttingDown {
+			checker.SetShuttingDown()
+		}
+		report, ready := checker.Ready(context.Background())
+		if ready != tt.wantReady || report.Status != tt.wantStatus {
+			t.Errorf("%s: Ready() = %s, %v; want %s, %v", tt.name, report.Status, ready, tt.wantStatus, tt.wantReady)
+		}
+		if len(report.Checks) != len(tt.wantChecks) {
+			t.Errorf("%s: checks = %+v, want %v", tt.name, report.Checks, tt.wantChecks)
+		}
+		for name, want := range tt.wantChecks {
+			if got := report.Checks[name]; got.Status != want || (want == StatusFailing) != (got.Error != "") {
+				t.Errorf("%s: check %s = %+v, want status %s with an error only when failing", tt.name, name, got, want)
+			}
+		}
+	}
+}
+
+func TestCheckerRunUnknownCheck(t *testing.T) {
+	if got := NewChecker(time.Second).Run(context.Background(), "redis"); got.Status != StatusFailing || got.Error != "unknown check" {
+		t.Errorf("Run(unknown) = %+v, want a failing unknown check", got)
+	}
+}
+
+func TestSpotifyProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr string
+	}{
+		{"unauthenticated answer means up", http.StatusBadRequest, ""},
+		{"ok", http.StatusOK, ""},
+		{"server error", http.StatusServiceUnavailable, "returned status 503"},
+	}
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(tt.status) }))
+		probe := NewSpotifyProbe(server.Client(), server.URL, time.Minute)
+		if err := probe.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "not probed yet") {
+			t.Errorf("%s: Check() before probing = %v, want not probed yet", tt.name, err)
+		}
+		probe.probe(context.Background())
+		err := probe.Check(context.Background())
+		if (tt.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: Check() = %v, want error containing %q", tt.name, err, tt.wantErr)
+		}
+		server.Close()
+	}
+}
+
+func TestSpotifyProbeUnreachableAndStale(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	server.Close()
+	probe := NewSpotifyProbe(server.Client(), server.URL, time.Minute)
+	probe.probe(context.Background())
+	if err := probe.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "unreachable") {
+		t.Errorf("Check() after a failed connection = %v, want unreachable", err)
+	}
+
+	probe.lastErr, probe.probedAt = nil, time.Now().Add(-3*time.Minute)
+	if err := probe.Check(context.Background()); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("Check() with an old result = %v, want stale", err)
+	}
+
+	// A probe cut short by shutdown keeps the previous result
+	probe.probedAt = time.Now()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	probe.probe(ctx)
+	if err := probe.Check(context.Background()); err != nil {
+		t.Errorf("Check() after a cancelled probe = %v, want the previous nil result", err)
+	}
+}
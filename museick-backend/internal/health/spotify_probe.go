@@ -0,0 +1,88 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// SpotifyTokenURL is the accounts endpoint every Spotify-backed request ultimately depends on.
+const SpotifyTokenURL = "https://accounts.spotify.com/api/token"
+
+// SpotifyProbe checks that the Spotify token endpoint is reachable. Probing on every readiness
+// request would hammer Spotify, so the probe runs in the background and Check returns the cached result.
+type SpotifyProbe struct {
+	client   *http.Client
+	url      string
+	interval time.Duration
+
+	mu       sync.RWMutex
+	lastErr  error
+	probedAt time.Time
+}
+
+// NewSpotifyProbe creates a probe that refreshes its result every interval.
+func NewSpotifyProbe(client *http.Client, url string, interval time.Duration) *SpotifyProbe {
+	return &SpotifyProbe{client: client, url: url, interval: interval}
+}
+
+// Run probes immediately and then on every interval until ctx is cancelled.
+func (p *SpotifyProbe) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.probe(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Check returns the most recent probe result. A result older than two intervals counts as a failure,
+// since it means the background probe has stopped.
+func (p *SpotifyProbe) Check(_ context.Context) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.probedAt.IsZero() {
+		return errors.New("spotify not probed yet")
+	}
+	if time.Since(p.probedAt) > 2*p.interval {
+		return fmt.Errorf("spotify probe result is stale (last probed %s ago)", time.Since(p.probedAt).Round(time.Second))
+	}
+	return p.lastErr
+}
+
+func (p *SpotifyProbe) probe(ctx context.Context) {
+	probeCtx, cancel := context.WithTimeout(ctx, p.interval/2)
+	defer cancel()
+
+	err := func() error {
+		req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, p.url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return fmt.Errorf("spotify token endpoint unreachable: %w", err)
+		}
+		resp.Body.Close()
+		// Without credentials the endpoint answers 4xx; any non-5xx answer means Spotify is up
+		if resp.StatusCode >= http.StatusInternalServerError {
+			return fmt.Errorf("spotify token endpoint returned status %d", resp.StatusCode)
+		}
+		return nil
+	}()
+	if ctx.Err() != nil {
+		return // Shutting down; keep the last real result
+	}
+
+	p.mu.Lock()
+	p.lastErr = err
+	p.probedAt = time.Now()
+	p.mu.Unlock()
+}
@@ -0,0 +1,126 @@
+// Package logging builds the application's structured logger and carries
+// request-scoped values (request ID, user sub) through context.Context so
+// every log line written with a *Context method is tagged automatically.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Options configures the application logger.
+type Options struct {
+	Level  string    // "debug", "info", "warn" or "error" (default "info")
+	Format string    // "text" or "json" (default "text")
+	Output io.Writer // Defaults to os.Stdout
+}
+
+// New creates a logger with the given options. All output passes through
+// the redaction rules in redact.go, and records logged with a context carry
+// the request ID and user sub stored in that context.
+func New(opts Options) (*slog.Logger, error) {
+	level, err := ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	out := opts.Output
+	if out == nil {
+		out = os.Stdout
+	}
+
+	handlerOpts := &slog.HandlerOptions{
+		Level:       level,
+		ReplaceAttr: redactAttr,
+	}
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case "", "text":
+		handler = slog.NewTextHandler(out, handlerOpts)
+	case "json":
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	default:
+		return nil, fmt.Errorf("unsupported log format %q, expected 'text' or 'json'", opts.Format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// ParseLevel converts a level name to a slog.Level. An empty string means info.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unsupported log level %q, expected debug, info, warn or error", level)
+}
+
+// --- Request-scoped values ---
+
+type contextKey int
+
+const (
+	requestIDKey contextKey = iota
+	userSubKey
+)
+
+// WithRequestID returns a copy of ctx carrying the request ID.
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestIDFromContext returns the request ID stored in ctx, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithUserSub returns a copy of ctx carrying the authenticated user's Clerk sub.
+func WithUserSub(ctx context.Context, sub string) context.Context {
+	return context.WithValue(ctx, userSubKey, sub)
+}
+
+// UserSubFromContext returns the user sub stored in ctx, if any.
+func UserSubFromContext(ctx context.Context) string {
+	sub, _ := ctx.Value(userSubKey).(string)
+	return sub
+}
+
+// contextHandler adds request_id, user_sub and (when tracing) trace_id and span_id
+// attributes from the record's context.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestIDFromContext(ctx); id != "" {
+			r.AddAttrs(slog.String("request_id", id))
+		}
+		if sub := UserSubFromContext(ctx); sub != "" {
+			r.AddAttrs(slog.String("user_sub", sub))
+		}
+		if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+			r.AddAttrs(slog.String("trace_id", sc.TraceID().String()), slog.String("span_id", sc.SpanID().String()))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
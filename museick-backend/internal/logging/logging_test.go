@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestLoggerAddsContextValues(t *testing.T) {
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	spanCtx := trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID, TraceFlags: trace.FlagsSampled})
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		want    []string
+		wantNot []string
+	}{
+		{"no values", context.Background(), nil, []string{"request_id", "user_sub", "trace_id", "span_id"}},
+		{
+			"request and user",
+			WithUserSub(WithRequestID(context.Background(), "req-1"), "user_1"),
+			[]string{"request_id=req-1", "user_sub=user_1"},
+			[]string{"trace_id"},
+		},
+		{
+			"span",
+			trace.ContextWithSpanContext(WithRequestID(context.Background(), "req-2"), spanCtx),
+			[]string{"request_id=req-2", "trace_id=4bf92f3577b34da6a3ce929d0e0e4736", "span_id=00f067aa0ba902b7"},
+			[]string{"user_sub"},
+		},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		logger, err := New(Options{Output: &buf})
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Values must survive derived loggers too
+		logger.With("component", "test").WithGroup("g").InfoContext(tt.ctx, "hello")
+		out := buf.String()
+		for _, want := range tt.want {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s: log line %q, want it to contain %q", tt.name, out, want)
+			}
+		}
+		for _, unwanted := range tt.wantNot {
+			if strings.Contains(out, unwanted) {
+				t.Errorf("%s: log line %q, want no %q", tt.name, out, unwanted)
+			}
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// Redacted replaces any value the logger considers sensitive.
+const Redacted = "[REDACTED]"
+
+// sensitiveKeyParts mark an attribute (or HTTP header) as sensitive when its
+// lowercased key contains any of them.
+var sensitiveKeyParts = []string{
+	"token", "secret", "password", "authorization", "cookie", "verifier", "api_key", "apikey", "credential",
+}
+
+// sensitiveKeys are matched exactly, for names too short to match by substring.
+var sensitiveKeys = map[string]bool{
+	"code": true, // Spotify authorization code
+}
+
+var (
+	// authSchemeRegex matches credentials following an HTTP auth scheme, e.g. "Bearer eyJ...".
+	authSchemeRegex = regexp.MustCompile(`(?i)\b(bearer|basic)\s+[A-Za-z0-9\-._~+/]+=*`)
+	// sensitiveParamRegex matches sensitive form or query parameters, e.g. "refresh_token=abc".
+	sensitiveParamRegex = regexp.MustCompile(`(?i)\b(access_token|refresh_token|id_token|client_secret|code_verifier|code)=[^&\s"]+`)
+)
+
+// IsSensitiveKey reports whether values logged under key should be redacted.
+func IsSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, part := range sensitiveKeyParts {
+		if strings.Contains(key, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString scrubs credentials embedded in free text such as messages and errors.
+func RedactString(s string) string {
+	s = authSchemeRegex.ReplaceAllString(s, "$1 "+Redacted)
+	return sensitiveParamRegex.ReplaceAllString(s, "$1="+Redacted)
+}
+
+// RedactHeaders returns a copy of h with sensitive headers (Authorization, Cookie, X-Spotify-Token, ...) redacted.
+func RedactHeaders(h http.Header) http.Header {
+	redacted := make(http.Header, len(h))
+	for key, values := range h {
+		if IsSensitiveKey(key) {
+			redacted[key] = []string{Redacted}
+			continue
+		}
+		redacted[key] = values
+	}
+	return redacted
+}
+
+// redactAttr is the slog ReplaceAttr hook applied to every attribute, including the message.
+func redactAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key != slog.MessageKey && IsSensitiveKey(a.Key) {
+		return slog.String(a.Key, Redacted)
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, RedactString(a.Value.String()))
+	case slog.KindAny:
+		switch v := a.Value.Any().(type) {
+		case error:
+			return slog.String(a.Key, RedactString(v.Error()))
+		case http.Header:
+			return slog.Any(a.Key, RedactHeaders(v))
+		case []string:
+			scrubbed := make([]string, len(v))
+			for i, s := range v {
+				scrubbed[i] = RedactString(s)
+			}
+			return slog.Any(a.Key, scrubbed)
+		}
+	}
+	return a
+}
@@ -0,0 +1,77 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileStore keeps media objects on the local filesystem, fanned out into subdirectories by the first
+// two characters of the name. Suitable for a single instance or a shared volume.
+type FileStore struct {
+	dir string
+}
+
+// NewFileStore creates a store rooted at dir, creating the directory if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("creating media directory: %w", err)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+func (s *FileStore) path(name string) (string, error) {
+	if len(name) < 2 || name != filepath.Base(name) {
+		return "", fmt.Errorf("invalid media object name %q", name)
+	}
+	return filepath.Join(s.dir, name[:2], name), nil
+}
+
+// Put writes the object through a temporary file, so readers never see a partial image.
+func (s *FileStore) Put(ctx context.Context, name string, data []byte) error {
+	path, err := s.path(name)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("creating media directory: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), name+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating media file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // No-op once renamed
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("writing media file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing media file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("storing media file: %w", err)
+	}
+	return nil
+}
+
+// Get reads the object stored under name.
+func (s *FileStore) Get(ctx context.Context, name string) ([]byte, error) {
+	path, err := s.path(name)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading media file: %w", err)
+	}
+	return data, nil
+}
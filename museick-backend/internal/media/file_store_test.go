@@ -0,0 +1,48 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileStore(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(filepath.Join(dir, "media"))
+	if err != nil {
+		t.Fatalf("NewFileStore() = %v", err)
+	}
+	ctx := context.Background()
+	name := testHash + "_64.jpg"
+
+	if _, err := store.Get(ctx, name); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get() before Put = %v, want %v", err, ErrNotFound)
+	}
+	if err := store.Put(ctx, name, []byte("first")); err != nil {
+		t.Fatalf("Put() = %v", err)
+	}
+	// Content-addressed, so a second Put of the same name keeps the first object
+	if err := store.Put(ctx, name, []byte("second")); err != nil {
+		t.Fatalf("second Put() = %v", err)
+	}
+	if got, err := store.Get(ctx, name); err != nil || string(got) != "first" {
+		t.Errorf("Get() = %q, %v; want first", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "media", "9f", name)); err != nil {
+		t.Errorf("object not fanned out by its first two characters: %v", err)
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(dir, "media", "9f", "*.tmp")); len(leftovers) != 0 {
+		t.Errorf("temporary files left behind: %v", leftovers)
+	}
+
+	for _, bad := range []string{"", "a", "../" + testHash, "9f/" + testHash} {
+		if err := store.Put(ctx, bad, []byte("x")); err == nil {
+			t.Errorf("Put(%q) succeeded, want an invalid name error", bad)
+		}
+		if _, err := store.Get(ctx, bad); err == nil || errors.Is(err, ErrNotFound) {
+			t.Errorf("Get(%q) = %v, want an invalid name error", bad, err)
+		}
+	}
+}
@@ -0,0 +1,79 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/gridfs"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// GridFSStore keeps media objects in a MongoDB GridFS bucket, so every instance serves the same images
+// without a shared volume. Objects are stored with their name as the file ID.
+type GridFSStore struct {
+	bucket *gridfs.Bucket
+}
+
+// NewGridFSStore creates a store backed by the named GridFS bucket.
+func NewGridFSStore(client *mongo.Client, dbName string, bucketName string) (*GridFSStore, error) {
+	bucket, err := gridfs.NewBucket(client.Database(dbName), options.GridFSBucket().SetName(bucketName))
+	if err != nil {
+		return nil, fmt.Errorf("opening GridFS bucket %s: %w", bucketName, err)
+	}
+	return &GridFSStore{bucket: bucket}, nil
+}
+
+// Put uploads the object unless a file with that ID already exists.
+// The GridFS API takes deadlines rather than contexts, so the context's deadline is applied to the upload.
+func (s *GridFSStore) Put(ctx context.Context, name string, data []byte) error {
+	err := s.bucket.GetFilesCollection().FindOne(ctx, bson.M{"_id": name}).Err()
+	if err == nil {
+		return nil
+	}
+	if !errors.Is(err, mongo.ErrNoDocuments) {
+		return fmt.Errorf("checking for media object %s: %w", name, err)
+	}
+
+	stream, err := s.bucket.OpenUploadStreamWithID(name, name)
+	if err != nil {
+		return fmt.Errorf("uploading media object %s: %w", name, err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetWriteDeadline(deadline)
+	}
+	if _, err := stream.Write(data); err != nil {
+		_ = stream.Abort()
+		return fmt.Errorf("uploading media object %s: %w", name, err)
+	}
+	if err := stream.Close(); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil // Another instance stored it first
+		}
+		return fmt.Errorf("uploading media object %s: %w", name, err)
+	}
+	return nil
+}
+
+// Get downloads the object stored under name.
+func (s *GridFSStore) Get(ctx context.Context, name string) ([]byte, error) {
+	stream, err := s.bucket.OpenDownloadStream(name)
+	if errors.Is(err, gridfs.ErrFileNotFound) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening media object %s: %w", name, err)
+	}
+	defer stream.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = stream.SetReadDeadline(deadline)
+	}
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(stream); err != nil {
+		return nil, fmt.Errorf("reading media object %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
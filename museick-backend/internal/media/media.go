@@ -0,0 +1,63 @@
+// Package media stores images mirrored from Spotify's CDN and derives thumbnails from them.
+// Objects are content-addressed: the original is stored under the SHA-256 of its bytes and each
+// variant under that hash plus its size and format, so an object never changes once written.
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// ErrNotFound is returned by Store.Get for objects that were never stored.
+var ErrNotFound = errors.New("media object not found")
+
+// Store keeps media objects by name.
+type Store interface {
+	// Put stores data under name. Names are content-addressed, so storing an existing name is a no-op.
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns the object stored under name, or ErrNotFound.
+	Get(ctx context.Context, name string) ([]byte, error)
+}
+
+// Formats a variant can be encoded in.
+const (
+	FormatJPEG = "jpeg"
+	FormatWebP = "webp"
+)
+
+// ThumbnailSizes are the square thumbnail edges generated for every mirrored image, in pixels.
+var ThumbnailSizes = []int{64, 160, 300}
+
+var hashRegex = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// ValidHash reports whether s looks like the hash of a mirrored image (lowercase hex SHA-256).
+func ValidHash(s string) bool {
+	return hashRegex.MatchString(s)
+}
+
+// ObjectName returns the name of an image's original (size 0) or of one of its thumbnails.
+func ObjectName(hash string, size int, format string) (string, error) {
+	if !ValidHash(hash) {
+		return "", fmt.Errorf("invalid media hash %q", hash)
+	}
+	if size == 0 {
+		if format != "" {
+			return "", fmt.Errorf("the original is only served in its own format, pick a size for %s", format)
+		}
+		return hash, nil
+	}
+	if !slices.Contains(ThumbnailSizes, size) {
+		return "", fmt.Errorf("unsupported thumbnail size %d, expected one of %v", size, ThumbnailSizes)
+	}
+	switch format {
+	case FormatJPEG, "":
+		return fmt.Sprintf("%s_%d.jpg", hash, size), nil
+	case FormatWebP:
+		return fmt.Sprintf("%s_%d.webp", hash, size), nil
+	default:
+		return "", fmt.Errorf("unsupported format %q, expected %s or %s", format, FormatJPEG, FormatWebP)
+	}
+}
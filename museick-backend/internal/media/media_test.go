@@ -0,0 +1,59 @@
+package media
+
+import (
+	"strings"
+	"testing"
+)
+
+const testHash = "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+
+func TestValidHash(t *testing.T) {
+	tests := []struct {
+		s    string
+		want bool
+	}{
+		{testHash, true},
+		{strings.ToUpper(testHash), false},
+		{testHash[:63], false},
+		{testHash + "0", false},
+		{"../" + testHash[3:], false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := ValidHash(tt.s); got != tt.want {
+			t.Errorf("ValidHash(%q) = %v, want %v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestObjectName(t *testing.T) {
+	tests := []struct {
+		hash    string
+		size    int
+		format  string
+		want    string
+		wantErr string
+	}{
+		{hash: testHash, want: testHash},
+		{hash: testHash, size: 64, want: testHash + "_64.jpg"},
+		{hash: testHash, size: 160, format: FormatJPEG, want: testHash + "_160.jpg"},
+		{hash: testHash, size: 300, format: FormatWebP, want: testHash + "_300.webp"},
+		{hash: testHash, format: FormatWebP, wantErr: "pick a size"},
+		{hash: testHash, size: 128, wantErr: "unsupported thumbnail size 128"},
+		{hash: testHash, size: 64, format: "png", wantErr: `unsupported format "png"`},
+		{hash: "../../etc/passwd", size: 64, wantErr: "invalid media hash"},
+		{hash: "", wantErr: "invalid media hash"},
+	}
+	for _, tt := range tests {
+		got, err := ObjectName(tt.hash, tt.size, tt.format)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ObjectName(%q, %d, %q) error = %v, want one containing %q", tt.hash, tt.size, tt.format, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("ObjectName(%q, %d, %q) = %q, %v; want %q", tt.hash, tt.size, tt.format, got, err, tt.want)
+		}
+	}
+}
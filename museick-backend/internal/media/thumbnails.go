@@ -0,0 +1,73 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	_ "image/png" // Spotify serves the odd PNG (mosaics, playlist covers)
+
+	"github.com/HugoSmits86/nativewebp"
+	xdraw "golang.org/x/image/draw"
+)
+
+// JPEG quality used for thumbnails.
+const jpegQuality = 85
+
+// Variant is an encoded thumbnail ready to be stored.
+type Variant struct {
+	Name        string
+	ContentType string
+	Width       int
+	Height      int
+	Data        []byte
+}
+
+// Decode decodes a downloaded image, returning it with the format's MIME type.
+func Decode(data []byte) (image.Image, string, error) {
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("decoding image: %w", err)
+	}
+	return img, "image/" + format, nil
+}
+
+// Thumbnails crops img to a centred square and scales it to every ThumbnailSizes edge, encoding each
+// as JPEG and as WebP. The WebP encoder is lossless, which is only worth it at thumbnail sizes,
+// so originals are kept as Spotify served them.
+func Thumbnails(hash string, img image.Image) ([]Variant, error) {
+	square := centerSquare(img.Bounds())
+	variants := make([]Variant, 0, 2*len(ThumbnailSizes))
+	for _, size := range ThumbnailSizes {
+		thumb := image.NewRGBA(image.Rect(0, 0, size, size))
+		xdraw.CatmullRom.Scale(thumb, thumb.Bounds(), img, square, xdraw.Src, nil)
+
+		var jpegBuf bytes.Buffer
+		if err := jpeg.Encode(&jpegBuf, thumb, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, fmt.Errorf("encoding %dpx JPEG thumbnail: %w", size, err)
+		}
+		var webpBuf bytes.Buffer
+		if err := nativewebp.Encode(&webpBuf, thumb, nil); err != nil {
+			return nil, fmt.Errorf("encoding %dpx WebP thumbnail: %w", size, err)
+		}
+
+		jpegName, _ := ObjectName(hash, size, FormatJPEG)
+		webpName, _ := ObjectName(hash, size, FormatWebP)
+		variants = append(variants,
+			Variant{Name: jpegName, ContentType: "image/jpeg", Width: size, Height: size, Data: jpegBuf.Bytes()},
+			Variant{Name: webpName, ContentType: "image/webp", Width: size, Height: size, Data: webpBuf.Bytes()},
+		)
+	}
+	return variants, nil
+}
+
+// centerSquare returns the largest square centred in r. Album art is already square; artist photos often aren't.
+func centerSquare(r image.Rectangle) image.Rectangle {
+	w, h := r.Dx(), r.Dy()
+	if w > h {
+		x := r.Min.X + (w-h)/2
+		return image.Rect(x, r.Min.Y, x+h, r.Max.Y)
+	}
+	y := r.Min.Y + (h-w)/2
+	return image.Rect(r.Min.X, y, r.Max.X, y+w)
+}
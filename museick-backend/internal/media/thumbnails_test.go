@@ -0,0 +1,80 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func TestThumbnails(t *testing.T) {
+	// A wide artist photo: red left margin, blue centre, red right margin
+	src := image.NewRGBA(image.Rect(0, 0, 400, 200))
+	for x := 0; x < 400; x++ {
+		for y := 0; y < 200; y++ {
+			c := color.RGBA{R: 255, A: 255}
+			if x >= 100 && x < 300 {
+				c = color.RGBA{B: 255, A: 255}
+			}
+			src.Set(x, y, c)
+		}
+	}
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, src); err != nil {
+		t.Fatal(err)
+	}
+	img, contentType, err := Decode(encoded.Bytes())
+	if err != nil || contentType != "image/png" {
+		t.Fatalf("Decode() = %s, %v; want image/png", contentType, err)
+	}
+
+	variants, err := Thumbnails(testHash, img)
+	if err != nil {
+		t.Fatalf("Thumbnails() = %v", err)
+	}
+	if len(variants) != 2*len(ThumbnailSizes) {
+		t.Fatalf("got %d variants, want %d", len(variants), 2*len(ThumbnailSizes))
+	}
+	for i, size := range ThumbnailSizes {
+		jpegVariant, webpVariant := variants[2*i], variants[2*i+1]
+		jpegName, _ := ObjectName(testHash, size, FormatJPEG)
+		webpName, _ := ObjectName(testHash, size, FormatWebP)
+		if jpegVariant.Name != jpegName || jpegVariant.ContentType != "image/jpeg" || webpVariant.Name != webpName || webpVariant.ContentType != "image/webp" {
+			t.Errorf("%dpx variants = %s (%s), %s (%s); want %s and %s", size, jpegVariant.Name, jpegVariant.ContentType, webpVariant.Name, webpVariant.ContentType, jpegName, webpName)
+		}
+		thumb, err := jpeg.Decode(bytes.NewReader(jpegVariant.Data))
+		if err != nil {
+			t.Fatalf("%dpx JPEG does not decode: %v", size, err)
+		}
+		if b := thumb.Bounds(); b.Dx() != size || b.Dy() != size {
+			t.Errorf("%dpx JPEG is %dx%d", size, b.Dx(), b.Dy())
+		}
+		// The crop keeps the centre, so even the corners are blue
+		if r, _, b, _ := thumb.At(1, 1).RGBA(); r > b {
+			t.Errorf("%dpx thumbnail corner is %v, want the blue centre of the photo", size, thumb.At(1, 1))
+		}
+	}
+
+	if _, _, err := Decode([]byte("not an image")); err == nil {
+		t.Error("Decode(garbage) succeeded, want an error")
+	}
+}
+
+func TestCenterSquare(t *testing.T) {
+	tests := []struct {
+		r    image.Rectangle
+		want image.Rectangle
+	}{
+		{image.Rect(0, 0, 640, 640), image.Rect(0, 0, 640, 640)},
+		{image.Rect(0, 0, 400, 200), image.Rect(100, 0, 300, 200)},
+		{image.Rect(0, 0, 200, 401), image.Rect(0, 100, 200, 300)},
+		{image.Rect(10, 20, 110, 70), image.Rect(35, 20, 85, 70)},
+	}
+	for _, tt := range tests {
+		if got := centerSquare(tt.r); got != tt.want {
+			t.Errorf("centerSquare(%v) = %v, want %v", tt.r, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,17 @@
+package metrics
+
+import (
+	"strconv"
+	"time"
+)
+
+// HTTPRequestStarted marks a request as in flight. Call the returned function when it completes.
+func HTTPRequestStarted() func(method, route string, status int, duration time.Duration) {
+	httpRequestsInFlight.Inc()
+	return func(method, route string, status int, duration time.Duration) {
+		httpRequestsInFlight.Dec()
+		code := strconv.Itoa(status)
+		httpRequestsTotal.WithLabelValues(method, route, code).Inc()
+		httpRequestDuration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+	}
+}
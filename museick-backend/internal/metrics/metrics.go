@@ -0,0 +1,151 @@
+// Package metrics defines the application's Prometheus collectors and the
+// instrumentation hooks (HTTP middleware, MongoDB command monitor, Spotify
+// transport) that feed them. Everything is registered on Registry, which
+// is served at /metrics.
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "museick"
+
+// Registry holds every collector exposed by the application.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "HTTP requests handled, by method, gin route and status code.",
+	}, []string{"method", "route", "status"})
+
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "HTTP request latency, by method, gin route and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method", "route", "status"})
+
+	httpRequestsInFlight = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "HTTP requests currently being handled.",
+	})
+
+	mongoCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "mongo",
+		Name:      "command_duration_seconds",
+		Help:      "MongoDB command latency, by command, collection and outcome.",
+		Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14), // 0.5ms to ~4s
+	}, []string{"command", "collection", "outcome"})
+
+	spotifyRequestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spotify",
+		Name:      "requests_total",
+		Help:      "Requests made to the Spotify Web API and accounts service, by endpoint and status code (\"error\" for transport failures).",
+	}, []string{"endpoint", "status"})
+
+	spotifyRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "spotify",
+		Name:      "request_duration_seconds",
+		Help:      "Spotify request latency, by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	spotifyRateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spotify",
+		Name:      "rate_limited_total",
+		Help:      "Spotify responses with status 429 (Too Many Requests), by endpoint.",
+	}, []string{"endpoint"})
+
+	spotifyCacheLookupsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spotify_cache",
+		Name:      "lookups_total",
+		Help:      "Spotify cache lookups, by item type (track, album, artist, related_artists, artist_top_tracks) and result (hit, miss, stale).",
+	}, []string{"item_type", "result"})
+
+	spotifyCacheGCDeletedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "spotify_cache",
+		Name:      "gc_deleted_total",
+		Help:      "Cached Spotify documents deleted by the cache garbage collector because no selection references them, by collection.",
+	}, []string{"collection"})
+
+	mediaImagesMirroredTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "media",
+		Name:      "images_mirrored_total",
+		Help:      "Spotify images mirrored into the media store, by result (downloaded, reused, failed).",
+	}, []string{"result"})
+
+	rateLimitedTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Requests rejected with 429 by our own rate limiter, by route group.",
+	}, []string{"group"})
+)
+
+// Cache lookup results recorded by RecordCacheLookup.
+const (
+	CacheHit   = "hit"   // Found in the database and fresh
+	CacheMiss  = "miss"  // Not in the database
+	CacheStale = "stale" // In the database but older than the refresh threshold
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestsTotal,
+		httpRequestDuration,
+		httpRequestsInFlight,
+		mongoCommandDuration,
+		spotifyRequestsTotal,
+		spotifyRequestDuration,
+		spotifyRateLimitedTotal,
+		spotifyCacheLookupsTotal,
+		spotifyCacheGCDeletedTotal,
+		mediaImagesMirroredTotal,
+		rateLimitedTotal,
+	)
+}
+
+// Handler serves the registry in the Prometheus exposition format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// RecordCacheLookup counts a Spotify item cache lookup.
+func RecordCacheLookup(itemType, result string) {
+	spotifyCacheLookupsTotal.WithLabelValues(itemType, result).Inc()
+}
+
+// RecordCacheGCDeleted counts cached documents removed from a collection by the cache garbage collector.
+func RecordCacheGCDeleted(collection string, deleted int64) {
+	spotifyCacheGCDeletedTotal.WithLabelValues(collection).Add(float64(deleted))
+}
+
+// RecordImageMirrored counts one image mirror attempt. result is downloaded, reused (already mirrored from
+// that URL or with the same bytes) or failed.
+func RecordImageMirrored(result string) {
+	mediaImagesMirroredTotal.WithLabelValues(result).Inc()
+}
+
+// RecordRateLimited counts a request rejected by the rate limiter.
+func RecordRateLimited(group string) {
+	rateLimitedTotal.WithLabelValues(group).Inc()
+}
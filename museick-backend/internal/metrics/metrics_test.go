@@ -0,0 +1,163 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// sample returns the counter or gauge value, or the histogram's observation count, of the series
+// with exactly the given labels.
+func sample(t *testing.T, name string, labels map[string]string) float64 {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatalf("gathering metrics: %v", err)
+	}
+	for _, family := range families {
+		if family.GetName() != name {
+			continue
+		}
+	series:
+		for _, m := range family.GetMetric() {
+			if len(m.GetLabel()) != len(labels) {
+				continue
+			}
+			for _, label := range m.GetLabel() {
+				if labels[label.GetName()] != label.GetValue() {
+					continue series
+				}
+			}
+			switch {
+			case m.GetCounter() != nil:
+				return m.GetCounter().GetValue()
+			case m.GetGauge() != nil:
+				return m.GetGauge().GetValue()
+			case m.GetHistogram() != nil:
+				return float64(m.GetHistogram().GetSampleCount())
+			}
+		}
+	}
+	return 0
+}
+
+func TestSpotifyEndpoint(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/v1/tracks/4iV5W9uYEdYUVa79Axb7Rh", "/v1/tracks/:id"},
+		{"/v1/albums/4aawyAB9vmqN3uQ7FjRGTy/tracks", "/v1/albums/:id/tracks"},
+		{"/v1/artists/0OdUWJ0sBjDrqHygGUXeCF/related-artists", "/v1/artists/:id/related-artists"},
+		{"/v1/tracks", "/v1/tracks"},
+		{"/v1/users/some.user-name/playlists", "/v1/users/:id/playlists"},
+		{"/v1/playlists/37i9dQZF1DXcBWIGoYBM5M/tracks", "/v1/playlists/:id/tracks"},
+		{"/v1/me/following/contains", "/v1/me/following/contains"},
+		{"/v1/me/tracks/contains", "/v1/me/tracks/contains"},
+		{"/api/token", "/api/token"},
+		{"/", "/"},
+	}
+	for _, tt := range tests {
+		if got := SpotifyEndpoint(tt.path); got != tt.want {
+			t.Errorf("SpotifyEndpoint(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func TestSpotifyTransport(t *testing.T) {
+	const endpoint = "/v1/albums/:id"
+	responses := []struct {
+		status int
+		err    error
+	}{{http.StatusOK, nil}, {http.StatusTooManyRequests, nil}, {0, errors.New("connection reset")}}
+
+	before := map[string]float64{
+		"200":   sample(t, "museick_spotify_requests_total", map[string]string{"endpoint": endpoint, "status": "200"}),
+		"429":   sample(t, "museick_spotify_requests_total", map[string]string{"endpoint": endpoint, "status": "429"}),
+		"error": sample(t, "museick_spotify_requests_total", map[string]string{"endpoint": endpoint, "status": "error"}),
+	}
+	limitedBefore := sample(t, "museick_spotify_rate_limited_total", map[string]string{"endpoint": endpoint})
+	timedBefore := sample(t, "museick_spotify_request_duration_seconds", map[string]string{"endpoint": endpoint})
+
+	for _, r := range responses {
+		transport := SpotifyTransport(roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			if r.err != nil {
+				return nil, r.err
+			}
+			return &http.Response{StatusCode: r.status, Body: http.NoBody}, nil
+		}))
+		req, _ := http.NewRequest(http.MethodGet, "https://api.spotify.com/v1/albums/4aawyAB9vmqN3uQ7FjRGTy", nil)
+		resp, err := transport.RoundTrip(req)
+		if !errors.Is(err, r.err) || (err == nil && resp.StatusCode != r.status) {
+			t.Errorf("RoundTrip() = %v, %v; want status %d, error %v passed through", resp, err, r.status, r.err)
+		}
+	}
+
+	for status, was := range before {
+		if got := sample(t, "museick_spotify_requests_total", map[string]string{"endpoint": endpoint, "status": status}); got != was+1 {
+			t.Errorf("requests with status %s = %v, want %v", status, got, was+1)
+		}
+	}
+	if got := sample(t, "museick_spotify_rate_limited_total", map[string]string{"endpoint": endpoint}); got != limitedBefore+1 {
+		t.Errorf("rate limited = %v, want %v", got, limitedBefore+1)
+	}
+	if got := sample(t, "museick_spotify_request_duration_seconds", map[string]string{"endpoint": endpoint}); got != timedBefore+3 {
+		t.Errorf("timed requests = %v, want %v", got, timedBefore+3)
+	}
+}
+
+func TestHTTPRequestStarted(t *testing.T) {
+	labels := map[string]string{"method": "GET", "route": "/api/test-metrics/:id", "status": "404"}
+	inFlight := sample(t, "museick_http_requests_in_flight", nil)
+	total := sample(t, "museick_http_requests_total", labels)
+
+	done := HTTPRequestStarted()
+	if got := sample(t, "museick_http_requests_in_flight", nil); got != inFlight+1 {
+		t.Errorf("in flight while handling = %v, want %v", got, inFlight+1)
+	}
+	done("GET", "/api/test-metrics/:id", http.StatusNotFound, 5*time.Millisecond)
+
+	if got := sample(t, "museick_http_requests_in_flight", nil); got != inFlight {
+		t.Errorf("in flight after completion = %v, want %v", got, inFlight)
+	}
+	if got := sample(t, "museick_http_requests_total", labels); got != total+1 {
+		t.Errorf("requests = %v, want %v", got, total+1)
+	}
+	if got := sample(t, "museick_http_request_duration_seconds", labels); got < 1 {
+		t.Errorf("timed requests = %v, want at least 1", got)
+	}
+}
+
+func TestMongoCommandMonitor(t *testing.T) {
+	monitor := NewMongoCommandMonitor()
+	find, _ := bson.Marshal(bson.D{{Key: "find", Value: "test_metrics_users"}, {Key: "filter", Value: bson.D{}}})
+	ping, _ := bson.Marshal(bson.D{{Key: "ping", Value: 1}})
+	found := map[string]string{"command": "find", "collection": "test_metrics_users", "outcome": "success"}
+	failed := map[string]string{"command": "find", "collection": "test_metrics_users", "outcome": "failure"}
+	pinged := map[string]string{"command": "ping", "collection": "none", "outcome": "success"}
+	before := []float64{sample(t, "museick_mongo_command_duration_seconds", found), sample(t, "museick_mongo_command_duration_seconds", failed), sample(t, "museick_mongo_command_duration_seconds", pinged)}
+
+	ctx := context.Background()
+	// Two connections reuse request ID 1; each finished event must pick up its own collection
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: find, CommandName: "find", RequestID: 1, ConnectionID: "a"})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: ping, CommandName: "ping", RequestID: 1, ConnectionID: "b"})
+	monitor.Started(ctx, &event.CommandStartedEvent{Command: find, CommandName: "find", RequestID: 2, ConnectionID: "a"})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "ping", RequestID: 1, ConnectionID: "b", Duration: time.Millisecond}})
+	monitor.Succeeded(ctx, &event.CommandSucceededEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 1, ConnectionID: "a", Duration: time.Millisecond}})
+	monitor.Failed(ctx, &event.CommandFailedEvent{CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", RequestID: 2, ConnectionID: "a", Duration: time.Millisecond}})
+
+	for i, labels := range []map[string]string{found, failed, pinged} {
+		if got := sample(t, "museick_mongo_command_duration_seconds", labels); got != before[i]+1 {
+			t.Errorf("commands %v = %v, want %v", labels, got, before[i]+1)
+		}
+	}
+}
@@ -0,0 +1,44 @@
+package metrics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// NewMongoCommandMonitor returns a command monitor that times every MongoDB command.
+// The collection is read from the started event, since finished events only carry the command name.
+func NewMongoCommandMonitor() *event.CommandMonitor {
+	var collections sync.Map // connection ID + request ID -> collection name
+
+	key := func(connectionID string, requestID int64) string {
+		return connectionID + "/" + strconv.FormatInt(requestID, 10)
+	}
+	finish := func(connectionID string, requestID int64, commandName string, duration time.Duration, outcome string) {
+		collection := "none"
+		if v, ok := collections.LoadAndDelete(key(connectionID, requestID)); ok {
+			collection = v.(string)
+		}
+		mongoCommandDuration.WithLabelValues(commandName, collection, outcome).Observe(duration.Seconds())
+	}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			// For CRUD commands the first element's value is the collection name, e.g. {find: "users", ...}
+			if elem, err := evt.Command.IndexErr(0); err == nil {
+				if name, ok := elem.Value().StringValueOK(); ok && elem.Key() == evt.CommandName {
+					collections.Store(key(evt.ConnectionID, evt.RequestID), name)
+				}
+			}
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			finish(evt.ConnectionID, evt.RequestID, evt.CommandName, evt.Duration, "success")
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			finish(evt.ConnectionID, evt.RequestID, evt.CommandName, evt.Duration, "failure")
+		},
+	}
+}
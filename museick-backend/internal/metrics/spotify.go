@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// spotifyIDRegex matches base-62 Spotify IDs in request paths.
+var spotifyIDRegex = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// spotifyResourceSegments are path segments followed by a resource ID (user and playlist IDs are not base-62).
+var spotifyResourceSegments = map[string]bool{
+	"tracks": true, "albums": true, "artists": true, "users": true, "playlists": true,
+	"audio-features": true, "audio-analysis": true, "shows": true, "episodes": true,
+}
+
+// SpotifyTransport wraps base (http.DefaultTransport if nil) so every request
+// to Spotify is counted and timed by endpoint and status.
+func SpotifyTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &spotifyTransport{base: base}
+}
+
+type spotifyTransport struct {
+	base http.RoundTripper
+}
+
+func (t *spotifyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	endpoint := SpotifyEndpoint(req.URL.Path)
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	spotifyRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+
+	if err != nil {
+		spotifyRequestsTotal.WithLabelValues(endpoint, "error").Inc()
+		return resp, err
+	}
+	spotifyRequestsTotal.WithLabelValues(endpoint, strconv.Itoa(resp.StatusCode)).Inc()
+	if resp.StatusCode == http.StatusTooManyRequests {
+		spotifyRateLimitedTotal.WithLabelValues(endpoint).Inc()
+	}
+	return resp, nil
+}
+
+// SpotifyEndpoint normalizes a request path into a low-cardinality label,
+// e.g. "/v1/albums/4aawyAB9vmqN3uQ7FjRGTy/tracks" becomes "/v1/albums/:id/tracks".
+func SpotifyEndpoint(path string) string {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	for i, segment := range segments {
+		if spotifyIDRegex.MatchString(segment) ||
+			(i > 0 && spotifyResourceSegments[segments[i-1]] && segment != "contains") {
+			segments[i] = ":id"
+		}
+	}
+	return "/" + strings.Join(segments, "/")
+}
@@ -0,0 +1,19 @@
+package migrations
+
+// All returns every migration in version order. Append new migrations with the next version;
+// never renumber or edit one that has shipped, since deployments record versions they've applied.
+func All() []Migration {
+	return []Migration{
+		{Version: 1, Name: "create_users_indexes", Required: true, Up: createUsersIndexes, Down: dropUsersIndexes},
+		{Version: 2, Name: "create_user_selections_indexes", Required: true, Up: createUserSelectionsIndexes, Down: dropUserSelectionsIndexes},
+		{Version: 3, Name: "create_spotify_cache_indexes", Required: true, Up: createSpotifyCacheIndexes, Down: dropSpotifyCacheIndexes},
+		{Version: 4, Name: "create_idempotency_keys_indexes", Required: true, Up: createIdempotencyKeysIndexes, Down: dropIdempotencyKeysIndexes},
+		{Version: 5, Name: "backfill_track_is_local", Up: backfillTrackIsLocal},
+		{Version: 6, Name: "backfill_selection_search_names", Up: backfillSelectionSearchNames},
+		{Version: 7, Name: "recompute_track_is_local", Up: noop}, // Retired: backfill_track_is_local (5) covers it
+		{Version: 8, Name: "backfill_canonical_ids", Up: backfillCanonicalIDs},
+		{Version: 9, Name: "create_cache_gc_indexes", Required: true, Up: createCacheGCIndexes, Down: dropCacheGCIndexes},
+		{Version: 10, Name: "create_media_assets_indexes", Required: true, Up: createMediaAssetsIndexes, Down: dropMediaAssetsIndexes},
+		{Version: 11, Name: "create_rate_limits_indexes", Required: true, Up: createRateLimitsIndexes, Down: dropRateLimitsIndexes},
+	}
+}
@@ -0,0 +1,216 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// How many selections are looked up and updated per round trip in backfills.
+const backfillBatchSize = 500
+
+// noop stands in for a retired migration, keeping its version recorded so later versions never shift.
+func noop(ctx context.Context, db *mongo.Database) error {
+	return nil
+}
+
+// backfillTrackIsLocal recomputes is_local on cached tracks. It used to be filled from Spotify's is_playable flag;
+// local files are the tracks whose URI starts with spotify:local:.
+func backfillTrackIsLocal(ctx context.Context, db *mongo.Database) error {
+	update := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "is_local", Value: bson.D{{Key: "$eq", Value: bson.A{
+			bson.D{{Key: "$substrCP", Value: bson.A{bson.D{{Key: "$ifNull", Value: bson.A{"$uri", ""}}}, 0, len("spotify:local:")}}},
+			"spotify:local:",
+		}}}}}}},
+	}
+	if _, err := db.Collection(dao.SpotifyTracksCollection).UpdateMany(ctx, bson.M{}, update); err != nil {
+		return fmt.Errorf("recomputing is_local: %w", err)
+	}
+	return nil
+}
+
+// backfillSelectionSearchNames fills item_name, album_name and artist_names on selections created before
+// they were denormalized, using the Spotify cache. Selections whose item isn't cached are left as they are.
+func backfillSelectionSearchNames(ctx context.Context, db *mongo.Database) error {
+	selections := db.Collection(dao.UserSelectionsCollection)
+	cursor, err := selections.Find(ctx,
+		bson.M{"item_name": bson.M{"$in": bson.A{nil, ""}}},
+		options.Find().SetProjection(bson.M{"spotify_item_id": 1, "item_type": 1}))
+	if err != nil {
+		return fmt.Errorf("finding selections without names: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	pending := map[string]map[string]bool{} // item type -> spotify IDs
+	count := 0
+	flush := func() error {
+		for itemType, ids := range pending {
+			if err := fillSearchNames(ctx, db, itemType, ids); err != nil {
+				return err
+			}
+		}
+		pending, count = map[string]map[string]bool{}, 0
+		return nil
+	}
+	for cursor.Next(ctx) {
+		var sel struct {
+			SpotifyItemID string `bson:"spotify_item_id"`
+			ItemType      string `bson:"item_type"`
+		}
+		if err := cursor.Decode(&sel); err != nil {
+			return fmt.Errorf("decoding selection: %w", err)
+		}
+		if pending[sel.ItemType] == nil {
+			pending[sel.ItemType] = map[string]bool{}
+		}
+		pending[sel.ItemType][sel.SpotifyItemID] = true
+		if count++; count >= backfillBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("iterating selections: %w", err)
+	}
+	return flush()
+}
+
+// fillSearchNames sets the denormalized names on every unnamed selection of the given cached items.
+func fillSearchNames(ctx context.Context, db *mongo.Database, itemType string, ids map[string]bool) error {
+	var collectionName string
+	var decode func(cursor *mongo.Cursor) (string, interface{}, error)
+	switch itemType {
+	case "track":
+		collectionName = dao.SpotifyTracksCollection
+		decode = func(cursor *mongo.Cursor) (string, interface{}, error) {
+			var v models.SpotifyTrack
+			err := cursor.Decode(&v)
+			return v.SpotifyID, &v, err
+		}
+	case "album":
+		collectionName = dao.SpotifyAlbumsCollection
+		decode = func(cursor *mongo.Cursor) (string, interface{}, error) {
+			var v models.SpotifyAlbum
+			err := cursor.Decode(&v)
+			return v.SpotifyID, &v, err
+		}
+	case "artist":
+		collectionName = dao.SpotifyArtistsCollection
+		decode = func(cursor *mongo.Cursor) (string, interface{}, error) {
+			var v models.SpotifyArtist
+			err := cursor.Decode(&v)
+			return v.SpotifyID, &v, err
+		}
+	default:
+		return nil // Unknown item types have no cache to read from
+	}
+
+	idList := make([]string, 0, len(ids))
+	for id := range ids {
+		idList = append(idList, id)
+	}
+	cursor, err := db.Collection(collectionName).Find(ctx, bson.M{"_id": bson.M{"$in": idList}})
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", collectionName, err)
+	}
+	defer cursor.Close(ctx)
+
+	var writes []mongo.WriteModel
+	for cursor.Next(ctx) {
+		id, item, err := decode(cursor)
+		if err != nil {
+			return fmt.Errorf("decoding %s item: %w", itemType, err)
+		}
+		itemName, albumName, artistNames := models.SearchNames(item)
+		writes = append(writes, mongo.NewUpdateManyModel().
+			SetFilter(bson.M{"item_type": itemType, "spotify_item_id": id, "item_name": bson.M{"$in": bson.A{nil, ""}}}).
+			SetUpdate(bson.M{"$set": bson.M{"item_name": itemName, "album_name": albumName, "artist_names": artistNames}}))
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("iterating %s: %w", collectionName, err)
+	}
+	if len(writes) == 0 {
+		return nil
+	}
+	if _, err := db.Collection(dao.UserSelectionsCollection).BulkWrite(ctx, writes, options.BulkWrite().SetOrdered(false)); err != nil {
+		return fmt.Errorf("updating selection names: %w", err)
+	}
+	return nil
+}
+
+// backfillCanonicalIDs sets canonical_id on cached tracks and albums from their ISRC/UPC, then copies it onto
+// the selections of those items. Artist selections get theirs from the artist ID. Selections whose item isn't
+// cached are left without one; they are grouped by Spotify ID until the item is next selected.
+func backfillCanonicalIDs(ctx context.Context, db *mongo.Database) error {
+	if err := fillCanonicalIDs(ctx, db, dao.SpotifyTracksCollection, "track", models.TrackCanonicalID); err != nil {
+		return err
+	}
+	if err := fillCanonicalIDs(ctx, db, dao.SpotifyAlbumsCollection, "album", models.AlbumCanonicalID); err != nil {
+		return err
+	}
+	artistCanonicalID := mongo.Pipeline{
+		{{Key: "$set", Value: bson.D{{Key: "canonical_id", Value: bson.D{{Key: "$concat", Value: bson.A{"spotify:artist:", "$spotify_item_id"}}}}}}},
+	}
+	_, err := db.Collection(dao.UserSelectionsCollection).UpdateMany(ctx,
+		bson.M{"item_type": "artist", "canonical_id": bson.M{"$in": bson.A{nil, ""}}}, artistCanonicalID)
+	if err != nil {
+		return fmt.Errorf("setting canonical_id on artist selections: %w", err)
+	}
+	return nil
+}
+
+// fillCanonicalIDs computes canonical_id for every item in a cache collection and sets it on the item and its selections.
+func fillCanonicalIDs(ctx context.Context, db *mongo.Database, collectionName, itemType string, canonicalID func(spotifyID string, externalIDs map[string]string) string) error {
+	cache := db.Collection(collectionName)
+	cursor, err := cache.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"external_ids": 1}))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", collectionName, err)
+	}
+	defer cursor.Close(ctx)
+
+	var cacheWrites, selectionWrites []mongo.WriteModel
+	flush := func() error {
+		if len(cacheWrites) == 0 {
+			return nil
+		}
+		if _, err := cache.BulkWrite(ctx, cacheWrites, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("updating %s canonical ids: %w", collectionName, err)
+		}
+		if _, err := db.Collection(dao.UserSelectionsCollection).BulkWrite(ctx, selectionWrites, options.BulkWrite().SetOrdered(false)); err != nil {
+			return fmt.Errorf("updating selection canonical ids: %w", err)
+		}
+		cacheWrites, selectionWrites = nil, nil
+		return nil
+	}
+	for cursor.Next(ctx) {
+		var item struct {
+			ID          string            `bson:"_id"`
+			ExternalIDs map[string]string `bson:"external_ids"`
+		}
+		if err := cursor.Decode(&item); err != nil {
+			return fmt.Errorf("decoding %s item: %w", itemType, err)
+		}
+		id := canonicalID(item.ID, item.ExternalIDs)
+		cacheWrites = append(cacheWrites, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": item.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"canonical_id": id}}))
+		selectionWrites = append(selectionWrites, mongo.NewUpdateManyModel().
+			SetFilter(bson.M{"item_type": itemType, "spotify_item_id": item.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"canonical_id": id}}))
+		if len(cacheWrites) >= backfillBatchSize {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+	}
+	if err := cursor.Err(); err != nil {
+		return fmt.Errorf("iterating %s: %w", collectionName, err)
+	}
+	return flush()
+}
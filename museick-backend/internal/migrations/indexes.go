@@ -0,0 +1,239 @@
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/ratelimit"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// SpotifyCacheFetchedIndexName indexes last_fetched_at on each Spotify cache collection, for finding stale items.
+const SpotifyCacheFetchedIndexName = "last_fetched_at_1"
+
+// MongoDB error codes that mean there is nothing to drop.
+const (
+	codeNamespaceNotFound = 26
+	codeIndexNotFound     = 27
+)
+
+// EnsureIndexes re-runs every index migration. Index creation is idempotent, so this repairs
+// indexes that were dropped by hand without touching the migration history.
+func EnsureIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, create := range []func(context.Context, *mongo.Database) error{
+		createUsersIndexes, createUserSelectionsIndexes, createSpotifyCacheIndexes, createIdempotencyKeysIndexes,
+		createCacheGCIndexes, createMediaAssetsIndexes, createRateLimitsIndexes,
+	} {
+		if err := create(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func createIndexes(ctx context.Context, collection *mongo.Collection, models ...mongo.IndexModel) error {
+	if _, err := collection.Indexes().CreateMany(ctx, models); err != nil {
+		return fmt.Errorf("creating indexes on %s: %w", collection.Name(), err)
+	}
+	return nil
+}
+
+// dropIndexes drops the named indexes, ignoring ones that don't exist so rollbacks can be re-run.
+func dropIndexes(ctx context.Context, collection *mongo.Collection, names ...string) error {
+	for _, name := range names {
+		_, err := collection.Indexes().DropOne(ctx, name)
+		var cmdErr mongo.CommandError
+		if errors.As(err, &cmdErr) && (cmdErr.Code == codeIndexNotFound || cmdErr.Code == codeNamespaceNotFound) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("dropping index %s on %s: %w", name, collection.Name(), err)
+		}
+	}
+	return nil
+}
+
+func createUsersIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db.Collection(dao.UsersCollection), mongo.IndexModel{
+		Keys:    bson.M{"sub": 1},
+		Options: options.Index().SetName(dao.UserSubIndexName).SetUnique(true),
+	})
+}
+
+func dropUsersIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection(dao.UsersCollection), dao.UserSubIndexName)
+}
+
+func createUserSelectionsIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db.Collection(dao.UserSelectionsCollection),
+		// A user can only add the same item once per month
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "month_year", Value: 1}, {Key: "spotify_item_id", Value: 1}},
+			Options: options.Index().SetName(dao.SelectionUniqueIndexName).SetUnique(true),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "month_year", Value: 1}},
+			Options: options.Index().SetName(dao.SelectionMonthIndexName),
+		},
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "month_year", Value: 1}, {Key: "selection_role", Value: 1}},
+			Options: options.Index().SetName(dao.SelectionRoleIndexName),
+		},
+		// Multikey index for listing by mood tag
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_id", Value: 1}, {Key: "mood_tags", Value: 1}},
+			Options: options.Index().SetName(dao.SelectionMoodTagIndexName),
+		},
+		// Journal search: notes carry the most weight, followed by the item's own name
+		mongo.IndexModel{
+			Keys: bson.D{
+				{Key: "notes", Value: "text"},
+				{Key: "item_name", Value: "text"},
+				{Key: "album_name", Value: "text"},
+				{Key: "artist_names", Value: "text"},
+			},
+			Options: options.Index().
+				SetName(dao.JournalTextIndexName).
+				SetWeights(bson.D{
+					{Key: "notes", Value: 10},
+					{Key: "item_name", Value: 5},
+					{Key: "artist_names", Value: 3},
+					{Key: "album_name", Value: 2},
+				}),
+		},
+	)
+}
+
+func dropUserSelectionsIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection(dao.UserSelectionsCollection),
+		dao.SelectionUniqueIndexName, dao.SelectionMonthIndexName, dao.SelectionRoleIndexName,
+		dao.SelectionMoodTagIndexName, dao.JournalTextIndexName)
+}
+
+var spotifyCacheCollections = []string{dao.SpotifyTracksCollection, dao.SpotifyAlbumsCollection, dao.SpotifyArtistsCollection}
+
+func createSpotifyCacheIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, name := range spotifyCacheCollections {
+		err := createIndexes(ctx, db.Collection(name), mongo.IndexModel{
+			Keys:    bson.M{"last_fetched_at": 1},
+			Options: options.Index().SetName(SpotifyCacheFetchedIndexName),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropSpotifyCacheIndexes(ctx context.Context, db *mongo.Database) error {
+	for _, name := range spotifyCacheCollections {
+		if err := dropIndexes(ctx, db.Collection(name), SpotifyCacheFetchedIndexName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// The enrichment caches are keyed by artist, so the garbage collector also needs them indexed by fetch time.
+var spotifyEnrichmentCollections = []string{dao.SpotifyRelatedArtistsCollection, dao.SpotifyArtistTopTracksCollection}
+
+// createCacheGCIndexes adds the indexes the cache garbage collector relies on: a lookup from an item
+// to the selections referencing it, from an artist or album to the cached tracks and albums crediting
+// it, and last_fetched_at on the enrichment caches.
+func createCacheGCIndexes(ctx context.Context, db *mongo.Database) error {
+	err := createIndexes(ctx, db.Collection(dao.UserSelectionsCollection), mongo.IndexModel{
+		Keys:    bson.D{{Key: "item_type", Value: 1}, {Key: "spotify_item_id", Value: 1}},
+		Options: options.Index().SetName(dao.SelectionItemIndexName),
+	})
+	if err != nil {
+		return err
+	}
+	err = createIndexes(ctx, db.Collection(dao.SpotifyTracksCollection),
+		mongo.IndexModel{Keys: bson.M{"artists.id": 1}, Options: options.Index().SetName(dao.CacheArtistCreditIndexName)},
+		mongo.IndexModel{Keys: bson.M{"album.id": 1}, Options: options.Index().SetName(dao.CacheAlbumCreditIndexName)},
+	)
+	if err != nil {
+		return err
+	}
+	err = createIndexes(ctx, db.Collection(dao.SpotifyAlbumsCollection), mongo.IndexModel{
+		Keys:    bson.M{"artists.id": 1},
+		Options: options.Index().SetName(dao.CacheArtistCreditIndexName),
+	})
+	if err != nil {
+		return err
+	}
+	for _, name := range spotifyEnrichmentCollections {
+		err := createIndexes(ctx, db.Collection(name), mongo.IndexModel{
+			Keys:    bson.M{"last_fetched_at": 1},
+			Options: options.Index().SetName(SpotifyCacheFetchedIndexName),
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func dropCacheGCIndexes(ctx context.Context, db *mongo.Database) error {
+	if err := dropIndexes(ctx, db.Collection(dao.UserSelectionsCollection), dao.SelectionItemIndexName); err != nil {
+		return err
+	}
+	if err := dropIndexes(ctx, db.Collection(dao.SpotifyTracksCollection), dao.CacheArtistCreditIndexName, dao.CacheAlbumCreditIndexName); err != nil {
+		return err
+	}
+	if err := dropIndexes(ctx, db.Collection(dao.SpotifyAlbumsCollection), dao.CacheArtistCreditIndexName); err != nil {
+		return err
+	}
+	for _, name := range spotifyEnrichmentCollections {
+		if err := dropIndexes(ctx, db.Collection(name), SpotifyCacheFetchedIndexName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Mirroring looks assets up by the Spotify URL they came from before downloading anything
+func createMediaAssetsIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db.Collection(dao.MediaAssetsCollection), mongo.IndexModel{
+		Keys:    bson.M{"source_urls": 1},
+		Options: options.Index().SetName(dao.MediaAssetSourceIndexName),
+	})
+}
+
+func dropMediaAssetsIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection(dao.MediaAssetsCollection), dao.MediaAssetSourceIndexName)
+}
+
+func createIdempotencyKeysIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db.Collection(dao.IdempotencyKeysCollection),
+		mongo.IndexModel{
+			Keys:    bson.D{{Key: "user_sub", Value: 1}, {Key: "key", Value: 1}},
+			Options: options.Index().SetName(dao.IdempotencyKeyIndexName).SetUnique(true),
+		},
+		// Records are removed once expires_at passes
+		mongo.IndexModel{
+			Keys:    bson.M{"expires_at": 1},
+			Options: options.Index().SetName(dao.IdempotencyExpiryIndexName).SetExpireAfterSeconds(0),
+		},
+	)
+}
+
+func dropIdempotencyKeysIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection(dao.IdempotencyKeysCollection), dao.IdempotencyKeyIndexName, dao.IdempotencyExpiryIndexName)
+}
+
+// Idle rate limit buckets are removed once expires_at passes
+func createRateLimitsIndexes(ctx context.Context, db *mongo.Database) error {
+	return createIndexes(ctx, db.Collection(dao.RateLimitsCollection), mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetName(ratelimit.MongoExpiryIndexName).SetExpireAfterSeconds(0),
+	})
+}
+
+func dropRateLimitsIndexes(ctx context.Context, db *mongo.Database) error {
+	return dropIndexes(ctx, db.Collection(dao.RateLimitsCollection), ratelimit.MongoExpiryIndexName)
+}
@@ -0,0 +1,313 @@
+// Package migrations applies versioned, ordered changes to the MongoDB schema
+// (indexes and data backfills) and records which ones have run in the
+// schema_migrations collection.
+package migrations
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Collections used to track migrations.
+const (
+	HistoryCollection = "schema_migrations"
+	LockCollection    = "schema_migrations_lock"
+)
+
+// How long a migration lock is honoured before another process may take it over
+// (covers a migrator that crashed without releasing it).
+const lockTTL = 15 * time.Minute
+
+var (
+	// ErrPending is returned by CheckRequired when required migrations have not been applied.
+	ErrPending = errors.New("required schema migrations are pending")
+	// ErrLocked is returned when another process is running migrations.
+	ErrLocked = errors.New("schema migrations are locked by another process")
+	// ErrIrreversible is returned when rolling back a migration that has no Down step.
+	ErrIrreversible = errors.New("migration cannot be rolled back")
+	// ErrNameMismatch is returned when a version was recorded under a different name, e.g. after the list was renumbered.
+	ErrNameMismatch = errors.New("applied migrations do not match this version's migrations")
+)
+
+// Migration is one versioned schema change.
+type Migration struct {
+	Version  int    // Unique and increasing; migrations run in version order
+	Name     string // Short snake_case description
+	Required bool   // The server refuses to start while a required migration is pending
+	Up       func(ctx context.Context, db *mongo.Database) error
+	Down     func(ctx context.Context, db *mongo.Database) error // nil if the migration cannot be rolled back
+}
+
+// Record is the schema_migrations document written when a migration is applied.
+type Record struct {
+	Version    int       `bson:"_id"`
+	Name       string    `bson:"name"`
+	AppliedAt  time.Time `bson:"applied_at"`
+	DurationMS int64     `bson:"duration_ms"`
+}
+
+// Status describes a migration and whether it has been applied.
+type Status struct {
+	Version    int
+	Name       string
+	Required   bool
+	Reversible bool
+	Applied    bool
+	AppliedAt  time.Time
+	Unknown    bool   // Recorded as applied but not known to this binary (applied by a newer version)
+	AppliedAs  string // Name recorded when applied, if it differs from Name
+}
+
+// Migrator runs migrations against a database.
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	history    *mongo.Collection
+	lock       *mongo.Collection
+	logger     *slog.Logger
+}
+
+// NewMigrator creates a Migrator for the given migrations, which must have unique positive versions.
+func NewMigrator(db *mongo.Database, migrations []Migration, logger *slog.Logger) (*Migrator, error) {
+	sorted := append([]Migration(nil), migrations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Version < sorted[j].Version })
+	for i, m := range sorted {
+		if m.Version <= 0 {
+			return nil, fmt.Errorf("migration %q has invalid version %d", m.Name, m.Version)
+		}
+		if i > 0 && sorted[i-1].Version == m.Version {
+			return nil, fmt.Errorf("duplicate migration version %d (%s and %s)", m.Version, sorted[i-1].Name, m.Name)
+		}
+		if m.Up == nil {
+			return nil, fmt.Errorf("migration %d_%s has no Up step", m.Version, m.Name)
+		}
+	}
+	return &Migrator{
+		db:         db,
+		migrations: sorted,
+		history:    db.Collection(HistoryCollection),
+		lock:       db.Collection(LockCollection),
+		logger:     logger.With("component", "migrations"),
+	}, nil
+}
+
+// Status lists every known migration, plus any applied migration this binary doesn't know, in version order.
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name, Required: mig.Required, Reversible: mig.Down != nil}
+		if rec, ok := applied[mig.Version]; ok {
+			s.Applied, s.AppliedAt = true, rec.AppliedAt
+			if rec.Name != mig.Name {
+				s.AppliedAs = rec.Name
+			}
+			delete(applied, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, rec := range applied {
+		statuses = append(statuses, Status{Version: rec.Version, Name: rec.Name, Applied: true, AppliedAt: rec.AppliedAt, Unknown: true})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+// Pending returns the migrations that have not been applied, in version order.
+// It returns an error wrapping ErrNameMismatch if an applied version was recorded under another name.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkNames(m.migrations, applied); err != nil {
+		return nil, err
+	}
+	var pending []Migration
+	for _, mig := range m.migrations {
+		if _, ok := applied[mig.Version]; !ok {
+			pending = append(pending, mig)
+		}
+	}
+	return pending, nil
+}
+
+// CheckRequired returns an error wrapping ErrPending if any required migration has not been applied,
+// or ErrNameMismatch if the recorded history doesn't match this binary's migrations.
+func (m *Migrator) CheckRequired(ctx context.Context) error {
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, mig := range pending {
+		if mig.Required {
+			names = append(names, mig.String())
+		}
+	}
+	if len(names) > 0 {
+		return fmt.Errorf("%w: %s", ErrPending, strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// Up applies pending migrations in order, up to and including target (0 applies all).
+// It stops at the first failure; migrations applied before it stay applied.
+func (m *Migrator) Up(ctx context.Context, target int) (applied []Migration, err error) {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	pending, err := m.Pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, mig := range pending {
+		if target > 0 && mig.Version > target {
+			break
+		}
+		m.logger.InfoContext(ctx, "applying migration", "version", mig.Version, "name", mig.Name)
+		start := time.Now()
+		if err := mig.Up(ctx, m.db); err != nil {
+			return applied, fmt.Errorf("migration %s failed: %w", mig, err)
+		}
+		rec := Record{Version: mig.Version, Name: mig.Name, AppliedAt: time.Now().UTC(), DurationMS: time.Since(start).Milliseconds()}
+		if _, err := m.history.InsertOne(ctx, rec); err != nil {
+			return applied, fmt.Errorf("migration %s ran but could not be recorded: %w", mig, err)
+		}
+		m.logger.InfoContext(ctx, "migration applied", "version", mig.Version, "name", mig.Name, "duration_ms", rec.DurationMS)
+		applied = append(applied, mig)
+	}
+	return applied, nil
+}
+
+// Down rolls back the last steps applied migrations, newest first.
+func (m *Migrator) Down(ctx context.Context, steps int) (rolledBack []Migration, err error) {
+	release, err := m.acquireLock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[int]Migration, len(m.migrations))
+	for _, mig := range m.migrations {
+		known[mig.Version] = mig
+	}
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+
+	for _, v := range versions {
+		if len(rolledBack) >= steps {
+			break
+		}
+		mig, ok := known[v]
+		if !ok {
+			return rolledBack, fmt.Errorf("migration %d_%s was applied by a newer version and cannot be rolled back by this one", v, applied[v].Name)
+		}
+		if applied[v].Name != mig.Name {
+			return rolledBack, fmt.Errorf("%w: %d was applied as %s but is now %s", ErrNameMismatch, v, applied[v].Name, mig.Name)
+		}
+		if mig.Down == nil {
+			return rolledBack, fmt.Errorf("%w: %s", ErrIrreversible, mig)
+		}
+		m.logger.InfoContext(ctx, "rolling back migration", "version", mig.Version, "name", mig.Name)
+		if err := mig.Down(ctx, m.db); err != nil {
+			return rolledBack, fmt.Errorf("rollback of %s failed: %w", mig, err)
+		}
+		if _, err := m.history.DeleteOne(ctx, bson.M{"_id": mig.Version}); err != nil {
+			return rolledBack, fmt.Errorf("migration %s was rolled back but its record could not be removed: %w", mig, err)
+		}
+		rolledBack = append(rolledBack, mig)
+	}
+	return rolledBack, nil
+}
+
+// String formats the migration as <version>_<name>.
+func (mig Migration) String() string {
+	return fmt.Sprintf("%04d_%s", mig.Version, mig.Name)
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]Record, error) {
+	cursor, err := m.history.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", HistoryCollection, err)
+	}
+	var records []Record
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("error decoding %s: %w", HistoryCollection, err)
+	}
+	applied := make(map[int]Record, len(records))
+	for _, rec := range records {
+		applied[rec.Version] = rec
+	}
+	return applied, nil
+}
+
+// checkNames fails if any known version was recorded under a different name, since the applied
+// migration isn't the one this binary would run for that version.
+func checkNames(migrations []Migration, applied map[int]Record) error {
+	var mismatched []string
+	for _, mig := range migrations {
+		if rec, ok := applied[mig.Version]; ok && rec.Name != mig.Name {
+			mismatched = append(mismatched, fmt.Sprintf("%d was applied as %s but is now %s", mig.Version, rec.Name, mig.Name))
+		}
+	}
+	if len(mismatched) > 0 {
+		return fmt.Errorf("%w: %s", ErrNameMismatch, strings.Join(mismatched, ", "))
+	}
+	return nil
+}
+
+// acquireLock stops two processes (e.g. two instances starting with MIGRATE_ON_START) migrating at once.
+func (m *Migrator) acquireLock(ctx context.Context) (func(), error) {
+	host, _ := os.Hostname()
+	owner := fmt.Sprintf("%s/%d/%d", host, os.Getpid(), time.Now().UnixNano())
+	now := time.Now().UTC()
+
+	// Take over a lock left behind by a crashed process
+	if _, err := m.lock.DeleteOne(ctx, bson.M{"_id": "lock", "expires_at": bson.M{"$lt": now}}); err != nil {
+		return nil, fmt.Errorf("error clearing expired migration lock: %w", err)
+	}
+	_, err := m.lock.InsertOne(ctx, bson.M{"_id": "lock", "owner": owner, "locked_at": now, "expires_at": now.Add(lockTTL)})
+	if mongo.IsDuplicateKeyError(err) {
+		var holder struct {
+			Owner    string    `bson:"owner"`
+			LockedAt time.Time `bson:"locked_at"`
+		}
+		_ = m.lock.FindOne(ctx, bson.M{"_id": "lock"}).Decode(&holder)
+		return nil, fmt.Errorf("%w (held by %s since %s)", ErrLocked, holder.Owner, holder.LockedAt.Format(time.RFC3339))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error acquiring migration lock: %w", err)
+	}
+
+	return func() {
+		// Release even if the migration's context was cancelled
+		releaseCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+		defer cancel()
+		if _, err := m.lock.DeleteOne(releaseCtx, bson.M{"_id": "lock", "owner": owner}); err != nil {
+			m.logger.ErrorContext(releaseCtx, "could not release migration lock", "error", err)
+		}
+	}, nil
+}
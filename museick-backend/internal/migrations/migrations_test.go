@@ -0,0 +1,43 @@
+package migrations
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCheckNames(t *testing.T) {
+	migrations := []Migration{{Version: 1, Name: "create_indexes"}, {Version: 2, Name: "backfill_ids"}, {Version: 3, Name: "add_cache"}}
+	tests := []struct {
+		name    string
+		applied map[int]Record
+		wantErr []string // Substrings the error must contain; none means the names match
+	}{
+		{"nothing applied", map[int]Record{}, nil},
+		{"matching names", map[int]Record{1: {Version: 1, Name: "create_indexes"}, 2: {Version: 2, Name: "backfill_ids"}}, nil},
+		{"unknown newer version", map[int]Record{4: {Version: 4, Name: "from_the_future"}}, nil},
+		{
+			"renumbered list",
+			map[int]Record{1: {Version: 1, Name: "create_indexes"}, 2: {Version: 2, Name: "retired_step"}, 3: {Version: 3, Name: "backfill_ids"}},
+			[]string{"2 was applied as retired_step but is now backfill_ids", "3 was applied as backfill_ids but is now add_cache"},
+		},
+	}
+	for _, tt := range tests {
+		err := checkNames(migrations, tt.applied)
+		if len(tt.wantErr) == 0 {
+			if err != nil {
+				t.Errorf("%s: checkNames() = %v, want nil", tt.name, err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrNameMismatch) {
+			t.Errorf("%s: checkNames() = %v, want %v", tt.name, err, ErrNameMismatch)
+			continue
+		}
+		for _, want := range tt.wantErr {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("%s: checkNames() = %v, want it to contain %q", tt.name, err, want)
+			}
+		}
+	}
+}
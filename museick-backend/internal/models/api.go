@@ -0,0 +1,12 @@
+package models
+
+// ErrorResponse is the body of every 4xx and 5xx JSON response.
+type ErrorResponse struct {
+	Error string `json:"error" example:"Selection not found"`
+}
+
+// WelcomeResponse is returned by GET /.
+type WelcomeResponse struct {
+	Status  string `json:"status" example:"success"`
+	Message string `json:"message" example:"Welcome to Museick API"`
+}
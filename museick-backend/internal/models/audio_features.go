@@ -0,0 +1,78 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// AudioFeatures summarizes how a track sounds. Valence (musical positiveness), energy, danceability and
+// acousticness range from 0 to 1; tempo is in beats per minute.
+type AudioFeatures struct {
+	Valence      float64            `bson:"valence" json:"valence"`
+	Energy       float64            `bson:"energy" json:"energy"`
+	Danceability float64            `bson:"danceability" json:"danceability"`
+	Tempo        float64            `bson:"tempo" json:"tempo"`
+	Acousticness float64            `bson:"acousticness" json:"acousticness"`
+	Source       string             `bson:"source" json:"source"` // Provider the features came from: spotify or fixture
+	FetchedAt    primitive.DateTime `bson:"fetched_at" json:"fetched_at"`
+}
+
+// Names of the audio features, as used in analytics responses.
+const (
+	FeatureValence      = "valence"
+	FeatureEnergy       = "energy"
+	FeatureDanceability = "danceability"
+	FeatureTempo        = "tempo"
+	FeatureAcousticness = "acousticness"
+)
+
+// AudioFeatureNames lists every feature the analytics report on, in display order.
+var AudioFeatureNames = []string{FeatureValence, FeatureEnergy, FeatureDanceability, FeatureTempo, FeatureAcousticness}
+
+// Value returns the named feature, and false for an unknown name.
+func (f *AudioFeatures) Value(name string) (float64, bool) {
+	switch name {
+	case FeatureValence:
+		return f.Valence, true
+	case FeatureEnergy:
+		return f.Energy, true
+	case FeatureDanceability:
+		return f.Danceability, true
+	case FeatureTempo:
+		return f.Tempo, true
+	case FeatureAcousticness:
+		return f.Acousticness, true
+	}
+	return 0, false
+}
+
+// HistogramBin counts the values in [From, To). The first and last bins also take values below and above the range.
+type HistogramBin struct {
+	From  float64 `json:"from" example:"0.5"`
+	To    float64 `json:"to" example:"0.6"`
+	Count int     `json:"count" example:"4"`
+}
+
+// FeatureDistribution describes the values of one audio feature across a group of tracks.
+type FeatureDistribution struct {
+	Mean      float64        `json:"mean" example:"0.62"`
+	Median    float64        `json:"median" example:"0.64"`
+	StdDev    float64        `json:"std_dev" example:"0.18"`
+	Min       float64        `json:"min" example:"0.12"`
+	Max       float64        `json:"max" example:"0.97"`
+	Histogram []HistogramBin `json:"histogram"`
+}
+
+// MoodGroupStats describes the audio features of the tracks in one group (Muses or Icks) of a year.
+type MoodGroupStats struct {
+	Tracks       int                            `json:"tracks" example:"14"`        // Distinct recordings selected in the group
+	WithFeatures int                            `json:"with_features" example:"12"` // Of those, how many have audio features cached
+	Features     map[string]FeatureDistribution `json:"features"`                   // By feature name; empty when no track has features
+}
+
+// MoodAnalyticsResponse is returned by GET /api/stats/audio-features/:year. Differences are the Muse mean
+// minus the Ick mean per feature, for features both groups have values for.
+type MoodAnalyticsResponse struct {
+	Year         int                `json:"year" example:"2024"`
+	SelectedOnly bool               `json:"selected_only" example:"false"`
+	Muses        MoodGroupStats     `json:"muses"`
+	Icks         MoodGroupStats     `json:"icks"`
+	Differences  map[string]float64 `json:"differences"`
+}
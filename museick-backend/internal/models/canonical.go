@@ -0,0 +1,64 @@
+package models
+
+import "strings"
+
+// Canonical IDs group Spotify items that are the same recording or release under different Spotify IDs:
+// a single and its album version, a remaster reissued with the same codes, regional copies. Tracks are
+// grouped by ISRC and albums by UPC (or EAN). Items without a code, and artists, are a group of their own.
+const (
+	canonicalISRCPrefix = "isrc:"
+	canonicalUPCPrefix  = "upc:"
+)
+
+// TrackCanonicalID returns the canonical recording ID for a track: "isrc:<ISRC>", or the track's URI without one.
+func TrackCanonicalID(spotifyID string, externalIDs map[string]string) string {
+	if isrc := normalizeISRC(externalIDs["isrc"]); isrc != "" {
+		return canonicalISRCPrefix + isrc
+	}
+	return "spotify:track:" + spotifyID
+}
+
+// AlbumCanonicalID returns the canonical release ID for an album: "upc:<UPC>", or the album's URI without one.
+// EANs are folded into UPCs, since a UPC is an EAN-13 with a leading zero.
+func AlbumCanonicalID(spotifyID string, externalIDs map[string]string) string {
+	code := externalIDs["upc"]
+	if code == "" {
+		code = externalIDs["ean"]
+	}
+	if upc := normalizeUPC(code); upc != "" {
+		return canonicalUPCPrefix + upc
+	}
+	return "spotify:album:" + spotifyID
+}
+
+// CanonicalID returns the canonical ID of a cached Spotify item (*SpotifyTrack, *SpotifyAlbum or *SpotifyArtist).
+func CanonicalID(item interface{}) string {
+	switch v := item.(type) {
+	case *SpotifyTrack:
+		return TrackCanonicalID(v.SpotifyID, v.ExternalIDs)
+	case *SpotifyAlbum:
+		return AlbumCanonicalID(v.SpotifyID, v.ExternalIDs)
+	case *SpotifyArtist:
+		return "spotify:artist:" + v.SpotifyID
+	}
+	return ""
+}
+
+// GroupKey identifies what a selection is about for recaps, charts and duplicate checks: its canonical ID,
+// or its Spotify item for selections made before canonical IDs were recorded.
+func (s *UserSelection) GroupKey() string {
+	if s.CanonicalID != "" {
+		return s.CanonicalID
+	}
+	return "spotify:" + s.ItemType + ":" + s.SpotifyItemID
+}
+
+// normalizeISRC uppercases an ISRC and drops the hyphens it is sometimes printed with (CC-XXX-YY-NNNNN).
+func normalizeISRC(isrc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(isrc), "-", ""))
+}
+
+// normalizeUPC strips leading zeros so the 12-digit UPC and 13-digit EAN forms of a code match.
+func normalizeUPC(code string) string {
+	return strings.TrimLeft(strings.TrimSpace(code), "0")
+}
@@ -0,0 +1,42 @@
+package models
+
+import "testing"
+
+func TestCanonicalIDs(t *testing.T) {
+	tests := []struct {
+		name string
+		item interface{}
+		want string
+	}{
+		{"track with ISRC", &SpotifyTrack{SpotifyID: "t1", ExternalIDs: map[string]string{"isrc": "USRC17607839"}}, "isrc:USRC17607839"},
+		{"ISRC printed with hyphens", &SpotifyTrack{SpotifyID: "t2", ExternalIDs: map[string]string{"isrc": " us-rc1-76-07839 "}}, "isrc:USRC17607839"},
+		{"track without ISRC", &SpotifyTrack{SpotifyID: "t3"}, "spotify:track:t3"},
+		{"album with UPC", &SpotifyAlbum{SpotifyID: "a1", ExternalIDs: map[string]string{"upc": "886443671584"}}, "upc:886443671584"},
+		{"EAN folds into the UPC", &SpotifyAlbum{SpotifyID: "a2", ExternalIDs: map[string]string{"ean": "0886443671584"}}, "upc:886443671584"},
+		{"UPC wins over EAN", &SpotifyAlbum{SpotifyID: "a3", ExternalIDs: map[string]string{"upc": "1", "ean": "2"}}, "upc:1"},
+		{"all-zero code", &SpotifyAlbum{SpotifyID: "a4", ExternalIDs: map[string]string{"upc": "0000"}}, "spotify:album:a4"},
+		{"album without codes", &SpotifyAlbum{SpotifyID: "a5"}, "spotify:album:a5"},
+		{"artist", &SpotifyArtist{SpotifyID: "r1"}, "spotify:artist:r1"},
+		{"unknown item", "t1", ""},
+	}
+	for _, tt := range tests {
+		if got := CanonicalID(tt.item); got != tt.want {
+			t.Errorf("%s: CanonicalID() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestGroupKey(t *testing.T) {
+	tests := []struct {
+		selection UserSelection
+		want      string
+	}{
+		{UserSelection{SpotifyItemID: "t1", ItemType: "track", CanonicalID: "isrc:USRC17607839"}, "isrc:USRC17607839"},
+		{UserSelection{SpotifyItemID: "t1", ItemType: "track"}, "spotify:track:t1"},
+	}
+	for _, tt := range tests {
+		if got := tt.selection.GroupKey(); got != tt.want {
+			t.Errorf("%+v.GroupKey() = %q, want %q", tt.selection, got, tt.want)
+		}
+	}
+}
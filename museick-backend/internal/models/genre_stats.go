@@ -0,0 +1,42 @@
+package models
+
+// GenreShare is a genre's weight among a set of picks. Each pick carries a weight of 1, split evenly across
+// the distinct genres of its artists, so the shares of one month add up to 1.
+type GenreShare struct {
+	Genre  string  `json:"genre" example:"indie rock"`
+	Weight float64 `json:"weight" example:"2.5"`
+	Share  float64 `json:"share" example:"0.21"` // Weight over the picks with genres
+}
+
+// GenreMonth is the genre mix of one month's picks.
+type GenreMonth struct {
+	MonthYear  string       `json:"month_year" example:"2024-03"`
+	Picks      int          `json:"picks" example:"14"`
+	WithGenres int          `json:"with_genres" example:"12"`
+	Genres     []GenreShare `json:"genres"` // Largest share first, the top 10
+}
+
+// ArtistRollup counts the distinct picks of the year that credit an artist, whether the artist was picked
+// directly or through one of their tracks or albums.
+type ArtistRollup struct {
+	SpotifyID  string         `json:"spotify_id" example:"0gxyHStUsqpMadRV0Di1Qt"`
+	Name       string         `json:"name" example:"Rick Astley"`
+	Genres     []string       `json:"genres"`
+	Picks      int            `json:"picks" example:"5"`
+	Muses      int            `json:"muses" example:"4"`
+	Icks       int            `json:"icks" example:"1"`
+	ByItemType map[string]int `json:"by_item_type"` // Picks per item type: track, album or artist
+}
+
+// GenreTimelineResponse is returned by GET /api/stats/genres/:year. Genres come from the cached artists of
+// each pick. Unresolved picks are tracks or albums that couldn't be found, so their artists are unknown.
+type GenreTimelineResponse struct {
+	Year         int            `json:"year" example:"2024"`
+	SelectedOnly bool           `json:"selected_only" example:"false"`
+	Picks        int            `json:"picks" example:"96"`
+	Unresolved   int            `json:"unresolved" example:"2"`
+	Months       []GenreMonth   `json:"months"`       // January to December, empty months included
+	TopGenres    []GenreShare   `json:"top_genres"`   // Over the whole year
+	TopArtists   []ArtistRollup `json:"top_artists"`  // Most picks first
+	MuseAndIck   []ArtistRollup `json:"muse_and_ick"` // Artists with both Muse and Ick picks
+}
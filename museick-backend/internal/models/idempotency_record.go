@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Idempotency record states.
+const (
+	IdempotencyInProgress = "in_progress" // The first request with the key is still being handled
+	IdempotencyCompleted  = "completed"   // The response has been stored and will be replayed
+)
+
+// IdempotencyRecord stores the outcome of a mutating request made with an Idempotency-Key,
+// so retries of the same request get the original response instead of repeating the side effects.
+type IdempotencyRecord struct {
+	UserSub     string             `bson:"user_sub"`    // Keys are scoped per user
+	Key         string             `bson:"key"`         // Client-supplied Idempotency-Key header
+	Token       primitive.ObjectID `bson:"token"`       // Identifies the reservation holding the key; replaced on takeover
+	Fingerprint string             `bson:"fingerprint"` // SHA-256 of method, path and body of the first request
+	State       string             `bson:"state"`       // IdempotencyInProgress or IdempotencyCompleted
+	Status      int                `bson:"status,omitempty"`
+	ContentType string             `bson:"content_type,omitempty"`
+	Body        []byte             `bson:"body,omitempty"`
+	CreatedAt   time.Time          `bson:"created_at"`
+	ExpiresAt   time.Time          `bson:"expires_at"`             // TTL index removes the record after this
+	LockedUntil time.Time          `bson:"locked_until,omitempty"` // An in-progress record past this was abandoned and may be taken over
+}
@@ -0,0 +1,25 @@
+package models
+
+import "go.mongodb.org/mongo-driver/bson/primitive"
+
+// MediaAsset records an image mirrored from Spotify's CDN. Hash is the SHA-256 of the downloaded bytes,
+// so the same picture behind two URLs is stored once. The original and every variant live in the media store.
+type MediaAsset struct {
+	Hash        string             `bson:"_id" json:"hash"`
+	SourceURLs  []string           `bson:"source_urls" json:"source_urls"` // Every Spotify URL that served these bytes
+	ContentType string             `bson:"content_type" json:"content_type"`
+	Width       int                `bson:"width" json:"width"`
+	Height      int                `bson:"height" json:"height"`
+	Size        int64              `bson:"size" json:"size"`
+	Variants    []MediaVariant     `bson:"variants" json:"variants"`
+	CreatedAt   primitive.DateTime `bson:"created_at" json:"created_at"`
+}
+
+// MediaVariant is a thumbnail or re-encoded copy of a MediaAsset.
+type MediaVariant struct {
+	Name        string `bson:"name" json:"name"` // Object name in the media store
+	ContentType string `bson:"content_type" json:"content_type"`
+	Width       int    `bson:"width" json:"width"`
+	Height      int    `bson:"height" json:"height"`
+	Size        int64  `bson:"size" json:"size"`
+}
@@ -0,0 +1,30 @@
+package models
+
+// CreatePlaylistRequest defines the expected JSON body for POST /api/playlists.
+type CreatePlaylistRequest struct {
+	Year              int    `json:"year" binding:"required" example:"2024"`
+	Mode              string `json:"mode" binding:"required,oneof=muse ick" enums:"muse,ick"`
+	IncludeCandidates bool   `json:"include_candidates"` // Also add candidates, not just the monthly selections
+}
+
+// CreatePlaylistResponse is returned by POST /api/playlists.
+type CreatePlaylistResponse struct {
+	Message     string                      `json:"message" example:"Playlist created successfully"`
+	URL         string                      `json:"url"`                 // Spotify URL of the new playlist
+	Market      string                      `json:"market" example:"GB"` // Market track availability was checked for
+	Added       int                         `json:"added" example:"12"`  // Tracks added to the playlist
+	Substituted []PlaylistTrackSubstitution `json:"substituted"`         // Tracks replaced by a playable relinked copy
+	Skipped     []PlaylistTrackSkip         `json:"skipped"`             // Tracks left out, with the reason
+}
+
+// PlaylistTrackSubstitution records a selected track that was added as Spotify's relinked copy for the market.
+type PlaylistTrackSubstitution struct {
+	SpotifyItemID string `json:"spotify_item_id"` // Track the user selected
+	AddedID       string `json:"added_id"`        // Playable copy that was added instead
+}
+
+// PlaylistTrackSkip records a selected track that was left out of the playlist.
+type PlaylistTrackSkip struct {
+	SpotifyItemID string `json:"spotify_item_id"`
+	Reason        string `json:"reason" example:"not available in GB"`
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Spotify release date precisions.
+const (
+	PrecisionYear  = "year"
+	PrecisionMonth = "month"
+	PrecisionDay   = "day"
+)
+
+// ReleaseDate is a parsed Spotify release date. Spotify reports "2006", "2006-09" or "2006-09-12" with a
+// precision of year, month or day; Month and Day are 0 when the precision doesn't include them.
+type ReleaseDate struct {
+	Year      int
+	Month     int
+	Day       int
+	Precision string
+}
+
+// ParseReleaseDate parses a release date with its reported precision. A precision coarser than the string
+// (a year-precision "1990-01-01") drops the padded parts; a missing or finer precision falls back to the
+// string's shape. Empty dates, Spotify's "0000" placeholder and impossible dates are reported as not ok.
+func ParseReleaseDate(date, precision string) (ReleaseDate, bool) {
+	parts := strings.Split(strings.TrimSpace(date), "-")
+	if len(parts) > 3 || len(parts[0]) != 4 {
+		return ReleaseDate{}, false
+	}
+	switch precision {
+	case PrecisionYear:
+		parts = parts[:1]
+	case PrecisionMonth:
+		parts = parts[:min(len(parts), 2)]
+	}
+	numbers := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || (i > 0 && len(part) != 2) {
+			return ReleaseDate{}, false
+		}
+		numbers[i] = n
+	}
+	d := ReleaseDate{Year: numbers[0], Precision: PrecisionYear}
+	if d.Year == 0 {
+		return ReleaseDate{}, false
+	}
+	if len(numbers) > 1 {
+		d.Month, d.Precision = numbers[1], PrecisionMonth
+		if d.Month < 1 || d.Month > 12 {
+			return ReleaseDate{}, false
+		}
+	}
+	if len(numbers) > 2 {
+		d.Day, d.Precision = numbers[2], PrecisionDay
+		// time.Date normalizes out-of-range days into the next month, which is how invalid ones show up
+		if d.Day < 1 || time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC).Day() != d.Day {
+			return ReleaseDate{}, false
+		}
+	}
+	return d, true
+}
+
+// Decade returns the first year of the release's decade, e.g. 1990.
+func (d ReleaseDate) Decade() int {
+	return d.Year - d.Year%10
+}
+
+// String formats the date at its precision, as Spotify does.
+func (d ReleaseDate) String() string {
+	switch d.Precision {
+	case PrecisionDay:
+		return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+	case PrecisionMonth:
+		return fmt.Sprintf("%04d-%02d", d.Year, d.Month)
+	}
+	return fmt.Sprintf("%04d", d.Year)
+}
+
+// MonthsBefore returns the range of whole months the release could precede the given month by. Dates with
+// year precision span twelve possible months; a release after the month gives a negative gap.
+func (d ReleaseDate) MonthsBefore(year, month int) (minGap, maxGap int) {
+	target := year*12 + month - 1
+	if d.Precision == PrecisionYear {
+		return target - (d.Year*12 + 11), target - d.Year*12
+	}
+	gap := target - (d.Year*12 + d.Month - 1)
+	return gap, gap
+}
+
+// SortKey orders release dates chronologically, with unknown months and days first within their year or month.
+func (d ReleaseDate) SortKey() int {
+	return d.Year*10000 + d.Month*100 + d.Day
+}
+
+// ReleaseBucket counts picks released in a decade or a year.
+type ReleaseBucket struct {
+	Label string `json:"label" example:"1990s"`
+	Start int    `json:"start" example:"1990"` // First year of the decade, or the release year
+	Muses int    `json:"muses" example:"3"`
+	Icks  int    `json:"icks" example:"1"`
+}
+
+// NewVsCatalogStats splits picks into new releases (picked within WindowMonths of release) and catalog.
+// Undetermined picks have year-precision dates that could fall either side of the window.
+type NewVsCatalogStats struct {
+	WindowMonths    int      `json:"window_months" example:"12"`
+	NewReleases     int      `json:"new_releases" example:"8"`
+	Catalog         int      `json:"catalog" example:"15"`
+	Undetermined    int      `json:"undetermined" example:"2"`
+	MedianGapMonths *float64 `json:"median_gap_months,omitempty" example:"30"` // Over picks with month or day precision
+}
+
+// ReleaseEraPick is a pick with its release date, for the oldest and newest Muse.
+type ReleaseEraPick struct {
+	SelectionID          string        `json:"selection_id" example:"6634f0c2a1b2c3d4e5f60718"`
+	SpotifyItemID        string        `json:"spotify_item_id" example:"4uLU6hMCjMI75M1A2tKUQC"`
+	ItemType             string        `json:"item_type" example:"track"`
+	ItemName             string        `json:"item_name" example:"Never Gonna Give You Up"`
+	ArtistNames          []string      `json:"artist_names"`
+	MonthYear            string        `json:"month_year" example:"2024-03"`
+	SelectionRole        SelectionRole `json:"selection_role" example:"muse_selected"`
+	ReleaseDate          string        `json:"release_date" example:"1987-07-27"`
+	ReleaseDatePrecision string        `json:"release_date_precision" example:"day"`
+}
+
+// ReleaseEraResponse is returned by GET /api/stats/release-eras/:year. Picks are the distinct recordings
+// (tracks) and releases (albums) picked in the year, counted once per group (Muses and Icks).
+type ReleaseEraResponse struct {
+	Year            int               `json:"year" example:"2024"`
+	SelectedOnly    bool              `json:"selected_only" example:"false"`
+	Picks           int               `json:"picks" example:"27"`
+	WithReleaseDate int               `json:"with_release_date" example:"25"`
+	ByDecade        []ReleaseBucket   `json:"by_decade"`       // Oldest decade first
+	ByReleaseYear   []ReleaseBucket   `json:"by_release_year"` // Oldest year first
+	NewVsCatalog    NewVsCatalogStats `json:"new_vs_catalog"`
+	OldestMuse      *ReleaseEraPick   `json:"oldest_muse,omitempty"`
+	NewestMuse      *ReleaseEraPick   `json:"newest_muse,omitempty"`
+}
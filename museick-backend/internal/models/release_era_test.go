@@ -0,0 +1,82 @@
+package models
+
+import "testing"
+
+func TestParseReleaseDate(t *testing.T) {
+	tests := []struct {
+		date      string
+		precision string
+		want      ReleaseDate
+		ok        bool
+	}{
+		{"2006-09-12", PrecisionDay, ReleaseDate{Year: 2006, Month: 9, Day: 12, Precision: PrecisionDay}, true},
+		{"2006-09", PrecisionMonth, ReleaseDate{Year: 2006, Month: 9, Precision: PrecisionMonth}, true},
+		{"2006", PrecisionYear, ReleaseDate{Year: 2006, Precision: PrecisionYear}, true},
+		{" 2006 ", PrecisionYear, ReleaseDate{Year: 2006, Precision: PrecisionYear}, true},
+		// A coarser precision drops the padded parts
+		{"1990-01-01", PrecisionYear, ReleaseDate{Year: 1990, Precision: PrecisionYear}, true},
+		{"1990-01-01", PrecisionMonth, ReleaseDate{Year: 1990, Month: 1, Precision: PrecisionMonth}, true},
+		// A missing or finer precision falls back to the string's shape
+		{"2006-09-12", "", ReleaseDate{Year: 2006, Month: 9, Day: 12, Precision: PrecisionDay}, true},
+		{"2006", PrecisionDay, ReleaseDate{Year: 2006, Precision: PrecisionYear}, true},
+		{"2024-02-29", PrecisionDay, ReleaseDate{Year: 2024, Month: 2, Day: 29, Precision: PrecisionDay}, true},
+		{"", "", ReleaseDate{}, false},
+		{"0000", PrecisionYear, ReleaseDate{}, false},
+		{"0000-00-00", PrecisionDay, ReleaseDate{}, false},
+		{"abcd", PrecisionYear, ReleaseDate{}, false},
+		{"06-09-12", PrecisionDay, ReleaseDate{}, false},
+		{"2006-9", PrecisionMonth, ReleaseDate{}, false},
+		{"2006-13", PrecisionMonth, ReleaseDate{}, false},
+		{"2006-00", PrecisionMonth, ReleaseDate{}, false},
+		{"2023-02-29", PrecisionDay, ReleaseDate{}, false},
+		{"2006-09-31", PrecisionDay, ReleaseDate{}, false},
+		{"2006-09-12-01", PrecisionDay, ReleaseDate{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := ParseReleaseDate(tt.date, tt.precision)
+		if ok != tt.ok || got != tt.want {
+			t.Errorf("ParseReleaseDate(%q, %q) = %+v, %v; want %+v, %v", tt.date, tt.precision, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestReleaseDateMonthsBefore(t *testing.T) {
+	tests := []struct {
+		name           string
+		date           ReleaseDate
+		year, month    int
+		minGap, maxGap int
+	}{
+		{"same month", ReleaseDate{Year: 2024, Month: 3, Day: 15, Precision: PrecisionDay}, 2024, 3, 0, 0},
+		{"across a year boundary", ReleaseDate{Year: 2023, Month: 12, Precision: PrecisionMonth}, 2024, 3, 3, 3},
+		{"released after the month", ReleaseDate{Year: 2024, Month: 5, Precision: PrecisionMonth}, 2024, 3, -2, -2},
+		{"year precision in the same year", ReleaseDate{Year: 2024, Precision: PrecisionYear}, 2024, 3, -9, 2},
+		{"year precision in an earlier year", ReleaseDate{Year: 2020, Precision: PrecisionYear}, 2024, 1, 37, 48},
+	}
+	for _, tt := range tests {
+		minGap, maxGap := tt.date.MonthsBefore(tt.year, tt.month)
+		if minGap != tt.minGap || maxGap != tt.maxGap {
+			t.Errorf("%s: MonthsBefore(%d, %d) = %d, %d; want %d, %d", tt.name, tt.year, tt.month, minGap, maxGap, tt.minGap, tt.maxGap)
+		}
+	}
+}
+
+func TestReleaseDateStringAndDecade(t *testing.T) {
+	tests := []struct {
+		date   ReleaseDate
+		str    string
+		decade int
+	}{
+		{ReleaseDate{Year: 1987, Month: 7, Day: 27, Precision: PrecisionDay}, "1987-07-27", 1980},
+		{ReleaseDate{Year: 2000, Month: 1, Precision: PrecisionMonth}, "2000-01", 2000},
+		{ReleaseDate{Year: 1999, Precision: PrecisionYear}, "1999", 1990},
+	}
+	for _, tt := range tests {
+		if got := tt.date.String(); got != tt.str {
+			t.Errorf("%+v.String() = %q, want %q", tt.date, got, tt.str)
+		}
+		if got := tt.date.Decade(); got != tt.decade {
+			t.Errorf("%+v.Decade() = %d, want %d", tt.date, got, tt.decade)
+		}
+	}
+}
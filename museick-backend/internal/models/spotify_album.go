@@ -19,7 +19,15 @@ type SpotifyAlbum struct {
 	Type                 string             `bson:"type" json:"type"`
 	URI                  string             `bson:"uri" json:"uri"`
 	Artists              []SimplifiedArtist `bson:"artists" json:"artists"` // Simplified artist objects
-	// TODO: Consider adding fields like Genres, Label, Copyrights if fetching full album object, but spotify GO api in use is very minimalistic
+	Label                string             `bson:"label,omitempty" json:"label,omitempty"`
+	Copyrights           []Copyright        `bson:"copyrights,omitempty" json:"copyrights,omitempty"`
+	Genres               []string           `bson:"genres,omitempty" json:"genres,omitempty"` // Usually empty; Spotify rarely tags albums
+	Popularity           int                `bson:"popularity" json:"popularity"`
+	ExternalIDs          map[string]string  `bson:"external_ids,omitempty" json:"external_ids,omitempty"` // upc, ean
+	CanonicalID          string             `bson:"canonical_id" json:"canonical_id"`                     // Groups copies of the same release, see AlbumCanonicalID
+	// Full tracklist in album order, so recaps and playlist expansion don't need another Spotify call.
+	// Compare its length with TotalTracks: very long albums are cut off at maxAlbumTracklistPages pages.
+	Tracks []SimplifiedTrack `bson:"tracks,omitempty" json:"tracks,omitempty"`
 	// Internal fields
 	LastFetchedAt primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"` // Track when we last updated this from Spotify
 }
@@ -5,10 +5,9 @@ import "go.mongodb.org/mongo-driver/bson/primitive"
 // SpotifyArtist represents the core data for an artist fetched from Spotify.
 // We use the Spotify ID as the primary key (_id) in MongoDB.
 type SpotifyArtist struct {
-	SpotifyID    string            `bson:"_id" json:"spotify_id"` // Use Spotify ID as the document ID
-	ExternalUrls map[string]string `bson:"external_urls" json:"external_urls"`
-	// TODO: Add Followers field if fetching full artist object
-	// Followers    *FollowersObject  `bson:"followers,omitempty" json:"followers,omitempty"`
+	SpotifyID     string             `bson:"_id" json:"spotify_id"` // Use Spotify ID as the document ID
+	ExternalUrls  map[string]string  `bson:"external_urls" json:"external_urls"`
+	Followers     *FollowersObject   `bson:"followers,omitempty" json:"followers,omitempty"`
 	Genres        []string           `bson:"genres,omitempty" json:"genres,omitempty"` // Often requires fetching full artist
 	Href          string             `bson:"href" json:"href"`
 	Images        []ImageObject      `bson:"images,omitempty" json:"images,omitempty"` // Often requires fetching full artist
@@ -19,8 +18,39 @@ type SpotifyArtist struct {
 	LastFetchedAt primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"` // Track when we last updated this from Spotify
 }
 
-// TODO: Define FollowersObject if needed
-// type FollowersObject struct {
-// 	Href  *string `bson:"href" json:"href"` // Can be null
-// 	Total int     `bson:"total" json:"total"`
-// }
+// FollowersObject represents follower information from Spotify API.
+type FollowersObject struct {
+	Href  *string `bson:"href" json:"href"` // Can be null
+	Total int     `bson:"total" json:"total"`
+}
+
+// SpotifyRelatedArtists caches Spotify's related artists for one artist.
+// It is refreshed on its own schedule, independently of the artist document.
+type SpotifyRelatedArtists struct {
+	ArtistID      string             `bson:"_id" json:"artist_id"` // Use the artist's Spotify ID as the document ID
+	Artists       []SpotifyArtist    `bson:"artists" json:"artists"`
+	LastFetchedAt primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"`
+}
+
+// SpotifyArtistTopTracks caches an artist's top tracks in one market.
+type SpotifyArtistTopTracks struct {
+	ID            string             `bson:"_id" json:"-"` // "<artist_id>:<market>", see ArtistTopTracksID
+	ArtistID      string             `bson:"artist_id" json:"artist_id"`
+	Market        string             `bson:"market" json:"market"` // ISO 3166-1 alpha-2 country code
+	Tracks        []SpotifyTrack     `bson:"tracks" json:"tracks"`
+	LastFetchedAt primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"`
+}
+
+// ArtistTopTracksID builds the document ID for an artist's top tracks in a market.
+func ArtistTopTracksID(artistID, market string) string {
+	return artistID + ":" + market
+}
+
+// ArtistDetailsResponse is an artist together with its related artists and top tracks.
+// Related artists and top tracks are best effort: they are empty when Spotify can't provide them.
+type ArtistDetailsResponse struct {
+	Artist         *SpotifyArtist  `json:"artist"`
+	RelatedArtists []SpotifyArtist `json:"related_artists"`
+	TopTracks      []SpotifyTrack  `json:"top_tracks"`
+	Market         string          `json:"market" example:"US"`
+}
@@ -0,0 +1,15 @@
+package models
+
+// SpotifyCodeExchangeRequest defines the expected JSON body for POST /api/spotify/exchange-code.
+type SpotifyCodeExchangeRequest struct {
+	Code         string `json:"code" binding:"required"`          // Authorization code from Spotify's redirect
+	CodeVerifier string `json:"code_verifier" binding:"required"` // PKCE verifier the frontend generated the challenge from
+}
+
+// SpotifyTokenResponse is the access token handed to the frontend. The refresh token is kept server-side.
+type SpotifyTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"` // Seconds
+	Scope       string `json:"scope,omitempty"`
+	TokenType   string `json:"token_type" example:"Bearer"`
+}
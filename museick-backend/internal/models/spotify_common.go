@@ -28,14 +28,59 @@ type SimplifiedAlbum struct {
 	Artists              []SimplifiedArtist `bson:"artists" json:"artists"` // Simplified artist objects
 }
 
+// SimplifiedTrack represents a simplified track object as returned by Spotify API within album objects.
+type SimplifiedTrack struct {
+	ID           string             `bson:"id" json:"id"` // Spotify Track ID
+	Name         string             `bson:"name" json:"name"`
+	Artists      []SimplifiedArtist `bson:"artists" json:"artists"`
+	DiscNumber   int                `bson:"disc_number" json:"disc_number"`
+	TrackNumber  int                `bson:"track_number" json:"track_number"`
+	DurationMs   int                `bson:"duration_ms" json:"duration_ms"`
+	Explicit     bool               `bson:"explicit" json:"explicit"`
+	PreviewURL   string             `bson:"preview_url,omitempty" json:"preview_url,omitempty"`
+	ExternalUrls map[string]string  `bson:"external_urls" json:"external_urls"`
+	URI          string             `bson:"uri" json:"uri"`
+}
+
+// Copyright represents a copyright statement on an album. Type is "C" (copyright) or "P" (sound recording).
+type Copyright struct {
+	Text string `bson:"text" json:"text"`
+	Type string `bson:"type" json:"type"`
+}
+
 // ImageObject represents an image object from Spotify API.
+// URL always stays the Spotify CDN URL; once the image has been mirrored, MirrorHash names the copy served from /media/:hash.
 type ImageObject struct {
-	URL    string `bson:"url" json:"url"`
-	Height *int   `bson:"height" json:"height"` // Pointer to handle null
-	Width  *int   `bson:"width" json:"width"`   // Pointer to handle null
+	URL        string `bson:"url" json:"url"`
+	Height     *int   `bson:"height" json:"height"` // Pointer to handle null
+	Width      *int   `bson:"width" json:"width"`   // Pointer to handle null
+	MirrorHash string `bson:"mirror_hash,omitempty" json:"mirror_hash,omitempty"`
 }
 
 // Restrictions represents restriction information from Spotify API.
 type Restrictions struct {
 	Reason string `bson:"reason" json:"reason"`
 }
+
+// SearchNames extracts the names denormalized onto a selection from a cached Spotify item
+// (*SpotifyTrack, *SpotifyAlbum or *SpotifyArtist), so notes and metadata can share one text index.
+// Only tracks have an album name; an album's own name is its item name.
+func SearchNames(item interface{}) (itemName, albumName string, artistNames []string) {
+	switch v := item.(type) {
+	case *SpotifyTrack:
+		return v.Name, v.Album.Name, simplifiedArtistNames(v.Artists)
+	case *SpotifyAlbum:
+		return v.Name, "", simplifiedArtistNames(v.Artists)
+	case *SpotifyArtist:
+		return v.Name, "", []string{v.Name}
+	}
+	return "", "", nil
+}
+
+func simplifiedArtistNames(artists []SimplifiedArtist) []string {
+	names := make([]string, 0, len(artists))
+	for _, a := range artists {
+		names = append(names, a.Name)
+	}
+	return names
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSearchNames(t *testing.T) {
+	credits := []SimplifiedArtist{{ID: "a1", Name: "Alpha"}, {ID: "a2", Name: "Beta"}}
+	tests := []struct {
+		name        string
+		item        interface{}
+		itemName    string
+		albumName   string
+		artistNames []string
+	}{
+		{"track", &SpotifyTrack{Name: "Song", Album: SimplifiedAlbum{Name: "Record"}, Artists: credits}, "Song", "Record", []string{"Alpha", "Beta"}},
+		{"album has no album name", &SpotifyAlbum{Name: "Record", Artists: credits}, "Record", "", []string{"Alpha", "Beta"}},
+		{"artist", &SpotifyArtist{Name: "Alpha"}, "Alpha", "", []string{"Alpha"}},
+		{"unknown item", "track", "", "", nil},
+	}
+	for _, tt := range tests {
+		itemName, albumName, artistNames := SearchNames(tt.item)
+		if itemName != tt.itemName || albumName != tt.albumName || !reflect.DeepEqual(artistNames, tt.artistNames) {
+			t.Errorf("%s: SearchNames() = %q, %q, %q; want %q, %q, %q",
+				tt.name, itemName, albumName, artistNames, tt.itemName, tt.albumName, tt.artistNames)
+		}
+	}
+}
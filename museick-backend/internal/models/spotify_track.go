@@ -13,6 +13,7 @@ type SpotifyTrack struct {
 	DurationMs       int                `bson:"duration_ms" json:"duration_ms"`
 	Explicit         bool               `bson:"explicit" json:"explicit"`
 	ExternalIDs      map[string]string  `bson:"external_ids,omitempty" json:"external_ids,omitempty"`
+	CanonicalID      string             `bson:"canonical_id" json:"canonical_id"` // Groups copies of the same recording, see TrackCanonicalID
 	ExternalUrls     map[string]string  `bson:"external_urls" json:"external_urls"`
 	Name             string             `bson:"name" json:"name"`
 	Popularity       int                `bson:"popularity" json:"popularity"`
@@ -20,6 +21,40 @@ type SpotifyTrack struct {
 	TrackNumber      int                `bson:"track_number" json:"track_number"`
 	Type             string             `bson:"type" json:"type"`
 	URI              string             `bson:"uri" json:"uri"`
-	IsLocal          bool               `bson:"is_local" json:"is_local"`
-	LastFetchedAt    primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"`
+	IsLocal          bool               `bson:"is_local" json:"is_local"` // Local file added to a playlist, not a catalogue track
+	// IsPlayable and LinkedFrom are only reported when the track is fetched for a market (see Market).
+	// The shared cache is fetched without one and relies on AvailableMarkets instead.
+	IsPlayable    *bool              `bson:"is_playable,omitempty" json:"is_playable,omitempty"`
+	LinkedFrom    *LinkedTrack       `bson:"linked_from,omitempty" json:"linked_from,omitempty"` // Original track when Spotify relinked to a playable copy
+	Market        string             `bson:"market,omitempty" json:"market,omitempty"`
+	AudioFeatures *AudioFeatures     `bson:"audio_features,omitempty" json:"audio_features,omitempty"` // Set during sync; a sync that can't fetch features keeps the cached ones
+	LastFetchedAt primitive.DateTime `bson:"last_fetched_at" json:"last_fetched_at"`
+}
+
+// LinkedTrack identifies the track a relinked track was requested as.
+// See https://developer.spotify.com/documentation/web-api/concepts/track-relinking
+type LinkedTrack struct {
+	ID           string            `bson:"id" json:"id"`
+	URI          string            `bson:"uri" json:"uri"`
+	ExternalUrls map[string]string `bson:"external_urls,omitempty" json:"external_urls,omitempty"`
+}
+
+// PlayableIn reports whether the track can be played in market. It uses IsPlayable when the track
+// was fetched for that market and AvailableMarkets otherwise; with neither, the track is assumed playable.
+func (t *SpotifyTrack) PlayableIn(market string) bool {
+	if t.IsLocal {
+		return false
+	}
+	if t.IsPlayable != nil && t.Market == market {
+		return *t.IsPlayable
+	}
+	if len(t.AvailableMarkets) == 0 {
+		return true
+	}
+	for _, m := range t.AvailableMarkets {
+		if m == market {
+			return true
+		}
+	}
+	return false
 }
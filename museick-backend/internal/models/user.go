@@ -6,6 +6,7 @@ type User struct {
 	Sub                 string `json:"sub" bson:"sub"`                                     // Unique identifier from Clerk (Primary Key)
 	Username            string `json:"username,omitempty" bson:"username,omitempty"`       // Optional: Store username if needed
 	SpotifyRefreshToken string `json:"-" bson:"spotify_refresh_token,omitempty"` // Store Spotify refresh token securely (omitempty so it's not added if empty)
+	SpotifyMarket       string `json:"spotify_market,omitempty" bson:"spotify_market,omitempty"` // Country of the user's Spotify account (ISO 3166-1 alpha-2), from /me
 	// TODO: Add fields like Email, CreatedAt, LastLoginAt if required
 	// Email     string             `json:"email,omitempty" bson:"email,omitempty"`
 	// CreatedAt primitive.DateTime `bson:"created_at" json:"created_at"`
@@ -18,24 +18,140 @@ const (
 
 // UserSelection represents a user's interaction with a Spotify item for a specific month.
 type UserSelection struct {
-	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID        string             `bson:"user_id" json:"user_id"`                 // Clerk User ID (sub)
-	SpotifyItemID string             `bson:"spotify_item_id" json:"spotify_item_id"` // ID of the Track/Album/Artist
-	ItemType      string             `bson:"item_type" json:"item_type"`             // "track", "album", or "artist"
-	SelectionRole SelectionRole      `bson:"selection_role" json:"selection_role"`   // "muse_candidate", "ick_candidate", "muse_selected", "ick_selected"
-	MonthYear     string             `bson:"month_year" json:"month_year"`           // Format: "YYYY-MM", e.g., "2024-07"
-	AddedAt       primitive.DateTime `bson:"added_at" json:"added_at"`               // When the user first added this item for this role/month
-	UpdatedAt     primitive.DateTime `bson:"updated_at" json:"updated_at"`           // When the selection was last modified
-	Notes         string             `bson:"notes,omitempty" json:"notes,omitempty"` // Optional user notes
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id" swaggertype:"string" example:"6650f1c2a4b7e8d9c0a1b2c3"`
+	UserID        string             `bson:"user_id" json:"user_id"`                                               // Clerk User ID (sub)
+	SpotifyItemID string             `bson:"spotify_item_id" json:"spotify_item_id"`                               // ID of the Track/Album/Artist
+	ItemType      string             `bson:"item_type" json:"item_type" enums:"track,album,artist"`                // "track", "album", or "artist"
+	SelectionRole SelectionRole      `bson:"selection_role" json:"selection_role"`                                 // "muse_candidate", "ick_candidate", "muse_selected", "ick_selected"
+	MonthYear     string             `bson:"month_year" json:"month_year" example:"2024-07"`                       // Format: "YYYY-MM", e.g., "2024-07"
+	AddedAt       primitive.DateTime `bson:"added_at" json:"added_at" swaggertype:"string" format:"date-time"`     // When the user first added this item for this role/month
+	UpdatedAt     primitive.DateTime `bson:"updated_at" json:"updated_at" swaggertype:"string" format:"date-time"` // When the selection was last modified
+	Notes         string             `bson:"notes,omitempty" json:"notes,omitempty"`                               // Optional user story (markdown)
+	// Journal fields
+	MoodTags         []string          `bson:"mood_tags,omitempty" json:"mood_tags,omitempty"`                 // User-defined mood tags, normalized to lowercase
+	ListeningContext *ListeningContext `bson:"listening_context,omitempty" json:"listening_context,omitempty"` // Optional "where/with whom" context
+	NotesHistory     []NotesRevision   `bson:"notes_history,omitempty" json:"notes_history,omitempty"`         // Previous versions of Notes, oldest first
+	// Denormalized from the Spotify cache at creation time so notes and metadata can share one text index
+	ItemName    string   `bson:"item_name,omitempty" json:"item_name,omitempty"`       // Track/Album/Artist name
+	AlbumName   string   `bson:"album_name,omitempty" json:"album_name,omitempty"`     // Album name (tracks only)
+	ArtistNames []string `bson:"artist_names,omitempty" json:"artist_names,omitempty"` // Names of the credited artists
+	// Denormalized canonical ID of the item, so copies of the same recording or release count as one (see GroupKey)
+	CanonicalID string `bson:"canonical_id,omitempty" json:"canonical_id,omitempty" example:"isrc:GBAYE0601498"`
+	// Set when this selection was created by carrying a candidate over from another month
+	CarriedOverFrom *primitive.ObjectID `bson:"carried_over_from,omitempty" json:"carried_over_from,omitempty" swaggertype:"string"` // Source selection ID, if linked
 	// TODO: Add fields for tracking changes if needed (e.g., previous_selection_type, change_history)
 }
 
+// ListeningContext captures where and how the user was listening when the item stuck with them.
+type ListeningContext struct {
+	Location   string   `bson:"location,omitempty" json:"location,omitempty"`     // e.g., "Road trip to Cornwall"
+	Companions []string `bson:"companions,omitempty" json:"companions,omitempty"` // Who the user was with
+	Activity   string   `bson:"activity,omitempty" json:"activity,omitempty"`     // e.g., "Running", "Cooking"
+}
+
+// NotesRevision is a previous version of a selection's notes, kept when the notes are edited.
+type NotesRevision struct {
+	Notes    string             `bson:"notes" json:"notes"`
+	EditedAt primitive.DateTime `bson:"edited_at" json:"edited_at" swaggertype:"string" format:"date-time"` // When this version was replaced
+}
+
+// MoodTagCount is the number of selections a user has tagged with a given mood tag.
+type MoodTagCount struct {
+	Tag   string `bson:"_id" json:"tag"`
+	Count int    `bson:"count" json:"count"`
+}
+
 // CreateSelectionRequest defines the expected JSON body for POST /api/selections
 // This is used by the handler to bind the incoming request.
 type CreateSelectionRequest struct {
-	SpotifyItemID string        `json:"spotify_item_id" binding:"required"`
-	ItemType      string        `json:"item_type" binding:"required"`      // "track", "album", or "artist"
-	Role          SelectionRole `json:"selection_role" binding:"required"` // "muse_candidate" or "ick_candidate"
-	MonthYear     string        `json:"month_year" binding:"required"`     // "YYYY-MM"
-	Notes         string        `json:"notes"`                             // Optional
+	SpotifyItemID    string            `json:"spotify_item_id" binding:"required"`
+	ItemType         string            `json:"item_type" binding:"required" enums:"track,album,artist"` // "track", "album", or "artist"
+	Role             SelectionRole     `json:"selection_role" binding:"required"`                       // "muse_candidate" or "ick_candidate"
+	MonthYear        string            `json:"month_year" binding:"required" example:"2024-07"`         // "YYYY-MM"
+	Notes            string            `json:"notes"`                                                   // Optional (markdown)
+	MoodTags         []string          `json:"mood_tags"`                                               // Optional
+	ListeningContext *ListeningContext `json:"listening_context"`                                       // Optional
+}
+
+// UpdateSelectionRequest defines the expected JSON body for PUT /api/selections/:id
+type UpdateSelectionRequest struct {
+	Role             *SelectionRole    `json:"selection_role"`    // Pointer to distinguish between not provided and empty string
+	Notes            *string           `json:"notes"`             // Pointer
+	MoodTags         *[]string         `json:"mood_tags"`         // Pointer; an empty list clears the tags
+	ListeningContext *ListeningContext `json:"listening_context"` // Pointer; an empty object clears the context
+}
+
+// JournalSearchResult is a selection matched by a text search, along with its relevance score.
+type JournalSearchResult struct {
+	UserSelection `bson:",inline"`
+	Score         float64 `bson:"score" json:"score"` // MongoDB textScore
+}
+
+// JournalSearchHighlight is a snippet of a matched field with the matching words wrapped in <mark> tags.
+type JournalSearchHighlight struct {
+	Field   string `json:"field"`   // "notes", "item_name", "album_name" or "artist_names"
+	Snippet string `json:"snippet"` // HTML-escaped text with <mark>...</mark> around matches
+}
+
+// JournalSearchHit is a single entry returned by GET /api/journal/search.
+type JournalSearchHit struct {
+	Selection  *UserSelection           `json:"selection"`
+	Score      float64                  `json:"score"`
+	Highlights []JournalSearchHighlight `json:"highlights"`
+}
+
+// BatchSelectionOperation is a single create, update or delete in POST /api/selections/batch.
+// Create uses the same fields as CreateSelectionRequest; update and delete identify the selection by ID.
+type BatchSelectionOperation struct {
+	Op               string            `json:"op" binding:"required,oneof=create update delete" enums:"create,update,delete"`
+	ID               string            `json:"id,omitempty"`              // Update/Delete: selection ID
+	SpotifyItemID    string            `json:"spotify_item_id,omitempty"` // Create
+	ItemType         string            `json:"item_type,omitempty"`       // Create: "track", "album", or "artist"
+	MonthYear        string            `json:"month_year,omitempty"`      // Create: "YYYY-MM"
+	Role             *SelectionRole    `json:"selection_role,omitempty"`  // Create (candidate roles only) or Update
+	Notes            *string           `json:"notes,omitempty"`
+	MoodTags         *[]string         `json:"mood_tags,omitempty"`
+	ListeningContext *ListeningContext `json:"listening_context,omitempty"`
+}
+
+// BatchSelectionRequest defines the expected JSON body for POST /api/selections/batch.
+type BatchSelectionRequest struct {
+	Operations []BatchSelectionOperation `json:"operations" binding:"required,min=1,max=100,dive"`
+}
+
+// BatchSelectionResult reports the outcome of one operation, in request order.
+type BatchSelectionResult struct {
+	Index     int            `json:"index"`
+	Op        string         `json:"op"`
+	Status    int            `json:"status"` // HTTP status the equivalent single request would have returned
+	Selection *UserSelection `json:"selection,omitempty"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// BatchSelectionResponse is returned by POST /api/selections/batch.
+type BatchSelectionResponse struct {
+	Results   []BatchSelectionResult `json:"results"`
+	Succeeded int                    `json:"succeeded"`
+	Failed    int                    `json:"failed"`
+}
+
+// CarryOverRequest defines the expected JSON body for POST /api/selections/carry-over.
+type CarryOverRequest struct {
+	SourceMonth string          `json:"source_month" binding:"required"` // "YYYY-MM"
+	TargetMonth string          `json:"target_month" binding:"required"` // "YYYY-MM"
+	Roles       []SelectionRole `json:"roles"`                           // Candidate roles to copy; defaults to both
+	LinkOrigin  bool            `json:"link_origin"`                     // Record the source selection ID on each copy
+}
+
+// CarryOverSkip describes a source candidate that was not copied.
+type CarryOverSkip struct {
+	SourceSelectionID primitive.ObjectID `json:"source_selection_id" swaggertype:"string"`
+	SpotifyItemID     string             `json:"spotify_item_id"`
+	Reason            string             `json:"reason"`
+}
+
+// CarryOverResponse is returned by POST /api/selections/carry-over.
+type CarryOverResponse struct {
+	Copied  []*UserSelection `json:"copied"`
+	Skipped []CarryOverSkip  `json:"skipped"`
 }
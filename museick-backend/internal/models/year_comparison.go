@@ -0,0 +1,94 @@
+package models
+
+// YearlyPick is one item a user picked in a year, with the months it was a Muse or an Ick and its cached
+// artists, as aggregated by UserSelectionDAO.AggregateYearlyPicks. Copies of the same recording or release
+// are one pick (see UserSelection.GroupKey).
+type YearlyPick struct {
+	Year          int                `bson:"year"`
+	GroupKey      string             `bson:"group_key"`
+	ItemType      string             `bson:"item_type"`
+	SpotifyItemID string             `bson:"spotify_item_id"`
+	ItemName      string             `bson:"item_name"`
+	ArtistNames   []string           `bson:"artist_names"`
+	MuseMonths    []string           `bson:"muse_months"` // "YYYY-MM", ascending
+	IckMonths     []string           `bson:"ick_months"`
+	Credits       []SimplifiedArtist `bson:"artist_credits"` // From the cached track or album; the artist itself for artist picks
+	Artists       []SpotifyArtist    `bson:"artists"`        // The credited artists found in the cache, for their genres
+}
+
+// YearSummary describes one year of a comparison.
+type YearSummary struct {
+	Year      int          `json:"year" example:"2024"`
+	Picks     int          `json:"picks" example:"84"`
+	Muses     int          `json:"muses" example:"50"`
+	Icks      int          `json:"icks" example:"36"` // A pick that was both counts in Muses and Icks
+	TopGenres []GenreShare `json:"top_genres"`        // Largest share first, the top 5
+}
+
+// YearShare is a value for one year.
+type YearShare struct {
+	Year  int     `json:"year" example:"2024"`
+	Share float64 `json:"share" example:"0.12"`
+}
+
+// GenreDrift follows a genre's share of the picks across the compared years.
+type GenreDrift struct {
+	Genre  string      `json:"genre" example:"hyperpop"`
+	Shares []YearShare `json:"shares"`                // Every compared year, oldest first, 0 when absent
+	Change float64     `json:"change" example:"0.08"` // Share in the last year minus share in the first
+}
+
+// RecurringArtist is an artist picked in more than one of the compared years.
+type RecurringArtist struct {
+	SpotifyID string `json:"spotify_id" example:"0gxyHStUsqpMadRV0Di1Qt"`
+	Name      string `json:"name" example:"Rick Astley"`
+	Years     []int  `json:"years"`
+	Picks     int    `json:"picks" example:"7"`
+	Muses     int    `json:"muses" example:"6"`
+	Icks      int    `json:"icks" example:"1"`
+}
+
+// CrossYearItem identifies a picked item in cross-year results.
+type CrossYearItem struct {
+	SpotifyItemID string   `json:"spotify_item_id" example:"4uLU6hMCjMI75M1A2tKUQC"`
+	ItemType      string   `json:"item_type" example:"track"`
+	ItemName      string   `json:"item_name" example:"Never Gonna Give You Up"`
+	ArtistNames   []string `json:"artist_names"`
+}
+
+// RepeatMuse is an item picked as a Muse in more than one year.
+type RepeatMuse struct {
+	CrossYearItem
+	Years []int `json:"years"`
+}
+
+// RoleChange is an item that was picked in one role and later, in a following year, in the other.
+type RoleChange struct {
+	CrossYearItem
+	FromMonth string `json:"from_month" example:"2023-04"` // First month in the earlier role
+	ToMonth   string `json:"to_month" example:"2024-09"`   // First month in the later role, in a later year
+}
+
+// SelectionStreak is a run of consecutive months an item was picked in the same role, crossing at least one
+// year boundary.
+type SelectionStreak struct {
+	CrossYearItem
+	Role   string `json:"role" example:"muse" enums:"muse,ick"`
+	From   string `json:"from" example:"2024-11"`
+	To     string `json:"to" example:"2025-02"`
+	Months int    `json:"months" example:"4"`
+}
+
+// YearComparisonResponse is returned by GET /api/stats/compare and GET /api/stats/retrospective.
+// Each cross-year list holds at most 20 entries.
+type YearComparisonResponse struct {
+	Years            []int             `json:"years"`
+	SelectedOnly     bool              `json:"selected_only" example:"false"`
+	Summaries        []YearSummary     `json:"summaries"`         // One per year, oldest first
+	RecurringArtists []RecurringArtist `json:"recurring_artists"` // Most years first
+	GenreDrift       []GenreDrift      `json:"genre_drift"`       // Largest change first
+	RepeatMuses      []RepeatMuse      `json:"repeat_muses"`
+	Redemptions      []RoleChange      `json:"redemptions"`      // Icks that became Muses
+	FallsFromGrace   []RoleChange      `json:"falls_from_grace"` // Muses that became Icks
+	Streaks          []SelectionStreak `json:"streaks"`          // Longest first
+}
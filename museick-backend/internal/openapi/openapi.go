@@ -0,0 +1,92 @@
+// Package openapi turns the swag-generated Swagger 2.0 document into an OpenAPI 3 description of the API,
+// and checks that description against the routes actually registered on the router.
+// The handler annotations stay the single source of truth: regenerate docs/ with `make swag` after changing them.
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/docs"
+)
+
+// UndocumentedRoutes are served but deliberately left out of the spec: the Swagger UI itself and
+// the Prometheus scrape endpoint, which speaks its own text format.
+var UndocumentedRoutes = []string{"GET /swagger/*any", "GET /metrics"}
+
+func init() {
+	// Keep validation errors to the failing rule; by default they embed the whole schema and value
+	openapi3.SchemaErrorDetailsDisabled = true
+}
+
+// Load converts the embedded Swagger 2.0 document to OpenAPI 3 and validates it.
+func Load() (*openapi3.T, error) {
+	var swagger openapi2.T
+	if err := json.Unmarshal([]byte(docs.SwaggerInfo.ReadDoc()), &swagger); err != nil {
+		return nil, fmt.Errorf("parsing swagger document: %w", err)
+	}
+	doc, err := openapi2conv.ToV3(&swagger)
+	if err != nil {
+		return nil, fmt.Errorf("converting swagger document to OpenAPI 3: %w", err)
+	}
+	// No host is baked in, so clients and the Swagger UI use whichever host served the document
+	doc.Servers = openapi3.Servers{{URL: swagger.BasePath}}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI document: %w", err)
+	}
+	return doc, nil
+}
+
+// Matches gin (":id", "*path") and OpenAPI ("{id}") path parameters.
+var pathParamRegex = regexp.MustCompile(`:[^/]+|\*[^/]+|\{[^}]+\}`)
+
+// routeKey identifies a route by method and path with parameter names erased,
+// since gin and the spec may name the same parameter differently.
+func routeKey(method, path string) string {
+	return strings.ToUpper(method) + " " + pathParamRegex.ReplaceAllString(path, "{}")
+}
+
+// CheckRoutes compares the registered routes with the operations in doc and describes every mismatch:
+// routes missing from the spec and operations with no route behind them. UndocumentedRoutes are skipped.
+func CheckRoutes(doc *openapi3.T, routes gin.RoutesInfo) []string {
+	ignored := map[string]bool{}
+	for _, route := range UndocumentedRoutes {
+		method, path, _ := strings.Cut(route, " ")
+		ignored[routeKey(method, path)] = true
+	}
+
+	registered := map[string]string{} // key -> "METHOD /gin/:path"
+	for _, route := range routes {
+		if key := routeKey(route.Method, route.Path); !ignored[key] {
+			registered[key] = route.Method + " " + route.Path
+		}
+	}
+	documented := map[string]string{}
+	for path, item := range doc.Paths.Map() {
+		for method := range item.Operations() {
+			documented[routeKey(method, path)] = method + " " + path
+		}
+	}
+
+	var problems []string
+	for key, route := range registered {
+		if _, ok := documented[key]; !ok {
+			problems = append(problems, route+" is registered but not in the OpenAPI spec")
+		}
+	}
+	for key, operation := range documented {
+		if _, ok := registered[key]; !ok {
+			problems = append(problems, operation+" is in the OpenAPI spec but not registered")
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// MongoExpiryIndexName is the TTL index that removes idle buckets.
+const MongoExpiryIndexName = "expires_at_ttl"
+
+// MongoStore keeps buckets in a MongoDB collection so every instance shares the same limits.
+// Each Take is a single atomic findAndModify, and the refill is computed from the server clock ($$NOW)
+// so instances with skewed clocks agree.
+type MongoStore struct {
+	collection *mongo.Collection
+}
+
+type mongoBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+// NewMongoStore creates a store backed by the given collection.
+func NewMongoStore(client *mongo.Client, dbName string, collectionName string) *MongoStore {
+	collection := client.Database(dbName).Collection(collectionName)
+	// The TTL index on expires_at is created by schema migrations (see internal/migrations)
+	return &MongoStore{collection: collection}
+}
+
+// Take takes one token from the bucket for key.
+func (s *MongoStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	if !limit.Enabled() {
+		return Result{Allowed: true}, nil
+	}
+
+	burst := float64(limit.Burst)
+	elapsedSeconds := bson.D{{Key: "$divide", Value: bson.A{
+		bson.D{{Key: "$subtract", Value: bson.A{"$$NOW", bson.D{{Key: "$ifNull", Value: bson.A{"$updated_at", "$$NOW"}}}}}},
+		1000,
+	}}}
+	update := mongo.Pipeline{
+		// Refill for the time since the last request, capped at the burst (new buckets start full)
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$min", Value: bson.A{burst, bson.D{{Key: "$add", Value: bson.A{
+				bson.D{{Key: "$ifNull", Value: bson.A{"$tokens", burst}}},
+				bson.D{{Key: "$multiply", Value: bson.A{elapsedSeconds, limit.Rate}}},
+			}}}}}}},
+			{Key: "updated_at", Value: "$$NOW"},
+		}}},
+		{{Key: "$set", Value: bson.D{{Key: "allowed", Value: bson.D{{Key: "$gte", Value: bson.A{"$tokens", 1}}}}}}},
+		{{Key: "$set", Value: bson.D{
+			{Key: "tokens", Value: bson.D{{Key: "$cond", Value: bson.A{"$allowed", bson.D{{Key: "$subtract", Value: bson.A{"$tokens", 1}}}, "$tokens"}}}},
+			{Key: "expires_at", Value: bson.D{{Key: "$add", Value: bson.A{"$$NOW", fullAfter(limit).Milliseconds()}}}},
+		}}},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var b mongoBucket
+	err := s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&b)
+	if mongo.IsDuplicateKeyError(err) {
+		// Two instances raced to create the bucket; the retry updates the one that won
+		err = s.collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, update, opts).Decode(&b)
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("error taking rate limit token: %w", err)
+	}
+	return result(b.Allowed, b.Tokens, limit), nil
+}
@@ -0,0 +1,107 @@
+// Package ratelimit implements token-bucket rate limiting with pluggable
+// storage: an in-memory store for single instances and a MongoDB store
+// that shares buckets across instances.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit describes a token bucket: it holds at most Burst tokens and refills at Rate tokens per second.
+// A zero Limit disables limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Enabled reports whether the limit restricts anything.
+func (l Limit) Enabled() bool {
+	return l.Rate > 0 && l.Burst > 0
+}
+
+// String formats the limit as it is written in configuration.
+func (l Limit) String() string {
+	if !l.Enabled() {
+		return "off"
+	}
+	return fmt.Sprintf("%s/s burst %d", strconv.FormatFloat(l.Rate, 'f', -1, 64), l.Burst)
+}
+
+// ParseLimit parses "<count>/<unit>" with an optional ",<burst>", e.g. "60/m" or "10/s,20".
+// Units are s, m and h. The burst defaults to count. "off", "0" or "" disable limiting.
+func ParseLimit(s string) (Limit, error) {
+	s = strings.TrimSpace(strings.ToLower(s))
+	if s == "" || s == "off" || s == "0" {
+		return Limit{}, nil
+	}
+
+	spec, burstPart, hasBurst := strings.Cut(s, ",")
+	countPart, unitPart, ok := strings.Cut(spec, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <count>/<s|m|h>[,<burst>]", s)
+	}
+	count, err := strconv.Atoi(strings.TrimSpace(countPart))
+	if err != nil || count <= 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit count in %q", s)
+	}
+
+	var per time.Duration
+	switch strings.TrimSpace(unitPart) {
+	case "s", "sec", "second":
+		per = time.Second
+	case "m", "min", "minute":
+		per = time.Minute
+	case "h", "hour":
+		per = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit unit in %q, expected s, m or h", s)
+	}
+
+	burst := count
+	if hasBurst {
+		burst, err = strconv.Atoi(strings.TrimSpace(burstPart))
+		if err != nil || burst <= 0 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst in %q", s)
+		}
+	}
+	return Limit{Rate: float64(count) / per.Seconds(), Burst: burst}, nil
+}
+
+// Result is the outcome of taking a token.
+type Result struct {
+	Allowed    bool
+	Remaining  int           // Whole tokens left after this request
+	RetryAfter time.Duration // When denied, how long until a token is available
+}
+
+// Store takes tokens from named buckets.
+type Store interface {
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// refill returns the token count after elapsed time, capped at the burst.
+func refill(tokens float64, elapsed time.Duration, limit Limit) float64 {
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	return math.Min(float64(limit.Burst), tokens+elapsed.Seconds()*limit.Rate)
+}
+
+// result builds the Result for a bucket holding tokens after the take attempt.
+func result(allowed bool, tokens float64, limit Limit) Result {
+	r := Result{Allowed: allowed, Remaining: int(math.Floor(tokens))}
+	if !allowed {
+		r.RetryAfter = time.Duration((1 - tokens) / limit.Rate * float64(time.Second))
+	}
+	return r
+}
+
+// fullAfter is how long an empty bucket takes to refill completely; idle buckets older than this can be dropped.
+func fullAfter(limit Limit) time.Duration {
+	return time.Duration(float64(limit.Burst) / limit.Rate * float64(time.Second))
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseLimit(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Limit
+		wantErr string
+	}{
+		{in: "", want: Limit{}},
+		{in: "off", want: Limit{}},
+		{in: " OFF ", want: Limit{}},
+		{in: "0", want: Limit{}},
+		{in: "10/s", want: Limit{Rate: 10, Burst: 10}},
+		{in: "60/m", want: Limit{Rate: 1, Burst: 60}},
+		{in: "30/m,10", want: Limit{Rate: 0.5, Burst: 10}},
+		{in: "3600/hour", want: Limit{Rate: 1, Burst: 3600}},
+		{in: " 120 / Min , 5 ", want: Limit{Rate: 2, Burst: 5}},
+		{in: "60", wantErr: "expected <count>/<s|m|h>"},
+		{in: "x/m", wantErr: "invalid rate limit count"},
+		{in: "0/m", wantErr: "invalid rate limit count"},
+		{in: "-5/m", wantErr: "invalid rate limit count"},
+		{in: "60/d", wantErr: "invalid rate limit unit"},
+		{in: "60/m,0", wantErr: "invalid rate limit burst"},
+		{in: "60/m,", wantErr: "invalid rate limit burst"},
+	}
+	for _, tt := range tests {
+		got, err := ParseLimit(tt.in)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParseLimit(%q) error = %v, want one containing %q", tt.in, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseLimit(%q) unexpected error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLimit(%q) = %+v, want %+v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestLimitString(t *testing.T) {
+	tests := []struct {
+		limit Limit
+		want  string
+	}{
+		{Limit{}, "off"},
+		{Limit{Rate: 0.5, Burst: 10}, "0.5/s burst 10"},
+		{Limit{Rate: 2, Burst: 0}, "off"},
+	}
+	for _, tt := range tests {
+		if got := tt.limit.String(); got != tt.want {
+			t.Errorf("%+v.String() = %q, want %q", tt.limit, got, tt.want)
+		}
+	}
+}
+
+func TestMemoryStoreTake(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 3} // One token a second, three at once
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	steps := []struct {
+		at            time.Duration // Since start
+		key           string
+		wantAllowed   bool
+		wantRemaining int
+		wantRetry     time.Duration
+	}{
+		{0, "a", true, 2, 0},
+		{0, "a", true, 1, 0},
+		{0, "a", true, 0, 0},
+		{0, "a", false, 0, time.Second},
+		{0, "b", true, 2, 0}, // Buckets are per key
+		{250 * time.Millisecond, "a", false, 0, 750 * time.Millisecond},
+		{time.Second, "a", true, 0, 0},
+		{time.Hour, "a", true, 2, 0}, // Refills no further than the burst
+	}
+	store := NewMemoryStore()
+	for i, step := range steps {
+		store.now = func() time.Time { return start.Add(step.at) }
+		got, err := store.Take(context.Background(), step.key, limit)
+		if err != nil {
+			t.Fatalf("step %d: Take() unexpected error: %v", i, err)
+		}
+		if got.Allowed != step.wantAllowed || got.Remaining != step.wantRemaining || got.RetryAfter != step.wantRetry {
+			t.Errorf("step %d: Take(%s) at +%s = %+v, want allowed %v, remaining %d, retry after %s",
+				i, step.key, step.at, got, step.wantAllowed, step.wantRemaining, step.wantRetry)
+		}
+	}
+}
+
+func TestMemoryStoreDisabledLimit(t *testing.T) {
+	store := NewMemoryStore()
+	for i := 0; i < 5; i++ {
+		if got, err := store.Take(context.Background(), "a", Limit{}); err != nil || !got.Allowed {
+			t.Fatalf("Take() with limiting off = %+v, %v; want allowed", got, err)
+		}
+	}
+	if len(store.buckets) != 0 {
+		t.Errorf("%d buckets kept with limiting off, want none", len(store.buckets))
+	}
+}
+
+func TestMemoryStoreSweep(t *testing.T) {
+	limit := Limit{Rate: 1, Burst: 10} // An empty bucket is full again after 10s
+	start := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return start }
+	store.Take(context.Background(), "idle", limit)
+	store.now = func() time.Time { return start.Add(5 * time.Second) }
+	store.Take(context.Background(), "active", limit)
+
+	store.now = func() time.Time { return start.Add(12 * time.Second) }
+	store.sweep()
+	if _, ok := store.buckets["idle"]; ok {
+		t.Error("idle bucket kept after it would have refilled")
+	}
+	if _, ok := store.buckets["active"]; !ok {
+		t.Error("active bucket dropped before it would have refilled")
+	}
+}
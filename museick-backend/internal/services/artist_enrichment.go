@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/tracing"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+	"github.com/zmb3/spotify/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// DefaultMarket is the market used for an artist's top tracks when the caller doesn't name one.
+const DefaultMarket = "US"
+
+// Cache lookup kinds for the artist enrichment caches, as reported in the cache lookup metric.
+const (
+	relatedArtistsCacheKind = "related_artists"
+	topTracksCacheKind      = "artist_top_tracks"
+)
+
+// GetArtistDetails returns an artist from the cache together with its related artists and top
+// tracks in market, syncing each from Spotify when missing or older than refreshThreshold.
+// The artist itself is required; related artists and top tracks are best effort and come back
+// empty if Spotify can't provide them and nothing is cached.
+func (s *SpotifySyncService) GetArtistDetails(ctx context.Context, artistID string, market string, spotifyToken string, refreshThreshold time.Duration) (_ *models.ArtistDetailsResponse, err error) {
+	ctx, span := tracing.Start(ctx, "SpotifySyncService.GetArtistDetails",
+		attribute.String("spotify.item_id", artistID), attribute.String("spotify.market", market))
+	defer func() { tracing.End(span, err) }()
+
+	item, err := s.GetOrSyncItem(ctx, artistID, "artist", spotifyToken, refreshThreshold)
+	if err != nil {
+		return nil, err
+	}
+	details := &models.ArtistDetailsResponse{
+		Artist:         item.(*models.SpotifyArtist),
+		RelatedArtists: []models.SpotifyArtist{},
+		TopTracks:      []models.SpotifyTrack{},
+		Market:         market,
+	}
+
+	related, err := s.GetOrSyncRelatedArtists(ctx, artistID, spotifyToken, refreshThreshold)
+	if err != nil {
+		s.logger.WarnContext(ctx, "related artists unavailable", "artist_id", artistID, "error", err)
+	} else {
+		details.RelatedArtists = related.Artists
+	}
+	topTracks, err := s.GetOrSyncArtistTopTracks(ctx, artistID, market, spotifyToken, refreshThreshold)
+	if err != nil {
+		s.logger.WarnContext(ctx, "artist top tracks unavailable", "artist_id", artistID, "market", market, "error", err)
+	} else {
+		details.TopTracks = topTracks.Tracks
+	}
+	return details, nil
+}
+
+// GetOrSyncRelatedArtists returns the cached related artists for an artist. If they are missing or
+// stale it fetches them from Spotify and updates the cache; stale data is returned if that fails.
+func (s *SpotifySyncService) GetOrSyncRelatedArtists(ctx context.Context, artistID string, spotifyToken string, refreshThreshold time.Duration) (*models.SpotifyRelatedArtists, error) {
+	cached, err := s.relatedArtistsDAO.GetByArtistID(ctx, artistID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("error getting related artists from DB: %w", err)
+	}
+	var lastFetched time.Time
+	if cached != nil {
+		lastFetched = cached.LastFetchedAt.Time()
+	}
+	if !s.enrichmentNeedsSync(relatedArtistsCacheKind, cached != nil, lastFetched, refreshThreshold) {
+		return cached, nil
+	}
+
+	fresh, err := s.syncRelatedArtists(ctx, artistID, spotifyToken)
+	if err != nil {
+		if cached != nil {
+			s.logger.WarnContext(ctx, "related artists sync failed, returning stale data", "artist_id", artistID, "error", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return fresh, nil
+}
+
+func (s *SpotifySyncService) syncRelatedArtists(ctx context.Context, artistID string, spotifyToken string) (*models.SpotifyRelatedArtists, error) {
+	if spotifyToken == "" {
+		return nil, errors.New("cannot sync related artists: spotify token missing")
+	}
+	client := utils.CreateTemporarySpotifyClient(ctx, spotifyToken)
+	artists, err := client.GetRelatedArtists(ctx, spotify.ID(artistID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get related artists from Spotify API: %w", err)
+	}
+
+	related := &models.SpotifyRelatedArtists{ArtistID: artistID, Artists: make([]models.SpotifyArtist, 0, len(artists))}
+	for i := range artists {
+		related.Artists = append(related.Artists, *mapSpotifyArtistToDBModel(&artists[i]))
+	}
+	if err := s.relatedArtistsDAO.Upsert(ctx, related); err != nil {
+		return nil, err
+	}
+	return related, nil
+}
+
+// GetOrSyncArtistTopTracks returns the cached top tracks for an artist in market. If they are missing
+// or stale it fetches them from Spotify and updates the cache; stale data is returned if that fails.
+func (s *SpotifySyncService) GetOrSyncArtistTopTracks(ctx context.Context, artistID string, market string, spotifyToken string, refreshThreshold time.Duration) (*models.SpotifyArtistTopTracks, error) {
+	cached, err := s.topTracksDAO.Get(ctx, artistID, market)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("error getting artist top tracks from DB: %w", err)
+	}
+	var lastFetched time.Time
+	if cached != nil {
+		lastFetched = cached.LastFetchedAt.Time()
+	}
+	if !s.enrichmentNeedsSync(topTracksCacheKind, cached != nil, lastFetched, refreshThreshold) {
+		return cached, nil
+	}
+
+	fresh, err := s.syncArtistTopTracks(ctx, artistID, market, spotifyToken)
+	if err != nil {
+		if cached != nil {
+			s.logger.WarnContext(ctx, "artist top tracks sync failed, returning stale data", "artist_id", artistID, "market", market, "error", err)
+			return cached, nil
+		}
+		return nil, err
+	}
+	return fresh, nil
+}
+
+func (s *SpotifySyncService) syncArtistTopTracks(ctx context.Context, artistID string, market string, spotifyToken string) (*models.SpotifyArtistTopTracks, error) {
+	if spotifyToken == "" {
+		return nil, errors.New("cannot sync artist top tracks: spotify token missing")
+	}
+	client := utils.CreateTemporarySpotifyClient(ctx, spotifyToken)
+	tracks, err := client.GetArtistsTopTracks(ctx, spotify.ID(artistID), market)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get artist top tracks from Spotify API: %w", err)
+	}
+
+	topTracks := &models.SpotifyArtistTopTracks{ArtistID: artistID, Market: market, Tracks: make([]models.SpotifyTrack, 0, len(tracks))}
+	for i := range tracks {
+		topTracks.Tracks = append(topTracks.Tracks, *mapSpotifyTrackToDBTrackModel(&tracks[i]))
+	}
+	if err := s.topTracksDAO.Upsert(ctx, topTracks); err != nil {
+		return nil, err
+	}
+	return topTracks, nil
+}
+
+// enrichmentNeedsSync records the cache lookup and reports whether the entry must be fetched from Spotify.
+func (s *SpotifySyncService) enrichmentNeedsSync(kind string, found bool, lastFetched time.Time, refreshThreshold time.Duration) bool {
+	switch {
+	case !found:
+		metrics.RecordCacheLookup(kind, metrics.CacheMiss)
+		return true
+	case needsRefresh(lastFetched, refreshThreshold):
+		metrics.RecordCacheLookup(kind, metrics.CacheStale)
+		return true
+	}
+	metrics.RecordCacheLookup(kind, metrics.CacheHit)
+	return false
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeArtistDAO, fakeRelatedArtistsDAO and fakeTopTracksDAO serve cached entries from memory.
+type fakeArtistDAO struct {
+	dao.SpotifyArtistDAO
+	artists map[string]*models.SpotifyArtist
+}
+
+func (f *fakeArtistDAO) GetByID(ctx context.Context, spotifyID string) (*models.SpotifyArtist, error) {
+	if artist, ok := f.artists[spotifyID]; ok {
+		return artist, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeArtistDAO) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyArtist, error) {
+	var found []*models.SpotifyArtist
+	for _, id := range spotifyIDs {
+		if artist, ok := f.artists[id]; ok {
+			found = append(found, artist)
+		}
+	}
+	return found, nil
+}
+
+type fakeRelatedArtistsDAO struct {
+	dao.SpotifyRelatedArtistsDAO
+	related map[string]*models.SpotifyRelatedArtists
+}
+
+func (f *fakeRelatedArtistsDAO) GetByArtistID(ctx context.Context, artistID string) (*models.SpotifyRelatedArtists, error) {
+	if related, ok := f.related[artistID]; ok {
+		return related, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+type fakeTopTracksDAO struct {
+	dao.SpotifyArtistTopTracksDAO
+	topTracks map[string]*models.SpotifyArtistTopTracks // By ArtistTopTracksID
+}
+
+func (f *fakeTopTracksDAO) Get(ctx context.Context, artistID string, market string) (*models.SpotifyArtistTopTracks, error) {
+	if topTracks, ok := f.topTracks[models.ArtistTopTracksID(artistID, market)]; ok {
+		return topTracks, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func TestGetArtistDetails(t *testing.T) {
+	fresh := primitive.NewDateTimeFromTime(time.Now())
+	stale := primitive.NewDateTimeFromTime(time.Now().Add(-48 * time.Hour))
+	service := NewSpotifySyncService(nil, nil,
+		&fakeArtistDAO{artists: map[string]*models.SpotifyArtist{
+			"a1": {SpotifyID: "a1", Name: "Artist", LastFetchedAt: fresh},
+			"a2": {SpotifyID: "a2", Name: "Bare Artist", LastFetchedAt: fresh},
+		}},
+		&fakeRelatedArtistsDAO{related: map[string]*models.SpotifyRelatedArtists{
+			"a1": {ArtistID: "a1", Artists: []models.SpotifyArtist{{SpotifyID: "r1"}}, LastFetchedAt: stale},
+		}},
+		&fakeTopTracksDAO{topTracks: map[string]*models.SpotifyArtistTopTracks{
+			models.ArtistTopTracksID("a1", "US"): {ArtistID: "a1", Market: "US", Tracks: []models.SpotifyTrack{{SpotifyID: "t1"}}, LastFetchedAt: fresh},
+		}},
+		nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	// No Spotify token, so nothing can be synced and only the cache answers
+	tests := []struct {
+		name        string
+		artistID    string
+		market      string
+		wantErr     bool
+		wantRelated int
+		wantTop     int
+	}{
+		{name: "stale related artists are still returned", artistID: "a1", market: "US", wantRelated: 1, wantTop: 1},
+		{name: "top tracks are cached per market", artistID: "a1", market: "GB", wantRelated: 1, wantTop: 0},
+		{name: "enrichment is best effort", artistID: "a2", market: "US", wantRelated: 0, wantTop: 0},
+		{name: "the artist itself is required", artistID: "a3", market: "US", wantErr: true},
+	}
+	for _, tt := range tests {
+		details, err := service.GetArtistDetails(context.Background(), tt.artistID, tt.market, "", 24*time.Hour)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: GetArtistDetails() = %+v, want an error", tt.name, details)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: GetArtistDetails() unexpected error: %v", tt.name, err)
+			continue
+		}
+		if details.Artist.SpotifyID != tt.artistID || details.Market != tt.market {
+			t.Errorf("%s: artist %s in %s, want %s in %s", tt.name, details.Artist.SpotifyID, details.Market, tt.artistID, tt.market)
+		}
+		// Empty lists, not nil, so the JSON response has [] rather than null
+		if details.RelatedArtists == nil || details.TopTracks == nil {
+			t.Errorf("%s: related artists %v, top tracks %v; want non-nil slices", tt.name, details.RelatedArtists, details.TopTracks)
+		}
+		if len(details.RelatedArtists) != tt.wantRelated || len(details.TopTracks) != tt.wantTop {
+			t.Errorf("%s: %d related artists and %d top tracks, want %d and %d", tt.name, len(details.RelatedArtists), len(details.TopTracks), tt.wantRelated, tt.wantTop)
+		}
+	}
+}
+
+func TestGetOrSyncRelatedArtistsFreshCache(t *testing.T) {
+	cached := &models.SpotifyRelatedArtists{ArtistID: "a1", LastFetchedAt: primitive.NewDateTimeFromTime(time.Now())}
+	service := NewSpotifySyncService(nil, nil, nil, &fakeRelatedArtistsDAO{related: map[string]*models.SpotifyRelatedArtists{"a1": cached}},
+		nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	// A token is given, but a fresh entry must not reach Spotify
+	got, err := service.GetOrSyncRelatedArtists(context.Background(), "a1", "token", time.Hour)
+	if err != nil || got != cached {
+		t.Errorf("GetOrSyncRelatedArtists() = %+v, %v; want the cached entry", got, err)
+	}
+	if _, err := service.GetOrSyncRelatedArtists(context.Background(), "a9", "", time.Hour); err == nil {
+		t.Error("GetOrSyncRelatedArtists() for an uncached artist without a token succeeded, want an error")
+	}
+}
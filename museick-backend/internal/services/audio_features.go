@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/zmb3/spotify/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Spotify's "get several audio features" endpoint takes at most 100 IDs.
+const maxSpotifyAudioFeaturesPerRequest = 100
+
+// AudioFeaturesProvider looks up audio features for tracks during sync. Tracks it has no features for
+// are simply absent from the result.
+type AudioFeaturesProvider interface {
+	GetAudioFeatures(ctx context.Context, trackIDs []string, client *spotify.Client) (map[string]*models.AudioFeatures, error)
+}
+
+// NewAudioFeaturesProvider returns the provider configured by name: spotify, fixture (reading fixturePath)
+// or none, which returns a nil provider so syncs skip audio features.
+func NewAudioFeaturesProvider(name, fixturePath string) (AudioFeaturesProvider, error) {
+	switch name {
+	case "spotify":
+		return NewSpotifyAudioFeaturesProvider(), nil
+	case "fixture":
+		return NewFixtureAudioFeaturesProvider(fixturePath)
+	case "none", "":
+		return nil, nil
+	default:
+		return nil, fmt.Errorf("unsupported audio features provider %q, expected spotify, fixture or none", name)
+	}
+}
+
+// SpotifyAudioFeaturesProvider reads audio features from the Spotify Web API with the syncing client.
+// Spotify no longer grants this endpoint to newly registered apps; for those it fails with 403, after
+// which the provider stops calling it until the process restarts.
+type SpotifyAudioFeaturesProvider struct {
+	forbidden atomic.Bool // Set after Spotify answered 403
+}
+
+// NewSpotifyAudioFeaturesProvider creates a new SpotifyAudioFeaturesProvider.
+func NewSpotifyAudioFeaturesProvider() *SpotifyAudioFeaturesProvider {
+	return &SpotifyAudioFeaturesProvider{}
+}
+
+// GetAudioFeatures fetches features in chunks of 100 tracks.
+func (p *SpotifyAudioFeaturesProvider) GetAudioFeatures(ctx context.Context, trackIDs []string, client *spotify.Client) (map[string]*models.AudioFeatures, error) {
+	if p.forbidden.Load() {
+		return nil, nil
+	}
+	if client == nil {
+		return nil, fmt.Errorf("spotify client not available for audio features")
+	}
+	features := make(map[string]*models.AudioFeatures, len(trackIDs))
+	now := primitive.NewDateTimeFromTime(time.Now())
+	for start := 0; start < len(trackIDs); start += maxSpotifyAudioFeaturesPerRequest {
+		end := start + maxSpotifyAudioFeaturesPerRequest
+		if end > len(trackIDs) {
+			end = len(trackIDs)
+		}
+		ids := make([]spotify.ID, 0, end-start)
+		for _, id := range trackIDs[start:end] {
+			ids = append(ids, spotify.ID(id))
+		}
+		fetched, err := client.GetAudioFeatures(ctx, ids...)
+		if err != nil {
+			var spotifyErr spotify.Error
+			if errors.As(err, &spotifyErr) && spotifyErr.Status == http.StatusForbidden {
+				p.forbidden.Store(true)
+				return features, fmt.Errorf("spotify denied audio features, not asking again until restart (set AUDIO_FEATURES_PROVIDER=none or fixture): %w", err)
+			}
+			return features, fmt.Errorf("failed to get audio features from Spotify API: %w", err)
+		}
+		for _, f := range fetched {
+			if f == nil { // Spotify has no analysis for the track
+				continue
+			}
+			features[f.ID.String()] = &models.AudioFeatures{
+				Valence:      float64(f.Valence),
+				Energy:       float64(f.Energy),
+				Danceability: float64(f.Danceability),
+				Tempo:        float64(f.Tempo),
+				Acousticness: float64(f.Acousticness),
+				Source:       "spotify",
+				FetchedAt:    now,
+			}
+		}
+	}
+	return features, nil
+}
+
+// FixtureAudioFeaturesProvider serves audio features from a JSON file mapping track IDs to features,
+// for development and tests, or for apps without access to Spotify's endpoint:
+//
+//	{"4uLU6hMCjMI75M1A2tKUQC": {"valence": 0.91, "energy": 0.85, "danceability": 0.72, "tempo": 113, "acousticness": 0.02}}
+type FixtureAudioFeaturesProvider struct {
+	features map[string]models.AudioFeatures
+}
+
+// NewFixtureAudioFeaturesProvider loads the fixture at path.
+func NewFixtureAudioFeaturesProvider(path string) (*FixtureAudioFeaturesProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading audio features fixture: %w", err)
+	}
+	var features map[string]models.AudioFeatures
+	if err := json.Unmarshal(data, &features); err != nil {
+		return nil, fmt.Errorf("parsing audio features fixture %s: %w", path, err)
+	}
+	return &FixtureAudioFeaturesProvider{features: features}, nil
+}
+
+// GetAudioFeatures returns the fixture's features for the tracks it lists. The client is not used.
+func (p *FixtureAudioFeaturesProvider) GetAudioFeatures(ctx context.Context, trackIDs []string, client *spotify.Client) (map[string]*models.AudioFeatures, error) {
+	features := make(map[string]*models.AudioFeatures, len(trackIDs))
+	now := primitive.NewDateTimeFromTime(time.Now())
+	for _, id := range trackIDs {
+		if f, ok := p.features[id]; ok {
+			f.Source = "fixture"
+			f.FetchedAt = now
+			features[id] = &f
+		}
+	}
+	return features, nil
+}
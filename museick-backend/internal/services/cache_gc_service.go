@@ -0,0 +1,80 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+)
+
+// CacheGCService deletes cached Spotify data that no user selection references any more.
+// Only data not re-fetched for the grace period is considered, so items somebody is browsing,
+// or has just removed from a month, stay cached for a while.
+type CacheGCService struct {
+	deleters map[string]func(ctx context.Context, fetchedBefore time.Time) (int64, error) // by collection
+	grace    time.Duration
+	logger   *slog.Logger
+}
+
+// NewCacheGCService creates a new instance of CacheGCService.
+func NewCacheGCService(
+	trackDAO dao.SpotifyTrackDAO,
+	albumDAO dao.SpotifyAlbumDAO,
+	artistDAO dao.SpotifyArtistDAO,
+	relatedArtistsDAO dao.SpotifyRelatedArtistsDAO,
+	topTracksDAO dao.SpotifyArtistTopTracksDAO,
+	grace time.Duration,
+	logger *slog.Logger,
+) *CacheGCService {
+	return &CacheGCService{
+		deleters: map[string]func(ctx context.Context, fetchedBefore time.Time) (int64, error){
+			dao.SpotifyTracksCollection:          trackDAO.DeleteUnreferenced,
+			dao.SpotifyAlbumsCollection:          albumDAO.DeleteUnreferenced,
+			dao.SpotifyArtistsCollection:         artistDAO.DeleteUnreferenced,
+			dao.SpotifyRelatedArtistsCollection:  relatedArtistsDAO.DeleteUnreferenced,
+			dao.SpotifyArtistTopTracksCollection: topTracksDAO.DeleteUnreferenced,
+		},
+		grace:  grace,
+		logger: logger.With("component", "cache_gc_service"),
+	}
+}
+
+// Collect runs one garbage collection pass over every cache collection and returns how many
+// documents were deleted from each. A failing collection doesn't stop the others.
+func (s *CacheGCService) Collect(ctx context.Context) (map[string]int64, error) {
+	fetchedBefore := time.Now().Add(-s.grace)
+	deleted := make(map[string]int64, len(s.deleters))
+	var errs []error
+	for collection, deleteUnreferenced := range s.deleters {
+		n, err := deleteUnreferenced(ctx, fetchedBefore)
+		deleted[collection] = n
+		metrics.RecordCacheGCDeleted(collection, n)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", collection, err))
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// Run collects every interval until ctx is cancelled.
+func (s *CacheGCService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.Collect(ctx)
+			if err != nil {
+				s.logger.ErrorContext(ctx, "cache garbage collection failed", "deleted", deleted, "error", err)
+				continue
+			}
+			s.logger.InfoContext(ctx, "cache garbage collection finished", "deleted", deleted, "grace", s.grace)
+		}
+	}
+}
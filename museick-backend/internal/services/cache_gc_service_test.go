@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+func TestCacheGCCollect(t *testing.T) {
+	var cutoffs []time.Time
+	deleter := func(n int64, err error) func(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+		return func(ctx context.Context, fetchedBefore time.Time) (int64, error) {
+			cutoffs = append(cutoffs, fetchedBefore)
+			return n, err
+		}
+	}
+	service := &CacheGCService{
+		deleters: map[string]func(ctx context.Context, fetchedBefore time.Time) (int64, error){
+			dao.SpotifyTracksCollection:  deleter(3, nil),
+			dao.SpotifyAlbumsCollection:  deleter(1, errors.New("aggregate failed")), // Partway through its batches
+			dao.SpotifyArtistsCollection: deleter(0, nil),
+		},
+		grace:  30 * 24 * time.Hour,
+		logger: slog.New(slog.NewTextHandler(io.Discard, nil)),
+	}
+
+	start := time.Now()
+	deleted, err := service.Collect(context.Background())
+	end := time.Now()
+	if err == nil || !strings.Contains(err.Error(), dao.SpotifyAlbumsCollection+": aggregate failed") {
+		t.Errorf("Collect() error = %v, want the albums failure", err)
+	}
+	want := map[string]int64{dao.SpotifyTracksCollection: 3, dao.SpotifyAlbumsCollection: 1, dao.SpotifyArtistsCollection: 0}
+	if len(deleted) != len(want) {
+		t.Errorf("Collect() = %v, want %v", deleted, want)
+	}
+	for collection, n := range want {
+		if deleted[collection] != n {
+			t.Errorf("deleted from %s = %d, want %d", collection, deleted[collection], n)
+		}
+	}
+	// Every collection shares one cutoff, a grace period before the pass
+	for _, cutoff := range cutoffs {
+		if cutoff != cutoffs[0] || cutoff.Before(start.Add(-service.grace)) || cutoff.After(end.Add(-service.grace)) {
+			t.Errorf("cutoffs = %v, want one cutoff %s before the pass", cutoffs, service.grace)
+			break
+		}
+	}
+}
+
+// bulkTrackDAO records the tracks written with BulkUpsert.
+type bulkTrackDAO struct {
+	dao.SpotifyTrackDAO
+	upserted []*models.SpotifyTrack
+}
+
+func (f *bulkTrackDAO) BulkUpsert(ctx context.Context, tracks []*models.SpotifyTrack) error {
+	f.upserted = append(f.upserted, tracks...)
+	return nil
+}
+
+func TestBulkUpsertItems(t *testing.T) {
+	trackDAO := &bulkTrackDAO{}
+	service := NewSpotifySyncService(trackDAO, nil, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	if err := service.bulkUpsertItems(context.Background(), nil); err != nil {
+		t.Errorf("bulkUpsertItems(nil) = %v, want nil", err)
+	}
+	tracks := []interface{}{&models.SpotifyTrack{SpotifyID: "t1"}, &models.SpotifyTrack{SpotifyID: "t2"}}
+	if err := service.bulkUpsertItems(context.Background(), tracks); err != nil || len(trackDAO.upserted) != 2 {
+		t.Errorf("bulkUpsertItems(tracks) = %v with %d written, want both tracks in one write", err, len(trackDAO.upserted))
+	}
+	if err := service.bulkUpsertItems(context.Background(), []interface{}{"t1"}); err == nil || !strings.Contains(err.Error(), "unsupported cache item type string") {
+		t.Errorf("bulkUpsertItems(string) = %v, want an unsupported type error", err)
+	}
+}
@@ -0,0 +1,275 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+	"github.com/zmb3/spotify/v2"
+)
+
+// Limits on the genre timeline's lists.
+const (
+	genresPerMonth    = 10
+	topGenresPerYear  = 20
+	topArtistsPerYear = 20
+)
+
+// GenreTimeline reports the genre mix of a user's picks month by month, the most picked artists of the year and
+// the artists they picked both as a Muse and as an Ick. Every item type counts: artists directly, tracks and
+// albums through their credited artists. Items and artists missing from the cache are synced from Spotify when
+// spotifyToken is set; otherwise whatever is cached is used.
+func (s *StatsService) GenreTimeline(ctx context.Context, userID string, year int, selectedOnly bool, spotifyToken string) (*models.GenreTimelineResponse, error) {
+	if err := validateStatsYear(year); err != nil {
+		return nil, err
+	}
+	selections, isMuse, err := s.yearSelections(ctx, userID, year, selectedOnly, "track", "album", "artist")
+	if err != nil {
+		return nil, err
+	}
+	var client *spotify.Client
+	if spotifyToken != "" {
+		client = utils.CreateTemporarySpotifyClient(ctx, spotifyToken)
+	}
+	itemArtists, artists, err := s.resolveArtists(ctx, selections, client)
+	if err != nil {
+		return nil, err
+	}
+	pickArtists := func(sel *models.UserSelection) []string {
+		return itemArtists[sel.ItemType+":"+sel.SpotifyItemID]
+	}
+	// pickGenres returns the distinct genres of a pick's artists, in credit order.
+	pickGenres := func(sel *models.UserSelection) []string {
+		var genres []string
+		seen := map[string]bool{}
+		for _, id := range pickArtists(sel) {
+			if artist := artists[id]; artist != nil {
+				for _, genre := range artist.Genres {
+					if !seen[genre] {
+						seen[genre] = true
+						genres = append(genres, genre)
+					}
+				}
+			}
+		}
+		return genres
+	}
+
+	response := &models.GenreTimelineResponse{Year: year, SelectedOnly: selectedOnly, Months: make([]models.GenreMonth, 0, 12)}
+
+	// Month by month, each month's picks counted once per group
+	byMonth := map[string][]*models.UserSelection{}
+	for _, sel := range selections {
+		byMonth[sel.MonthYear] = append(byMonth[sel.MonthYear], sel)
+	}
+	for month := 1; month <= 12; month++ {
+		monthYear := fmt.Sprintf("%d-%02d", year, month)
+		weights := map[string]float64{}
+		entry := models.GenreMonth{MonthYear: monthYear}
+		for _, pick := range distinctPicks(byMonth[monthYear], isMuse) {
+			entry.Picks++
+			if addGenreWeights(weights, pickGenres(pick.selection)) {
+				entry.WithGenres++
+			}
+		}
+		entry.Genres = topGenreShares(weights, entry.WithGenres, genresPerMonth)
+		response.Months = append(response.Months, entry)
+	}
+
+	// The whole year, each pick counted once per group
+	yearWeights := map[string]float64{}
+	withGenres := 0
+	rollups := map[string]*models.ArtistRollup{}
+	for _, pick := range distinctPicks(selections, isMuse) {
+		response.Picks++
+		if addGenreWeights(yearWeights, pickGenres(pick.selection)) {
+			withGenres++
+		}
+		ids := pickArtists(pick.selection)
+		if len(ids) == 0 {
+			response.Unresolved++
+		}
+		for _, id := range ids {
+			rollup := rollups[id]
+			if rollup == nil {
+				rollup = &models.ArtistRollup{SpotifyID: id, Genres: []string{}, ByItemType: map[string]int{}}
+				if artist := artists[id]; artist != nil {
+					rollup.Name = artist.Name
+					if artist.Genres != nil {
+						rollup.Genres = artist.Genres
+					}
+				}
+				rollups[id] = rollup
+			}
+			rollup.Picks++
+			rollup.ByItemType[pick.selection.ItemType]++
+			if pick.muse {
+				rollup.Muses++
+			} else {
+				rollup.Icks++
+			}
+		}
+	}
+	response.TopGenres = topGenreShares(yearWeights, withGenres, topGenresPerYear)
+
+	sorted := make([]models.ArtistRollup, 0, len(rollups))
+	for _, rollup := range rollups {
+		sorted = append(sorted, *rollup)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Picks != sorted[j].Picks {
+			return sorted[i].Picks > sorted[j].Picks
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	response.TopArtists = sorted[:min(len(sorted), topArtistsPerYear)]
+	response.MuseAndIck = []models.ArtistRollup{}
+	for _, rollup := range sorted {
+		if rollup.Muses > 0 && rollup.Icks > 0 {
+			response.MuseAndIck = append(response.MuseAndIck, rollup)
+		}
+	}
+	return response, nil
+}
+
+// resolveArtists maps each selected item, keyed by "<item type>:<spotify ID>", to the Spotify IDs of its
+// artists, and loads those artists. Items and artists missing from the cache are synced with client when
+// it is set; anything that still can't be found is left out.
+func (s *StatsService) resolveArtists(ctx context.Context, selections []*models.UserSelection, client *spotify.Client) (map[string][]string, map[string]*models.SpotifyArtist, error) {
+	var trackIDs, albumIDs []string
+	itemArtists := map[string][]string{}
+	// Names from the selections and credits, for artists that can't be loaded themselves
+	creditedNames := map[string]string{}
+	for _, sel := range selections {
+		switch sel.ItemType {
+		case "track":
+			trackIDs = append(trackIDs, sel.SpotifyItemID)
+		case "album":
+			albumIDs = append(albumIDs, sel.SpotifyItemID)
+		case "artist":
+			itemArtists["artist:"+sel.SpotifyItemID] = []string{sel.SpotifyItemID}
+			creditedNames[sel.SpotifyItemID] = sel.ItemName
+		}
+	}
+	credit := func(key string, credited []models.SimplifiedArtist) {
+		for _, artist := range credited {
+			if artist.ID != "" {
+				itemArtists[key] = append(itemArtists[key], artist.ID)
+				creditedNames[artist.ID] = artist.Name
+			}
+		}
+	}
+
+	tracks, err := s.trackDAO.GetByIDs(ctx, uniqueIDs(trackIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tracks: %w", err)
+	}
+	found := map[string]bool{}
+	for _, track := range tracks {
+		found[track.SpotifyID] = true
+		credit("track:"+track.SpotifyID, track.Artists)
+	}
+	for _, item := range s.syncMissing(ctx, trackIDs, found, "track", client) {
+		if track, ok := item.(*models.SpotifyTrack); ok {
+			credit("track:"+track.SpotifyID, track.Artists)
+		}
+	}
+	albums, err := s.albumDAO.GetByIDs(ctx, uniqueIDs(albumIDs))
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load albums: %w", err)
+	}
+	found = map[string]bool{}
+	for _, album := range albums {
+		found[album.SpotifyID] = true
+		credit("album:"+album.SpotifyID, album.Artists)
+	}
+	for _, item := range s.syncMissing(ctx, albumIDs, found, "album", client) {
+		if album, ok := item.(*models.SpotifyAlbum); ok {
+			credit("album:"+album.SpotifyID, album.Artists)
+		}
+	}
+
+	var artistIDs []string
+	for _, ids := range itemArtists {
+		artistIDs = append(artistIDs, ids...)
+	}
+	artistIDs = uniqueIDs(artistIDs)
+	artists := make(map[string]*models.SpotifyArtist, len(artistIDs))
+	cached, err := s.artistDAO.GetByIDs(ctx, artistIDs)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load artists: %w", err)
+	}
+	found = map[string]bool{}
+	for _, artist := range cached {
+		found[artist.SpotifyID] = true
+		artists[artist.SpotifyID] = artist
+	}
+	for _, item := range s.syncMissing(ctx, artistIDs, found, "artist", client) {
+		if artist, ok := item.(*models.SpotifyArtist); ok {
+			artists[artist.SpotifyID] = artist
+		}
+	}
+	for id, name := range creditedNames {
+		if artists[id] == nil && name != "" {
+			artists[id] = &models.SpotifyArtist{SpotifyID: id, Name: name}
+		}
+	}
+	return itemArtists, artists, nil
+}
+
+// syncMissing syncs the IDs not in found from Spotify, returning the items it had.
+// Without a client nothing is synced.
+func (s *StatsService) syncMissing(ctx context.Context, ids []string, found map[string]bool, itemType string, client *spotify.Client) map[string]interface{} {
+	var missing []string
+	for _, id := range uniqueIDs(ids) {
+		if !found[id] {
+			missing = append(missing, id)
+		}
+	}
+	if len(missing) == 0 || client == nil {
+		return nil
+	}
+	items, failures := s.syncService.SyncItems(ctx, missing, itemType, client)
+	if len(failures) > 0 {
+		s.logger.WarnContext(ctx, "could not sync some items for stats", "item_type", itemType, "failed", len(failures))
+	}
+	return items
+}
+
+// addGenreWeights splits a pick's weight of 1 evenly across its genres, reporting whether it had any.
+func addGenreWeights(weights map[string]float64, genres []string) bool {
+	for _, genre := range genres {
+		weights[genre] += 1 / float64(len(genres))
+	}
+	return len(genres) > 0
+}
+
+// topGenreShares returns the limit largest genre weights with their share of picks, largest first.
+func topGenreShares(weights map[string]float64, picks int, limit int) []models.GenreShare {
+	shares := make([]models.GenreShare, 0, len(weights))
+	for genre, weight := range weights {
+		shares = append(shares, models.GenreShare{Genre: genre, Weight: roundStat(weight), Share: roundStat(weight / float64(picks))})
+	}
+	sort.Slice(shares, func(i, j int) bool {
+		if shares[i].Weight != shares[j].Weight {
+			return shares[i].Weight > shares[j].Weight
+		}
+		return shares[i].Genre < shares[j].Genre
+	})
+	return shares[:min(len(shares), limit)]
+}
+
+// uniqueIDs drops repeated IDs, keeping the first occurrence of each.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeAlbumDAO serves cached albums from memory.
+type fakeAlbumDAO struct {
+	dao.SpotifyAlbumDAO
+	albums map[string]*models.SpotifyAlbum
+}
+
+func (f *fakeAlbumDAO) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyAlbum, error) {
+	var found []*models.SpotifyAlbum
+	for _, id := range spotifyIDs {
+		if album, ok := f.albums[id]; ok {
+			found = append(found, album)
+		}
+	}
+	return found, nil
+}
+
+func TestGenreTimeline(t *testing.T) {
+	credits := func(ids ...string) []models.SimplifiedArtist {
+		names := map[string]string{"a1": "Alpha", "a2": "Beta", "a3": "Gamma"}
+		var artists []models.SimplifiedArtist
+		for _, id := range ids {
+			artists = append(artists, models.SimplifiedArtist{ID: id, Name: names[id]})
+		}
+		return artists
+	}
+	selection := func(itemType, spotifyID, name, month string, role models.SelectionRole) *models.UserSelection {
+		return &models.UserSelection{
+			ID: primitive.NewObjectID(), UserID: "user", ItemType: itemType, SpotifyItemID: spotifyID, ItemName: name,
+			MonthYear: month, SelectionRole: role,
+		}
+	}
+	selections := map[primitive.ObjectID]*models.UserSelection{}
+	for _, sel := range []*models.UserSelection{
+		selection("track", "t1", "Duet", "2024-01", models.RoleMuseSelected),
+		selection("track", "t1", "Duet", "2024-02", models.RoleMuseCandidate), // A new pick for February, not for the year
+		selection("album", "al1", "Record", "2024-01", models.RoleIckSelected),
+		selection("artist", "a2", "Beta", "2024-03", models.RoleMuseSelected),
+		selection("track", "gone", "Deleted", "2024-03", models.RoleMuseCandidate),
+		selection("track", "t3", "Obscure", "2024-04", models.RoleIckCandidate),
+		selection("track", "t1", "Duet", "2023-12", models.RoleIckSelected),
+	} {
+		selections[sel.ID] = sel
+	}
+	service := NewStatsService(
+		&fakeSelectionDAO{selections: selections},
+		&fakeTrackDAO{tracks: map[string]*models.SpotifyTrack{
+			"t1": {SpotifyID: "t1", Artists: credits("a1", "a2")},
+			"t3": {SpotifyID: "t3", Artists: credits("a3")},
+		}},
+		&fakeAlbumDAO{albums: map[string]*models.SpotifyAlbum{
+			"al1": {SpotifyID: "al1", Artists: credits("a1")},
+		}},
+		// Gamma isn't cached, so only the name from the credits is known
+		&fakeArtistDAO{artists: map[string]*models.SpotifyArtist{
+			"a1": {SpotifyID: "a1", Name: "Alpha", Genres: []string{"indie rock", "dream pop"}},
+			"a2": {SpotifyID: "a2", Name: "Beta", Genres: []string{"hyperpop"}},
+		}},
+		nil,
+		slog.New(slog.NewTextHandler(io.Discard, nil)),
+	)
+
+	got, err := service.GenreTimeline(context.Background(), "user", 2024, false, "")
+	if err != nil {
+		t.Fatalf("GenreTimeline() unexpected error: %v", err)
+	}
+	if got.Picks != 5 || got.Unresolved != 1 {
+		t.Errorf("picks = %d, unresolved = %d; want 5 and 1", got.Picks, got.Unresolved)
+	}
+
+	// The duet splits its weight across both artists' genres; the album and the artist pick carry their own
+	wantTopGenres := []models.GenreShare{
+		{Genre: "hyperpop", Weight: 1.3333, Share: 0.4444},
+		{Genre: "dream pop", Weight: 0.8333, Share: 0.2778},
+		{Genre: "indie rock", Weight: 0.8333, Share: 0.2778},
+	}
+	if !reflect.DeepEqual(got.TopGenres, wantTopGenres) {
+		t.Errorf("top genres = %+v, want %+v", got.TopGenres, wantTopGenres)
+	}
+
+	if len(got.Months) != 12 {
+		t.Fatalf("months = %d, want 12", len(got.Months))
+	}
+	wantMonths := map[string]models.GenreMonth{
+		"2024-01": {MonthYear: "2024-01", Picks: 2, WithGenres: 2, Genres: []models.GenreShare{
+			{Genre: "dream pop", Weight: 0.8333, Share: 0.4167},
+			{Genre: "indie rock", Weight: 0.8333, Share: 0.4167},
+			{Genre: "hyperpop", Weight: 0.3333, Share: 0.1667},
+		}},
+		"2024-02": {MonthYear: "2024-02", Picks: 1, WithGenres: 1, Genres: []models.GenreShare{
+			{Genre: "dream pop", Weight: 0.3333, Share: 0.3333},
+			{Genre: "hyperpop", Weight: 0.3333, Share: 0.3333},
+			{Genre: "indie rock", Weight: 0.3333, Share: 0.3333},
+		}},
+		"2024-03": {MonthYear: "2024-03", Picks: 2, WithGenres: 1, Genres: []models.GenreShare{{Genre: "hyperpop", Weight: 1, Share: 1}}},
+		"2024-04": {MonthYear: "2024-04", Picks: 1, WithGenres: 0, Genres: []models.GenreShare{}},
+		"2024-05": {MonthYear: "2024-05", Genres: []models.GenreShare{}},
+	}
+	for _, month := range got.Months {
+		if want, ok := wantMonths[month.MonthYear]; ok && !reflect.DeepEqual(month, want) {
+			t.Errorf("month %s = %+v, want %+v", month.MonthYear, month, want)
+		}
+	}
+
+	alpha := models.ArtistRollup{
+		SpotifyID: "a1", Name: "Alpha", Genres: []string{"indie rock", "dream pop"},
+		Picks: 2, Muses: 1, Icks: 1, ByItemType: map[string]int{"track": 1, "album": 1},
+	}
+	wantTopArtists := []models.ArtistRollup{
+		alpha,
+		{SpotifyID: "a2", Name: "Beta", Genres: []string{"hyperpop"}, Picks: 2, Muses: 2, ByItemType: map[string]int{"track": 1, "artist": 1}},
+		{SpotifyID: "a3", Name: "Gamma", Genres: []string{}, Picks: 1, Icks: 1, ByItemType: map[string]int{"track": 1}},
+	}
+	if !reflect.DeepEqual(got.TopArtists, wantTopArtists) {
+		t.Errorf("top artists = %+v, want %+v", got.TopArtists, wantTopArtists)
+	}
+	if want := []models.ArtistRollup{alpha}; !reflect.DeepEqual(got.MuseAndIck, want) {
+		t.Errorf("muse and ick = %+v, want %+v", got.MuseAndIck, want)
+	}
+
+	selectedOnly, err := service.GenreTimeline(context.Background(), "user", 2024, true, "")
+	if err != nil {
+		t.Fatalf("GenreTimeline() selected only unexpected error: %v", err)
+	}
+	if selectedOnly.Picks != 3 || selectedOnly.Unresolved != 0 {
+		t.Errorf("selected only: picks = %d, unresolved = %d; want 3 and 0", selectedOnly.Picks, selectedOnly.Unresolved)
+	}
+}
+
+func TestTopGenreShares(t *testing.T) {
+	weights := map[string]float64{"b": 1, "a": 1, "c": 2, "d": 0.5}
+	want := []models.GenreShare{{Genre: "c", Weight: 2, Share: 0.5}, {Genre: "a", Weight: 1, Share: 0.25}, {Genre: "b", Weight: 1, Share: 0.25}}
+	if got := topGenreShares(weights, 4, 3); !reflect.DeepEqual(got, want) {
+		t.Errorf("topGenreShares() = %+v, want %+v", got, want)
+	}
+	if got := topGenreShares(map[string]float64{}, 0, 3); got == nil || len(got) != 0 {
+		t.Errorf("topGenreShares() without weights = %#v, want an empty slice", got)
+	}
+}
@@ -0,0 +1,312 @@
+package services
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/media"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+const (
+	// Spotify's cover art is at most 640x640 (a few hundred KB); anything far bigger isn't what we asked for
+	maxImageBytes = 10 << 20
+	// How many cached items are read per page while looking for unmirrored images
+	mirrorPageSize = 100
+	// How long an image that failed to mirror is skipped before being tried again
+	mirrorRetryAfter = 6 * time.Hour
+)
+
+// Hosts images are downloaded from. Image URLs come from Spotify API responses, but they are still fetched
+// server side, so anything off Spotify's CDNs is refused.
+var imageHostSuffixes = []string{".scdn.co", ".spotifycdn.com"}
+
+// ImageMirrorService copies the album and artist images referenced by the Spotify cache into the media store,
+// with thumbnails, and points the cached records at the copies. Mirrored images are served from /media/:hash,
+// so clients don't depend on CDN URLs that rotate and share pages don't send visitors to Spotify.
+type ImageMirrorService struct {
+	store      media.Store
+	assetDAO   dao.MediaAssetDAO
+	trackDAO   dao.SpotifyTrackDAO
+	albumDAO   dao.SpotifyAlbumDAO
+	artistDAO  dao.SpotifyArtistDAO
+	httpClient *http.Client
+	failed     map[string]time.Time // Image URL -> when mirroring it last failed; only touched by MirrorPending
+	logger     *slog.Logger
+}
+
+// NewImageMirrorService creates a new instance of ImageMirrorService.
+func NewImageMirrorService(
+	store media.Store,
+	assetDAO dao.MediaAssetDAO,
+	trackDAO dao.SpotifyTrackDAO,
+	albumDAO dao.SpotifyAlbumDAO,
+	artistDAO dao.SpotifyArtistDAO,
+	logger *slog.Logger,
+) *ImageMirrorService {
+	return &ImageMirrorService{
+		store:     store,
+		assetDAO:  assetDAO,
+		trackDAO:  trackDAO,
+		albumDAO:  albumDAO,
+		artistDAO: artistDAO,
+		httpClient: &http.Client{
+			Timeout: 30 * time.Second,
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 3 {
+					return errors.New("too many redirects")
+				}
+				return checkImageURL(req.URL)
+			},
+		},
+		failed: map[string]time.Time{},
+		logger: logger.With("component", "image_mirror_service"),
+	}
+}
+
+func checkImageURL(u *url.URL) error {
+	if u.Scheme != "https" {
+		return fmt.Errorf("refusing to download image over %s", u.Scheme)
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, suffix := range imageHostSuffixes {
+		if strings.HasSuffix(host, suffix) {
+			return nil
+		}
+	}
+	return fmt.Errorf("refusing to download image from %s, not a Spotify CDN", host)
+}
+
+// Mirror makes sure the image at sourceURL is in the media store and returns its hash. URLs mirrored
+// before, and images whose bytes are already stored under another URL, are not processed again.
+func (s *ImageMirrorService) Mirror(ctx context.Context, sourceURL string) (string, error) {
+	if asset, err := s.assetDAO.GetBySourceURL(ctx, sourceURL); err == nil {
+		metrics.RecordImageMirrored("reused")
+		return asset.Hash, nil
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return "", err
+	}
+
+	data, err := s.download(ctx, sourceURL)
+	if err != nil {
+		metrics.RecordImageMirrored("failed")
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	hash := hex.EncodeToString(sum[:])
+
+	if asset, err := s.assetDAO.GetByHash(ctx, hash); err == nil {
+		metrics.RecordImageMirrored("reused")
+		return hash, s.assetDAO.Upsert(ctx, asset, sourceURL)
+	} else if !errors.Is(err, mongo.ErrNoDocuments) {
+		return "", err
+	}
+
+	asset, err := s.storeImage(ctx, hash, data)
+	if err != nil {
+		metrics.RecordImageMirrored("failed")
+		return "", err
+	}
+	if err := s.assetDAO.Upsert(ctx, asset, sourceURL); err != nil {
+		return "", err
+	}
+	metrics.RecordImageMirrored("downloaded")
+	s.logger.DebugContext(ctx, "mirrored image", "url", sourceURL, "hash", hash, "size", asset.Size)
+	return hash, nil
+}
+
+func (s *ImageMirrorService) download(ctx context.Context, sourceURL string) ([]byte, error) {
+	u, err := url.Parse(sourceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid image URL: %w", err)
+	}
+	if err := checkImageURL(u); err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, sourceURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("building image request: %w", err)
+	}
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("downloading image: status %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxImageBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("downloading image: %w", err)
+	}
+	if len(data) > maxImageBytes {
+		return nil, fmt.Errorf("image is larger than %d bytes", maxImageBytes)
+	}
+	return data, nil
+}
+
+// storeImage writes the original and its thumbnails to the media store. The asset record is only
+// written afterwards, so a record always means every object is in place.
+func (s *ImageMirrorService) storeImage(ctx context.Context, hash string, data []byte) (*models.MediaAsset, error) {
+	img, contentType, err := media.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	variants, err := media.Thumbnails(hash, img)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.store.Put(ctx, hash, data); err != nil {
+		return nil, err
+	}
+	asset := &models.MediaAsset{
+		Hash:        hash,
+		ContentType: contentType,
+		Width:       img.Bounds().Dx(),
+		Height:      img.Bounds().Dy(),
+		Size:        int64(len(data)),
+	}
+	for _, v := range variants {
+		if err := s.store.Put(ctx, v.Name, v.Data); err != nil {
+			return nil, err
+		}
+		asset.Variants = append(asset.Variants, models.MediaVariant{
+			Name: v.Name, ContentType: v.ContentType, Width: v.Width, Height: v.Height, Size: int64(len(v.Data)),
+		})
+	}
+	return asset, nil
+}
+
+// mirrorImages mirrors the unmirrored images in the list and returns URL -> hash for the ones that succeeded.
+func (s *ImageMirrorService) mirrorImages(ctx context.Context, images []models.ImageObject) map[string]string {
+	mirrors := map[string]string{}
+	for _, image := range images {
+		if image.MirrorHash != "" || image.URL == "" {
+			continue
+		}
+		if failedAt, ok := s.failed[image.URL]; ok && time.Since(failedAt) < mirrorRetryAfter {
+			continue
+		}
+		hash, err := s.Mirror(ctx, image.URL)
+		if err != nil {
+			s.failed[image.URL] = time.Now()
+			s.logger.WarnContext(ctx, "could not mirror image", "url", image.URL, "error", err)
+			continue
+		}
+		delete(s.failed, image.URL)
+		mirrors[image.URL] = hash
+	}
+	return mirrors
+}
+
+// pruneFailed forgets the failures that are due a retry, so images that are no longer cached don't pile up.
+func (s *ImageMirrorService) pruneFailed(now time.Time) {
+	for imageURL, failedAt := range s.failed {
+		if now.Sub(failedAt) >= mirrorRetryAfter {
+			delete(s.failed, imageURL)
+		}
+	}
+}
+
+// MirrorPending mirrors every cached album, artist and track image that hasn't been mirrored yet and returns
+// how many images it recorded. Images that fail are logged and retried on a later pass. A sync replaces an
+// item's images, dropping their mirror_hash, but the next pass restores it from the asset without downloading again.
+func (s *ImageMirrorService) MirrorPending(ctx context.Context) (int, error) {
+	s.pruneFailed(time.Now())
+	mirrored := 0
+	err := mirrorPages(ctx, func(afterID string) (string, int, error) {
+		albums, err := s.albumDAO.ListUnmirroredImages(ctx, afterID, mirrorPageSize)
+		for _, album := range albums {
+			mirrors := s.mirrorImages(ctx, album.Images)
+			if err := s.albumDAO.SetImageMirrors(ctx, album.SpotifyID, mirrors); err != nil {
+				return "", 0, err
+			}
+			mirrored += len(mirrors)
+			afterID = album.SpotifyID
+		}
+		return afterID, len(albums), err
+	})
+	if err != nil {
+		return mirrored, err
+	}
+	err = mirrorPages(ctx, func(afterID string) (string, int, error) {
+		artists, err := s.artistDAO.ListUnmirroredImages(ctx, afterID, mirrorPageSize)
+		for _, artist := range artists {
+			mirrors := s.mirrorImages(ctx, artist.Images)
+			if err := s.artistDAO.SetImageMirrors(ctx, artist.SpotifyID, mirrors); err != nil {
+				return "", 0, err
+			}
+			mirrored += len(mirrors)
+			afterID = artist.SpotifyID
+		}
+		return afterID, len(artists), err
+	})
+	if err != nil {
+		return mirrored, err
+	}
+	err = mirrorPages(ctx, func(afterID string) (string, int, error) {
+		tracks, err := s.trackDAO.ListUnmirroredImages(ctx, afterID, mirrorPageSize)
+		for _, track := range tracks {
+			mirrors := s.mirrorImages(ctx, track.Album.Images)
+			if err := s.trackDAO.SetImageMirrors(ctx, track.SpotifyID, mirrors); err != nil {
+				return "", 0, err
+			}
+			mirrored += len(mirrors)
+			afterID = track.SpotifyID
+		}
+		return afterID, len(tracks), err
+	})
+	return mirrored, err
+}
+
+// mirrorPages calls page with the last ID it returned until a page comes back short. page lists and mirrors
+// up to mirrorPageSize items after the given ID and returns the last ID it handled and how many items it listed.
+func mirrorPages(ctx context.Context, page func(afterID string) (string, int, error)) error {
+	afterID := ""
+	for {
+		lastID, n, err := page(afterID)
+		if err != nil {
+			return err
+		}
+		if n < mirrorPageSize {
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		afterID = lastID
+	}
+}
+
+// Run mirrors pending images every interval until ctx is cancelled.
+func (s *ImageMirrorService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			mirrored, err := s.MirrorPending(ctx)
+			if err != nil && ctx.Err() == nil {
+				s.logger.ErrorContext(ctx, "image mirroring failed", "mirrored", mirrored, "error", err)
+				continue
+			}
+			if mirrored > 0 {
+				s.logger.InfoContext(ctx, "mirrored images", "count", mirrored)
+			}
+		}
+	}
+}
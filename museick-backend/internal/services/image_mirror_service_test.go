@@ -0,0 +1,26 @@
+package services
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestImageMirrorPruneFailed(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	s := NewImageMirrorService(nil, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	s.failed = map[string]time.Time{
+		"https://i.scdn.co/image/recent": now.Add(-time.Hour),
+		"https://i.scdn.co/image/due":    now.Add(-mirrorRetryAfter),
+		"https://i.scdn.co/image/old":    now.Add(-30 * 24 * time.Hour),
+	}
+
+	s.pruneFailed(now)
+	if len(s.failed) != 1 {
+		t.Errorf("failures kept = %v, want only the recent one", s.failed)
+	}
+	if _, ok := s.failed["https://i.scdn.co/image/recent"]; !ok {
+		t.Error("recent failure dropped before it is due a retry")
+	}
+}
@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log/slog"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+)
+
+const (
+	maxJournalQueryLength  = 200
+	journalSearchLimit     = 50
+	journalSnippetRadius   = 60 // Bytes of context kept on each side of the first match
+	minJournalSearchYear   = 1900
+	maxJournalSearchYear   = 2100
+	journalHighlightOpen   = "<mark>"
+	journalHighlightClose  = "</mark>"
+	journalSnippetEllipsis = "…"
+
+	maxNotesLength        = 10000 // Characters of markdown in a selection's story
+	maxNotesRevisions     = 20    // Previous versions of notes kept per selection
+	maxMoodTags           = 10
+	maxMoodTagLength      = 32
+	maxContextFieldLength = 200
+	maxCompanions         = 10
+)
+
+// ErrInvalidJournalEntry is returned when notes, mood tags or listening context fail validation.
+var ErrInvalidJournalEntry = errors.New("invalid journal entry")
+
+// JournalService handles the journal side of selections: searching notes and listing by mood tag.
+type JournalService struct {
+	selectionDAO dao.UserSelectionDAO
+	logger       *slog.Logger
+}
+
+// NewJournalService creates a new instance of JournalService.
+func NewJournalService(selectionDAO dao.UserSelectionDAO, logger *slog.Logger) *JournalService {
+	logger = logger.With("component", "journal_service")
+	logger.Info("initializing JournalService")
+	return &JournalService{selectionDAO: selectionDAO, logger: logger}
+}
+
+// SearchJournal runs a full-text search over the user's selections, ranked by relevance.
+// A year of 0 searches all years. Each hit carries highlighted snippets of the fields that matched.
+func (s *JournalService) SearchJournal(ctx context.Context, userID, query string, year int) ([]*models.JournalSearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("search query cannot be empty")
+	}
+	if utf8.RuneCountInString(query) > maxJournalQueryLength {
+		return nil, fmt.Errorf("search query too long, maximum is %d characters", maxJournalQueryLength)
+	}
+	if year != 0 && (year < minJournalSearchYear || year > maxJournalSearchYear) {
+		return nil, fmt.Errorf("invalid year: %d", year)
+	}
+
+	results, err := s.selectionDAO.SearchText(ctx, userID, query, year, journalSearchLimit)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error searching journal", "error", err)
+		return nil, fmt.Errorf("failed to search journal: %w", err)
+	}
+
+	terms := journalSearchTerms(query)
+	hits := make([]*models.JournalSearchHit, 0, len(results))
+	for _, result := range results {
+		selection := result.UserSelection
+		highlights := journalHighlights(&selection, terms)
+		sanitizeSelectionsForOutput(&selection)
+		hits = append(hits, &models.JournalSearchHit{
+			Selection:  &selection,
+			Score:      result.Score,
+			Highlights: highlights,
+		})
+	}
+	return hits, nil
+}
+
+// ListMoodTags returns every mood tag the user has applied, with usage counts.
+func (s *JournalService) ListMoodTags(ctx context.Context, userID string) ([]*models.MoodTagCount, error) {
+	tags, err := s.selectionDAO.ListMoodTags(ctx, userID)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error listing mood tags", "error", err)
+		return nil, fmt.Errorf("failed to list mood tags: %w", err)
+	}
+	return tags, nil
+}
+
+// ListSelectionsByMoodTag retrieves the user's selections tagged with a mood tag. A year of 0 searches all years.
+func (s *JournalService) ListSelectionsByMoodTag(ctx context.Context, userID, tag string, year int) ([]*models.UserSelection, error) {
+	normalized, err := normalizeMoodTag(tag)
+	if err != nil {
+		return nil, err
+	}
+	if year != 0 && (year < minJournalSearchYear || year > maxJournalSearchYear) {
+		return nil, fmt.Errorf("invalid year: %d", year)
+	}
+	selections, err := s.selectionDAO.FindByMoodTag(ctx, userID, normalized, year)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error listing selections by mood tag", "tag", normalized, "error", err)
+		return nil, fmt.Errorf("failed to list selections by mood tag: %w", err)
+	}
+	sanitizeSelectionsForOutput(selections...)
+	return selections, nil
+}
+
+// --- Validation Helpers ---
+
+var moodTagRegex = regexp.MustCompile(`^[\p{L}\p{N}][\p{L}\p{N}_-]*$`)
+
+func validateNotes(notes string) error {
+	if utf8.RuneCountInString(notes) > maxNotesLength {
+		return fmt.Errorf("%w: notes exceed %d characters", ErrInvalidJournalEntry, maxNotesLength)
+	}
+	return nil
+}
+
+// normalizeMoodTag lowercases a tag and joins words with dashes ("Late Night" -> "late-night").
+func normalizeMoodTag(tag string) (string, error) {
+	normalized := strings.Join(strings.Fields(strings.ToLower(tag)), "-")
+	if normalized == "" {
+		return "", fmt.Errorf("%w: mood tags cannot be empty", ErrInvalidJournalEntry)
+	}
+	if utf8.RuneCountInString(normalized) > maxMoodTagLength {
+		return "", fmt.Errorf("%w: mood tag '%s' exceeds %d characters", ErrInvalidJournalEntry, normalized, maxMoodTagLength)
+	}
+	if !moodTagRegex.MatchString(normalized) {
+		return "", fmt.Errorf("%w: mood tag '%s' may only contain letters, numbers, '-' and '_'", ErrInvalidJournalEntry, normalized)
+	}
+	return normalized, nil
+}
+
+// normalizeMoodTags normalizes and de-duplicates tags, preserving their order.
+func normalizeMoodTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		n, err := normalizeMoodTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[n] {
+			seen[n] = true
+			normalized = append(normalized, n)
+		}
+	}
+	if len(normalized) > maxMoodTags {
+		return nil, fmt.Errorf("%w: at most %d mood tags are allowed", ErrInvalidJournalEntry, maxMoodTags)
+	}
+	return normalized, nil
+}
+
+// normalizeListeningContext trims and validates the context. Returns nil if nothing was provided.
+func normalizeListeningContext(lc *models.ListeningContext) (*models.ListeningContext, error) {
+	if lc == nil {
+		return nil, nil
+	}
+	normalized := &models.ListeningContext{
+		Location: strings.TrimSpace(lc.Location),
+		Activity: strings.TrimSpace(lc.Activity),
+	}
+	for _, companion := range lc.Companions {
+		if c := strings.TrimSpace(companion); c != "" {
+			normalized.Companions = append(normalized.Companions, c)
+		}
+	}
+	if len(normalized.Companions) > maxCompanions {
+		return nil, fmt.Errorf("%w: at most %d companions are allowed", ErrInvalidJournalEntry, maxCompanions)
+	}
+	for _, field := range append([]string{normalized.Location, normalized.Activity}, normalized.Companions...) {
+		if utf8.RuneCountInString(field) > maxContextFieldLength {
+			return nil, fmt.Errorf("%w: listening context fields cannot exceed %d characters", ErrInvalidJournalEntry, maxContextFieldLength)
+		}
+	}
+	if normalized.Location == "" && normalized.Activity == "" && len(normalized.Companions) == 0 {
+		return nil, nil
+	}
+	return normalized, nil
+}
+
+// sanitizeSelectionsForOutput makes the markdown in notes and their history safe to render.
+// Notes are stored exactly as written; sanitization only happens on the way out.
+func sanitizeSelectionsForOutput(selections ...*models.UserSelection) {
+	for _, selection := range selections {
+		if selection == nil {
+			continue
+		}
+		selection.Notes = utils.SanitizeMarkdown(selection.Notes)
+		for i := range selection.NotesHistory {
+			selection.NotesHistory[i].Notes = utils.SanitizeMarkdown(selection.NotesHistory[i].Notes)
+		}
+	}
+}
+
+// --- Highlighting Helpers ---
+
+var journalWordRegex = regexp.MustCompile(`[\p{L}\p{N}']+`)
+
+// journalSearchTerms extracts the positive terms from a MongoDB $text query string.
+// Negated terms ("-word") are dropped since they can never appear in a match.
+func journalSearchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") {
+			continue
+		}
+		for _, word := range journalWordRegex.FindAllString(strings.ToLower(field), -1) {
+			terms = append(terms, journalStem(word))
+		}
+	}
+	return terms
+}
+
+// journalStem trims common English suffixes so "trips" highlights "trip" and vice versa.
+// It is deliberately much cruder than MongoDB's stemmer; it only drives highlighting.
+func journalStem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word)-len(suffix) >= 3 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+func journalWordMatches(word string, terms []string) bool {
+	lower := strings.ToLower(word)
+	for _, term := range terms {
+		if strings.HasPrefix(lower, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// journalHighlights builds one snippet per searchable field that contains a matching word.
+func journalHighlights(selection *models.UserSelection, terms []string) []models.JournalSearchHighlight {
+	fields := []struct {
+		name string
+		text string
+	}{
+		{"notes", selection.Notes},
+		{"item_name", selection.ItemName},
+		{"album_name", selection.AlbumName},
+		{"artist_names", strings.Join(selection.ArtistNames, ", ")},
+	}
+
+	highlights := []models.JournalSearchHighlight{}
+	for _, field := range fields {
+		if snippet, ok := journalSnippet(field.text, terms); ok {
+			highlights = append(highlights, models.JournalSearchHighlight{Field: field.name, Snippet: snippet})
+		}
+	}
+	return highlights
+}
+
+// journalSnippet returns a window of text around the first match with every match in the window marked.
+// The surrounding text is HTML-escaped so the snippet is safe to render.
+func journalSnippet(text string, terms []string) (string, bool) {
+	if text == "" || len(terms) == 0 {
+		return "", false
+	}
+	words := journalWordRegex.FindAllStringIndex(text, -1)
+
+	first := -1
+	for i, loc := range words {
+		if journalWordMatches(text[loc[0]:loc[1]], terms) {
+			first = i
+			break
+		}
+	}
+	if first == -1 {
+		return "", false
+	}
+
+	// Snap the window to word boundaries so we never cut through a word or a multi-byte rune.
+	start, end := 0, len(text)
+	for i := first; i >= 0; i-- {
+		if words[first][0]-words[i][0] > journalSnippetRadius {
+			start = words[i+1][0]
+			break
+		}
+	}
+	for i := first; i < len(words); i++ {
+		if words[i][1]-words[first][1] > journalSnippetRadius {
+			end = words[i-1][1]
+			break
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(journalSnippetEllipsis)
+	}
+	cursor := start
+	for _, loc := range words {
+		if loc[0] < start || loc[1] > end {
+			continue
+		}
+		word := text[loc[0]:loc[1]]
+		if !journalWordMatches(word, terms) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[cursor:loc[0]]))
+		b.WriteString(journalHighlightOpen)
+		b.WriteString(html.EscapeString(word))
+		b.WriteString(journalHighlightClose)
+		cursor = loc[1]
+	}
+	b.WriteString(html.EscapeString(text[cursor:end]))
+	if end < len(text) {
+		b.WriteString(journalSnippetEllipsis)
+	}
+	return b.String(), true
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+func TestJournalStem(t *testing.T) {
+	tests := []struct {
+		word string
+		want string
+	}{
+		{"trips", "trip"},
+		{"driving", "driv"},
+		{"danced", "danc"},
+		{"boxes", "box"},
+		{"trip", "trip"},
+		{"sing", "sing"}, // Too short to lose "ing"
+		{"bus", "bus"},   // Too short to lose "s"
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := journalStem(tt.word); got != tt.want {
+			t.Errorf("journalStem(%q) = %q, want %q", tt.word, got, tt.want)
+		}
+	}
+}
+
+func TestJournalSearchTerms(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"road trips", []string{"road", "trip"}},
+		{"Summer -rain", []string{"summer"}},
+		{`"late night" drives`, []string{"late", "night", "driv"}},
+		{"don't", []string{"don't"}},
+		{"-only -negated", nil},
+	}
+	for _, tt := range tests {
+		if got := journalSearchTerms(tt.query); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("journalSearchTerms(%q) = %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestJournalSnippet(t *testing.T) {
+	long := strings.Repeat("filler ", 20)
+	tests := []struct {
+		name  string
+		text  string
+		terms []string
+		want  string
+		found bool
+	}{
+		{
+			name:  "marks every stemmed match",
+			text:  "A trip with friends, tripping over the beat",
+			terms: []string{"trip"},
+			want:  "A <mark>trip</mark> with friends, <mark>tripping</mark> over the beat",
+			found: true,
+		},
+		{
+			name:  "matches case-insensitively",
+			text:  "Summer anthem",
+			terms: []string{"summer"},
+			want:  "<mark>Summer</mark> anthem",
+			found: true,
+		},
+		{
+			name:  "escapes the surrounding text",
+			text:  "<b>loud</b> & proud",
+			terms: []string{"proud"},
+			want:  "&lt;b&gt;loud&lt;/b&gt; &amp; <mark>proud</mark>",
+			found: true,
+		},
+		{
+			name:  "trims long text around the first match",
+			text:  long + "midnight" + " " + long,
+			terms: []string{"midnight"},
+			want:  "…" + strings.Repeat("filler ", 8) + "<mark>midnight</mark> " + strings.TrimSpace(strings.Repeat("filler ", 8)) + "…",
+			found: true,
+		},
+		{
+			name:  "no match",
+			text:  "quiet evening",
+			terms: []string{"trip"},
+		},
+		{
+			name:  "no terms",
+			text:  "quiet evening",
+			terms: nil,
+		},
+		{
+			name:  "empty text",
+			terms: []string{"trip"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, found := journalSnippet(tt.text, tt.terms)
+			if found != tt.found || got != tt.want {
+				t.Errorf("journalSnippet(%q, %q) = %q, %v; want %q, %v", tt.text, tt.terms, got, found, tt.want, tt.found)
+			}
+		})
+	}
+}
+
+func TestJournalHighlights(t *testing.T) {
+	selection := &models.UserSelection{
+		Notes:       "Played it on every road trip",
+		ItemName:    "Trip Switch",
+		AlbumName:   "Nothing to match",
+		ArtistNames: []string{"Nilüfer Yanya", "Trippie"},
+	}
+	got := journalHighlights(selection, []string{"trip"})
+	want := []models.JournalSearchHighlight{
+		{Field: "notes", Snippet: "Played it on every road <mark>trip</mark>"},
+		{Field: "item_name", Snippet: "<mark>Trip</mark> Switch"},
+		{Field: "artist_names", Snippet: "Nilüfer Yanya, <mark>Trippie</mark>"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("journalHighlights() = %+v, want %+v", got, want)
+	}
+}
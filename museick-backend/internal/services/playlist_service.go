@@ -3,25 +3,39 @@ package services
 import (
 	"context"
 	"fmt"
+	"log/slog"
 
 	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
 	"github.com/seven7een/museick/museick-backend/internal/utils"
 	"github.com/zmb3/spotify/v2"
 )
 
+// marketFromToken asks Spotify to use the market of the token's user, for accounts whose country we don't know.
+const marketFromToken = "from_token"
+
 type PlaylistService struct {
-	userSelectionDAO dao.UserSelectionDAO
-	spotifyService   *SpotifyService
+	userSelectionDAO   dao.UserSelectionDAO
+	userDAO            dao.UserDAO
+	spotifyService     *SpotifyService
+	spotifySyncService *SpotifySyncService
+	logger             *slog.Logger
 }
 
-func NewPlaylistService(userSelectionDAO dao.UserSelectionDAO, spotifyService *SpotifyService) *PlaylistService {
+func NewPlaylistService(userSelectionDAO dao.UserSelectionDAO, userDAO dao.UserDAO, spotifyService *SpotifyService, spotifySyncService *SpotifySyncService, logger *slog.Logger) *PlaylistService {
 	return &PlaylistService{
-		userSelectionDAO: userSelectionDAO,
-		spotifyService:   spotifyService,
+		userSelectionDAO:   userSelectionDAO,
+		userDAO:            userDAO,
+		spotifyService:     spotifyService,
+		spotifySyncService: spotifySyncService,
+		logger:             logger.With("component", "playlist_service"),
 	}
 }
 
-func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID string, spotifyToken string, year int, mode string, includeCandidates bool) (string, error) {
+// CreateYearlyPlaylist creates a private playlist of the user's tracks for a year. Tracks are checked
+// against the user's market first: unavailable ones are skipped and relinked ones are added as their
+// playable copy. The returned response reports both; its Message is left for the caller to fill in.
+func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID string, spotifyToken string, year int, mode string, includeCandidates bool) (*models.CreatePlaylistResponse, error) {
 	// Get all tracks for the year based on mode and includeCandidates
 	selectionRoles := []string{mode + "_selected"}
 	if includeCandidates {
@@ -31,11 +45,11 @@ func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID strin
 	// Get user's selections for the year
 	selections, err := s.userSelectionDAO.GetUserSelectionsForYear(ctx, userID, year, "track", selectionRoles)
 	if err != nil {
-		return "", fmt.Errorf("failed to get selections: %w", err)
+		return nil, fmt.Errorf("failed to get selections: %w", err)
 	}
 
 	if len(selections) == 0 {
-		return "", fmt.Errorf("no tracks found for year %d", year)
+		return nil, fmt.Errorf("no tracks found for year %d", year)
 	}
 
 	// Create Spotify client
@@ -44,7 +58,60 @@ func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID strin
 	// Get user ID from Spotify
 	user, err := client.CurrentUser(ctx)
 	if err != nil {
-		return "", fmt.Errorf("failed to get Spotify user: %w", err)
+		return nil, fmt.Errorf("failed to get Spotify user: %w", err)
+	}
+	market := user.Country
+	if market == "" {
+		market = marketFromToken
+	} else if err := s.userDAO.UpdateSpotifyMarket(ctx, userID, market); err != nil {
+		s.logger.WarnContext(ctx, "could not store spotify user market", "error", err)
+	}
+
+	response := &models.CreatePlaylistResponse{
+		Market:      market,
+		Substituted: []models.PlaylistTrackSubstitution{},
+		Skipped:     []models.PlaylistTrackSkip{},
+	}
+
+	// The same recording selected in several months, or as several Spotify copies, goes in once
+	var selectedIDs []string
+	firstCopy := map[string]string{} // group key -> first selected track ID
+	for _, selection := range selections {
+		key := selection.GroupKey()
+		first, seen := firstCopy[key]
+		switch {
+		case !seen:
+			firstCopy[key] = selection.SpotifyItemID
+			selectedIDs = append(selectedIDs, selection.SpotifyItemID)
+		case first != selection.SpotifyItemID:
+			response.Skipped = append(response.Skipped, models.PlaylistTrackSkip{SpotifyItemID: selection.SpotifyItemID, Reason: "same recording as " + first})
+		}
+	}
+
+	// Work out what can actually be played in the user's market before creating anything
+	tracks, err := s.spotifySyncService.FetchTracksForMarket(ctx, selectedIDs, market, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check track availability: %w", err)
+	}
+	var trackIDs []spotify.ID
+	for _, id := range selectedIDs {
+		track, ok := tracks[id]
+		switch {
+		case !ok:
+			response.Skipped = append(response.Skipped, models.PlaylistTrackSkip{SpotifyItemID: id, Reason: "not found on Spotify"})
+		case track.IsLocal:
+			response.Skipped = append(response.Skipped, models.PlaylistTrackSkip{SpotifyItemID: id, Reason: "local file"})
+		case !track.PlayableIn(market):
+			response.Skipped = append(response.Skipped, models.PlaylistTrackSkip{SpotifyItemID: id, Reason: "not available in " + market})
+		default:
+			if track.SpotifyID != id {
+				response.Substituted = append(response.Substituted, models.PlaylistTrackSubstitution{SpotifyItemID: id, AddedID: track.SpotifyID})
+			}
+			trackIDs = append(trackIDs, spotify.ID(track.SpotifyID))
+		}
+	}
+	if len(trackIDs) == 0 {
+		return nil, fmt.Errorf("none of the %d tracks for year %d are available in %s", len(selectedIDs), year, market)
 	}
 
 	// Create playlist
@@ -52,15 +119,10 @@ func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID strin
 	description := fmt.Sprintf("My %s tracks from %d", mode, year)
 	playlist, err := client.CreatePlaylistForUser(ctx, user.ID, playlistName, description, false, false)
 	if err != nil {
-		return "", fmt.Errorf("failed to create playlist: %w", err)
+		return nil, fmt.Errorf("failed to create playlist: %w", err)
 	}
 
 	// Add tracks in batches of 100 (Spotify API limit)
-	var trackIDs []spotify.ID
-	for _, selection := range selections {
-		trackIDs = append(trackIDs, spotify.ID(selection.SpotifyItemID))
-	}
-
 	const batchSize = 100
 	for i := 0; i < len(trackIDs); i += batchSize {
 		end := i + batchSize
@@ -70,10 +132,14 @@ func (s *PlaylistService) CreateYearlyPlaylist(ctx context.Context, userID strin
 		batch := trackIDs[i:end]
 		_, err = client.AddTracksToPlaylist(ctx, playlist.ID, batch...)
 		if err != nil {
-			return "", fmt.Errorf("failed to add tracks to playlist: %w", err)
+			return nil, fmt.Errorf("failed to add tracks to playlist: %w", err)
 		}
 	}
 
+	s.logger.InfoContext(ctx, "created yearly playlist", "year", year, "mode", mode, "market", market,
+		"added", len(trackIDs), "substituted", len(response.Substituted), "skipped", len(response.Skipped))
+	response.Added = len(trackIDs)
 	// Return the external URL of the playlist
-	return playlist.ExternalURLs["spotify"], nil
+	response.URL = playlist.ExternalURLs["spotify"]
+	return response, nil
 }
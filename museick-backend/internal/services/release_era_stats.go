@@ -0,0 +1,178 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+// A pick made within this many months of its release counts as a new release rather than catalog.
+const newReleaseWindowMonths = 12
+
+// ReleaseEraStats reports when the tracks and albums a user picked in a year were released: by decade and
+// release year, how many were new releases rather than catalog, and the oldest and newest Muse. Tracks use
+// their album's release date. Picks whose item isn't cached, or whose date is missing or invalid, count
+// towards Picks only.
+func (s *StatsService) ReleaseEraStats(ctx context.Context, userID string, year int, selectedOnly bool) (*models.ReleaseEraResponse, error) {
+	if err := validateStatsYear(year); err != nil {
+		return nil, err
+	}
+	picks, err := s.yearPicks(ctx, userID, year, selectedOnly, "track", "album")
+	if err != nil {
+		return nil, err
+	}
+	dates, err := s.releaseDates(ctx, picks)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &models.ReleaseEraResponse{
+		Year:          year,
+		SelectedOnly:  selectedOnly,
+		Picks:         len(picks),
+		ByDecade:      []models.ReleaseBucket{},
+		ByReleaseYear: []models.ReleaseBucket{},
+		NewVsCatalog:  models.NewVsCatalogStats{WindowMonths: newReleaseWindowMonths},
+	}
+	decades, years := map[int]*models.ReleaseBucket{}, map[int]*models.ReleaseBucket{}
+	var gaps []float64
+	var oldest, newest *statsPick
+	var oldestDate, newestDate models.ReleaseDate
+	for i := range picks {
+		pick := &picks[i]
+		date, ok := dates[pick.selection.ItemType+":"+pick.selection.SpotifyItemID]
+		if !ok {
+			continue
+		}
+		response.WithReleaseDate++
+		countReleaseBucket(decades, date.Decade(), fmt.Sprintf("%ds", date.Decade()), pick.muse)
+		countReleaseBucket(years, date.Year, strconv.Itoa(date.Year), pick.muse)
+
+		if pickYear, pickMonth, ok := parseMonthYear(pick.selection.MonthYear); ok {
+			minGap, maxGap := date.MonthsBefore(pickYear, pickMonth)
+			switch {
+			case maxGap < newReleaseWindowMonths: // Includes picks of items released after the month (pre-release singles)
+				response.NewVsCatalog.NewReleases++
+			case minGap >= newReleaseWindowMonths:
+				response.NewVsCatalog.Catalog++
+			default:
+				response.NewVsCatalog.Undetermined++
+			}
+			if date.Precision != models.PrecisionYear {
+				gaps = append(gaps, float64(max(minGap, 0)))
+			}
+		}
+
+		if pick.muse {
+			// Ties keep the earliest pick, since picks are in month order
+			if oldest == nil || date.SortKey() < oldestDate.SortKey() {
+				oldest, oldestDate = pick, date
+			}
+			if newest == nil || date.SortKey() > newestDate.SortKey() {
+				newest, newestDate = pick, date
+			}
+		}
+	}
+
+	response.ByDecade = sortedReleaseBuckets(decades)
+	response.ByReleaseYear = sortedReleaseBuckets(years)
+	if len(gaps) > 0 {
+		sort.Float64s(gaps)
+		median := gaps[len(gaps)/2]
+		if len(gaps)%2 == 0 {
+			median = (gaps[len(gaps)/2-1] + gaps[len(gaps)/2]) / 2
+		}
+		response.NewVsCatalog.MedianGapMonths = &median
+	}
+	if oldest != nil {
+		response.OldestMuse = releaseEraPick(oldest.selection, oldestDate)
+		response.NewestMuse = releaseEraPick(newest.selection, newestDate)
+	}
+	return response, nil
+}
+
+// releaseDates looks up the release dates of the picked tracks and albums in the cache, keyed by
+// "<item type>:<spotify ID>". Items that aren't cached or have no valid date are left out.
+func (s *StatsService) releaseDates(ctx context.Context, picks []statsPick) (map[string]models.ReleaseDate, error) {
+	var trackIDs, albumIDs []string
+	for _, pick := range picks {
+		switch pick.selection.ItemType {
+		case "track":
+			trackIDs = append(trackIDs, pick.selection.SpotifyItemID)
+		case "album":
+			albumIDs = append(albumIDs, pick.selection.SpotifyItemID)
+		}
+	}
+	dates := map[string]models.ReleaseDate{}
+	tracks, err := s.trackDAO.GetByIDs(ctx, trackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracks: %w", err)
+	}
+	for _, track := range tracks {
+		if date, ok := models.ParseReleaseDate(track.Album.ReleaseDate, track.Album.ReleaseDatePrecision); ok {
+			dates["track:"+track.SpotifyID] = date
+		}
+	}
+	albums, err := s.albumDAO.GetByIDs(ctx, albumIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load albums: %w", err)
+	}
+	for _, album := range albums {
+		if date, ok := models.ParseReleaseDate(album.ReleaseDate, album.ReleaseDatePrecision); ok {
+			dates["album:"+album.SpotifyID] = date
+		}
+	}
+	return dates, nil
+}
+
+func countReleaseBucket(buckets map[int]*models.ReleaseBucket, start int, label string, muse bool) {
+	bucket := buckets[start]
+	if bucket == nil {
+		bucket = &models.ReleaseBucket{Label: label, Start: start}
+		buckets[start] = bucket
+	}
+	if muse {
+		bucket.Muses++
+	} else {
+		bucket.Icks++
+	}
+}
+
+func sortedReleaseBuckets(buckets map[int]*models.ReleaseBucket) []models.ReleaseBucket {
+	sorted := make([]models.ReleaseBucket, 0, len(buckets))
+	for _, bucket := range buckets {
+		sorted = append(sorted, *bucket)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Start < sorted[j].Start })
+	return sorted
+}
+
+// parseMonthYear splits a selection's "YYYY-MM".
+func parseMonthYear(monthYear string) (year, month int, ok bool) {
+	if len(monthYear) != 7 || monthYear[4] != '-' {
+		return 0, 0, false
+	}
+	year, yearErr := strconv.Atoi(monthYear[:4])
+	month, monthErr := strconv.Atoi(monthYear[5:])
+	if yearErr != nil || monthErr != nil || month < 1 || month > 12 {
+		return 0, 0, false
+	}
+	return year, month, true
+}
+
+func releaseEraPick(sel *models.UserSelection, date models.ReleaseDate) *models.ReleaseEraPick {
+	return &models.ReleaseEraPick{
+		SelectionID:          sel.ID.Hex(),
+		SpotifyItemID:        sel.SpotifyItemID,
+		ItemType:             sel.ItemType,
+		ItemName:             sel.ItemName,
+		ArtistNames:          sel.ArtistNames,
+		MonthYear:            sel.MonthYear,
+		SelectionRole:        sel.SelectionRole,
+		ReleaseDate:          date.String(),
+		ReleaseDatePrecision: date.Precision,
+	}
+}
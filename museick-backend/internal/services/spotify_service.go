@@ -1,32 +1,37 @@
 package services
 
 import (
+	"context"
 	"encoding/base64" // Added for Basic Auth
 	"encoding/json"
 	"fmt"
-	"io"  // Added for reading response body
-	"log" // Added for logging
+	"io" // Added for reading response body
+	"log/slog"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"github.com/seven7een/museick/museick-backend/initializers"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
 )
 
 type SpotifyService struct {
 	ClientID     string
 	ClientSecret string
+	logger       *slog.Logger
 }
 
-func NewSpotifyService(clientID, clientSecret string) *SpotifyService {
+func NewSpotifyService(clientID, clientSecret string, logger *slog.Logger) *SpotifyService {
 	return &SpotifyService{
 		ClientID:     clientID,
 		ClientSecret: clientSecret,
+		logger:       logger.With("component", "spotify_service"),
 	}
 }
 
 // ExchangeCodeForToken exchanges the authorization code for an access token and refresh token
-func (s *SpotifyService) ExchangeCodeForToken(code, codeVerifier string) (map[string]interface{}, error) {
+func (s *SpotifyService) ExchangeCodeForToken(ctx context.Context, code, codeVerifier string) (map[string]interface{}, error) {
 	tokenURL := "https://accounts.spotify.com/api/token"
 	data := url.Values{}
 	data.Set("grant_type", "authorization_code")
@@ -35,61 +40,56 @@ func (s *SpotifyService) ExchangeCodeForToken(code, codeVerifier string) (map[st
 	data.Set("client_id", s.ClientID)
 	data.Set("code_verifier", codeVerifier)
 
-	// Log the request being sent to Spotify
-	log.Printf("Sending token exchange request to Spotify: URL=%s, Body=%s", tokenURL, data.Encode())
+	// The body carries the authorization code and PKCE verifier, so only the URL is logged
+	s.logger.DebugContext(ctx, "sending token exchange request to spotify", "url", tokenURL)
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
-		log.Printf("Error creating Spotify request: %v", err)
+		s.logger.ErrorContext(ctx, "error creating spotify request", "error", err)
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-	client := &http.Client{}
+	client := utils.NewSpotifyHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error sending request to Spotify: %v", err)
+		s.logger.ErrorContext(ctx, "error sending request to spotify", "error", err)
 		return nil, fmt.Errorf("failed to exchange code for token: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Read the raw response body first for logging
 	bodyBytes, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
-		log.Printf("Error reading Spotify response body: %v", readErr)
+		s.logger.ErrorContext(ctx, "error reading spotify response body", "error", readErr)
 		// Still try to proceed if status was OK, but log the read error
 	}
-	rawBody := string(bodyBytes) // Keep raw body for logging
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Spotify token exchange failed: Status=%d, Body=%s", resp.StatusCode, rawBody)
+		s.logger.ErrorContext(ctx, "spotify token exchange failed",
+			"status", resp.StatusCode, "spotify_error", spotifyTokenErrorDescription(bodyBytes))
 		return nil, fmt.Errorf("failed to exchange code for token, status: %d", resp.StatusCode)
 	}
 
-	log.Printf("Spotify token exchange successful: Status=%d, RawBody=%s", resp.StatusCode, rawBody)
-
 	var tokenData map[string]interface{}
 	// Use the already read bodyBytes for decoding
 	if err := json.Unmarshal(bodyBytes, &tokenData); err != nil {
-		log.Printf("Error decoding Spotify response JSON: %v, RawBody: %s", err, rawBody)
+		s.logger.ErrorContext(ctx, "error decoding spotify token response", "error", err)
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
 
-	// Log the decoded data before returning
-	log.Printf("Decoded Spotify token data: %+v", tokenData)
-
+	s.logger.InfoContext(ctx, "spotify token exchange successful", "scope", tokenData["scope"], "expires_in", tokenData["expires_in"])
 	return tokenData, nil
 }
 
 // RefreshAccessToken refreshes the access token using the refresh token
-func (s *SpotifyService) RefreshAccessToken(refreshToken string) (map[string]interface{}, error) {
+func (s *SpotifyService) RefreshAccessToken(ctx context.Context, refreshToken string) (map[string]interface{}, error) {
 	tokenURL := "https://accounts.spotify.com/api/token"
 	data := url.Values{}
 	data.Set("grant_type", "refresh_token")
 	data.Set("refresh_token", refreshToken)
 	data.Set("client_id", s.ClientID) // Client ID is needed for refresh
 
-	req, err := http.NewRequest("POST", tokenURL, strings.NewReader(data.Encode()))
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create refresh request: %w", err)
 	}
@@ -101,42 +101,102 @@ func (s *SpotifyService) RefreshAccessToken(refreshToken string) (map[string]int
 	req.Header.Set("Authorization", encodedAuth)
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 
-	// Log the refresh request being sent
-	log.Printf("Sending token refresh request to Spotify: URL=%s, Body=%s", tokenURL, data.Encode())
+	// The body carries the refresh token, so only the URL is logged
+	s.logger.DebugContext(ctx, "sending token refresh request to spotify", "url", tokenURL)
 
-	client := &http.Client{}
+	client := utils.NewSpotifyHTTPClient(0)
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("Error sending refresh request to Spotify: %v", err)
+		s.logger.ErrorContext(ctx, "error sending refresh request to spotify", "error", err)
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
 	defer resp.Body.Close()
 
-	// Read the raw response body first for logging
 	bodyBytes, readErr := io.ReadAll(resp.Body)
 	if readErr != nil {
-		log.Printf("Error reading Spotify refresh response body: %v", readErr)
+		s.logger.ErrorContext(ctx, "error reading spotify refresh response body", "error", readErr)
 	}
-	rawBody := string(bodyBytes)
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("Spotify token refresh failed: Status=%d, Body=%s", resp.StatusCode, rawBody)
+		s.logger.ErrorContext(ctx, "spotify token refresh failed",
+			"status", resp.StatusCode, "spotify_error", spotifyTokenErrorDescription(bodyBytes))
 		return nil, fmt.Errorf("failed to refresh token, status: %d", resp.StatusCode)
 	}
 
-	log.Printf("Spotify token refresh successful: Status=%d, RawBody=%s", resp.StatusCode, rawBody)
-
 	var tokenData map[string]interface{}
 	if err := json.Unmarshal(bodyBytes, &tokenData); err != nil {
-		log.Printf("Error decoding Spotify refresh response JSON: %v, RawBody: %s", err, rawBody)
+		s.logger.ErrorContext(ctx, "error decoding spotify refresh response", "error", err)
 		return nil, fmt.Errorf("failed to decode refresh response: %w", err)
 	}
 
-	// Log the decoded data before returning
-	log.Printf("Decoded Spotify refresh token data: %+v", tokenData)
+	_, rotated := tokenData["refresh_token"]
+	s.logger.InfoContext(ctx, "spotify token refresh successful", "expires_in", tokenData["expires_in"], "refresh_rotated", rotated)
 
 	// Note: The refresh response might not include a new refresh_token.
 	// If it does, we should securely store the new one.
 	// If it doesn't, the original refresh_token remains valid.
 	return tokenData, nil
 }
+
+// ClientCredentialsToken gets an app access token (no user context) for catalog endpoints.
+func (s *SpotifyService) ClientCredentialsToken(ctx context.Context) (string, error) {
+	tokenURL := "https://accounts.spotify.com/api/token"
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+
+	req, err := http.NewRequestWithContext(ctx, "POST", tokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create client credentials request: %w", err)
+	}
+	req.Header.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(s.ClientID+":"+s.ClientSecret)))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := utils.NewSpotifyHTTPClient(10 * time.Second)
+	resp, err := client.Do(req)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error sending client credentials request to spotify", "error", err)
+		return "", fmt.Errorf("failed to get client credentials token: %w", err)
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		s.logger.ErrorContext(ctx, "spotify client credentials request failed",
+			"status", resp.StatusCode, "spotify_error", spotifyTokenErrorDescription(bodyBytes))
+		return "", fmt.Errorf("failed to get client credentials token, status: %d", resp.StatusCode)
+	}
+
+	var tokenData struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.Unmarshal(bodyBytes, &tokenData); err != nil || tokenData.AccessToken == "" {
+		return "", fmt.Errorf("failed to decode client credentials response")
+	}
+	return tokenData.AccessToken, nil
+}
+
+// UserMarket returns the country of the Spotify account behind accessToken (ISO 3166-1 alpha-2), which
+// Spotify treats as the user's market. It is empty if the token was granted without user-read-private.
+func (s *SpotifyService) UserMarket(ctx context.Context, accessToken string) (string, error) {
+	user, err := utils.CreateTemporarySpotifyClient(ctx, accessToken).CurrentUser(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Spotify user: %w", err)
+	}
+	return user.Country, nil
+}
+
+// spotifyTokenErrorDescription extracts the OAuth error fields from a failed token response.
+// Error responses never carry tokens, but the body is not logged verbatim in case that changes.
+func spotifyTokenErrorDescription(body []byte) string {
+	var oauthErr struct {
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.Unmarshal(body, &oauthErr); err != nil || oauthErr.Error == "" {
+		return "unrecognized error response"
+	}
+	if oauthErr.ErrorDescription == "" {
+		return oauthErr.Error
+	}
+	return oauthErr.Error + ": " + oauthErr.ErrorDescription
+}
@@ -4,22 +4,32 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
+	"net/url"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
 	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/tracing"
 	"github.com/seven7een/museick/museick-backend/internal/utils"
 	"github.com/zmb3/spotify/v2"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // SpotifySyncService handles fetching data from Spotify API and syncing it to the database.
 type SpotifySyncService struct {
-	trackDAO  dao.SpotifyTrackDAO
-	albumDAO  dao.SpotifyAlbumDAO
-	artistDAO dao.SpotifyArtistDAO
+	trackDAO          dao.SpotifyTrackDAO
+	albumDAO          dao.SpotifyAlbumDAO
+	artistDAO         dao.SpotifyArtistDAO
+	relatedArtistsDAO dao.SpotifyRelatedArtistsDAO
+	topTracksDAO      dao.SpotifyArtistTopTracksDAO
+	audioFeatures     AudioFeaturesProvider // nil disables audio features
+	logger            *slog.Logger
 }
 
 // NewSpotifySyncService creates a new instance of SpotifySyncService.
@@ -27,26 +37,38 @@ func NewSpotifySyncService(
 	trackDAO dao.SpotifyTrackDAO,
 	albumDAO dao.SpotifyAlbumDAO,
 	artistDAO dao.SpotifyArtistDAO,
+	relatedArtistsDAO dao.SpotifyRelatedArtistsDAO,
+	topTracksDAO dao.SpotifyArtistTopTracksDAO,
+	audioFeatures AudioFeaturesProvider,
+	logger *slog.Logger,
 ) *SpotifySyncService {
-	log.Println("Initializing SpotifySyncService")
+	logger = logger.With("component", "spotify_sync_service")
+	logger.Info("initializing SpotifySyncService")
 	return &SpotifySyncService{
-		trackDAO:  trackDAO,
-		albumDAO:  albumDAO,
-		artistDAO: artistDAO,
+		trackDAO:          trackDAO,
+		albumDAO:          albumDAO,
+		artistDAO:         artistDAO,
+		relatedArtistsDAO: relatedArtistsDAO,
+		topTracksDAO:      topTracksDAO,
+		audioFeatures:     audioFeatures,
+		logger:            logger,
 	}
 }
 
 // SyncItem fetches details for a Spotify item using a provided client
 // and upserts it into the corresponding database collection.
-func (s *SpotifySyncService) SyncItem(ctx context.Context, spotifyID string, itemType string, client *spotify.Client) error {
+func (s *SpotifySyncService) SyncItem(ctx context.Context, spotifyID string, itemType string, client *spotify.Client) (err error) {
+	ctx, span := tracing.Start(ctx, "SpotifySyncService.SyncItem",
+		attribute.String("spotify.item_id", spotifyID), attribute.String("spotify.item_type", itemType))
+	defer func() { tracing.End(span, err) }()
+
 	if client == nil {
-		log.Println("Error: Spotify client is nil in SyncItem")
+		s.logger.ErrorContext(ctx, "spotify client is nil in SyncItem")
 		return errors.New("spotify client not available for sync")
 	}
 
-	log.Printf("Syncing Spotify item: ID=%s, Type=%s", spotifyID, itemType)
+	s.logger.DebugContext(ctx, "syncing spotify item", "spotify_id", spotifyID, "item_type", itemType)
 
-	var err error
 	switch itemType {
 	case "track":
 		err = s.syncTrack(ctx, spotifyID, client)
@@ -59,11 +81,11 @@ func (s *SpotifySyncService) SyncItem(ctx context.Context, spotifyID string, ite
 	}
 
 	if err != nil {
-		log.Printf("Error syncing item %s (%s): %v", spotifyID, itemType, err)
+		s.logger.ErrorContext(ctx, "error syncing spotify item", "spotify_id", spotifyID, "item_type", itemType, "error", err)
 		return err // Return the specific sync error
 	}
 
-	log.Printf("Successfully synced item %s (%s)", spotifyID, itemType)
+	s.logger.InfoContext(ctx, "synced spotify item", "spotify_id", spotifyID, "item_type", itemType)
 	return nil
 }
 
@@ -74,17 +96,99 @@ func (s *SpotifySyncService) syncTrack(ctx context.Context, spotifyID string, cl
 		return fmt.Errorf("failed to get track from Spotify API: %w", err)
 	}
 	dbTrack := mapSpotifyTrackToDBTrackModel(fullTrack)
+	s.attachAudioFeatures(ctx, []*models.SpotifyTrack{dbTrack}, client)
 	return s.trackDAO.Upsert(ctx, dbTrack)
 }
 
-// syncAlbum fetches album details and upserts to DB.
+// attachAudioFeatures sets AudioFeatures on the tracks the provider knows. Failures are only logged: features
+// are an extra, and a track upserted without them keeps the ones already cached.
+func (s *SpotifySyncService) attachAudioFeatures(ctx context.Context, tracks []*models.SpotifyTrack, client *spotify.Client) {
+	if s.audioFeatures == nil {
+		return
+	}
+	ids := make([]string, 0, len(tracks))
+	for _, track := range tracks {
+		if !track.IsLocal { // Local files have no analysis
+			ids = append(ids, track.SpotifyID)
+		}
+	}
+	if len(ids) == 0 {
+		return
+	}
+	features, err := s.audioFeatures.GetAudioFeatures(ctx, ids, client)
+	if err != nil {
+		s.logger.WarnContext(ctx, "could not fetch audio features", "count", len(ids), "error", err)
+	}
+	for _, track := range tracks {
+		if f := features[track.SpotifyID]; f != nil {
+			track.AudioFeatures = f
+		}
+	}
+}
+
+// syncAlbum fetches album details, including the full tracklist, and upserts to DB.
 func (s *SpotifySyncService) syncAlbum(ctx context.Context, spotifyID string, client *spotify.Client) error {
-	fullAlbum, err := client.GetAlbum(ctx, spotify.ID(spotifyID))
+	albums, err := s.fetchAlbums(ctx, []spotify.ID{spotify.ID(spotifyID)}, client)
 	if err != nil {
 		return fmt.Errorf("failed to get album from Spotify API: %w", err)
 	}
-	dbAlbum := mapSpotifyAlbumToDBModel(fullAlbum) // Map from FullAlbum
-	return s.albumDAO.Upsert(ctx, dbAlbum)
+	if len(albums) == 0 || albums[0] == nil {
+		return errors.New("spotify item not found")
+	}
+	return s.albumDAO.Upsert(ctx, mapSpotifyAlbumToDBModel(albums[0]))
+}
+
+// spotifyFullAlbum is an album object as the Web API returns it. The spotify library's FullAlbum
+// has no label field, so albums are fetched with utils.GetSpotifyJSON into this instead.
+type spotifyFullAlbum struct {
+	spotify.FullAlbum
+	Label string `json:"label"`
+}
+
+// Album objects include the first page of tracks (50); longer albums are paged in during sync.
+// This caps the extra requests for very long compilations.
+const maxAlbumTracklistPages = 20
+
+// fetchAlbums calls Spotify's several-albums endpoint and completes each album's tracklist.
+// The result is aligned with ids; albums Spotify could not find are nil.
+func (s *SpotifySyncService) fetchAlbums(ctx context.Context, ids []spotify.ID, client *spotify.Client) ([]*spotifyFullAlbum, error) {
+	idStrings := make([]string, len(ids))
+	for i, id := range ids {
+		idStrings[i] = id.String()
+	}
+	var resp struct {
+		Albums []*spotifyFullAlbum `json:"albums"`
+	}
+	if err := utils.GetSpotifyJSON(ctx, client, "albums", url.Values{"ids": {strings.Join(idStrings, ",")}}, &resp); err != nil {
+		return nil, err
+	}
+	for _, album := range resp.Albums {
+		if album != nil {
+			s.fetchRemainingAlbumTracks(ctx, album, client)
+		}
+	}
+	return resp.Albums, nil
+}
+
+// fetchRemainingAlbumTracks appends the album's remaining track pages to its first page.
+// A failure keeps what was fetched so far: the album is still worth caching without its tail,
+// and the next refresh tries again.
+func (s *SpotifySyncService) fetchRemainingAlbumTracks(ctx context.Context, album *spotifyFullAlbum, client *spotify.Client) {
+	page := album.Tracks
+	for i := 0; i < maxAlbumTracklistPages && page.Next != ""; i++ {
+		if err := client.NextPage(ctx, &page); err != nil {
+			if !errors.Is(err, spotify.ErrNoMorePages) {
+				s.logger.WarnContext(ctx, "error fetching album tracklist page, caching partial tracklist",
+					"spotify_id", album.ID.String(), "fetched", len(album.Tracks.Tracks), "total", int(album.Tracks.Total), "error", err)
+			}
+			return
+		}
+		album.Tracks.Tracks = append(album.Tracks.Tracks, page.Tracks...)
+	}
+	if page.Next != "" {
+		s.logger.WarnContext(ctx, "album tracklist truncated", "spotify_id", album.ID.String(),
+			"fetched", len(album.Tracks.Tracks), "total", int(album.Tracks.Total))
+	}
 }
 
 // syncArtist fetches artist details and upserts to DB.
@@ -97,16 +201,229 @@ func (s *SpotifySyncService) syncArtist(ctx context.Context, spotifyID string, c
 	return s.artistDAO.Upsert(ctx, dbArtist)
 }
 
+// Maximum IDs accepted by Spotify's "get several" endpoints.
+const (
+	maxSpotifyTracksPerRequest  = 50
+	maxSpotifyAlbumsPerRequest  = 20
+	maxSpotifyArtistsPerRequest = 50
+)
+
+// spotifyIDRegex matches a base-62 Spotify ID. The several-items endpoints reject the whole
+// request with a 400 if any ID is malformed, so we filter those out before calling them.
+var spotifyIDRegex = regexp.MustCompile(`^[0-9A-Za-z]{22}$`)
+
+// SyncItems fetches several items of the same type using Spotify's "get several" endpoints
+// and upserts each found item into the cache. This both verifies the items exist and refreshes
+// the cache in one request per chunk. Returned items are keyed by Spotify ID; IDs that could
+// not be fetched are reported in the failures map instead.
+func (s *SpotifySyncService) SyncItems(ctx context.Context, spotifyIDs []string, itemType string, client *spotify.Client) (map[string]interface{}, map[string]error) {
+	items := make(map[string]interface{}, len(spotifyIDs))
+	failures := make(map[string]error)
+
+	ctx, span := tracing.Start(ctx, "SpotifySyncService.SyncItems",
+		attribute.String("spotify.item_type", itemType), attribute.Int("spotify.requested", len(spotifyIDs)))
+	defer func() {
+		span.SetAttributes(attribute.Int("spotify.synced", len(items)), attribute.Int("spotify.failed", len(failures)))
+		span.End()
+	}()
+	if client == nil {
+		for _, id := range spotifyIDs {
+			failures[id] = errors.New("spotify client not available for sync")
+		}
+		return items, failures
+	}
+
+	var chunkSize int
+	switch itemType {
+	case "track":
+		chunkSize = maxSpotifyTracksPerRequest
+	case "album":
+		chunkSize = maxSpotifyAlbumsPerRequest
+	case "artist":
+		chunkSize = maxSpotifyArtistsPerRequest
+	default:
+		for _, id := range spotifyIDs {
+			failures[id] = fmt.Errorf("unsupported spotify item type: %s", itemType)
+		}
+		return items, failures
+	}
+
+	// De-duplicate and drop malformed IDs
+	seen := make(map[string]bool, len(spotifyIDs))
+	var ids []spotify.ID
+	for _, id := range spotifyIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if !spotifyIDRegex.MatchString(id) {
+			failures[id] = fmt.Errorf("invalid spotify ID: %s", id)
+			continue
+		}
+		ids = append(ids, spotify.ID(id))
+	}
+
+	s.logger.DebugContext(ctx, "bulk syncing spotify items", "count", len(ids), "item_type", itemType)
+	for start := 0; start < len(ids); start += chunkSize {
+		end := start + chunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		chunk := ids[start:end]
+
+		fetched, err := s.fetchSeveral(ctx, chunk, itemType, client)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "error bulk fetching spotify items", "count", len(chunk), "item_type", itemType, "error", err)
+			for _, id := range chunk {
+				failures[id.String()] = err
+			}
+			continue
+		}
+		var foundIDs []string
+		var found []interface{}
+		for i, id := range chunk {
+			if fetched[i] == nil {
+				failures[id.String()] = errors.New("spotify item not found")
+				continue
+			}
+			foundIDs = append(foundIDs, id.String())
+			found = append(found, fetched[i])
+		}
+		if err := s.bulkUpsertItems(ctx, found); err != nil {
+			for _, id := range foundIDs {
+				failures[id] = fmt.Errorf("failed to cache spotify item: %w", err)
+			}
+			continue
+		}
+		for i, id := range foundIDs {
+			items[id] = found[i]
+		}
+	}
+	return items, failures
+}
+
+// FetchTracksForMarket fetches tracks as they are playable in market, so IsPlayable and LinkedFrom are set.
+// When Spotify relinks a track, the returned track is the playable copy and LinkedFrom names the requested one.
+// market may also be "from_token" to use the market of the client's user. The results are keyed by requested ID
+// and, unlike SyncItems, not written to the cache: market-specific responses omit AvailableMarkets, which the
+// shared cache relies on.
+func (s *SpotifySyncService) FetchTracksForMarket(ctx context.Context, spotifyIDs []string, market string, client *spotify.Client) (_ map[string]*models.SpotifyTrack, err error) {
+	ctx, span := tracing.Start(ctx, "SpotifySyncService.FetchTracksForMarket",
+		attribute.String("spotify.market", market), attribute.Int("spotify.requested", len(spotifyIDs)))
+	defer func() { tracing.End(span, err) }()
+
+	tracks := make(map[string]*models.SpotifyTrack, len(spotifyIDs))
+	var ids []spotify.ID
+	for _, id := range spotifyIDs {
+		if _, seen := tracks[id]; !seen && spotifyIDRegex.MatchString(id) {
+			tracks[id] = nil
+			ids = append(ids, spotify.ID(id))
+		}
+	}
+	for start := 0; start < len(ids); start += maxSpotifyTracksPerRequest {
+		end := start + maxSpotifyTracksPerRequest
+		if end > len(ids) {
+			end = len(ids)
+		}
+		fetched, err := client.GetTracks(ctx, ids[start:end], spotify.Market(market))
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks from Spotify API: %w", err)
+		}
+		for i, id := range ids[start:end] {
+			if i < len(fetched) && fetched[i] != nil {
+				track := mapSpotifyTrackToDBTrackModel(fetched[i])
+				track.Market = market
+				tracks[id.String()] = track
+			}
+		}
+	}
+	for id, track := range tracks {
+		if track == nil {
+			delete(tracks, id)
+		}
+	}
+	return tracks, nil
+}
+
+// fetchSeveral calls the "get several" endpoint for the item type and maps the results to
+// our DB models. The result is aligned with ids; items Spotify could not find are nil.
+func (s *SpotifySyncService) fetchSeveral(ctx context.Context, ids []spotify.ID, itemType string, client *spotify.Client) ([]interface{}, error) {
+	results := make([]interface{}, len(ids))
+	switch itemType {
+	case "track":
+		tracks, err := client.GetTracks(ctx, ids)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get tracks from Spotify API: %w", err)
+		}
+		var mapped []*models.SpotifyTrack
+		for i := range ids {
+			if i < len(tracks) && tracks[i] != nil {
+				track := mapSpotifyTrackToDBTrackModel(tracks[i])
+				results[i] = track
+				mapped = append(mapped, track)
+			}
+		}
+		s.attachAudioFeatures(ctx, mapped, client)
+	case "album":
+		albums, err := s.fetchAlbums(ctx, ids, client)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get albums from Spotify API: %w", err)
+		}
+		for i := range ids {
+			if i < len(albums) && albums[i] != nil {
+				results[i] = mapSpotifyAlbumToDBModel(albums[i])
+			}
+		}
+	case "artist":
+		artists, err := client.GetArtists(ctx, ids...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get artists from Spotify API: %w", err)
+		}
+		for i := range ids {
+			if i < len(artists) && artists[i] != nil {
+				results[i] = mapSpotifyArtistToDBModel(artists[i])
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unsupported spotify item type: %s", itemType)
+	}
+	return results, nil
+}
+
+// bulkUpsertItems writes mapped DB models of one type to the matching cache collection in a single BulkWrite.
+func (s *SpotifySyncService) bulkUpsertItems(ctx context.Context, items []interface{}) error {
+	if len(items) == 0 {
+		return nil
+	}
+	switch items[0].(type) {
+	case *models.SpotifyTrack:
+		tracks := make([]*models.SpotifyTrack, len(items))
+		for i, item := range items {
+			tracks[i] = item.(*models.SpotifyTrack)
+		}
+		return s.trackDAO.BulkUpsert(ctx, tracks)
+	case *models.SpotifyAlbum:
+		albums := make([]*models.SpotifyAlbum, len(items))
+		for i, item := range items {
+			albums[i] = item.(*models.SpotifyAlbum)
+		}
+		return s.albumDAO.BulkUpsert(ctx, albums)
+	case *models.SpotifyArtist:
+		artists := make([]*models.SpotifyArtist, len(items))
+		for i, item := range items {
+			artists[i] = item.(*models.SpotifyArtist)
+		}
+		return s.artistDAO.BulkUpsert(ctx, artists)
+	}
+	return fmt.Errorf("unsupported cache item type %T", items[0])
+}
+
 // --- Mapping Functions ---
 
 func mapSpotifyTrackToDBTrackModel(st *spotify.FullTrack) *models.SpotifyTrack {
 	if st == nil {
 		return nil
 	}
-	isPlayable := false // Default value if IsPlayable is nil
-	if st.IsPlayable != nil {
-		isPlayable = *st.IsPlayable
-	}
 	return &models.SpotifyTrack{
 		SpotifyID:        st.ID.String(), // Use SpotifyID as _id
 		Name:             st.Name,
@@ -121,17 +438,35 @@ func mapSpotifyTrackToDBTrackModel(st *spotify.FullTrack) *models.SpotifyTrack {
 		AvailableMarkets: st.AvailableMarkets,
 		DiscNumber:       int(st.DiscNumber),
 		ExternalIDs:      st.ExternalIDs,
+		CanonicalID:      models.TrackCanonicalID(st.ID.String(), st.ExternalIDs),
 		Type:             string(st.Type),
 		URI:              string(st.URI),
-		IsLocal:          isPlayable, // Note: Library uses IsPlayable (*bool), model uses IsLocal (bool)
+		IsLocal:          strings.HasPrefix(string(st.URI), "spotify:local:"), // The library's FullTrack has no is_local
+		IsPlayable:       st.IsPlayable,
+		LinkedFrom:       mapSpotifyLinkedFromToDBModel(st.LinkedFrom),
 		LastFetchedAt:    primitive.NewDateTimeFromTime(time.Now()),
 	}
 }
 
-func mapSpotifyAlbumToDBModel(fa *spotify.FullAlbum) *models.SpotifyAlbum {
+func mapSpotifyLinkedFromToDBModel(lf *spotify.LinkedFromInfo) *models.LinkedTrack {
+	if lf == nil || lf.ID == "" {
+		return nil
+	}
+	return &models.LinkedTrack{
+		ID:           lf.ID.String(),
+		URI:          lf.URI,
+		ExternalUrls: lf.ExternalURLs,
+	}
+}
+
+func mapSpotifyAlbumToDBModel(fa *spotifyFullAlbum) *models.SpotifyAlbum {
 	if fa == nil {
 		return nil
 	}
+	var copyrights []models.Copyright
+	for _, c := range fa.Copyrights {
+		copyrights = append(copyrights, models.Copyright{Text: c.Text, Type: c.Type})
+	}
 	// Access SimpleAlbum fields via fa.SimpleAlbum embedding
 	return &models.SpotifyAlbum{
 		SpotifyID:        fa.ID.String(), // Use SpotifyID as _id
@@ -145,14 +480,42 @@ func mapSpotifyAlbumToDBModel(fa *spotify.FullAlbum) *models.SpotifyAlbum {
 		ReleaseDate:          fa.ReleaseDate,
 		ReleaseDatePrecision: fa.ReleaseDatePrecision,
 		// Restrictions:         mapRestrictions(fa.Restrictions), // Not in the Go Api we're using
-		Type:    string(fa.AlbumType), // Use AlbumType from FullAlbum as Type
-		URI:     string(fa.URI),
-		Artists: mapSpotifySimpleArtistsToDBModels(fa.Artists), // Map embedded SimpleArtists
-		// TODO: Add Genres, Label, Copyrights if they exist in models.SpotifyAlbum and fa.FullAlbum
+		Type:          string(fa.AlbumType), // Use AlbumType from FullAlbum as Type
+		URI:           string(fa.URI),
+		Artists:       mapSpotifySimpleArtistsToDBModels(fa.Artists), // Map embedded SimpleArtists
+		Label:         fa.Label,
+		Copyrights:    copyrights,
+		Genres:        fa.Genres,
+		Popularity:    int(fa.Popularity),
+		ExternalIDs:   fa.ExternalIDs,
+		CanonicalID:   models.AlbumCanonicalID(fa.ID.String(), fa.ExternalIDs),
+		Tracks:        mapSpotifySimpleTracksToDBModels(fa.Tracks.Tracks),
 		LastFetchedAt: primitive.NewDateTimeFromTime(time.Now()),
 	}
 }
 
+func mapSpotifySimpleTracksToDBModels(sts []spotify.SimpleTrack) []models.SimplifiedTrack {
+	if sts == nil {
+		return nil
+	}
+	dbTracks := make([]models.SimplifiedTrack, len(sts))
+	for i, st := range sts {
+		dbTracks[i] = models.SimplifiedTrack{
+			ID:           st.ID.String(),
+			Name:         st.Name,
+			Artists:      mapSpotifySimpleArtistsToDBModels(st.Artists),
+			DiscNumber:   int(st.DiscNumber),
+			TrackNumber:  int(st.TrackNumber),
+			DurationMs:   int(st.Duration),
+			Explicit:     st.Explicit,
+			PreviewURL:   st.PreviewURL,
+			ExternalUrls: st.ExternalURLs,
+			URI:          string(st.URI),
+		}
+	}
+	return dbTracks
+}
+
 func mapSpotifySimpleAlbumToDBModel(sa *spotify.SimpleAlbum) *models.SimplifiedAlbum {
 	if sa == nil {
 		return nil
@@ -190,12 +553,21 @@ func mapSpotifyArtistToDBModel(fa *spotify.FullArtist) *models.SpotifyArtist {
 		Name:       fa.Name,
 		Popularity: &popularity,
 		// Type:         string(fa.Type), // Not in the Go Api we're using
-		URI: string(fa.URI),
-		// TODO: Map Followers if needed (fa.Followers.Count)
+		URI:           string(fa.URI),
+		Followers:     mapSpotifyFollowersToDBModel(fa.Followers),
 		LastFetchedAt: primitive.NewDateTimeFromTime(time.Now()),
 	}
 }
 
+func mapSpotifyFollowersToDBModel(f spotify.Followers) *models.FollowersObject {
+	followers := &models.FollowersObject{Total: int(f.Count)}
+	if f.Endpoint != "" {
+		href := f.Endpoint
+		followers.Href = &href
+	}
+	return followers
+}
+
 func mapSpotifySimpleArtistsToDBModels(sas []spotify.SimpleArtist) []models.SimplifiedArtist {
 	if sas == nil {
 		return nil
@@ -251,14 +623,17 @@ func needsRefresh(lastFetched time.Time, threshold time.Duration) bool {
 
 // GetOrSyncItem tries to get an item from the DB first. If not found or stale,
 // it fetches from Spotify API, upserts to DB, and returns the item.
-func (s *SpotifySyncService) GetOrSyncItem(ctx context.Context, spotifyID string, itemType string, spotifyToken string, refreshThreshold time.Duration) (interface{}, error) {
+func (s *SpotifySyncService) GetOrSyncItem(ctx context.Context, spotifyID string, itemType string, spotifyToken string, refreshThreshold time.Duration) (_ interface{}, err error) {
+	ctx, span := tracing.Start(ctx, "SpotifySyncService.GetOrSyncItem",
+		attribute.String("spotify.item_id", spotifyID), attribute.String("spotify.item_type", itemType))
+	defer func() { tracing.End(span, err) }()
+
 	if spotifyToken == "" {
-		log.Println("Warning: Spotify token is missing in GetOrSyncItem, cannot sync if needed.")
+		s.logger.WarnContext(ctx, "spotify token is missing in GetOrSyncItem, cannot sync if needed")
 	}
 
 	var dbItem interface{}
 	var lastFetched time.Time
-	var err error
 	var foundInDB bool
 
 	// 1. Try to get from DB using GetByID
@@ -296,14 +671,23 @@ func (s *SpotifySyncService) GetOrSyncItem(ctx context.Context, spotifyID string
 
 	// 2. Check if refresh is needed (not found or stale)
 	needsSync := !foundInDB || needsRefresh(lastFetched, refreshThreshold)
+	cacheResult := metrics.CacheHit
+	switch {
+	case !foundInDB:
+		cacheResult = metrics.CacheMiss
+	case needsSync:
+		cacheResult = metrics.CacheStale
+	}
+	metrics.RecordCacheLookup(itemType, cacheResult)
+	span.SetAttributes(attribute.String("cache.result", cacheResult))
 
 	if needsSync {
-		log.Printf("Item %s (%s) not found in DB or needs refresh. Attempting sync...", spotifyID, itemType)
+		s.logger.DebugContext(ctx, "item not found in DB or needs refresh, attempting sync", "spotify_id", spotifyID, "item_type", itemType, "found_in_db", foundInDB)
 
 		if spotifyToken == "" {
-			log.Printf("Cannot sync item %s (%s): Spotify token is missing.", spotifyID, itemType)
+			s.logger.WarnContext(ctx, "cannot sync item, spotify token is missing", "spotify_id", spotifyID, "item_type", itemType)
 			if foundInDB {
-				log.Println("Returning stale item as sync is not possible without token.")
+				s.logger.WarnContext(ctx, "returning stale item as sync is not possible without token", "spotify_id", spotifyID)
 				return dbItem, nil
 			}
 			return nil, errors.New("cannot sync item: spotify token missing")
@@ -316,7 +700,7 @@ func (s *SpotifySyncService) GetOrSyncItem(ctx context.Context, spotifyID string
 		err = s.SyncItem(ctx, spotifyID, itemType, client)
 		if err != nil {
 			if foundInDB {
-				log.Printf("Sync failed for stale item %s (%s), returning stale data. Error: %v", spotifyID, itemType, err)
+				s.logger.WarnContext(ctx, "sync failed for stale item, returning stale data", "spotify_id", spotifyID, "item_type", itemType, "error", err)
 				return dbItem, nil // Return stale data if sync fails
 			}
 			return nil, fmt.Errorf("failed to sync item from Spotify: %w", err) // Sync failed and no stale data
@@ -334,9 +718,9 @@ func (s *SpotifySyncService) GetOrSyncItem(ctx context.Context, spotifyID string
 		if err != nil {
 			return nil, fmt.Errorf("failed to get item from DB after sync: %w", err)
 		}
-		log.Printf("Successfully synced and retrieved item %s (%s).", spotifyID, itemType)
+		s.logger.DebugContext(ctx, "synced and retrieved item", "spotify_id", spotifyID, "item_type", itemType)
 	} else {
-		log.Printf("Item %s (%s) found in DB and is fresh.", spotifyID, itemType)
+		s.logger.DebugContext(ctx, "item found in DB and is fresh", "spotify_id", spotifyID, "item_type", itemType)
 	}
 
 	return dbItem, nil
@@ -0,0 +1,134 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/zmb3/spotify/v2"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeTrackDAO serves cached tracks from memory.
+type fakeTrackDAO struct {
+	dao.SpotifyTrackDAO
+	tracks map[string]*models.SpotifyTrack
+}
+
+func (f *fakeTrackDAO) GetByID(ctx context.Context, spotifyID string) (*models.SpotifyTrack, error) {
+	if track, ok := f.tracks[spotifyID]; ok {
+		return track, nil
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeTrackDAO) GetByIDs(ctx context.Context, spotifyIDs []string) ([]*models.SpotifyTrack, error) {
+	var found []*models.SpotifyTrack
+	for _, id := range spotifyIDs {
+		if track, ok := f.tracks[id]; ok {
+			found = append(found, track)
+		}
+	}
+	return found, nil
+}
+
+// albumJSON is a trimmed several-albums entry, with the fields the spotify library doesn't model.
+const albumJSON = `{
+	"id": "4aawyAB9vmqN3uQ7FjRGTy", "name": "Global Warming", "album_type": "album", "uri": "spotify:album:4aawyAB9vmqN3uQ7FjRGTy",
+	"release_date": "2012-11-16", "release_date_precision": "day", "total_tracks": 3,
+	"artists": [{"id": "0TnOYISbd1XYRBk9myaseg", "name": "Pitbull"}],
+	"label": "Mr.305/Polo Grounds Music/RCA Records",
+	"copyrights": [{"text": "(P) 2012 RCA Records", "type": "P"}],
+	"genres": ["latin pop"], "popularity": 57, "external_ids": {"upc": "886443671584"},
+	"tracks": {"total": 3, "next": "%s", "items": [
+		{"id": "6OmhkSOpvYBokMKQxpIGx2", "name": "Global Warming", "disc_number": 1, "track_number": 1, "duration_ms": 85400, "explicit": true,
+		 "artists": [{"id": "0TnOYISbd1XYRBk9myaseg", "name": "Pitbull"}], "uri": "spotify:track:6OmhkSOpvYBokMKQxpIGx2"}
+	]}
+}`
+
+func decodeAlbum(t *testing.T, next string) *spotifyFullAlbum {
+	t.Helper()
+	var album spotifyFullAlbum
+	if err := json.Unmarshal([]byte(fmt.Sprintf(albumJSON, next)), &album); err != nil {
+		t.Fatalf("decoding album fixture: %v", err)
+	}
+	return &album
+}
+
+func TestMapSpotifyAlbumToDBModel(t *testing.T) {
+	album := mapSpotifyAlbumToDBModel(decodeAlbum(t, ""))
+	if album.Label != "Mr.305/Polo Grounds Music/RCA Records" || album.Popularity != 57 || album.ExternalIDs["upc"] != "886443671584" {
+		t.Errorf("label %q, popularity %d, external IDs %v; want the album's", album.Label, album.Popularity, album.ExternalIDs)
+	}
+	if !reflect.DeepEqual(album.Copyrights, []models.Copyright{{Text: "(P) 2012 RCA Records", Type: "P"}}) || !reflect.DeepEqual(album.Genres, []string{"latin pop"}) {
+		t.Errorf("copyrights %+v, genres %q; want the album's", album.Copyrights, album.Genres)
+	}
+	if len(album.Tracks) != 1 {
+		t.Fatalf("tracks = %+v, want one", album.Tracks)
+	}
+	track := album.Tracks[0]
+	if track.ID != "6OmhkSOpvYBokMKQxpIGx2" || track.DiscNumber != 1 || track.TrackNumber != 1 || track.DurationMs != 85400 || !track.Explicit ||
+		len(track.Artists) != 1 || track.Artists[0].Name != "Pitbull" {
+		t.Errorf("track = %+v, want the tracklist entry", track)
+	}
+	if mapSpotifyAlbumToDBModel(nil) != nil {
+		t.Error("mapSpotifyAlbumToDBModel(nil) is not nil")
+	}
+}
+
+func TestFetchRemainingAlbumTracks(t *testing.T) {
+	page := func(next string, ids ...string) string {
+		items := make([]string, len(ids))
+		for i, id := range ids {
+			items[i] = fmt.Sprintf(`{"id": %q, "name": %q}`, id, id)
+		}
+		return fmt.Sprintf(`{"total": 5, "next": %q, "items": [%s]}`, next, strings.Join(items, ","))
+	}
+	tests := []struct {
+		name     string
+		pages    map[string]string // Path to body; missing paths answer 500
+		wantIDs  []string
+		firstURL string
+	}{
+		{"all pages", map[string]string{"/p2": page("/p3", "t2", "t3"), "/p3": page("", "t4", "t5")}, []string{"6OmhkSOpvYBokMKQxpIGx2", "t2", "t3", "t4", "t5"}, "/p2"},
+		{"failure keeps the pages fetched so far", map[string]string{"/p2": page("/p3", "t2", "t3")}, []string{"6OmhkSOpvYBokMKQxpIGx2", "t2", "t3"}, "/p2"},
+		{"single page", nil, []string{"6OmhkSOpvYBokMKQxpIGx2"}, ""},
+	}
+	for _, tt := range tests {
+		var server *httptest.Server
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, ok := tt.pages[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			// Next links are absolute, as Spotify sends them
+			w.Write([]byte(strings.Replace(body, `"next": "/`, `"next": "`+server.URL+"/", 1)))
+		}))
+		next := tt.firstURL
+		if next != "" {
+			next = server.URL + next
+		}
+		album := decodeAlbum(t, next)
+		service := &SpotifySyncService{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+		service.fetchRemainingAlbumTracks(context.Background(), album, spotify.New(server.Client()))
+
+		var ids []string
+		for _, track := range album.Tracks.Tracks {
+			ids = append(ids, track.ID.String())
+		}
+		if !reflect.DeepEqual(ids, tt.wantIDs) {
+			t.Errorf("%s: tracklist %v, want %v", tt.name, ids, tt.wantIDs)
+		}
+		server.Close()
+	}
+}
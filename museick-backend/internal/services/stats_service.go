@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+const (
+	minStatsYear = 1900
+	maxStatsYear = 2100
+)
+
+// ErrInvalidStatsRequest is returned when analytics are asked for with invalid parameters.
+var ErrInvalidStatsRequest = errors.New("invalid stats request")
+
+// Histogram ranges per audio feature: the unit-interval features in tenths, tempo in 20 BPM steps.
+var featureHistogramRanges = map[string]struct {
+	from, to float64
+	bins     int
+}{
+	models.FeatureValence:      {0, 1, 10},
+	models.FeatureEnergy:       {0, 1, 10},
+	models.FeatureDanceability: {0, 1, 10},
+	models.FeatureAcousticness: {0, 1, 10},
+	models.FeatureTempo:        {40, 220, 9},
+}
+
+// StatsService computes analytics over a user's selections and the Spotify cache.
+type StatsService struct {
+	selectionDAO dao.UserSelectionDAO
+	trackDAO     dao.SpotifyTrackDAO
+	albumDAO     dao.SpotifyAlbumDAO
+	artistDAO    dao.SpotifyArtistDAO
+	syncService  *SpotifySyncService
+	logger       *slog.Logger
+}
+
+// NewStatsService creates a new instance of StatsService.
+// syncService is used to fill in tracks, albums and artists missing from the cache.
+func NewStatsService(
+	selectionDAO dao.UserSelectionDAO,
+	trackDAO dao.SpotifyTrackDAO,
+	albumDAO dao.SpotifyAlbumDAO,
+	artistDAO dao.SpotifyArtistDAO,
+	syncService *SpotifySyncService,
+	logger *slog.Logger,
+) *StatsService {
+	logger = logger.With("component", "stats_service")
+	logger.Info("initializing StatsService")
+	return &StatsService{
+		selectionDAO: selectionDAO,
+		trackDAO:     trackDAO,
+		albumDAO:     albumDAO,
+		artistDAO:    artistDAO,
+		syncService:  syncService,
+		logger:       logger,
+	}
+}
+
+func validateStatsYear(year int) error {
+	if year < minStatsYear || year > maxStatsYear {
+		return fmt.Errorf("%w: year must be between %d and %d", ErrInvalidStatsRequest, minStatsYear, maxStatsYear)
+	}
+	return nil
+}
+
+// museAndIckRoles returns the roles that count as Muses and as Icks: only the monthly picks with
+// selectedOnly, otherwise the candidates too.
+func museAndIckRoles(selectedOnly bool) (muse, ick []models.SelectionRole) {
+	if selectedOnly {
+		return []models.SelectionRole{models.RoleMuseSelected}, []models.SelectionRole{models.RoleIckSelected}
+	}
+	return []models.SelectionRole{models.RoleMuseSelected, models.RoleMuseCandidate},
+		[]models.SelectionRole{models.RoleIckSelected, models.RoleIckCandidate}
+}
+
+// statsPick is one distinct pick: a recording, release or artist the user selected as a Muse or as an Ick.
+// selection is the earliest of the user's selections of it in that group.
+type statsPick struct {
+	selection *models.UserSelection
+	muse      bool
+}
+
+// yearPicks lists the user's distinct picks of the given item types in a year. A recording selected in several
+// months, or as different Spotify copies, is one pick per group; one both loved and loathed is a pick in each.
+func (s *StatsService) yearPicks(ctx context.Context, userID string, year int, selectedOnly bool, itemTypes ...string) ([]statsPick, error) {
+	selections, isMuse, err := s.yearSelections(ctx, userID, year, selectedOnly, itemTypes...)
+	if err != nil {
+		return nil, err
+	}
+	return distinctPicks(selections, isMuse), nil
+}
+
+// yearSelections lists the user's Muse and Ick selections of the given item types in a year, in month order,
+// together with which of the roles count as Muses.
+func (s *StatsService) yearSelections(ctx context.Context, userID string, year int, selectedOnly bool, itemTypes ...string) ([]*models.UserSelection, map[models.SelectionRole]bool, error) {
+	museRoles, ickRoles := museAndIckRoles(selectedOnly)
+	roles := make([]string, 0, len(museRoles)+len(ickRoles))
+	isMuse := map[models.SelectionRole]bool{}
+	for _, role := range museRoles {
+		roles = append(roles, string(role))
+		isMuse[role] = true
+	}
+	for _, role := range ickRoles {
+		roles = append(roles, string(role))
+	}
+
+	var selections []*models.UserSelection
+	for _, itemType := range itemTypes {
+		found, err := s.selectionDAO.GetUserSelectionsForYear(ctx, userID, year, itemType, roles)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to list selections: %w", err)
+		}
+		selections = append(selections, found...)
+	}
+	sort.SliceStable(selections, func(i, j int) bool { return selections[i].MonthYear < selections[j].MonthYear })
+	return selections, isMuse, nil
+}
+
+// distinctPicks reduces selections to one pick per item and group, keeping the earliest selection.
+func distinctPicks(selections []*models.UserSelection, isMuse map[models.SelectionRole]bool) []statsPick {
+	var picks []statsPick
+	seen := map[string]bool{}
+	for _, sel := range selections {
+		muse := isMuse[sel.SelectionRole]
+		key := fmt.Sprintf("%t|%s", muse, sel.GroupKey())
+		if !seen[key] {
+			seen[key] = true
+			picks = append(picks, statsPick{selection: sel, muse: muse})
+		}
+	}
+	return picks
+}
+
+// AudioFeatureStats compares the audio features of the tracks a user picked as Muses with those picked as
+// Icks in a year. Tracks whose features aren't cached count towards Tracks but not WithFeatures.
+func (s *StatsService) AudioFeatureStats(ctx context.Context, userID string, year int, selectedOnly bool) (*models.MoodAnalyticsResponse, error) {
+	if err := validateStatsYear(year); err != nil {
+		return nil, err
+	}
+	picks, err := s.yearPicks(ctx, userID, year, selectedOnly, "track")
+	if err != nil {
+		return nil, err
+	}
+	var museTracks, ickTracks, trackIDs []string
+	for _, pick := range picks {
+		if pick.muse {
+			museTracks = append(museTracks, pick.selection.SpotifyItemID)
+		} else {
+			ickTracks = append(ickTracks, pick.selection.SpotifyItemID)
+		}
+		trackIDs = append(trackIDs, pick.selection.SpotifyItemID)
+	}
+	tracks, err := s.trackDAO.GetByIDs(ctx, trackIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tracks: %w", err)
+	}
+	features := make(map[string]*models.AudioFeatures, len(tracks))
+	for _, track := range tracks {
+		if track.AudioFeatures != nil {
+			features[track.SpotifyID] = track.AudioFeatures
+		}
+	}
+
+	response := &models.MoodAnalyticsResponse{
+		Year:         year,
+		SelectedOnly: selectedOnly,
+		Muses:        moodGroupStats(museTracks, features),
+		Icks:         moodGroupStats(ickTracks, features),
+		Differences:  map[string]float64{},
+	}
+	for _, name := range models.AudioFeatureNames {
+		muse, museOK := response.Muses.Features[name]
+		ick, ickOK := response.Icks.Features[name]
+		if museOK && ickOK {
+			response.Differences[name] = roundStat(muse.Mean - ick.Mean)
+		}
+	}
+	return response, nil
+}
+
+// moodGroupStats computes the feature distributions of one group's tracks.
+func moodGroupStats(tracks []string, features map[string]*models.AudioFeatures) models.MoodGroupStats {
+	stats := models.MoodGroupStats{Tracks: len(tracks), Features: map[string]models.FeatureDistribution{}}
+	values := map[string][]float64{}
+	for _, spotifyID := range tracks {
+		f := features[spotifyID]
+		if f == nil {
+			continue
+		}
+		stats.WithFeatures++
+		for _, name := range models.AudioFeatureNames {
+			v, _ := f.Value(name)
+			values[name] = append(values[name], v)
+		}
+	}
+	for name, vs := range values {
+		stats.Features[name] = featureDistribution(name, vs)
+	}
+	return stats
+}
+
+// featureDistribution summarizes a non-empty list of values of the named feature.
+func featureDistribution(name string, values []float64) models.FeatureDistribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	n := float64(len(sorted))
+
+	var sum float64
+	for _, v := range sorted {
+		sum += v
+	}
+	mean := sum / n
+	var squares float64
+	for _, v := range sorted {
+		squares += (v - mean) * (v - mean)
+	}
+	median := sorted[len(sorted)/2]
+	if len(sorted)%2 == 0 {
+		median = (sorted[len(sorted)/2-1] + sorted[len(sorted)/2]) / 2
+	}
+
+	histRange := featureHistogramRanges[name]
+	width := (histRange.to - histRange.from) / float64(histRange.bins)
+	histogram := make([]models.HistogramBin, histRange.bins)
+	for i := range histogram {
+		histogram[i].From = roundStat(histRange.from + float64(i)*width)
+		histogram[i].To = roundStat(histRange.from + float64(i+1)*width)
+	}
+	for _, v := range sorted {
+		bin := int((v - histRange.from) / width)
+		bin = max(0, min(bin, histRange.bins-1))
+		histogram[bin].Count++
+	}
+
+	return models.FeatureDistribution{
+		Mean:      roundStat(mean),
+		Median:    roundStat(median),
+		StdDev:    roundStat(math.Sqrt(squares / n)),
+		Min:       roundStat(sorted[0]),
+		Max:       roundStat(sorted[len(sorted)-1]),
+		Histogram: histogram,
+	}
+}
+
+// roundStat rounds to 4 decimal places, which is more precision than any of the underlying data has.
+func roundStat(v float64) float64 {
+	return math.Round(v*1e4) / 1e4
+}
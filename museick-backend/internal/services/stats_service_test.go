@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// histogramCounts returns just the counts of a histogram, bin by bin.
+func histogramCounts(histogram []models.HistogramBin) []int {
+	counts := make([]int, len(histogram))
+	for i, bin := range histogram {
+		counts[i] = bin.Count
+	}
+	return counts
+}
+
+func TestFeatureDistribution(t *testing.T) {
+	tests := []struct {
+		name       string
+		feature    string
+		values     []float64
+		want       models.FeatureDistribution // Without the histogram
+		wantCounts []int
+	}{
+		{
+			"single value", models.FeatureValence, []float64{0.55},
+			models.FeatureDistribution{Mean: 0.55, Median: 0.55, StdDev: 0, Min: 0.55, Max: 0.55},
+			[]int{0, 0, 0, 0, 0, 1, 0, 0, 0, 0},
+		},
+		{
+			"even count takes the middle two for the median", models.FeatureEnergy, []float64{0.95, 0.15, 0.45, 0.25},
+			models.FeatureDistribution{Mean: 0.45, Median: 0.35, StdDev: 0.3082, Min: 0.15, Max: 0.95},
+			[]int{0, 1, 1, 0, 1, 0, 0, 0, 0, 1},
+		},
+		{
+			"odd count", models.FeatureDanceability, []float64{0.65, 0.05, 0.35},
+			models.FeatureDistribution{Mean: 0.35, Median: 0.35, StdDev: 0.2449, Min: 0.05, Max: 0.65},
+			[]int{1, 0, 0, 1, 0, 0, 1, 0, 0, 0},
+		},
+		{
+			"tempo outside the range lands in the end bins", models.FeatureTempo, []float64{30, 130, 250},
+			models.FeatureDistribution{Mean: 136.6667, Median: 130, StdDev: 89.9383, Min: 30, Max: 250},
+			[]int{1, 0, 0, 0, 1, 0, 0, 0, 1},
+		},
+	}
+	for _, tt := range tests {
+		got := featureDistribution(tt.feature, tt.values)
+		if counts := histogramCounts(got.Histogram); !reflect.DeepEqual(counts, tt.wantCounts) {
+			t.Errorf("%s: histogram counts = %v, want %v", tt.name, counts, tt.wantCounts)
+		}
+		got.Histogram = nil
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: featureDistribution(%s, %v) = %+v, want %+v", tt.name, tt.feature, tt.values, got, tt.want)
+		}
+	}
+}
+
+func TestFeatureDistributionHistogramBins(t *testing.T) {
+	unit := featureDistribution(models.FeatureAcousticness, []float64{0.5}).Histogram
+	if len(unit) != 10 || unit[0] != (models.HistogramBin{From: 0, To: 0.1}) || unit[9].From != 0.9 || unit[9].To != 1 {
+		t.Errorf("acousticness bins = %+v, want tenths from 0 to 1", unit)
+	}
+	tempo := featureDistribution(models.FeatureTempo, []float64{100}).Histogram
+	if len(tempo) != 9 || tempo[0].From != 40 || tempo[0].To != 60 || tempo[8].From != 200 || tempo[8].To != 220 {
+		t.Errorf("tempo bins = %+v, want 20 BPM steps from 40 to 220", tempo)
+	}
+}
+
+func TestMoodGroupStats(t *testing.T) {
+	features := map[string]*models.AudioFeatures{
+		"t1": {Valence: 0.2, Energy: 0.4, Danceability: 0.6, Tempo: 100, Acousticness: 0.1},
+		"t2": {Valence: 0.6, Energy: 0.8, Danceability: 0.4, Tempo: 140, Acousticness: 0.3},
+	}
+
+	got := moodGroupStats([]string{"t1", "t2", "unanalyzed"}, features)
+	if got.Tracks != 3 || got.WithFeatures != 2 {
+		t.Errorf("tracks = %d, with features = %d; want 3 and 2", got.Tracks, got.WithFeatures)
+	}
+	if len(got.Features) != len(models.AudioFeatureNames) {
+		t.Errorf("features reported = %d, want all %d", len(got.Features), len(models.AudioFeatureNames))
+	}
+	for name, want := range map[string]float64{models.FeatureValence: 0.4, models.FeatureTempo: 120, models.FeatureAcousticness: 0.2} {
+		if mean := got.Features[name].Mean; mean != want {
+			t.Errorf("%s mean = %v, want %v", name, mean, want)
+		}
+	}
+
+	none := moodGroupStats([]string{"unanalyzed"}, features)
+	if none.Tracks != 1 || none.WithFeatures != 0 || none.Features == nil || len(none.Features) != 0 {
+		t.Errorf("group without features = %+v, want 1 track and an empty feature map", none)
+	}
+}
+
+func TestAudioFeatureStats(t *testing.T) {
+	selection := func(spotifyID, month string, role models.SelectionRole) *models.UserSelection {
+		return &models.UserSelection{
+			ID: primitive.NewObjectID(), UserID: "user", ItemType: "track", SpotifyItemID: spotifyID, MonthYear: month, SelectionRole: role,
+		}
+	}
+	selections := map[primitive.ObjectID]*models.UserSelection{}
+	for _, sel := range []*models.UserSelection{
+		selection("loved", "2024-01", models.RoleMuseSelected),
+		selection("loved", "2024-02", models.RoleMuseCandidate), // Same recording again: still one pick
+		selection("shortlisted", "2024-03", models.RoleMuseCandidate),
+		selection("unanalyzed", "2024-03", models.RoleMuseSelected),
+		selection("loathed", "2024-01", models.RoleIckSelected),
+		selection("old", "2023-12", models.RoleIckSelected),
+	} {
+		selections[sel.ID] = sel
+	}
+	trackDAO := &fakeTrackDAO{tracks: map[string]*models.SpotifyTrack{
+		"loved":       {SpotifyID: "loved", AudioFeatures: &models.AudioFeatures{Valence: 0.9, Energy: 0.8, Danceability: 0.7, Tempo: 128, Acousticness: 0.1}},
+		"shortlisted": {SpotifyID: "shortlisted", AudioFeatures: &models.AudioFeatures{Valence: 0.5, Energy: 0.6, Danceability: 0.5, Tempo: 100, Acousticness: 0.3}},
+		"unanalyzed":  {SpotifyID: "unanalyzed"},
+		"loathed":     {SpotifyID: "loathed", AudioFeatures: &models.AudioFeatures{Valence: 0.2, Energy: 0.3, Danceability: 0.4, Tempo: 90, Acousticness: 0.6}},
+		"old":         {SpotifyID: "old", AudioFeatures: &models.AudioFeatures{Valence: 0, Energy: 0, Danceability: 0, Tempo: 60, Acousticness: 1}},
+	}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewStatsService(&fakeSelectionDAO{selections: selections}, trackDAO, nil, nil, nil, logger)
+
+	tests := []struct {
+		name            string
+		selectedOnly    bool
+		wantMuses       [2]int // Tracks, with features
+		wantIcks        [2]int
+		wantValence     float64 // Muse mean
+		wantValenceDiff float64
+		wantTempoDiff   float64
+	}{
+		{"candidates count", false, [2]int{3, 2}, [2]int{1, 1}, 0.7, 0.5, 24},
+		{"monthly picks only", true, [2]int{2, 1}, [2]int{1, 1}, 0.9, 0.7, 38},
+	}
+	for _, tt := range tests {
+		got, err := service.AudioFeatureStats(context.Background(), "user", 2024, tt.selectedOnly)
+		if err != nil {
+			t.Fatalf("%s: AudioFeatureStats() unexpected error: %v", tt.name, err)
+		}
+		if got.Year != 2024 || got.SelectedOnly != tt.selectedOnly {
+			t.Errorf("%s: year = %d, selected only = %v", tt.name, got.Year, got.SelectedOnly)
+		}
+		if muses := [2]int{got.Muses.Tracks, got.Muses.WithFeatures}; muses != tt.wantMuses {
+			t.Errorf("%s: muses tracks, with features = %v, want %v", tt.name, muses, tt.wantMuses)
+		}
+		if icks := [2]int{got.Icks.Tracks, got.Icks.WithFeatures}; icks != tt.wantIcks {
+			t.Errorf("%s: icks tracks, with features = %v, want %v", tt.name, icks, tt.wantIcks)
+		}
+		if mean := got.Muses.Features[models.FeatureValence].Mean; mean != tt.wantValence {
+			t.Errorf("%s: muse valence mean = %v, want %v", tt.name, mean, tt.wantValence)
+		}
+		if diff := got.Differences[models.FeatureValence]; diff != tt.wantValenceDiff {
+			t.Errorf("%s: valence difference = %v, want %v", tt.name, diff, tt.wantValenceDiff)
+		}
+		if diff := got.Differences[models.FeatureTempo]; diff != tt.wantTempoDiff {
+			t.Errorf("%s: tempo difference = %v, want %v", tt.name, diff, tt.wantTempoDiff)
+		}
+	}
+
+	// Without Icks there is nothing to compare against
+	for id, sel := range selections {
+		if sel.SelectionRole == models.RoleIckSelected {
+			delete(selections, id)
+		}
+	}
+	got, err := service.AudioFeatureStats(context.Background(), "user", 2024, false)
+	if err != nil {
+		t.Fatalf("AudioFeatureStats() without icks unexpected error: %v", err)
+	}
+	if got.Icks.Tracks != 0 || len(got.Icks.Features) != 0 || len(got.Differences) != 0 {
+		t.Errorf("without icks: icks = %+v, differences = %v; want none", got.Icks, got.Differences)
+	}
+
+	if _, err := service.AudioFeatureStats(context.Background(), "user", 1800, false); !errors.Is(err, ErrInvalidStatsRequest) {
+		t.Errorf("AudioFeatureStats(1800) error = %v, want %v", err, ErrInvalidStatsRequest)
+	}
+}
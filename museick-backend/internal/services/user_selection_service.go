@@ -4,16 +4,21 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"github.com/seven7een/museick/museick-backend/internal/dao"
 	"github.com/seven7een/museick/museick-backend/internal/models"
+	"github.com/seven7een/museick/museick-backend/internal/tracing"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+	"github.com/zmb3/spotify/v2"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/attribute"
 )
 
 // UserSelectionService handles business logic related to user selections.
@@ -22,6 +27,7 @@ type UserSelectionService struct {
 	spotifySyncSvc   *SpotifySyncService
 	spotifySvc       *SpotifyService // Keep for potential future use (e.g., client credentials flow)
 	refreshThreshold time.Duration
+	logger           *slog.Logger
 }
 
 // NewUserSelectionService creates a new instance of UserSelectionService.
@@ -29,18 +35,25 @@ func NewUserSelectionService(
 	selectionDAO dao.UserSelectionDAO,
 	spotifySyncSvc *SpotifySyncService,
 	spotifySvc *SpotifyService,
+	refreshThreshold time.Duration, // How old a cached Spotify item may get before it is re-fetched
+	logger *slog.Logger,
 ) *UserSelectionService {
-	log.Println("Initializing UserSelectionService")
+	logger = logger.With("component", "user_selection_service")
+	logger.Info("initializing UserSelectionService")
 	return &UserSelectionService{
 		selectionDAO:     selectionDAO,
 		spotifySyncSvc:   spotifySyncSvc,
 		spotifySvc:       spotifySvc,
-		refreshThreshold: 24 * time.Hour,
+		refreshThreshold: refreshThreshold,
+		logger:           logger,
 	}
 }
 
 // verifySpotifyItem checks if a Spotify item exists and is accessible with the given token
-func (s *UserSelectionService) verifySpotifyItem(spotifyToken string, spotifyItemID string, itemType string) error {
+func (s *UserSelectionService) verifySpotifyItem(ctx context.Context, spotifyToken string, spotifyItemID string, itemType string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.verifySpotifyItem",
+		attribute.String("spotify.item_id", spotifyItemID), attribute.String("spotify.item_type", itemType))
+	defer func() { tracing.End(span, err) }()
 	if spotifyToken == "" {
 		return errors.New("spotify token is required for verification")
 	}
@@ -57,13 +70,13 @@ func (s *UserSelectionService) verifySpotifyItem(spotifyToken string, spotifyIte
 	}
 
 	url := fmt.Sprintf("https://api.spotify.com/v1%s", endpoint)
-	req, err := http.NewRequestWithContext(context.Background(), "GET", url, nil) // Use context
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return fmt.Errorf("error creating verification request: %w", err)
 	}
 
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", spotifyToken))
-	httpClient := &http.Client{Timeout: 10 * time.Second}
+	httpClient := utils.NewSpotifyHTTPClient(10 * time.Second)
 	resp, err := httpClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("error making verification request: %w", err)
@@ -85,23 +98,22 @@ func (s *UserSelectionService) verifySpotifyItem(spotifyToken string, spotifyIte
 // CreateSelection handles the logic for creating a user selection.
 // It verifies the Spotify item, ensures the item exists in our local Spotify cache,
 // and then creates the UserSelection document, handling duplicates gracefully.
-func (s *UserSelectionService) CreateSelection(ctx context.Context, userID string, spotifyToken string, req *models.CreateSelectionRequest) (*models.UserSelection, error) {
-
-	// Validate input
-	if !isValidMonthYear(req.MonthYear) {
-		return nil, errors.New("invalid MonthYear format, expected YYYY-MM")
-	}
-	if req.Role != models.RoleMuseCandidate && req.Role != models.RoleIckCandidate {
-		return nil, fmt.Errorf("invalid initial selection role: %s. Must be 'muse_candidate' or 'ick_candidate'", req.Role)
-	}
-	if req.ItemType != "track" && req.ItemType != "album" && req.ItemType != "artist" {
-		return nil, fmt.Errorf("invalid item_type: %s. Must be 'track', 'album', or 'artist'", req.ItemType)
+func (s *UserSelectionService) CreateSelection(ctx context.Context, userID string, spotifyToken string, req *models.CreateSelectionRequest) (_ *models.UserSelection, err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.CreateSelection",
+		attribute.String("spotify.item_id", req.SpotifyItemID), attribute.String("spotify.item_type", req.ItemType),
+		attribute.String("selection.month_year", req.MonthYear))
+	defer func() { tracing.End(span, err) }()
+
+	// Validate input and build the record before touching Spotify
+	newSelection, err := newSelectionFromRequest(userID, req)
+	if err != nil {
+		return nil, err
 	}
 
 	// 1. Verify the Spotify item exists using the provided token
-	err := s.verifySpotifyItem(spotifyToken, req.SpotifyItemID, req.ItemType)
+	err = s.verifySpotifyItem(ctx, spotifyToken, req.SpotifyItemID, req.ItemType)
 	if err != nil {
-		log.Printf("Spotify item verification failed for user %s, item %s (%s): %v", userID, req.SpotifyItemID, req.ItemType, err)
+		s.logger.WarnContext(ctx, "spotify item verification failed", "spotify_item_id", req.SpotifyItemID, "item_type", req.ItemType, "error", err)
 		// Return a more specific error if verification failed due to auth
 		if errors.Is(err, fmt.Errorf("spotify authentication failed during verification")) { // More specific check might be needed
 			return nil, errors.New("spotify authentication failed, please reconnect")
@@ -111,94 +123,85 @@ func (s *UserSelectionService) CreateSelection(ctx context.Context, userID strin
 
 	// 2. Ensure the core Spotify item exists in our local DB cache (sync if needed)
 	// Pass the spotifyToken for potential API calls within GetOrSyncItem
-	_, err = s.spotifySyncSvc.GetOrSyncItem(ctx, req.SpotifyItemID, req.ItemType, spotifyToken, s.refreshThreshold)
+	cachedItem, err := s.spotifySyncSvc.GetOrSyncItem(ctx, req.SpotifyItemID, req.ItemType, spotifyToken, s.refreshThreshold)
 	if err != nil {
-		log.Printf("Error ensuring Spotify item %s (%s) exists in local DB for user %s: %v", req.SpotifyItemID, req.ItemType, userID, err)
+		s.logger.ErrorContext(ctx, "error ensuring spotify item exists in local DB", "spotify_item_id", req.SpotifyItemID, "item_type", req.ItemType, "error", err)
 		// If sync fails (e.g., token expired between verification and sync), we might still proceed
 		// but log a warning. For now, return error.
 		return nil, fmt.Errorf("failed to sync spotify item to local cache: %w", err)
 	}
 
-	// 3. Prepare the UserSelection record
-	now := primitive.NewDateTimeFromTime(time.Now())
-	newSelection := &models.UserSelection{
-		UserID:        userID,
-		SpotifyItemID: req.SpotifyItemID,
-		ItemType:      req.ItemType,
-		SelectionRole: req.Role,
-		MonthYear:     req.MonthYear,
-		AddedAt:       now,
-		UpdatedAt:     now,
-		Notes:         req.Notes,
-	}
-
-	// 4. Attempt to insert into database
+	// 3. Denormalize the cached item's names onto the record for search
+	newSelection.ItemName, newSelection.AlbumName, newSelection.ArtistNames = models.SearchNames(cachedItem)
+	newSelection.CanonicalID = models.CanonicalID(cachedItem)
+
+	// 4. Another copy of the same recording or release this month counts as the same selection
+	existingCopy, err := s.selectionDAO.FindByUserMonthCanonicalID(ctx, userID, req.MonthYear, newSelection.CanonicalID)
+	if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+		return nil, fmt.Errorf("error checking for duplicate selection: %w", err)
+	}
+	if existingCopy != nil && existingCopy.SpotifyItemID != req.SpotifyItemID {
+		s.logger.InfoContext(ctx, "item is a copy of an existing selection, returning existing",
+			"spotify_item_id", req.SpotifyItemID, "existing_spotify_item_id", existingCopy.SpotifyItemID, "canonical_id", newSelection.CanonicalID)
+		sanitizeSelectionsForOutput(existingCopy)
+		return existingCopy, nil
+	}
+
+	// 5. Attempt to insert into database
 	createdSelection, err := s.selectionDAO.Create(ctx, newSelection)
 	if err != nil {
 		// Check if the error indicates the selection already exists (using the sentinel error from DAO)
 		if errors.Is(err, dao.ErrSelectionExists) {
 			// The DAO indicated a duplicate. Now fetch the existing document explicitly.
-			log.Printf("Selection already exists for user %s, month %s, item %s. Fetching existing.", userID, req.MonthYear, req.SpotifyItemID)
+			s.logger.DebugContext(ctx, "selection already exists, fetching existing", "month_year", req.MonthYear, "spotify_item_id", req.SpotifyItemID)
 			existingSelection, findErr := s.selectionDAO.FindByUserMonthSpotifyItem(ctx, userID, req.MonthYear, req.SpotifyItemID)
 			if findErr != nil {
 				// This is unexpected if ErrSelectionExists was returned, but handle defensively.
-				log.Printf("Error fetching existing selection after ErrSelectionExists: %v", findErr)
+				s.logger.ErrorContext(ctx, "error fetching existing selection after duplicate error", "error", findErr)
 				return nil, fmt.Errorf("error fetching existing selection after duplicate error: %w", findErr)
 			}
-			log.Printf("Returning existing selection ID %s.", existingSelection.ID.Hex())
+			s.logger.DebugContext(ctx, "returning existing selection", "selection_id", existingSelection.ID.Hex())
+			sanitizeSelectionsForOutput(existingSelection)
 			return existingSelection, nil // Return the fetched existing selection
 		}
 
 		// If it's any other error (not ErrSelectionExists)
-		log.Printf("Error creating selection: %v", err)
+		s.logger.ErrorContext(ctx, "error creating selection", "error", err)
 		return nil, fmt.Errorf("error creating selection: %w", err) // Return the original wrapped error
 	}
 
 	// Successfully created a new selection
-	log.Printf("Successfully created selection ID %s for user %s with role %s", createdSelection.ID.Hex(), userID, req.Role)
+	s.logger.InfoContext(ctx, "created selection", "selection_id", createdSelection.ID.Hex(), "role", req.Role)
+	sanitizeSelectionsForOutput(createdSelection)
 	return createdSelection, nil
 }
 
-
-// UpdateSelectionInput defines the input for updating a selection's role or notes.
+// UpdateSelectionInput defines the input for updating a selection's role or journal fields.
 type UpdateSelectionInput struct {
-	SelectionID string // The MongoDB _id of the UserSelection record
-	UserID      string // For authorization check
-	Role        *models.SelectionRole
-	Notes       *string
+	SelectionID      string // The MongoDB _id of the UserSelection record
+	UserID           string // For authorization check
+	Role             *models.SelectionRole
+	Notes            *string
+	MoodTags         *[]string                // Replaces the full tag list; an empty list clears it
+	ListeningContext *models.ListeningContext // Replaces the context; an empty object clears it
 }
 
 // UpdateSelection modifies an existing selection (e.g., change role, update notes).
+// When the notes change, the previous version is kept in the selection's notes history.
 // Handles demoting the previously selected item if a new item is selected as Muse/Ick.
-func (s *UserSelectionService) UpdateSelection(ctx context.Context, input UpdateSelectionInput) (*models.UserSelection, error) {
+func (s *UserSelectionService) UpdateSelection(ctx context.Context, input UpdateSelectionInput) (_ *models.UserSelection, err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.UpdateSelection", attribute.String("selection.id", input.SelectionID))
+	defer func() { tracing.End(span, err) }()
 	selectionObjID, err := primitive.ObjectIDFromHex(input.SelectionID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid selection ID format: %w", err)
 	}
 
-	updates := bson.M{}
-	hasRoleUpdate := false
-	var newRole models.SelectionRole
-
-	if input.Role != nil {
-		newRole = *input.Role
-		if newRole != models.RoleMuseCandidate && newRole != models.RoleIckCandidate &&
-			newRole != models.RoleMuseSelected && newRole != models.RoleIckSelected {
-			return nil, fmt.Errorf("invalid target selection role: %s", newRole)
-		}
-		updates["selection_role"] = newRole
-		hasRoleUpdate = true
-	}
-	if input.Notes != nil {
-		updates["notes"] = *input.Notes
-	}
-
-	if len(updates) == 0 {
-		return nil, errors.New("no updates provided")
-	}
-
 	now := primitive.NewDateTimeFromTime(time.Now())
-	updates["updated_at"] = now
+	updates, err := buildSelectionUpdates(input, now)
+	if err != nil {
+		return nil, err
+	}
 
 	// --- Authorization & Pre-Update Logic ---
 	selectionToUpdate, err := s.selectionDAO.GetByID(ctx, selectionObjID)
@@ -206,58 +209,57 @@ func (s *UserSelectionService) UpdateSelection(ctx context.Context, input Update
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return nil, errors.New("selection not found")
 		}
-		log.Printf("Error fetching selection %s for update check: %v", input.SelectionID, err)
+		s.logger.ErrorContext(ctx, "error fetching selection for update check", "selection_id", input.SelectionID, "error", err)
 		return nil, fmt.Errorf("failed to retrieve selection for update")
 	}
 
 	if selectionToUpdate.UserID != input.UserID {
-		log.Printf("Authorization error: User %s attempted to update selection %s belonging to user %s", input.UserID, input.SelectionID, selectionToUpdate.UserID)
+		s.logger.WarnContext(ctx, "authorization error: attempted to update another user's selection", "selection_id", input.SelectionID, "owner_sub", selectionToUpdate.UserID)
 		return nil, errors.New("authorization failed: selection does not belong to user")
 	}
 
 	// --- Demotion Logic ---
-	if hasRoleUpdate && (newRole == models.RoleMuseSelected || newRole == models.RoleIckSelected) {
-		var roleToDemote models.SelectionRole
-		var demoteToRole models.SelectionRole
-		if newRole == models.RoleMuseSelected {
-			roleToDemote = models.RoleMuseSelected
-			demoteToRole = models.RoleMuseCandidate
-		} else {
-			roleToDemote = models.RoleIckSelected
-			demoteToRole = models.RoleIckCandidate
-		}
-
+	if roleToDemote, demoteToRole, ok := demotionRoles(input.Role); ok {
 		// Find the currently selected item *of the same type* for this user/month/role
 		currentlySelected, err := s.selectionDAO.FindSelected(ctx, input.UserID, selectionToUpdate.MonthYear, roleToDemote, selectionToUpdate.ItemType) // Pass itemType
 		if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
-			log.Printf("Error finding currently selected %s (role %s) for user %s, month %s: %v", selectionToUpdate.ItemType, roleToDemote, input.UserID, selectionToUpdate.MonthYear, err) // Log itemType
+			s.logger.ErrorContext(ctx, "error finding currently selected item", "item_type", selectionToUpdate.ItemType, "role", roleToDemote, "month_year", selectionToUpdate.MonthYear, "error", err)
 			return nil, fmt.Errorf("failed to check current selection status for %s", selectionToUpdate.ItemType)
 		}
 
 		if currentlySelected != nil && currentlySelected.ID != selectionObjID {
-			log.Printf("Demoting previously selected item %s (role %s) to %s for user %s, month %s",
-				currentlySelected.ID.Hex(), roleToDemote, demoteToRole, input.UserID, selectionToUpdate.MonthYear)
+			s.logger.InfoContext(ctx, "demoting previously selected item",
+				"selection_id", currentlySelected.ID.Hex(), "from", roleToDemote, "to", demoteToRole, "month_year", selectionToUpdate.MonthYear)
 			demotionErr := s.selectionDAO.UpdateRole(ctx, currentlySelected.ID, demoteToRole, now)
 			if demotionErr != nil {
-				log.Printf("Error demoting previous selection %s: %v", currentlySelected.ID.Hex(), demotionErr)
+				s.logger.ErrorContext(ctx, "error demoting previous selection", "selection_id", currentlySelected.ID.Hex(), "error", demotionErr)
 				// TODO: Consider if this should be a fatal error for the update operation
 			}
 		}
 	}
 
 	// --- Perform the Actual Update ---
-	updatedSelection, err := s.selectionDAO.Update(ctx, selectionObjID, updates)
+	var updatedSelection *models.UserSelection
+	if revision := notesRevisionFor(selectionToUpdate, input.Notes, now); revision != nil {
+		// Keep the version being replaced so the story's edit history is preserved
+		updatedSelection, err = s.selectionDAO.UpdateWithNotesRevision(ctx, selectionObjID, updates, *revision, maxNotesRevisions)
+	} else {
+		updatedSelection, err = s.selectionDAO.Update(ctx, selectionObjID, updates)
+	}
 	if err != nil {
-		log.Printf("Error performing final update on selection ID %s: %v", input.SelectionID, err)
+		s.logger.ErrorContext(ctx, "error performing final update on selection", "selection_id", input.SelectionID, "error", err)
 		return nil, fmt.Errorf("failed to update selection: %w", err)
 	}
 
-	log.Printf("Successfully updated selection ID %s for user %s", updatedSelection.ID.Hex(), input.UserID)
+	s.logger.InfoContext(ctx, "updated selection", "selection_id", updatedSelection.ID.Hex())
+	sanitizeSelectionsForOutput(updatedSelection)
 	return updatedSelection, nil
 }
 
 // DeleteSelection removes a user's selection.
-func (s *UserSelectionService) DeleteSelection(ctx context.Context, selectionID string, userID string) error {
+func (s *UserSelectionService) DeleteSelection(ctx context.Context, selectionID string, userID string) (err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.DeleteSelection", attribute.String("selection.id", selectionID))
+	defer func() { tracing.End(span, err) }()
 	selectionObjID, err := primitive.ObjectIDFromHex(selectionID)
 	if err != nil {
 		return fmt.Errorf("invalid selection ID format: %w", err)
@@ -269,11 +271,11 @@ func (s *UserSelectionService) DeleteSelection(ctx context.Context, selectionID
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return errors.New("selection not found")
 		}
-		log.Printf("Error fetching selection %s for delete check: %v", selectionID, err)
+		s.logger.ErrorContext(ctx, "error fetching selection for delete check", "selection_id", selectionID, "error", err)
 		return fmt.Errorf("failed to retrieve selection for deletion")
 	}
 	if selection.UserID != userID {
-		log.Printf("Authorization error: User %s attempted to delete selection %s belonging to user %s", userID, selectionID, selection.UserID)
+		s.logger.WarnContext(ctx, "authorization error: attempted to delete another user's selection", "selection_id", selectionID, "owner_sub", selection.UserID)
 		return errors.New("unauthorized")
 	}
 
@@ -283,29 +285,523 @@ func (s *UserSelectionService) DeleteSelection(ctx context.Context, selectionID
 		if errors.Is(err, mongo.ErrNoDocuments) {
 			return errors.New("selection not found") // Should have been caught by pre-fetch
 		}
-		log.Printf("Error deleting selection ID %s for user %s: %v", selectionID, userID, err)
+		s.logger.ErrorContext(ctx, "error deleting selection", "selection_id", selectionID, "error", err)
 		return fmt.Errorf("failed to delete selection: %w", err)
 	}
 
-	log.Printf("Successfully deleted selection ID %s for user %s", selectionID, userID)
+	s.logger.InfoContext(ctx, "deleted selection", "selection_id", selectionID)
 	return nil
 }
 
 // ListSelectionsByMonth retrieves all selections for a user for a specific month.
-func (s *UserSelectionService) ListSelectionsByMonth(ctx context.Context, userID, monthYear string) ([]*models.UserSelection, error) {
+func (s *UserSelectionService) ListSelectionsByMonth(ctx context.Context, userID, monthYear string) (_ []*models.UserSelection, err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.ListSelectionsByMonth", attribute.String("selection.month_year", monthYear))
+	defer func() { tracing.End(span, err) }()
 	if !isValidMonthYear(monthYear) {
 		return nil, errors.New("invalid MonthYear format, expected YYYY-MM")
 	}
 	selections, err := s.selectionDAO.ListByUserAndMonth(ctx, userID, monthYear)
 	if err != nil {
-		log.Printf("Error listing selections for user %s, month %s: %v", userID, monthYear, err)
+		s.logger.ErrorContext(ctx, "error listing selections", "month_year", monthYear, "error", err)
 		return nil, fmt.Errorf("failed to list selections: %w", err)
 	}
+	sanitizeSelectionsForOutput(selections...)
 	return selections, nil
 }
 
+// BatchOperationOutcome is the result of one operation in ApplyBatch, in request order.
+type BatchOperationOutcome struct {
+	Selection *models.UserSelection // The created/updated selection (or the existing one for a duplicate create)
+	Created   bool                  // True when a create inserted a new selection
+	Err       error
+}
+
+// ApplyBatch applies a list of create, update and delete operations for a user.
+// Created items are verified against Spotify in bulk (one "get several" call per item type and chunk)
+// and the writes are sent to MongoDB as a single unordered bulk write, so one failing operation
+// does not prevent the others from being applied. Updates selecting a Muse or Ick follow it one by one,
+// demoting the previous holder only once the new one is in place. Per-operation failures are reported in the
+// outcomes; the returned error is only set when the batch could not be attempted at all.
+func (s *UserSelectionService) ApplyBatch(ctx context.Context, userID string, spotifyToken string, ops []models.BatchSelectionOperation) (_ []BatchOperationOutcome, err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.ApplyBatch", attribute.Int("batch.operations", len(ops)))
+	defer func() { tracing.End(span, err) }()
+	outcomes := make([]BatchOperationOutcome, len(ops))
+	now := primitive.NewDateTimeFromTime(time.Now())
+
+	// --- Resolve the selections referenced by updates and deletes in one query ---
+	objIDs := make([]primitive.ObjectID, len(ops))
+	referencedBy := make(map[primitive.ObjectID]int)
+	var lookupIDs []primitive.ObjectID
+	for i, op := range ops {
+		if op.Op == string(dao.SelectionBatchCreate) {
+			continue
+		}
+		objID, err := primitive.ObjectIDFromHex(op.ID)
+		if err != nil {
+			outcomes[i].Err = fmt.Errorf("invalid selection ID format: %w", err)
+			continue
+		}
+		if first, seen := referencedBy[objID]; seen {
+			outcomes[i].Err = fmt.Errorf("invalid batch: selection %s is already modified by operation %d", op.ID, first)
+			continue
+		}
+		referencedBy[objID] = i
+		objIDs[i] = objID
+		lookupIDs = append(lookupIDs, objID)
+	}
+	existing, err := s.selectionDAO.FindByIDs(ctx, lookupIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error fetching selections for batch", "error", err)
+		return nil, fmt.Errorf("failed to retrieve selections for batch: %w", err)
+	}
+	existingByID := make(map[primitive.ObjectID]*models.UserSelection, len(existing))
+	for _, selection := range existing {
+		existingByID[selection.ID] = selection
+	}
+
+	// --- Validate every operation and build the writes ---
+	var batchOps []dao.SelectionBatchOp
+	var batchOwners []int                    // batchOwners[j] is the index in ops of batchOps[j]
+	createIDsByType := map[string][]string{} // Spotify IDs to verify, per item type
+	selectedSlots := map[string]int{}        // "month|type|role" -> op that selects it, to reject conflicting selects
+	for i, op := range ops {
+		if outcomes[i].Err != nil {
+			continue
+		}
+		switch op.Op {
+		case string(dao.SelectionBatchCreate):
+			req := &models.CreateSelectionRequest{
+				SpotifyItemID:    op.SpotifyItemID,
+				ItemType:         op.ItemType,
+				MonthYear:        op.MonthYear,
+				ListeningContext: op.ListeningContext,
+			}
+			if op.Role != nil {
+				req.Role = models.SelectionRole(strings.ToLower(string(*op.Role)))
+			}
+			if op.Notes != nil {
+				req.Notes = *op.Notes
+			}
+			if op.MoodTags != nil {
+				req.MoodTags = *op.MoodTags
+			}
+			if req.SpotifyItemID == "" {
+				outcomes[i].Err = errors.New("invalid batch: spotify_item_id is required for create")
+				continue
+			}
+			selection, err := newSelectionFromRequest(userID, req)
+			if err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			createIDsByType[req.ItemType] = append(createIDsByType[req.ItemType], req.SpotifyItemID)
+			batchOps = append(batchOps, dao.SelectionBatchOp{Type: dao.SelectionBatchCreate, Selection: selection})
+			batchOwners = append(batchOwners, i)
+
+		case string(dao.SelectionBatchUpdate), string(dao.SelectionBatchDelete):
+			current, found := existingByID[objIDs[i]]
+			if !found {
+				outcomes[i].Err = errors.New("selection not found")
+				continue
+			}
+			if current.UserID != userID {
+				s.logger.WarnContext(ctx, "authorization error: batch operation on another user's selection", "op", op.Op, "selection_id", op.ID, "owner_sub", current.UserID)
+				outcomes[i].Err = errors.New("unauthorized")
+				continue
+			}
+			if op.Op == string(dao.SelectionBatchDelete) {
+				batchOps = append(batchOps, dao.SelectionBatchOp{Type: dao.SelectionBatchDelete, SelectionID: objIDs[i], UserID: userID})
+				batchOwners = append(batchOwners, i)
+				continue
+			}
+
+			input := UpdateSelectionInput{
+				SelectionID:      op.ID,
+				UserID:           userID,
+				Role:             op.Role,
+				Notes:            op.Notes,
+				MoodTags:         op.MoodTags,
+				ListeningContext: op.ListeningContext,
+			}
+			if input.Role != nil {
+				role := models.SelectionRole(strings.ToLower(string(*input.Role)))
+				input.Role = &role
+			}
+			updates, err := buildSelectionUpdates(input, now)
+			if err != nil {
+				outcomes[i].Err = err
+				continue
+			}
+			batchOp := dao.SelectionBatchOp{
+				Type:          dao.SelectionBatchUpdate,
+				SelectionID:   objIDs[i],
+				UserID:        userID,
+				Updates:       updates,
+				NotesRevision: notesRevisionFor(current, input.Notes, now),
+			}
+			if from, to, ok := demotionRoles(input.Role); ok {
+				slot := current.MonthYear + "|" + current.ItemType + "|" + string(from)
+				if first, taken := selectedSlots[slot]; taken {
+					outcomes[i].Err = fmt.Errorf("invalid batch: operation %d already selects a %s for %s", first, current.ItemType, current.MonthYear)
+					continue
+				}
+				selectedSlots[slot] = i
+				batchOp.Demote = &dao.SelectionDemotion{MonthYear: current.MonthYear, ItemType: current.ItemType, From: from, To: to}
+			}
+			batchOps = append(batchOps, batchOp)
+			batchOwners = append(batchOwners, i)
+
+		default:
+			outcomes[i].Err = fmt.Errorf("invalid batch: unsupported operation %q", op.Op)
+		}
+	}
+
+	// --- Verify created items against Spotify in bulk, caching them as we go ---
+	createdCopies := map[string]int{} // "month|canonical ID" -> create op adding it
+	copyOf := map[int]int{}           // Create op -> earlier create op in the batch adding a copy of the same item
+	if len(createIDsByType) > 0 {
+		verified := make(map[string]interface{})
+		verifyFailures := make(map[string]error)
+		if spotifyToken == "" {
+			for itemType, ids := range createIDsByType {
+				for _, id := range ids {
+					verifyFailures[itemType+"|"+id] = errors.New("spotify token is required for verification")
+				}
+			}
+		} else {
+			client := utils.CreateTemporarySpotifyClient(ctx, spotifyToken)
+			for itemType, ids := range createIDsByType {
+				items, failures := s.spotifySyncSvc.SyncItems(ctx, ids, itemType, client)
+				for id, item := range items {
+					verified[itemType+"|"+id] = item
+				}
+				for id, failure := range failures {
+					verifyFailures[itemType+"|"+id] = failure
+				}
+			}
+		}
+
+		remainingOps := batchOps[:0]
+		remainingOwners := batchOwners[:0]
+		for j, batchOp := range batchOps {
+			owner := batchOwners[j]
+			if batchOp.Type == dao.SelectionBatchCreate {
+				key := batchOp.Selection.ItemType + "|" + batchOp.Selection.SpotifyItemID
+				if failure, failed := verifyFailures[key]; failed {
+					s.logger.WarnContext(ctx, "spotify item verification failed in batch", "item", key, "error", failure)
+					outcomes[owner].Err = batchVerificationError(failure)
+					continue
+				}
+				batchOp.Selection.ItemName, batchOp.Selection.AlbumName, batchOp.Selection.ArtistNames = models.SearchNames(verified[key])
+				batchOp.Selection.CanonicalID = models.CanonicalID(verified[key])
+
+				// Another copy of the same recording or release this month counts as the same selection
+				if canonicalID := batchOp.Selection.CanonicalID; canonicalID != "" {
+					slot := batchOp.Selection.MonthYear + "|" + canonicalID
+					if first, taken := createdCopies[slot]; taken {
+						copyOf[owner] = first
+						continue
+					}
+					existingCopy, err := s.selectionDAO.FindByUserMonthCanonicalID(ctx, userID, batchOp.Selection.MonthYear, canonicalID)
+					if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+						outcomes[owner].Err = fmt.Errorf("error checking for duplicate selection: %w", err)
+						continue
+					}
+					if existingCopy != nil && existingCopy.SpotifyItemID != batchOp.Selection.SpotifyItemID {
+						s.logger.InfoContext(ctx, "batch item is a copy of an existing selection, returning existing",
+							"spotify_item_id", batchOp.Selection.SpotifyItemID, "existing_spotify_item_id", existingCopy.SpotifyItemID, "canonical_id", canonicalID)
+						outcomes[owner].Selection = existingCopy
+						continue
+					}
+					createdCopies[slot] = owner
+				}
+			}
+			remainingOps = append(remainingOps, batchOp)
+			remainingOwners = append(remainingOwners, owner)
+		}
+		batchOps, batchOwners = remainingOps, remainingOwners
+	}
+
+	// --- Apply all writes in a single bulk write ---
+	opErrors, err := s.selectionDAO.BulkApply(ctx, batchOps, maxNotesRevisions)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error applying selection batch", "error", err)
+		return nil, fmt.Errorf("failed to apply selection batch: %w", err)
+	}
+
+	var updatedIDs []primitive.ObjectID
+	for j, batchOp := range batchOps {
+		owner := batchOwners[j]
+		opErr := opErrors[j]
+		switch {
+		case batchOp.Type == dao.SelectionBatchCreate && errors.Is(opErr, dao.ErrSelectionExists):
+			existingSelection, findErr := s.selectionDAO.FindByUserMonthSpotifyItem(ctx, userID, batchOp.Selection.MonthYear, batchOp.Selection.SpotifyItemID)
+			if findErr != nil {
+				outcomes[owner].Err = fmt.Errorf("error fetching existing selection after duplicate error: %w", findErr)
+				continue
+			}
+			outcomes[owner].Selection = existingSelection
+		case opErr != nil:
+			outcomes[owner].Err = opErr
+		case batchOp.Type == dao.SelectionBatchCreate:
+			outcomes[owner].Selection = batchOp.Selection
+			outcomes[owner].Created = true
+		case batchOp.Type == dao.SelectionBatchUpdate:
+			updatedIDs = append(updatedIDs, batchOp.SelectionID)
+		}
+	}
+
+	// --- Return the post-update state of updated selections ---
+	updated, err := s.selectionDAO.FindByIDs(ctx, updatedIDs)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error fetching updated selections after batch", "error", err)
+		return nil, fmt.Errorf("failed to retrieve updated selections: %w", err)
+	}
+	for _, selection := range updated {
+		outcomes[referencedBy[selection.ID]].Selection = selection
+	}
+	for owner, first := range copyOf {
+		outcomes[owner].Selection = outcomes[first].Selection
+		outcomes[owner].Err = outcomes[first].Err
+	}
+	for i := range outcomes {
+		sanitizeSelectionsForOutput(outcomes[i].Selection)
+	}
+
+	s.logger.InfoContext(ctx, "applied selection batch", "operations", len(ops))
+	return outcomes, nil
+}
+
+// CarryOverCandidates copies a user's candidates from one month into another without re-verifying
+// them against Spotify, since the items were verified and cached when first added. Only the item
+// itself is copied; notes, tags and listening context belong to the month they were written in.
+// Items already present in the target month, or another copy of the same recording or release, are skipped.
+func (s *UserSelectionService) CarryOverCandidates(ctx context.Context, userID string, req *models.CarryOverRequest) (_ *models.CarryOverResponse, err error) {
+	ctx, span := tracing.Start(ctx, "UserSelectionService.CarryOverCandidates",
+		attribute.String("carry_over.source_month", req.SourceMonth), attribute.String("carry_over.target_month", req.TargetMonth))
+	defer func() { tracing.End(span, err) }()
+	if !isValidMonthYear(req.SourceMonth) || !isValidMonthYear(req.TargetMonth) {
+		return nil, errors.New("invalid MonthYear format, expected YYYY-MM")
+	}
+	if req.SourceMonth == req.TargetMonth {
+		return nil, errors.New("invalid carry-over: source and target months must differ")
+	}
+	roles := req.Roles
+	if len(roles) == 0 {
+		roles = []models.SelectionRole{models.RoleMuseCandidate, models.RoleIckCandidate}
+	}
+	wanted := make(map[models.SelectionRole]bool, len(roles))
+	for _, role := range roles {
+		role = models.SelectionRole(strings.ToLower(string(role)))
+		if role != models.RoleMuseCandidate && role != models.RoleIckCandidate {
+			return nil, fmt.Errorf("invalid carry-over role: %s. Must be 'muse_candidate' or 'ick_candidate'", role)
+		}
+		wanted[role] = true
+	}
+
+	sourceSelections, err := s.selectionDAO.ListByUserAndMonth(ctx, userID, req.SourceMonth)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error listing source selections for carry-over", "source_month", req.SourceMonth, "error", err)
+		return nil, fmt.Errorf("failed to list source selections: %w", err)
+	}
+
+	response := &models.CarryOverResponse{Copied: []*models.UserSelection{}, Skipped: []models.CarryOverSkip{}}
+	alreadyInTarget := func(source *models.UserSelection) {
+		response.Skipped = append(response.Skipped, models.CarryOverSkip{
+			SourceSelectionID: source.ID,
+			SpotifyItemID:     source.SpotifyItemID,
+			Reason:            "already in target month",
+		})
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	var batchOps []dao.SelectionBatchOp
+	var sources []*models.UserSelection
+	carried := map[string]bool{} // Canonical IDs being carried over
+	for _, source := range sourceSelections {
+		if !wanted[source.SelectionRole] {
+			continue
+		}
+		// Another copy of the same recording or release counts as the same selection, as in CreateSelection
+		if source.CanonicalID != "" {
+			if carried[source.CanonicalID] {
+				alreadyInTarget(source)
+				continue
+			}
+			existingCopy, err := s.selectionDAO.FindByUserMonthCanonicalID(ctx, userID, req.TargetMonth, source.CanonicalID)
+			if err != nil && !errors.Is(err, mongo.ErrNoDocuments) {
+				return nil, fmt.Errorf("error checking for duplicate selection: %w", err)
+			}
+			if existingCopy != nil {
+				alreadyInTarget(source)
+				continue
+			}
+			carried[source.CanonicalID] = true
+		}
+		copied := &models.UserSelection{
+			UserID:        userID,
+			SpotifyItemID: source.SpotifyItemID,
+			ItemType:      source.ItemType,
+			SelectionRole: source.SelectionRole,
+			MonthYear:     req.TargetMonth,
+			AddedAt:       now,
+			UpdatedAt:     now,
+			ItemName:      source.ItemName,
+			AlbumName:     source.AlbumName,
+			ArtistNames:   source.ArtistNames,
+			CanonicalID:   source.CanonicalID,
+		}
+		if req.LinkOrigin {
+			originID := source.ID
+			copied.CarriedOverFrom = &originID
+		}
+		batchOps = append(batchOps, dao.SelectionBatchOp{Type: dao.SelectionBatchCreate, Selection: copied})
+		sources = append(sources, source)
+	}
+
+	opErrors, err := s.selectionDAO.BulkApply(ctx, batchOps, maxNotesRevisions)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "error carrying over selections", "source_month", req.SourceMonth, "target_month", req.TargetMonth, "error", err)
+		return nil, fmt.Errorf("failed to carry over selections: %w", err)
+	}
+	for i, batchOp := range batchOps {
+		switch {
+		case errors.Is(opErrors[i], dao.ErrSelectionExists):
+			alreadyInTarget(sources[i])
+		case opErrors[i] != nil:
+			response.Skipped = append(response.Skipped, models.CarryOverSkip{
+				SourceSelectionID: sources[i].ID,
+				SpotifyItemID:     sources[i].SpotifyItemID,
+				Reason:            opErrors[i].Error(),
+			})
+		default:
+			response.Copied = append(response.Copied, batchOp.Selection)
+		}
+	}
+
+	s.logger.InfoContext(ctx, "carried over candidates",
+		"copied", len(response.Copied), "candidates", len(batchOps), "source_month", req.SourceMonth, "target_month", req.TargetMonth)
+	return response, nil
+}
+
+// batchVerificationError maps a bulk Spotify fetch failure to the errors the single-item flow returns.
+func batchVerificationError(err error) error {
+	var spotifyErr spotify.Error
+	if errors.As(err, &spotifyErr) && (spotifyErr.Status == http.StatusUnauthorized || spotifyErr.Status == http.StatusForbidden) {
+		return fmt.Errorf("spotify authentication failed during verification (status %d)", spotifyErr.Status)
+	}
+	if err.Error() == "spotify item not found" {
+		return errors.New("spotify item not found")
+	}
+	return fmt.Errorf("failed to verify spotify item: %w", err)
+}
+
 // --- Helper Functions ---
 
+// newSelectionFromRequest validates a create request and builds the selection to insert.
+// Spotify metadata is filled in later, once the item has been verified and cached.
+func newSelectionFromRequest(userID string, req *models.CreateSelectionRequest) (*models.UserSelection, error) {
+	if !isValidMonthYear(req.MonthYear) {
+		return nil, errors.New("invalid MonthYear format, expected YYYY-MM")
+	}
+	if req.Role != models.RoleMuseCandidate && req.Role != models.RoleIckCandidate {
+		return nil, fmt.Errorf("invalid initial selection role: %s. Must be 'muse_candidate' or 'ick_candidate'", req.Role)
+	}
+	if req.ItemType != "track" && req.ItemType != "album" && req.ItemType != "artist" {
+		return nil, fmt.Errorf("invalid item_type: %s. Must be 'track', 'album', or 'artist'", req.ItemType)
+	}
+	if err := validateNotes(req.Notes); err != nil {
+		return nil, err
+	}
+	moodTags, err := normalizeMoodTags(req.MoodTags)
+	if err != nil {
+		return nil, err
+	}
+	listeningContext, err := normalizeListeningContext(req.ListeningContext)
+	if err != nil {
+		return nil, err
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	selection := &models.UserSelection{
+		UserID:           userID,
+		SpotifyItemID:    req.SpotifyItemID,
+		ItemType:         req.ItemType,
+		SelectionRole:    req.Role,
+		MonthYear:        req.MonthYear,
+		AddedAt:          now,
+		UpdatedAt:        now,
+		Notes:            req.Notes,
+		ListeningContext: listeningContext,
+	}
+	if len(moodTags) > 0 {
+		selection.MoodTags = moodTags
+	}
+	return selection, nil
+}
+
+// buildSelectionUpdates validates the requested changes and returns the fields to $set.
+func buildSelectionUpdates(input UpdateSelectionInput, now primitive.DateTime) (bson.M, error) {
+	updates := bson.M{}
+	if input.Role != nil {
+		newRole := *input.Role
+		if newRole != models.RoleMuseCandidate && newRole != models.RoleIckCandidate &&
+			newRole != models.RoleMuseSelected && newRole != models.RoleIckSelected {
+			return nil, fmt.Errorf("invalid target selection role: %s", newRole)
+		}
+		updates["selection_role"] = newRole
+	}
+	if input.Notes != nil {
+		if err := validateNotes(*input.Notes); err != nil {
+			return nil, err
+		}
+		updates["notes"] = *input.Notes
+	}
+	if input.MoodTags != nil {
+		moodTags, err := normalizeMoodTags(*input.MoodTags)
+		if err != nil {
+			return nil, err
+		}
+		updates["mood_tags"] = moodTags
+	}
+	if input.ListeningContext != nil {
+		listeningContext, err := normalizeListeningContext(input.ListeningContext)
+		if err != nil {
+			return nil, err
+		}
+		updates["listening_context"] = listeningContext
+	}
+
+	if len(updates) == 0 {
+		return nil, errors.New("no updates provided")
+	}
+	updates["updated_at"] = now
+	return updates, nil
+}
+
+// demotionRoles returns the role another selection must be demoted from (and to) when newRole
+// is a selected role. ok is false when the update does not select anything.
+func demotionRoles(newRole *models.SelectionRole) (from, to models.SelectionRole, ok bool) {
+	if newRole == nil {
+		return "", "", false
+	}
+	switch *newRole {
+	case models.RoleMuseSelected:
+		return models.RoleMuseSelected, models.RoleMuseCandidate, true
+	case models.RoleIckSelected:
+		return models.RoleIckSelected, models.RoleIckCandidate, true
+	}
+	return "", "", false
+}
+
+// notesRevisionFor returns the revision to record when newNotes replaces non-empty notes, or nil.
+func notesRevisionFor(current *models.UserSelection, newNotes *string, now primitive.DateTime) *models.NotesRevision {
+	if newNotes == nil || current.Notes == "" || *newNotes == current.Notes {
+		return nil
+	}
+	return &models.NotesRevision{Notes: current.Notes, EditedAt: now}
+}
+
 var monthYearRegex = regexp.MustCompile(`^\d{4}-\d{2}$`)
 
 func isValidMonthYear(monthYear string) bool {
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// fakeSelectionDAO serves the lookups ApplyBatch, CarryOverCandidates and the stats make from memory and records the writes
+// they would apply.
+// Methods neither reaches are left to the nil embedded interface.
+type fakeSelectionDAO struct {
+	dao.UserSelectionDAO
+	selections map[primitive.ObjectID]*models.UserSelection
+	applied    []dao.SelectionBatchOp
+	createErrs map[string]error // BulkApply result for creates, by Spotify item ID
+}
+
+func (f *fakeSelectionDAO) ListByUserAndMonth(ctx context.Context, userID, monthYear string) ([]*models.UserSelection, error) {
+	var found []*models.UserSelection
+	for _, selection := range f.selections {
+		if selection.UserID == userID && selection.MonthYear == monthYear {
+			found = append(found, selection)
+		}
+	}
+	// Map order is random; ObjectIDs from one process sort in creation order
+	sort.Slice(found, func(i, j int) bool { return found[i].ID.Hex() < found[j].ID.Hex() })
+	return found, nil
+}
+
+func (f *fakeSelectionDAO) GetUserSelectionsForYear(ctx context.Context, userID string, year int, itemType string, roles []string) ([]*models.UserSelection, error) {
+	var found []*models.UserSelection
+	for _, selection := range f.selections {
+		if selection.UserID == userID && selection.ItemType == itemType && strings.HasPrefix(selection.MonthYear, fmt.Sprintf("%d-", year)) &&
+			slices.Contains(roles, string(selection.SelectionRole)) {
+			found = append(found, selection)
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].ID.Hex() < found[j].ID.Hex() })
+	return found, nil
+}
+
+func (f *fakeSelectionDAO) FindByUserMonthCanonicalID(ctx context.Context, userID, monthYear, canonicalID string) (*models.UserSelection, error) {
+	for _, selection := range f.selections {
+		if selection.UserID == userID && selection.MonthYear == monthYear && selection.CanonicalID == canonicalID {
+			return selection, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeSelectionDAO) FindByUserMonthSpotifyItem(ctx context.Context, userID, monthYear, spotifyItemID string) (*models.UserSelection, error) {
+	for _, selection := range f.selections {
+		if selection.UserID == userID && selection.MonthYear == monthYear && selection.SpotifyItemID == spotifyItemID {
+			return selection, nil
+		}
+	}
+	return nil, mongo.ErrNoDocuments
+}
+
+func (f *fakeSelectionDAO) Create(ctx context.Context, selection *models.UserSelection) (*models.UserSelection, error) {
+	if _, err := f.FindByUserMonthSpotifyItem(ctx, selection.UserID, selection.MonthYear, selection.SpotifyItemID); err == nil {
+		return nil, dao.ErrSelectionExists
+	}
+	selection.ID = primitive.NewObjectID()
+	f.selections[selection.ID] = selection
+	return selection, nil
+}
+
+func (f *fakeSelectionDAO) FindByIDs(ctx context.Context, selectionIDs []primitive.ObjectID) ([]*models.UserSelection, error) {
+	var found []*models.UserSelection
+	for _, id := range selectionIDs {
+		if selection, ok := f.selections[id]; ok {
+			found = append(found, selection)
+		}
+	}
+	return found, nil
+}
+
+func (f *fakeSelectionDAO) BulkApply(ctx context.Context, ops []dao.SelectionBatchOp, maxNotesRevisions int) ([]error, error) {
+	f.applied = append(f.applied, ops...)
+	opErrors := make([]error, len(ops))
+	for i, op := range ops {
+		if op.Type == dao.SelectionBatchCreate {
+			opErrors[i] = f.createErrs[op.Selection.SpotifyItemID]
+		}
+	}
+	return opErrors, nil
+}
+
+func TestApplyBatchValidation(t *testing.T) {
+	mine := primitive.NewObjectID()
+	other := primitive.NewObjectID()
+	second := primitive.NewObjectID()
+	unchanged := primitive.NewObjectID()
+	misrole := primitive.NewObjectID()
+	selectionDAO := &fakeSelectionDAO{selections: map[primitive.ObjectID]*models.UserSelection{
+		mine:      {ID: mine, UserID: "user", ItemType: "track", MonthYear: "2025-03", SelectionRole: models.RoleMuseCandidate},
+		second:    {ID: second, UserID: "user", ItemType: "track", MonthYear: "2025-03", SelectionRole: models.RoleMuseCandidate},
+		unchanged: {ID: unchanged, UserID: "user", ItemType: "album", MonthYear: "2025-03"},
+		misrole:   {ID: misrole, UserID: "user", ItemType: "album", MonthYear: "2025-03"},
+		other:     {ID: other, UserID: "someone-else", ItemType: "track", MonthYear: "2025-03"},
+	}}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	service := NewUserSelectionService(selectionDAO, nil, nil, time.Hour, logger)
+
+	role := func(r models.SelectionRole) *models.SelectionRole { return &r }
+	notes := func(n string) *string { return &n }
+	tooManyTags := strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")
+
+	tests := []struct {
+		name    string
+		op      models.BatchSelectionOperation
+		wantErr string // Substring of the operation's error; empty when it should be applied
+	}{
+		{"create without item", models.BatchSelectionOperation{Op: "create", ItemType: "track", MonthYear: "2025-03", Role: role(models.RoleMuseCandidate)}, "spotify_item_id is required"},
+		{"create with bad month", models.BatchSelectionOperation{Op: "create", SpotifyItemID: "t1", ItemType: "track", MonthYear: "2025-3", Role: role(models.RoleMuseCandidate)}, "invalid MonthYear"},
+		{"create as selected", models.BatchSelectionOperation{Op: "create", SpotifyItemID: "t1", ItemType: "track", MonthYear: "2025-03", Role: role(models.RoleMuseSelected)}, "invalid initial selection role"},
+		{"create with bad item type", models.BatchSelectionOperation{Op: "create", SpotifyItemID: "t1", ItemType: "podcast", MonthYear: "2025-03", Role: role(models.RoleMuseCandidate)}, "invalid item_type"},
+		{"create with too many tags", models.BatchSelectionOperation{Op: "create", SpotifyItemID: "t1", ItemType: "track", MonthYear: "2025-03", Role: role(models.RoleIckCandidate), MoodTags: &tooManyTags}, "at most 10 mood tags"},
+		{"valid create without a Spotify token", models.BatchSelectionOperation{Op: "create", SpotifyItemID: "t1", ItemType: "track", MonthYear: "2025-03", Role: role("ICK_CANDIDATE")}, "spotify token is required"},
+		{"update with bad ID", models.BatchSelectionOperation{Op: "update", ID: "not-an-id", Notes: notes("x")}, "invalid selection ID"},
+		{"update of missing selection", models.BatchSelectionOperation{Op: "update", ID: primitive.NewObjectID().Hex(), Notes: notes("x")}, "selection not found"},
+		{"update of another user's selection", models.BatchSelectionOperation{Op: "update", ID: other.Hex(), Notes: notes("x")}, "unauthorized"},
+		{"update to an unknown role", models.BatchSelectionOperation{Op: "update", ID: misrole.Hex(), Role: role("favourite")}, "invalid target selection role"},
+		{"update selecting a muse", models.BatchSelectionOperation{Op: "update", ID: mine.Hex(), Role: role(models.RoleMuseSelected)}, ""},
+		{"second update of the same selection", models.BatchSelectionOperation{Op: "update", ID: mine.Hex(), Notes: notes("x")}, "already modified by operation 10"},
+		{"second muse for the month", models.BatchSelectionOperation{Op: "update", ID: second.Hex(), Role: role(models.RoleMuseSelected)}, "operation 10 already selects a track for 2025-03"},
+		{"update with nothing to change", models.BatchSelectionOperation{Op: "update", ID: unchanged.Hex()}, "no updates provided"},
+		{"delete of a selection already in the batch", models.BatchSelectionOperation{Op: "delete", ID: other.Hex()}, "already modified by operation 8"},
+		{"unsupported op", models.BatchSelectionOperation{Op: "upsert", ID: primitive.NewObjectID().Hex()}, "unsupported operation"},
+	}
+
+	ops := make([]models.BatchSelectionOperation, len(tests))
+	for i, tt := range tests {
+		ops[i] = tt.op
+	}
+	outcomes, err := service.ApplyBatch(context.Background(), "user", "", ops)
+	if err != nil {
+		t.Fatalf("ApplyBatch returned %v", err)
+	}
+	for i, tt := range tests {
+		gotErr := outcomes[i].Err
+		switch {
+		case tt.wantErr == "" && gotErr != nil:
+			t.Errorf("op %d (%s): unexpected error %v", i, tt.name, gotErr)
+		case tt.wantErr != "" && (gotErr == nil || !strings.Contains(gotErr.Error(), tt.wantErr)):
+			t.Errorf("op %d (%s): error %v, want one containing %q", i, tt.name, gotErr, tt.wantErr)
+		}
+	}
+	if len(selectionDAO.applied) != 1 || selectionDAO.applied[0].SelectionID != mine || selectionDAO.applied[0].Demote == nil {
+		t.Errorf("applied %+v, want only the muse selection with a demotion", selectionDAO.applied)
+	}
+}
+
+func TestBuildSelectionUpdates(t *testing.T) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	if _, err := buildSelectionUpdates(UpdateSelectionInput{SelectionID: "x", UserID: "user"}, now); err == nil || err.Error() != "no updates provided" {
+		t.Errorf("buildSelectionUpdates with no changes = %v, want \"no updates provided\"", err)
+	}
+	updates, err := buildSelectionUpdates(UpdateSelectionInput{MoodTags: &[]string{"Late Night", "late-night"}}, now)
+	if err != nil {
+		t.Fatalf("buildSelectionUpdates: %v", err)
+	}
+	if tags := updates["mood_tags"].([]string); len(tags) != 1 || tags[0] != "late-night" {
+		t.Errorf("mood_tags = %q, want [late-night]", tags)
+	}
+}
+
+func TestCarryOverCandidates(t *testing.T) {
+	selection := func(month, item, canonicalID string, role models.SelectionRole) *models.UserSelection {
+		return &models.UserSelection{ID: primitive.NewObjectID(), UserID: "user", SpotifyItemID: item, ItemType: "track", ItemName: item, MonthYear: month, SelectionRole: role, CanonicalID: canonicalID}
+	}
+	museCandidate := selection("2025-03", "t1", "isrc:A", models.RoleMuseCandidate)
+	ickCandidate := selection("2025-03", "t2", "", models.RoleIckCandidate)
+	selected := selection("2025-03", "t3", "isrc:C", models.RoleMuseSelected)
+	inTarget := selection("2025-03", "t4", "isrc:D", models.RoleMuseCandidate)
+	sameRecording := selection("2025-03", "t5", "isrc:A", models.RoleIckCandidate) // Another copy of t1's recording
+	raced := selection("2025-03", "t6", "", models.RoleIckCandidate)
+	failing := selection("2025-03", "t7", "", models.RoleMuseCandidate)
+	targetCopy := selection("2025-04", "t4-remaster", "isrc:D", models.RoleIckCandidate)
+	otherUser := selection("2025-03", "t8", "", models.RoleMuseCandidate)
+	otherUser.UserID = "someone-else"
+
+	tests := []struct {
+		name        string
+		req         models.CarryOverRequest
+		wantErr     string
+		wantCopied  []string          // Spotify item IDs, in order
+		wantSkipped map[string]string // Spotify item ID to a substring of the reason
+	}{
+		{name: "bad month", req: models.CarryOverRequest{SourceMonth: "2025-3", TargetMonth: "2025-04"}, wantErr: "invalid MonthYear"},
+		{name: "same month", req: models.CarryOverRequest{SourceMonth: "2025-03", TargetMonth: "2025-03"}, wantErr: "must differ"},
+		{name: "selected role", req: models.CarryOverRequest{SourceMonth: "2025-03", TargetMonth: "2025-04", Roles: []models.SelectionRole{models.RoleMuseSelected}}, wantErr: "invalid carry-over role"},
+		{
+			name:       "both candidate roles by default",
+			req:        models.CarryOverRequest{SourceMonth: "2025-03", TargetMonth: "2025-04"},
+			wantCopied: []string{"t1", "t2"},
+			wantSkipped: map[string]string{
+				"t4": "already in target month", "t5": "already in target month", "t6": "already in target month", "t7": "write failed",
+			},
+		},
+		{
+			name:        "muse candidates only, in any case",
+			req:         models.CarryOverRequest{SourceMonth: "2025-03", TargetMonth: "2025-04", Roles: []models.SelectionRole{"MUSE_CANDIDATE"}},
+			wantCopied:  []string{"t1"},
+			wantSkipped: map[string]string{"t4": "already in target month", "t7": "write failed"},
+		},
+		{name: "empty source month", req: models.CarryOverRequest{SourceMonth: "2025-01", TargetMonth: "2025-04"}, wantCopied: nil, wantSkipped: map[string]string{}},
+	}
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selectionDAO := &fakeSelectionDAO{
+				selections: map[primitive.ObjectID]*models.UserSelection{},
+				createErrs: map[string]error{"t6": dao.ErrSelectionExists, "t7": errors.New("write failed")},
+			}
+			for _, s := range []*models.UserSelection{museCandidate, ickCandidate, selected, inTarget, sameRecording, raced, failing, targetCopy, otherUser} {
+				selectionDAO.selections[s.ID] = s
+			}
+			service := NewUserSelectionService(selectionDAO, nil, nil, time.Hour, logger)
+
+			resp, err := service.CarryOverCandidates(context.Background(), "user", &tt.req)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("CarryOverCandidates() error = %v, want one containing %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CarryOverCandidates() unexpected error: %v", err)
+			}
+			var copied []string
+			for _, c := range resp.Copied {
+				copied = append(copied, c.SpotifyItemID)
+				if c.MonthYear != tt.req.TargetMonth || c.UserID != "user" || c.ID != (primitive.ObjectID{}) {
+					t.Errorf("copy of %s = %+v, want a new selection for user in %s", c.SpotifyItemID, c, tt.req.TargetMonth)
+				}
+			}
+			if strings.Join(copied, ",") != strings.Join(tt.wantCopied, ",") {
+				t.Errorf("copied %v, want %v", copied, tt.wantCopied)
+			}
+			if len(resp.Skipped) != len(tt.wantSkipped) {
+				t.Errorf("skipped %+v, want %v", resp.Skipped, tt.wantSkipped)
+			}
+			for _, skip := range resp.Skipped {
+				if want, ok := tt.wantSkipped[skip.SpotifyItemID]; !ok || !strings.Contains(skip.Reason, want) {
+					t.Errorf("skipped %s because %q, want %q", skip.SpotifyItemID, skip.Reason, want)
+				}
+			}
+		})
+	}
+}
+
+func TestCarryOverCandidatesLinksOrigin(t *testing.T) {
+	source := &models.UserSelection{ID: primitive.NewObjectID(), UserID: "user", SpotifyItemID: "t1", ItemType: "track", MonthYear: "2025-03", SelectionRole: models.RoleIckCandidate, Notes: "keep me here"}
+	selectionDAO := &fakeSelectionDAO{selections: map[primitive.ObjectID]*models.UserSelection{source.ID: source}}
+	service := NewUserSelectionService(selectionDAO, nil, nil, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	for _, link := range []bool{false, true} {
+		resp, err := service.CarryOverCandidates(context.Background(), "user", &models.CarryOverRequest{SourceMonth: "2025-03", TargetMonth: "2025-04", LinkOrigin: link})
+		if err != nil || len(resp.Copied) != 1 {
+			t.Fatalf("CarryOverCandidates(link %v) = %+v, %v; want one copy", link, resp, err)
+		}
+		copied := resp.Copied[0]
+		if link != (copied.CarriedOverFrom != nil) || (link && *copied.CarriedOverFrom != source.ID) {
+			t.Errorf("link %v: carried over from %v, want %s only when linking", link, copied.CarriedOverFrom, source.ID.Hex())
+		}
+		if copied.SelectionRole != models.RoleIckCandidate || copied.Notes != "" {
+			t.Errorf("copy has role %s and notes %q, want the source's role and no notes", copied.SelectionRole, copied.Notes)
+		}
+	}
+}
+
+// verifiedTransport answers every Spotify request with 200, standing in for the item verification call.
+type verifiedTransport struct{}
+
+func (verifiedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestCreateSelectionCanonicalDuplicates(t *testing.T) {
+	// Spotify clients are built on http.DefaultTransport, so the verification request never leaves the test
+	defaultTransport := http.DefaultTransport
+	http.DefaultTransport = verifiedTransport{}
+	defer func() { http.DefaultTransport = defaultTransport }()
+
+	fresh := primitive.NewDateTimeFromTime(time.Now())
+	track := func(id, isrc string) *models.SpotifyTrack {
+		return &models.SpotifyTrack{SpotifyID: id, Name: "Song " + id, ExternalIDs: map[string]string{"isrc": isrc}, LastFetchedAt: fresh}
+	}
+	syncService := NewSpotifySyncService(&fakeTrackDAO{tracks: map[string]*models.SpotifyTrack{
+		"single":  track("single", "USRC17607839"),
+		"album":   track("album", "us-rc1-76-07839"), // Same recording, printed with hyphens
+		"other":   track("other", "GBAYE0601498"),
+		"no-isrc": track("no-isrc", ""),
+	}}, nil, nil, nil, nil, nil, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	existingID := primitive.NewObjectID()
+	existing := &models.UserSelection{ID: existingID, UserID: "user", SpotifyItemID: "single", ItemType: "track", MonthYear: "2025-03",
+		SelectionRole: models.RoleMuseCandidate, CanonicalID: "isrc:USRC17607839"}
+
+	tests := []struct {
+		name          string
+		userID        string
+		item          string
+		month         string
+		wantExisting  bool // The existing selection is returned instead of a new one
+		wantCanonical string
+	}{
+		{"same recording under another ID", "user", "album", "2025-03", true, ""},
+		{"same Spotify item", "user", "single", "2025-03", true, ""},
+		{"same recording in another month", "user", "album", "2025-04", false, "isrc:USRC17607839"},
+		{"same recording for another user", "someone-else", "album", "2025-03", false, "isrc:USRC17607839"},
+		{"different recording", "user", "other", "2025-03", false, "isrc:GBAYE0601498"},
+		{"track without an ISRC", "user", "no-isrc", "2025-03", false, "spotify:track:no-isrc"},
+	}
+	for _, tt := range tests {
+		selectionDAO := &fakeSelectionDAO{selections: map[primitive.ObjectID]*models.UserSelection{existingID: existing}}
+		service := NewUserSelectionService(selectionDAO, syncService, nil, time.Hour, slog.New(slog.NewTextHandler(io.Discard, nil)))
+		got, err := service.CreateSelection(context.Background(), tt.userID, "token", &models.CreateSelectionRequest{
+			SpotifyItemID: tt.item, ItemType: "track", Role: models.RoleIckCandidate, MonthYear: tt.month,
+		})
+		if err != nil {
+			t.Errorf("%s: CreateSelection() unexpected error: %v", tt.name, err)
+			continue
+		}
+		if tt.wantExisting {
+			if got.ID != existingID || len(selectionDAO.selections) != 1 {
+				t.Errorf("%s: CreateSelection() = %s with %d selections stored, want the existing %s", tt.name, got.ID.Hex(), len(selectionDAO.selections), existingID.Hex())
+			}
+			continue
+		}
+		if got.ID == existingID || len(selectionDAO.selections) != 2 || got.CanonicalID != tt.wantCanonical || got.ItemName != "Song "+tt.item {
+			t.Errorf("%s: CreateSelection() = %+v with %d selections stored, want a new selection with canonical ID %s", tt.name, got, len(selectionDAO.selections), tt.wantCanonical)
+		}
+	}
+}
@@ -3,7 +3,7 @@ package services
 import (
 	"context"
 	"errors"
-	"log"
+	"log/slog"
 
 	"github.com/seven7een/museick/museick-backend/internal/dao"
 	"github.com/seven7een/museick/museick-backend/internal/models"
@@ -19,11 +19,12 @@ type UserService interface {
 // userServiceImpl implements the UserService interface.
 type userServiceImpl struct {
 	userDAO dao.UserDAO
+	logger  *slog.Logger
 }
 
 // NewUserService creates a new instance of UserService.
-func NewUserService(userDAO dao.UserDAO) UserService {
-	return &userServiceImpl{userDAO: userDAO}
+func NewUserService(userDAO dao.UserDAO, logger *slog.Logger) UserService {
+	return &userServiceImpl{userDAO: userDAO, logger: logger.With("component", "user_service")}
 }
 
 // SyncUser finds a user by Clerk subject ID (sub) and creates them if they don't exist.
@@ -36,12 +37,12 @@ func (s *userServiceImpl) SyncUser(ctx context.Context, sub string) error {
 
 	if err == nil && existingUser != nil {
 		// User already exists - Sync successful (or update last login etc.)
-		log.Printf("User with sub '%s' already exists. Sync successful.\n", sub)
+		s.logger.DebugContext(ctx, "user already exists, sync successful", "sub", sub)
 		// TODO: Optionally: Update last login timestamp here if needed
 		return nil
 	} else if errors.Is(err, mongo.ErrNoDocuments) {
 		// User does not exist - Create new user
-		log.Printf("User with sub '%s' not found. Creating new user.\n", sub)
+		s.logger.InfoContext(ctx, "user not found, creating new user", "sub", sub)
 		newUser := &models.User{
 			Sub: sub,
 			// Add default values if needed
@@ -50,16 +51,16 @@ func (s *userServiceImpl) SyncUser(ctx context.Context, sub string) error {
 
 		createErr := s.userDAO.Create(ctx, newUser)
 		if createErr != nil {
-			log.Printf("Error creating user during sync for sub '%s': %v\n", sub, createErr)
+			s.logger.ErrorContext(ctx, "error creating user during sync", "sub", sub, "error", createErr)
 			return createErr // Return the creation error
 		}
 		return nil // User created successfully
 	} else {
 		// Handle other potential errors from FindBySub
-		log.Printf("Error checking user existence for sub '%s': %v\n", sub, err)
+		s.logger.ErrorContext(ctx, "error checking user existence", "sub", sub, "error", err)
 		return err
 	}
 	// This part should ideally not be reached if logic above is correct, but putting in defensively
-	log.Printf("Unexpected state during user sync check for sub '%s'.\n", sub)
+	s.logger.ErrorContext(ctx, "unexpected state during user sync check", "sub", sub)
 	return errors.New("unexpected error during user sync")
 }
@@ -0,0 +1,329 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+const (
+	maxComparedYears     = 10
+	summaryGenresPerYear = 5
+	comparisonListLimit  = 20
+)
+
+// CompareYears compares a user's picks across two or more years: recurring artists, how the genre mix drifted,
+// repeat Muses, items that switched between Ick and Muse, and streaks that carried over New Year.
+func (s *StatsService) CompareYears(ctx context.Context, userID string, years []int, selectedOnly bool) (*models.YearComparisonResponse, error) {
+	years = uniqueYears(years)
+	if len(years) < 2 || len(years) > maxComparedYears {
+		return nil, fmt.Errorf("%w: compare between 2 and %d distinct years", ErrInvalidStatsRequest, maxComparedYears)
+	}
+	for _, year := range years {
+		if err := validateStatsYear(year); err != nil {
+			return nil, err
+		}
+	}
+	picks, err := s.yearlyPicks(ctx, userID, years, selectedOnly)
+	if err != nil {
+		return nil, err
+	}
+	return compareYears(years, selectedOnly, picks), nil
+}
+
+// Retrospective compares every year the user has picks in, as CompareYears does.
+func (s *StatsService) Retrospective(ctx context.Context, userID string, selectedOnly bool) (*models.YearComparisonResponse, error) {
+	picks, err := s.yearlyPicks(ctx, userID, nil, selectedOnly)
+	if err != nil {
+		return nil, err
+	}
+	years := make([]int, 0, len(picks))
+	for _, pick := range picks {
+		years = append(years, pick.Year)
+	}
+	return compareYears(uniqueYears(years), selectedOnly, picks), nil
+}
+
+func (s *StatsService) yearlyPicks(ctx context.Context, userID string, years []int, selectedOnly bool) ([]*models.YearlyPick, error) {
+	museRoles, ickRoles := museAndIckRoles(selectedOnly)
+	roleNames := func(roles []models.SelectionRole) []string {
+		names := make([]string, len(roles))
+		for i, role := range roles {
+			names[i] = string(role)
+		}
+		return names
+	}
+	picks, err := s.selectionDAO.AggregateYearlyPicks(ctx, userID, years, roleNames(museRoles), roleNames(ickRoles))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate selections: %w", err)
+	}
+	return picks, nil
+}
+
+// compareYears builds the comparison of the given years (ascending) from the aggregated picks.
+func compareYears(years []int, selectedOnly bool, picks []*models.YearlyPick) *models.YearComparisonResponse {
+	response := &models.YearComparisonResponse{
+		Years:            years,
+		SelectedOnly:     selectedOnly,
+		Summaries:        make([]models.YearSummary, 0, len(years)),
+		RecurringArtists: []models.RecurringArtist{},
+		GenreDrift:       []models.GenreDrift{},
+		RepeatMuses:      []models.RepeatMuse{},
+		Redemptions:      []models.RoleChange{},
+		FallsFromGrace:   []models.RoleChange{},
+		Streaks:          []models.SelectionStreak{},
+	}
+	byYear := map[int][]*models.YearlyPick{}
+	byItem := map[string][]*models.YearlyPick{}
+	var itemKeys []string
+	for _, pick := range picks {
+		byYear[pick.Year] = append(byYear[pick.Year], pick)
+		if byItem[pick.GroupKey] == nil {
+			itemKeys = append(itemKeys, pick.GroupKey)
+		}
+		byItem[pick.GroupKey] = append(byItem[pick.GroupKey], pick)
+	}
+
+	// Per-year summaries and genre shares, each pick's weight split across its artists' genres as in GenreTimeline
+	genreShares := map[string]map[int]float64{}
+	for _, year := range years {
+		summary := models.YearSummary{Year: year}
+		weights := map[string]float64{}
+		withGenres := 0
+		for _, pick := range byYear[year] {
+			summary.Picks++
+			if len(pick.MuseMonths) > 0 {
+				summary.Muses++
+			}
+			if len(pick.IckMonths) > 0 {
+				summary.Icks++
+			}
+			if addGenreWeights(weights, yearlyPickGenres(pick)) {
+				withGenres++
+			}
+		}
+		for genre, weight := range weights {
+			if genreShares[genre] == nil {
+				genreShares[genre] = map[int]float64{}
+			}
+			genreShares[genre][year] = weight / float64(withGenres)
+		}
+		summary.TopGenres = topGenreShares(weights, withGenres, summaryGenresPerYear)
+		response.Summaries = append(response.Summaries, summary)
+	}
+	if len(years) > 0 {
+		first, last := years[0], years[len(years)-1]
+		for genre, shares := range genreShares {
+			drift := models.GenreDrift{Genre: genre, Shares: make([]models.YearShare, 0, len(years)), Change: roundStat(shares[last] - shares[first])}
+			for _, year := range years {
+				drift.Shares = append(drift.Shares, models.YearShare{Year: year, Share: roundStat(shares[year])})
+			}
+			response.GenreDrift = append(response.GenreDrift, drift)
+		}
+		sort.Slice(response.GenreDrift, func(i, j int) bool {
+			a, b := math.Abs(response.GenreDrift[i].Change), math.Abs(response.GenreDrift[j].Change)
+			if a != b {
+				return a > b
+			}
+			return response.GenreDrift[i].Genre < response.GenreDrift[j].Genre
+		})
+		response.GenreDrift = response.GenreDrift[:min(len(response.GenreDrift), comparisonListLimit)]
+	}
+
+	response.RecurringArtists = recurringArtists(picks)
+
+	for _, key := range itemKeys {
+		history := byItem[key]
+		item := crossYearItem(history[len(history)-1])
+		var museYears []int
+		for _, pick := range history {
+			if len(pick.MuseMonths) > 0 {
+				museYears = append(museYears, pick.Year)
+			}
+		}
+		if len(museYears) > 1 {
+			response.RepeatMuses = append(response.RepeatMuses, models.RepeatMuse{CrossYearItem: item, Years: museYears})
+		}
+		ickMonths, museMonths := roleMonths(history)
+		if change, ok := roleChange(ickMonths, museMonths); ok {
+			change.CrossYearItem = item
+			response.Redemptions = append(response.Redemptions, change)
+		}
+		if change, ok := roleChange(museMonths, ickMonths); ok {
+			change.CrossYearItem = item
+			response.FallsFromGrace = append(response.FallsFromGrace, change)
+		}
+		response.Streaks = append(response.Streaks, crossYearStreaks(item, "muse", museMonths)...)
+		response.Streaks = append(response.Streaks, crossYearStreaks(item, "ick", ickMonths)...)
+	}
+
+	sort.SliceStable(response.RepeatMuses, func(i, j int) bool {
+		return len(response.RepeatMuses[i].Years) > len(response.RepeatMuses[j].Years)
+	})
+	sort.SliceStable(response.Redemptions, func(i, j int) bool { return response.Redemptions[i].ToMonth < response.Redemptions[j].ToMonth })
+	sort.SliceStable(response.FallsFromGrace, func(i, j int) bool { return response.FallsFromGrace[i].ToMonth < response.FallsFromGrace[j].ToMonth })
+	sort.SliceStable(response.Streaks, func(i, j int) bool {
+		if response.Streaks[i].Months != response.Streaks[j].Months {
+			return response.Streaks[i].Months > response.Streaks[j].Months
+		}
+		return response.Streaks[i].From < response.Streaks[j].From
+	})
+	response.RepeatMuses = response.RepeatMuses[:min(len(response.RepeatMuses), comparisonListLimit)]
+	response.Redemptions = response.Redemptions[:min(len(response.Redemptions), comparisonListLimit)]
+	response.FallsFromGrace = response.FallsFromGrace[:min(len(response.FallsFromGrace), comparisonListLimit)]
+	response.Streaks = response.Streaks[:min(len(response.Streaks), comparisonListLimit)]
+	return response
+}
+
+// recurringArtists lists the artists credited on picks in more than one year, most years first.
+func recurringArtists(picks []*models.YearlyPick) []models.RecurringArtist {
+	rollups := map[string]*models.RecurringArtist{}
+	for _, pick := range picks {
+		names := map[string]string{}
+		for _, artist := range pick.Artists {
+			names[artist.SpotifyID] = artist.Name
+		}
+		seen := map[string]bool{}
+		for _, credit := range pick.Credits {
+			if credit.ID == "" || seen[credit.ID] {
+				continue
+			}
+			seen[credit.ID] = true
+			rollup := rollups[credit.ID]
+			if rollup == nil {
+				rollup = &models.RecurringArtist{SpotifyID: credit.ID, Name: credit.Name}
+				rollups[credit.ID] = rollup
+			}
+			if name := names[credit.ID]; name != "" {
+				rollup.Name = name
+			}
+			if len(rollup.Years) == 0 || rollup.Years[len(rollup.Years)-1] != pick.Year {
+				rollup.Years = append(rollup.Years, pick.Year) // Picks come in year order
+			}
+			rollup.Picks++
+			if len(pick.MuseMonths) > 0 {
+				rollup.Muses++
+			}
+			if len(pick.IckMonths) > 0 {
+				rollup.Icks++
+			}
+		}
+	}
+	recurring := []models.RecurringArtist{}
+	for _, rollup := range rollups {
+		if len(rollup.Years) > 1 {
+			recurring = append(recurring, *rollup)
+		}
+	}
+	sort.Slice(recurring, func(i, j int) bool {
+		a, b := recurring[i], recurring[j]
+		if len(a.Years) != len(b.Years) {
+			return len(a.Years) > len(b.Years)
+		}
+		if a.Picks != b.Picks {
+			return a.Picks > b.Picks
+		}
+		return a.Name < b.Name
+	})
+	return recurring[:min(len(recurring), comparisonListLimit)]
+}
+
+// yearlyPickGenres returns the distinct genres of a pick's cached artists.
+func yearlyPickGenres(pick *models.YearlyPick) []string {
+	var genres []string
+	seen := map[string]bool{}
+	for _, artist := range pick.Artists {
+		for _, genre := range artist.Genres {
+			if !seen[genre] {
+				seen[genre] = true
+				genres = append(genres, genre)
+			}
+		}
+	}
+	return genres
+}
+
+// roleMonths collects an item's Ick and Muse months across years, ascending.
+func roleMonths(history []*models.YearlyPick) (ickMonths, museMonths []string) {
+	for _, pick := range history {
+		ickMonths = append(ickMonths, pick.IckMonths...)
+		museMonths = append(museMonths, pick.MuseMonths...)
+	}
+	sort.Strings(ickMonths)
+	sort.Strings(museMonths)
+	return ickMonths, museMonths
+}
+
+// roleChange finds the first month in the from role and the first month in the to role in a later year.
+func roleChange(fromMonths, toMonths []string) (models.RoleChange, bool) {
+	if len(fromMonths) == 0 {
+		return models.RoleChange{}, false
+	}
+	from := fromMonths[0]
+	for _, month := range toMonths {
+		if month[:4] > from[:4] {
+			return models.RoleChange{FromMonth: from, ToMonth: month}, true
+		}
+	}
+	return models.RoleChange{}, false
+}
+
+// crossYearStreaks finds the runs of consecutive months (ascending) that cross a year boundary.
+func crossYearStreaks(item models.CrossYearItem, role string, months []string) []models.SelectionStreak {
+	var streaks []models.SelectionStreak
+	start, previous := "", -1
+	flush := func(end string, length int) {
+		if start != "" && start[:4] != end[:4] {
+			streaks = append(streaks, models.SelectionStreak{CrossYearItem: item, Role: role, From: start, To: end, Months: length})
+		}
+	}
+	length, last := 0, ""
+	for _, month := range months {
+		year, m, ok := parseMonthYear(month)
+		if !ok {
+			continue
+		}
+		index := year*12 + m - 1
+		if index == previous {
+			continue
+		}
+		if index != previous+1 || start == "" {
+			flush(last, length)
+			start, length = month, 0
+		}
+		length++
+		previous, last = index, month
+	}
+	flush(last, length)
+	return streaks
+}
+
+func crossYearItem(pick *models.YearlyPick) models.CrossYearItem {
+	artistNames := pick.ArtistNames
+	if artistNames == nil {
+		artistNames = []string{}
+	}
+	return models.CrossYearItem{
+		SpotifyItemID: pick.SpotifyItemID,
+		ItemType:      pick.ItemType,
+		ItemName:      pick.ItemName,
+		ArtistNames:   artistNames,
+	}
+}
+
+// uniqueYears returns the distinct years, ascending.
+func uniqueYears(years []int) []int {
+	seen := map[int]bool{}
+	unique := []int{}
+	for _, year := range years {
+		if !seen[year] {
+			seen[year] = true
+			unique = append(unique, year)
+		}
+	}
+	sort.Ints(unique)
+	return unique
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/seven7een/museick/museick-backend/internal/models"
+)
+
+func TestRoleChange(t *testing.T) {
+	tests := []struct {
+		name       string
+		fromMonths []string
+		toMonths   []string
+		want       models.RoleChange
+		ok         bool
+	}{
+		{"never in the earlier role", nil, []string{"2024-09"}, models.RoleChange{}, false},
+		{"later year", []string{"2023-04", "2024-01"}, []string{"2023-09", "2024-09"}, models.RoleChange{FromMonth: "2023-04", ToMonth: "2024-09"}, true},
+		{"first later month wins", []string{"2023-04"}, []string{"2024-02", "2025-01"}, models.RoleChange{FromMonth: "2023-04", ToMonth: "2024-02"}, true},
+		{"same year only", []string{"2024-01"}, []string{"2024-06", "2024-12"}, models.RoleChange{}, false},
+		{"other role came first", []string{"2024-02"}, []string{"2023-05"}, models.RoleChange{}, false},
+		{"never in the later role", []string{"2023-04"}, nil, models.RoleChange{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := roleChange(tt.fromMonths, tt.toMonths)
+		if ok != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: roleChange(%q, %q) = %+v, %v; want %+v, %v", tt.name, tt.fromMonths, tt.toMonths, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCrossYearStreaks(t *testing.T) {
+	item := models.CrossYearItem{SpotifyItemID: "t1", ItemType: "track", ItemName: "Song", ArtistNames: []string{}}
+	streak := func(from, to string, months int) models.SelectionStreak {
+		return models.SelectionStreak{CrossYearItem: item, Role: "muse", From: from, To: to, Months: months}
+	}
+	tests := []struct {
+		name   string
+		months []string
+		want   []models.SelectionStreak
+	}{
+		{"no months", nil, nil},
+		{"crosses new year", []string{"2024-11", "2024-12", "2025-01", "2025-02"}, []models.SelectionStreak{streak("2024-11", "2025-02", 4)}},
+		{"within one year", []string{"2024-03", "2024-04", "2024-05"}, nil},
+		{"gap at new year", []string{"2024-12", "2025-02"}, nil},
+		{"single month", []string{"2024-12"}, nil},
+		{
+			"two streaks",
+			[]string{"2023-12", "2024-01", "2024-06", "2024-12", "2025-01"},
+			[]models.SelectionStreak{streak("2023-12", "2024-01", 2), streak("2024-12", "2025-01", 2)},
+		},
+		{
+			"spans several years",
+			[]string{"2023-12", "2024-01", "2024-02", "2024-03", "2024-04", "2024-05", "2024-06", "2024-07", "2024-08", "2024-09", "2024-10", "2024-11", "2024-12", "2025-01"},
+			[]models.SelectionStreak{streak("2023-12", "2025-01", 14)},
+		},
+		{"repeated month", []string{"2024-12", "2024-12", "2025-01"}, []models.SelectionStreak{streak("2024-12", "2025-01", 2)}},
+		{"malformed month skipped", []string{"2024-12", "bad", "2025-01"}, []models.SelectionStreak{streak("2024-12", "2025-01", 2)}},
+	}
+	for _, tt := range tests {
+		if got := crossYearStreaks(item, "muse", tt.months); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: crossYearStreaks(%q) = %+v, want %+v", tt.name, tt.months, got, tt.want)
+		}
+	}
+}
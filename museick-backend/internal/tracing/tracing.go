@@ -0,0 +1,143 @@
+// Package tracing configures OpenTelemetry tracing for the backend and
+// provides small helpers for starting and ending spans in services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName identifies spans created by this application's own code.
+const InstrumentationName = "github.com/seven7een/museick/museick-backend"
+
+// DefaultServiceName is used when Options.ServiceName is empty.
+const DefaultServiceName = "museick-backend"
+
+// Supported exporters.
+const (
+	ExporterNone   = "none"   // Tracing disabled (spans are no-ops)
+	ExporterStdout = "stdout" // Pretty-printed JSON spans on stdout
+	ExporterFile   = "file"   // JSON spans appended to FilePath, for offline inspection
+	ExporterOTLP   = "otlp"   // OTLP over HTTP to OTLPEndpoint (e.g. a local collector or Jaeger)
+)
+
+// Options configures tracing.
+type Options struct {
+	Exporter     string  // One of the Exporter* constants (default none)
+	ServiceName  string  // service.name resource attribute (default DefaultServiceName)
+	SampleRatio  float64 // Fraction of new traces to sample, 0 < ratio <= 1 (0 means 1)
+	FilePath     string  // Output file for the file exporter
+	OTLPEndpoint string  // host:port for the OTLP exporter (default localhost:4318)
+	OTLPInsecure bool    // Use plain HTTP for the OTLP exporter
+}
+
+// Setup installs the global tracer provider and propagators.
+// The returned function flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, opts Options) (func(context.Context) error, error) {
+	// Propagate W3C trace context even when not exporting, so upstream trace IDs still reach our logs
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	exporterName := strings.ToLower(opts.Exporter)
+	if exporterName == "" || exporterName == ExporterNone {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	var (
+		exporter sdktrace.SpanExporter
+		closer   io.Closer
+		err      error
+	)
+	switch exporterName {
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		if opts.FilePath == "" {
+			return nil, fmt.Errorf("tracing file exporter requires a file path")
+		}
+		var file *os.File
+		file, err = os.OpenFile(opts.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		closer = file
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	case ExporterOTLP:
+		clientOpts := []otlptracehttp.Option{}
+		if opts.OTLPEndpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpoint(opts.OTLPEndpoint))
+		}
+		if opts.OTLPInsecure {
+			clientOpts = append(clientOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err = otlptracehttp.New(ctx, clientOpts...)
+	default:
+		return nil, fmt.Errorf("unsupported tracing exporter %q, expected none, stdout, file or otlp", opts.Exporter)
+	}
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", exporterName, err)
+	}
+
+	serviceName := opts.ServiceName
+	if serviceName == "" {
+		serviceName = DefaultServiceName
+	}
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(serviceName),
+	))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build trace resource: %w", err)
+	}
+
+	ratio := opts.SampleRatio
+	if ratio <= 0 || ratio > 1 {
+		ratio = 1
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span from the global tracer provider. Spans are no-ops when tracing is disabled.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(InstrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End records err on the span (if non-nil) and ends it.
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
@@ -0,0 +1,110 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartAndEnd(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	defer otel.SetTracerProvider(previous)
+
+	ctx, parent := Start(context.Background(), "UserSelectionService.CreateSelection", attribute.String("spotify.item_id", "t1"))
+	_, child := Start(ctx, "SpotifySyncService.GetTrack")
+	End(child, errors.New("spotify item not found"))
+	End(parent, nil)
+
+	spans := recorder.Ended()
+	if len(spans) != 2 {
+		t.Fatalf("ended %d spans, want 2", len(spans))
+	}
+	failed, succeeded := spans[0], spans[1]
+	if failed.Parent().SpanID() != succeeded.SpanContext().SpanID() {
+		t.Errorf("child span's parent = %s, want %s", failed.Parent().SpanID(), succeeded.SpanContext().SpanID())
+	}
+	if failed.Status().Code != codes.Error || failed.Status().Description != "spotify item not found" || len(failed.Events()) != 1 {
+		t.Errorf("failed span status = %+v with %d events, want an error status and a recorded exception", failed.Status(), len(failed.Events()))
+	}
+	if succeeded.Status().Code != codes.Unset || len(succeeded.Events()) != 0 {
+		t.Errorf("succeeded span status = %+v with %d events, want unset and none", succeeded.Status(), len(succeeded.Events()))
+	}
+	if attrs := succeeded.Attributes(); len(attrs) != 1 || attrs[0] != attribute.String("spotify.item_id", "t1") {
+		t.Errorf("span attributes = %v, want spotify.item_id=t1", attrs)
+	}
+	if succeeded.InstrumentationScope().Name != InstrumentationName {
+		t.Errorf("instrumentation scope = %q, want %q", succeeded.InstrumentationScope().Name, InstrumentationName)
+	}
+}
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr string
+	}{
+		{name: "disabled by default", opts: Options{}},
+		{name: "disabled", opts: Options{Exporter: "NONE"}},
+		{name: "file without a path", opts: Options{Exporter: ExporterFile}, wantErr: "requires a file path"},
+		{name: "unknown exporter", opts: Options{Exporter: "zipkin"}, wantErr: `unsupported tracing exporter "zipkin"`},
+		{name: "unwritable file", opts: Options{Exporter: ExporterFile, FilePath: filepath.Join(t.TempDir(), "missing", "spans.json")}, wantErr: "failed to open trace file"},
+	}
+	for _, tt := range tests {
+		shutdown, err := Setup(context.Background(), tt.opts)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("%s: Setup() error = %v, want one containing %q", tt.name, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: Setup() unexpected error: %v", tt.name, err)
+			continue
+		}
+		if err := shutdown(context.Background()); err != nil {
+			t.Errorf("%s: shutdown() = %v", tt.name, err)
+		}
+	}
+}
+
+func TestSetupFileExporter(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	path := filepath.Join(t.TempDir(), "spans.json")
+	shutdown, err := Setup(context.Background(), Options{Exporter: ExporterFile, FilePath: path, ServiceName: "museick-test"})
+	if err != nil {
+		t.Fatalf("Setup() unexpected error: %v", err)
+	}
+	_, span := Start(context.Background(), "test-span")
+	End(span, nil)
+	// Shutdown flushes the batcher and closes the file
+	if err := shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown() = %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`"Name":"test-span"`, `"Value":"museick-test"`} {
+		if !strings.Contains(string(written), want) {
+			t.Errorf("trace file = %s, want it to contain %s", written, want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	// rawHTMLRegex matches HTML tags and comments embedded in markdown.
+	rawHTMLRegex = regexp.MustCompile(`(?s)<!--.*?-->|</?[A-Za-z][^<>]*>`)
+	// referenceDefinitionRegex matches a link reference definition: [label]: dest
+	referenceDefinitionRegex = regexp.MustCompile(`(?m)^( {0,3}\[[^\]]+\]:\s*<?)(\S+?)(>?(?:\s|$))`)
+	// safeAutolinkRegex matches autolinks (<https://...>) that should survive HTML escaping.
+	safeAutolinkRegex = regexp.MustCompile(`^<(?i:https?://|mailto:)[^\s<>]*>$`)
+	// inlineCodeRegex matches backtick code spans so their contents are left untouched.
+	inlineCodeRegex = regexp.MustCompile("`+[^`]*`+")
+)
+
+// unsafeURLSchemes are link schemes that can execute script or smuggle content when rendered.
+var unsafeURLSchemes = []string{"javascript:", "vbscript:", "data:", "file:"}
+
+// SanitizeMarkdown makes user-written markdown safe to hand to a renderer.
+// Raw HTML outside code is escaped so it renders as text, and links or images
+// pointing at unsafe schemes (javascript:, data:, ...) are replaced with "#".
+// Fenced code blocks and inline code spans are passed through unchanged.
+func SanitizeMarkdown(markdown string) string {
+	if markdown == "" {
+		return markdown
+	}
+
+	var out strings.Builder
+	inFence := false
+	fence := ""
+	lines := strings.SplitAfter(markdown, "\n")
+	for _, line := range lines {
+		trimmed := strings.TrimLeft(line, " ")
+		if marker := fenceMarker(trimmed); marker != "" {
+			if !inFence {
+				inFence, fence = true, marker
+			} else if strings.HasPrefix(trimmed, fence) {
+				inFence = false
+			}
+			out.WriteString(line)
+			continue
+		}
+		if inFence {
+			out.WriteString(line)
+			continue
+		}
+		out.WriteString(sanitizeMarkdownLine(line))
+	}
+	return out.String()
+}
+
+// fenceMarker returns the ``` or ~~~ run that opens or closes a fenced code block, if any.
+func fenceMarker(line string) string {
+	for _, ch := range []string{"`", "~"} {
+		if strings.HasPrefix(line, strings.Repeat(ch, 3)) {
+			n := len(line) - len(strings.TrimLeft(line, ch))
+			return strings.Repeat(ch, n)
+		}
+	}
+	return ""
+}
+
+// sanitizeMarkdownLine sanitizes everything on a line except inline code spans.
+func sanitizeMarkdownLine(line string) string {
+	var out strings.Builder
+	last := 0
+	for _, loc := range inlineCodeRegex.FindAllStringIndex(line, -1) {
+		out.WriteString(sanitizeMarkdownText(line[last:loc[0]]))
+		out.WriteString(line[loc[0]:loc[1]])
+		last = loc[1]
+	}
+	out.WriteString(sanitizeMarkdownText(line[last:]))
+	return out.String()
+}
+
+func sanitizeMarkdownText(text string) string {
+	text = rawHTMLRegex.ReplaceAllStringFunc(text, func(tag string) string {
+		if safeAutolinkRegex.MatchString(tag) {
+			return tag
+		}
+		return html.EscapeString(tag)
+	})
+	text = sanitizeLinkDestinations(text)
+	return referenceDefinitionRegex.ReplaceAllStringFunc(text, func(match string) string {
+		sub := referenceDefinitionRegex.FindStringSubmatch(match)
+		if isUnsafeURL(sub[2]) {
+			return sub[1] + "#" + sub[3]
+		}
+		return match
+	})
+}
+
+// sanitizeLinkDestinations replaces unsafe destinations of inline links and images: [text](dest "title").
+// Destinations may contain balanced parentheses, so they are scanned rather than matched with a regex.
+func sanitizeLinkDestinations(text string) string {
+	var out strings.Builder
+	for {
+		idx := strings.Index(text, "](")
+		if idx == -1 {
+			out.WriteString(text)
+			return out.String()
+		}
+		out.WriteString(text[:idx+2])
+		text = text[idx+2:]
+
+		start := len(text) - len(strings.TrimLeft(text, " \t"))
+		end, depth := start, 0
+		for end < len(text) {
+			ch := text[end]
+			if ch == ' ' || ch == '\t' || ch == '\n' || (ch == ')' && depth == 0) {
+				break
+			}
+			if ch == '(' {
+				depth++
+			} else if ch == ')' {
+				depth--
+			}
+			end++
+		}
+		if isUnsafeURL(text[start:end]) {
+			out.WriteString(text[:start])
+			out.WriteString("#")
+			text = text[end:]
+		}
+	}
+}
+
+func isUnsafeURL(dest string) bool {
+	// Browsers ignore whitespace and control characters inside schemes ("java\tscript:").
+	normalized := strings.Map(func(r rune) rune {
+		if r <= ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(html.UnescapeString(dest)))
+	normalized = strings.TrimPrefix(normalized, "<")
+	for _, scheme := range unsafeURLSchemes {
+		if strings.HasPrefix(normalized, scheme) {
+			return true
+		}
+	}
+	return false
+}
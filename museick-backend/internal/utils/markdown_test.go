@@ -0,0 +1,50 @@
+package utils
+
+import "testing"
+
+func TestSanitizeMarkdown(t *testing.T) {
+	tests := []struct {
+		name     string
+		markdown string
+		want     string
+	}{
+		{"empty", "", ""},
+		{"plain text", "Heard it on the *night bus*.", "Heard it on the *night bus*."},
+		{"safe link", "[live](https://example.com/a_(b))", "[live](https://example.com/a_(b))"},
+		{"javascript link", "[click](javascript:alert(1))", "[click](#)"},
+		{"javascript link with title", `[click](javascript:alert(1) "hi")`, `[click](# "hi")`},
+		{"uppercase scheme", "[click](JavaScript:alert(1))", "[click](#)"},
+		{"scheme split by encoded tab", "[click](java&#9;script:alert(1))", "[click](#)"},
+		{"entity-encoded scheme", "[click](&#106;avascript:alert(1))", "[click](#)"},
+		{"data image", "![cover](data:image/svg+xml;base64,PHN2Zz4=)", "![cover](#)"},
+		{"vbscript link", "[x](vbscript:msgbox)", "[x](#)"},
+		{"reference definition", "[cover]: javascript:alert(1)", "[cover]: #"},
+		{"safe reference definition", "[cover]: https://example.com", "[cover]: https://example.com"},
+		{"raw html", `<img src=x onerror="alert(1)">`, `&lt;img src=x onerror=&#34;alert(1)&#34;&gt;`},
+		{"html comment", "a <!-- hidden --> b", "a &lt;!-- hidden --&gt; b"},
+		{"autolink", "<https://example.com>", "<https://example.com>"},
+		{"inline code", "Use `<script>` and `[x](javascript:y)`", "Use `<script>` and `[x](javascript:y)`"},
+		{
+			"backtick fence",
+			"```html\n<script>alert(1)</script>\n[x](javascript:y)\n```\n<b>after</b>",
+			"```html\n<script>alert(1)</script>\n[x](javascript:y)\n```\n&lt;b&gt;after&lt;/b&gt;",
+		},
+		{
+			"tilde fence not closed by backticks",
+			"~~~\n```\n<i>still code</i>\n~~~\n<i>text</i>",
+			"~~~\n```\n<i>still code</i>\n~~~\n&lt;i&gt;text&lt;/i&gt;",
+		},
+		{
+			"longer fence needs a long enough close",
+			"````\n```\n<i>still code</i>\n````\n<i>text</i>",
+			"````\n```\n<i>still code</i>\n````\n&lt;i&gt;text&lt;/i&gt;",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeMarkdown(tt.markdown); got != tt.want {
+				t.Errorf("SanitizeMarkdown(%q) = %q, want %q", tt.markdown, got, tt.want)
+			}
+		})
+	}
+}
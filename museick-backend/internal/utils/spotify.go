@@ -2,14 +2,84 @@ package utils
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
 	"github.com/zmb3/spotify/v2"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/propagation"
 	"golang.org/x/oauth2"
 )
 
-// CreateTemporarySpotifyClient creates a new Spotify client using an access token
+// NewSpotifyHTTPClient returns an http.Client for calls to Spotify. Every request is
+// traced (as a child of the span in the request context) and counted in /metrics.
+func NewSpotifyHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Transport: spotifyTransport(), Timeout: timeout}
+}
+
+// CreateTemporarySpotifyClient creates a new Spotify client using an access token.
+// Requests go through the same instrumented transport as NewSpotifyHTTPClient.
 func CreateTemporarySpotifyClient(ctx context.Context, accessToken string) *spotify.Client {
 	token := &oauth2.Token{AccessToken: accessToken, TokenType: "Bearer"}
+	// oauth2 builds its transport on top of the client stored under this context key
+	ctx = context.WithValue(ctx, oauth2.HTTPClient, &http.Client{Transport: spotifyTransport()})
 	httpClient := oauth2.NewClient(ctx, oauth2.StaticTokenSource(token))
 	return spotify.New(httpClient)
 }
+
+// spotifyAPIBaseURL is the Web API root used by GetSpotifyJSON; it matches the spotify library's default.
+const spotifyAPIBaseURL = "https://api.spotify.com/v1/"
+
+// GetSpotifyJSON performs an authenticated GET against the Spotify Web API with client's token and
+// decodes the JSON response into result. It is for fields the spotify library doesn't model (an album's
+// label, for instance); use the client's own methods everywhere else. Error responses are returned as
+// spotify.Error, like the library does, so callers can inspect the status.
+func GetSpotifyJSON(ctx context.Context, client *spotify.Client, path string, query url.Values, result interface{}) error {
+	token, err := client.Token()
+	if err != nil {
+		return fmt.Errorf("spotify client has no token: %w", err)
+	}
+	endpoint := spotifyAPIBaseURL + strings.TrimPrefix(path, "/")
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	token.SetAuthHeader(req)
+
+	resp, err := NewSpotifyHTTPClient(30 * time.Second).Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var body struct {
+			Error spotify.Error `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&body) != nil || body.Error.Message == "" {
+			body.Error.Message = fmt.Sprintf("spotify: unexpected HTTP %d: %s", resp.StatusCode, http.StatusText(resp.StatusCode))
+		}
+		body.Error.Status = resp.StatusCode
+		return body.Error
+	}
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func spotifyTransport() http.RoundTripper {
+	return otelhttp.NewTransport(
+		metrics.SpotifyTransport(nil),
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return "spotify " + r.Method + " " + metrics.SpotifyEndpoint(r.URL.Path)
+		}),
+		// Spotify is a third party, so don't send it our trace context headers
+		otelhttp.WithPropagators(propagation.NewCompositeTextMapPropagator()),
+	)
+}
@@ -0,0 +1,50 @@
+// Package workers manages the lifecycle of background goroutines so they can
+// be stopped cleanly during graceful shutdown.
+package workers
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+)
+
+// Group runs background workers that share a single cancellation context.
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+	logger *slog.Logger
+}
+
+// NewGroup creates an empty worker group.
+func NewGroup(logger *slog.Logger) *Group {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Group{ctx: ctx, cancel: cancel, logger: logger.With("component", "workers")}
+}
+
+// Go starts fn in a goroutine. fn must return once its context is cancelled.
+func (g *Group) Go(name string, fn func(ctx context.Context)) {
+	g.wg.Add(1)
+	go func() {
+		defer g.wg.Done()
+		g.logger.Info("background worker started", "worker", name)
+		fn(g.ctx)
+		g.logger.Info("background worker stopped", "worker", name)
+	}()
+}
+
+// Stop cancels every worker and waits for them to return, or for ctx to expire.
+func (g *Group) Stop(ctx context.Context) error {
+	g.cancel()
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
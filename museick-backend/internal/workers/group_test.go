@@ -0,0 +1,42 @@
+package workers
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestGroupStop(t *testing.T) {
+	group := NewGroup(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	var stopped atomic.Int32
+	for _, name := range []string{"cache_gc", "image_mirror"} {
+		group.Go(name, func(ctx context.Context) {
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond) // Finish the current pass
+			stopped.Add(1)
+		})
+	}
+	if err := group.Stop(context.Background()); err != nil {
+		t.Fatalf("Stop() = %v, want nil", err)
+	}
+	if got := stopped.Load(); got != 2 {
+		t.Errorf("%d workers returned before Stop did, want 2", got)
+	}
+}
+
+func TestGroupStopTimesOut(t *testing.T) {
+	group := NewGroup(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	release := make(chan struct{})
+	defer close(release)
+	group.Go("stuck", func(ctx context.Context) { <-release })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := group.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Stop() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -2,8 +2,14 @@ package main
 
 import (
 	"context" // Add context import
-	"log"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -11,24 +17,31 @@ import (
 	"github.com/seven7een/museick/museick-backend/initializers"
 	"github.com/seven7een/museick/museick-backend/internal/dao"
 	"github.com/seven7een/museick/museick-backend/internal/handlers"
+	"github.com/seven7een/museick/museick-backend/internal/health"
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+	"github.com/seven7een/museick/museick-backend/internal/media"
+	"github.com/seven7een/museick/museick-backend/internal/migrations"
+	"github.com/seven7een/museick/museick-backend/internal/openapi"
+	"github.com/seven7een/museick/museick-backend/internal/ratelimit"
 	"github.com/seven7een/museick/museick-backend/internal/services"
+	"github.com/seven7een/museick/museick-backend/internal/tracing"
+	"github.com/seven7een/museick/museick-backend/internal/utils"
+	"github.com/seven7een/museick/museick-backend/internal/workers"
 	"github.com/seven7een/museick/museick-backend/middleware"
-
-	swaggerFiles "github.com/swaggo/files"
-	ginSwagger "github.com/swaggo/gin-swagger"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 
 	_ "github.com/seven7een/museick/museick-backend/docs" // Gin-Swagger docs
 )
 
 var server *gin.Engine
 
-// init runs before main() to load configuration and set up the Gin engine.
+// init runs before main() to load configuration.
 func init() {
 	err := initializers.LoadConfig(".") // Load .env file from current directory
 	if err != nil {
-		log.Fatal("❌ Could not load environment variables:", err)
+		slog.Error("could not load environment variables", "error", err)
+		os.Exit(1)
 	}
-	server = gin.Default() // Initialize Gin engine
 }
 
 // @title Museick API
@@ -43,7 +56,6 @@ func init() {
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
-// @host localhost:8080
 // @BasePath /
 // @schemes http https
 
@@ -52,107 +64,342 @@ func init() {
 // @name Authorization
 // @description Type "Bearer" followed by a space and JWT token.
 func main() {
+	// `museick-backend migrate ...` manages schema migrations instead of starting the server
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrateCommand(os.Args[2:]))
+	}
+
 	config := initializers.GetConfig()
 
+	// `museick-backend openapi` prints the OpenAPI 3 spec (written to docs/openapi3.json by `make swag`)
+	if len(os.Args) > 1 && os.Args[1] == "openapi" {
+		os.Exit(printOpenAPI())
+	}
+
+	// `museick-backend --print-config` shows the effective configuration (secrets masked) and whether it is valid
+	if len(os.Args) > 1 && (os.Args[1] == "--print-config" || os.Args[1] == "-print-config") {
+		os.Exit(printConfig(config))
+	}
+	if err := config.Validate(); err != nil {
+		slog.Error("invalid configuration, run with --print-config to inspect it", "profile", config.AppEnv, "error", err)
+		os.Exit(1)
+	}
+
+	logger, err := logging.New(logging.Options{Level: config.LogLevel, Format: config.LogFormat})
+	if err != nil {
+		slog.Error("could not configure logging", "error", err)
+		os.Exit(1)
+	}
+	slog.SetDefault(logger) // Route any package-level slog calls through the same handler and redaction
+	logger.Debug("configuration loaded", "config", config)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:     config.TracingExporter,
+		ServiceName:  config.TracingServiceName,
+		SampleRatio:  config.TracingSampleRatio,
+		FilePath:     config.TracingFilePath,
+		OTLPEndpoint: config.TracingOTLPEndpoint,
+		OTLPInsecure: config.TracingOTLPInsecure,
+	})
+	if err != nil {
+		logger.Error("could not configure tracing", "error", err)
+		os.Exit(1)
+	}
+
 	client, err := initializers.NewMongoConnection(config)
 	if err != nil {
-		log.Fatal("❌ Could not connect to MongoDB:", err)
-	}
-	// Add defer client.Disconnect for graceful shutdown
-	defer func() {
-		if err = client.Disconnect(context.Background()); err != nil {
-			log.Printf("Error disconnecting from MongoDB: %v", err)
-		} else {
-			log.Println("MongoDB connection closed.")
+		logger.Error("could not connect to MongoDB", "error", err)
+		os.Exit(1)
+	}
+	logger.Info("connected to MongoDB")
+
+	// Refuse to start against a schema this version doesn't expect
+	migrator, err := migrations.NewMigrator(client.Database(config.MongoDBName), migrations.All(), logger)
+	if err != nil {
+		logger.Error("invalid migration set", "error", err)
+		os.Exit(1)
+	}
+	if config.MigrateOnStart {
+		if _, err := migrator.Up(context.Background(), 0); err != nil {
+			logger.Error("could not apply migrations on start", "error", err)
+			os.Exit(1)
 		}
-	}()
+	}
+	if err := migrator.CheckRequired(context.Background()); err != nil {
+		logger.Error("refusing to start, run `museick-backend migrate up` or set MIGRATE_ON_START=true", "error", err)
+		os.Exit(1)
+	}
+	if pending, err := migrator.Pending(context.Background()); err == nil && len(pending) > 0 {
+		logger.Warn("optional migrations are pending, run `museick-backend migrate up` to apply them", "count", len(pending))
+	}
+
+	// The API contract, generated from the handler annotations
+	apiSpec, err := openapi.Load()
+	if err != nil {
+		logger.Error("could not load OpenAPI spec", "error", err)
+		os.Exit(1)
+	}
+	// Responses are only checked in the test profile, where the extra buffering and logging are worth it
+	openAPIValidation, err := middleware.OpenAPIValidation(apiSpec, config.AppEnv == initializers.ProfileTest, logger)
+	if err != nil {
+		logger.Error("could not build OpenAPI validator", "error", err)
+		os.Exit(1)
+	}
 
 	// --- Dependency Injection ---
 	// Core DAOs
-	userDAO := dao.NewUserDAO(client, config.MongoDBName, "users")
-	spotifyTrackDAO := dao.NewSpotifyTrackDAO(client, config.MongoDBName, "spotify_tracks")
-	spotifyAlbumDAO := dao.NewSpotifyAlbumDAO(client, config.MongoDBName, "spotify_albums")
-	spotifyArtistDAO := dao.NewSpotifyArtistDAO(client, config.MongoDBName, "spotify_artists")
-	userSelectionDAO := dao.NewUserSelectionDAO(client, config.MongoDBName, "user_selections")
+	userDAO := dao.NewUserDAO(client, config.MongoDBName, dao.UsersCollection, logger)
+	spotifyTrackDAO := dao.NewSpotifyTrackDAO(client, config.MongoDBName, dao.SpotifyTracksCollection, logger)
+	spotifyAlbumDAO := dao.NewSpotifyAlbumDAO(client, config.MongoDBName, dao.SpotifyAlbumsCollection, logger)
+	spotifyArtistDAO := dao.NewSpotifyArtistDAO(client, config.MongoDBName, dao.SpotifyArtistsCollection, logger)
+	spotifyRelatedArtistsDAO := dao.NewSpotifyRelatedArtistsDAO(client, config.MongoDBName, dao.SpotifyRelatedArtistsCollection, logger)
+	spotifyArtistTopTracksDAO := dao.NewSpotifyArtistTopTracksDAO(client, config.MongoDBName, dao.SpotifyArtistTopTracksCollection, logger)
+	userSelectionDAO := dao.NewUserSelectionDAO(client, config.MongoDBName, dao.UserSelectionsCollection, logger)
+	idempotencyDAO := dao.NewIdempotencyDAO(client, config.MongoDBName, dao.IdempotencyKeysCollection, logger)
+	mediaAssetDAO := dao.NewMediaAssetDAO(client, config.MongoDBName, dao.MediaAssetsCollection, logger)
+
+	// Mirrored images: in GridFS so every instance shares them, or on a local (or shared) volume
+	var mediaStore media.Store
+	switch config.MediaStore {
+	case "gridfs", "":
+		mediaStore, err = media.NewGridFSStore(client, config.MongoDBName, dao.MediaBucket)
+	case "filesystem":
+		mediaStore, err = media.NewFileStore(config.MediaDir)
+	default:
+		err = fmt.Errorf("unsupported media store %q, expected gridfs or filesystem", config.MediaStore)
+	}
+	if err != nil {
+		logger.Error("could not set up media store", "store", config.MediaStore, "error", err)
+		os.Exit(1)
+	}
 
 	// Core Services
-	userService := services.NewUserService(userDAO)
-	spotifyService := services.NewSpotifyService(config.SpotifyClientID, config.SpotifyClientSecret)               // Handles basic auth, token exchange with spotify
-	spotifySyncService := services.NewSpotifySyncService(spotifyTrackDAO, spotifyAlbumDAO, spotifyArtistDAO)       // Inject Track DAO
-	userSelectionService := services.NewUserSelectionService(userSelectionDAO, spotifySyncService, spotifyService) // Pass DAOs and other services
-	playlistService := services.NewPlaylistService(userSelectionDAO, spotifyService)
+	userService := services.NewUserService(userDAO, logger)
+	spotifyService := services.NewSpotifyService(config.SpotifyClientID, config.SpotifyClientSecret, logger) // Handles basic auth, token exchange with spotify
+	audioFeaturesProvider, err := services.NewAudioFeaturesProvider(config.AudioFeaturesProvider, config.AudioFeaturesFixture)
+	if err != nil {
+		logger.Error("could not set up audio features provider", "provider", config.AudioFeaturesProvider, "error", err)
+		os.Exit(1)
+	}
+	spotifySyncService := services.NewSpotifySyncService(
+		spotifyTrackDAO, spotifyAlbumDAO, spotifyArtistDAO, spotifyRelatedArtistsDAO, spotifyArtistTopTracksDAO, audioFeaturesProvider, logger) // Inject cache DAOs
+	userSelectionService := services.NewUserSelectionService(
+		userSelectionDAO, spotifySyncService, spotifyService, config.CacheRefreshThreshold, logger) // Pass DAOs and other services
+	playlistService := services.NewPlaylistService(userSelectionDAO, userDAO, spotifyService, spotifySyncService, logger)
+	journalService := services.NewJournalService(userSelectionDAO, logger)
+	statsService := services.NewStatsService(userSelectionDAO, spotifyTrackDAO, spotifyAlbumDAO, spotifyArtistDAO, spotifySyncService, logger)
 
 	// Handlers
-	userHandler := handlers.NewUserHandler(userService)
-	spotifyHandler := handlers.NewSpotifyHandler(spotifyService, userDAO)
-	selectionHandler := handlers.NewSelectionHandler(userSelectionService) // Handles POST/GET/PUT/DELETE on /selections
-	playlistHandler := handlers.NewPlaylistHandler(playlistService)
+	userHandler := handlers.NewUserHandler(userService, logger)
+	spotifyHandler := handlers.NewSpotifyHandler(spotifyService, userDAO, logger)
+	selectionHandler := handlers.NewSelectionHandler(userSelectionService, logger) // Handles POST/GET/PUT/DELETE on /selections
+	playlistHandler := handlers.NewPlaylistHandler(playlistService, logger)
+	journalHandler := handlers.NewJournalHandler(journalService, logger)
+	artistHandler := handlers.NewArtistHandler(spotifySyncService, config.CacheRefreshThreshold, logger)
+	mediaHandler := handlers.NewMediaHandler(mediaStore, logger)
+	statsHandler := handlers.NewStatsHandler(statsService, logger)
+
+	// Readiness checks and the background workers that feed them
+	backgroundWorkers := workers.NewGroup(logger)
+	spotifyProbe := health.NewSpotifyProbe(utils.NewSpotifyHTTPClient(3*time.Second), health.SpotifyTokenURL, time.Minute)
+	backgroundWorkers.Go("spotify_probe", spotifyProbe.Run)
+	if config.CacheGCEnabled {
+		cacheGC := services.NewCacheGCService(spotifyTrackDAO, spotifyAlbumDAO, spotifyArtistDAO,
+			spotifyRelatedArtistsDAO, spotifyArtistTopTracksDAO, config.CacheGCGrace, logger)
+		backgroundWorkers.Go("spotify_cache_gc", func(ctx context.Context) { cacheGC.Run(ctx, config.CacheGCInterval) })
+	}
+	if config.MediaMirrorEnabled {
+		imageMirror := services.NewImageMirrorService(mediaStore, mediaAssetDAO, spotifyTrackDAO, spotifyAlbumDAO, spotifyArtistDAO, logger)
+		backgroundWorkers.Go("image_mirror", func(ctx context.Context) { imageMirror.Run(ctx, config.MediaMirrorInterval) })
+	}
+
+	checker := health.NewChecker(2 * time.Second)
+	checker.Register(handlers.CheckMongo, health.MongoPing(client))
+	checker.Register(handlers.CheckMongoIndexes, health.MongoIndexes(client.Database(config.MongoDBName), map[string][]string{
+		dao.UsersCollection:          {dao.UserSubIndexName},
+		dao.UserSelectionsCollection: {dao.SelectionUniqueIndexName, dao.JournalTextIndexName},
+	}))
+	checker.Register(handlers.CheckSpotify, spotifyProbe.Check)
+	healthHandler := handlers.NewHealthHandler(checker, logger)
+
+	// Rate limiting: per IP on public routes, per user on /api, and a tighter per-user limit on routes that call Spotify
+	var rateLimitStore ratelimit.Store
+	switch config.RateLimitStore {
+	case "mongo":
+		rateLimitStore = ratelimit.NewMongoStore(client, config.MongoDBName, dao.RateLimitsCollection)
+	case "memory", "":
+		memoryStore := ratelimit.NewMemoryStore()
+		backgroundWorkers.Go("rate_limit_sweeper", func(ctx context.Context) { memoryStore.Run(ctx, time.Minute) })
+		rateLimitStore = memoryStore
+	default:
+		logger.Error("unsupported rate limit store, expected memory or mongo", "store", config.RateLimitStore)
+		os.Exit(1)
+	}
+	rateLimits := map[string]ratelimit.Limit{}
+	for group, value := range map[string]string{"public": config.RateLimitPublic, "api": config.RateLimitAPI, "spotify": config.RateLimitSpotify} {
+		limit, err := ratelimit.ParseLimit(value)
+		if err != nil {
+			logger.Error("invalid rate limit configuration", "group", group, "error", err)
+			os.Exit(1)
+		}
+		rateLimits[group] = limit
+	}
+	logger.Info("rate limits configured", "store", config.RateLimitStore,
+		"public", rateLimits["public"].String(), "api", rateLimits["api"].String(), "spotify", rateLimits["spotify"].String())
 
 	// --- End Dependency Injection ---
 
-	log.Printf("DEBUG: Configuring CORS with AllowOrigins: %v", config.ClientOrigin)
+	// Tracing, request IDs and access logging first so every later middleware and handler can use them
+	serviceName := config.TracingServiceName
+	if serviceName == "" {
+		serviceName = tracing.DefaultServiceName
+	}
+	logger.Debug("configuring CORS", "allow_origins", config.ClientOrigin)
+	server = newRouter(routeHandlers{
+		user:      userHandler,
+		spotify:   spotifyHandler,
+		selection: selectionHandler,
+		playlist:  playlistHandler,
+		journal:   journalHandler,
+		artist:    artistHandler,
+		media:     mediaHandler,
+		stats:     statsHandler,
+		health:    healthHandler,
+	}, routeMiddleware{
+		global: []gin.HandlerFunc{
+			otelgin.Middleware(serviceName),
+			middleware.RequestLogger(logger),
+			gin.Recovery(),
+			middleware.Metrics(),
+			cors.New(cors.Config{
+				AllowOrigins:     config.ClientOrigin, // Allow multiple origins
+				AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+				AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "x-spotify-token", middleware.RequestIDHeader, middleware.IdempotencyKeyHeader},
+				ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader, middleware.IdempotentReplayedHeader, "Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining"},
+				AllowCredentials: true,
+				MaxAge:           12 * time.Hour,
+			}),
+			// Apply Clerk client setup middleware globally
+			middleware.SetupClerk(logger),
+		},
+		publicRateLimit:   middleware.RateLimit(rateLimitStore, "public", rateLimits["public"], middleware.KeyByIP, logger),
+		authenticate:      middleware.AuthenticateClerkJWT(logger),
+		apiRateLimit:      middleware.RateLimit(rateLimitStore, "api", rateLimits["api"], middleware.KeyByUser, logger),
+		spotifyRateLimit:  middleware.RateLimit(rateLimitStore, "spotify", rateLimits["spotify"], middleware.KeyByUser, logger),
+		openAPIValidation: openAPIValidation,
+		idempotency:       middleware.Idempotency(idempotencyDAO, config.IdempotencyTTL, config.HTTPWriteTimeout, logger),
+	})
+	// Only believe X-Forwarded-For from our own proxies, so clients can't pick the IP they are rate limited by
+	if err := server.SetTrustedProxies(config.TrustedProxies); err != nil {
+		logger.Error("invalid trusted proxies", "error", err)
+		os.Exit(1)
+	}
 
-	server.Use(cors.New(cors.Config{
-		AllowOrigins:     config.ClientOrigin, // Allow multiple origins
-		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", "x-spotify-token"},
-		ExposeHeaders:    []string{"Content-Length"},
-		AllowCredentials: true,
-		MaxAge:           12 * time.Hour,
-	}))
+	// Routes and spec must agree. TestRoutesMatchOpenAPI catches drift in CI; this is a second line of defence
+	if problems := openapi.CheckRoutes(apiSpec, server.Routes()); len(problems) > 0 {
+		for _, problem := range problems {
+			logger.Warn("route and OpenAPI spec disagree", "problem", problem)
+		}
+		if config.AppEnv == initializers.ProfileTest {
+			logger.Error("refusing to start with routes missing from or extra to the OpenAPI spec, update the handler annotations and run `make swag`", "count", len(problems))
+			os.Exit(1)
+		}
+	}
+
+	srv := &http.Server{
+		Addr:              ":" + config.ServerPort,
+		Handler:           server,
+		ReadHeaderTimeout: config.HTTPReadHeaderTimeout,
+		ReadTimeout:       config.HTTPReadTimeout,
+		WriteTimeout:      config.HTTPWriteTimeout,
+		IdleTimeout:       config.HTTPIdleTimeout,
+	}
+
+	// Stop on SIGINT/SIGTERM; a second signal kills the process immediately
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stopSignals()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server starting", "port", config.ServerPort)
+		if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+		close(serverErr)
+	}()
 
-	// Apply Clerk client setup middleware globally
-	server.Use(middleware.SetupClerk())
-
-	// --- Public Routes ---
-	router := server.Group("/")
-	{
-		router.GET("/", func(ctx *gin.Context) {
-			ctx.JSON(http.StatusOK, gin.H{"status": "success", "message": "Welcome to Museick API"})
-		})
-		router.GET("/ping", func(ctx *gin.Context) { ctx.JSON(http.StatusOK, "pong") })
-		// TODO: Implement a more robust DB health check
-		router.GET("/db_health", func(ctx *gin.Context) {
-			// Use a timeout context for the ping
-			pingCtx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
-			defer cancel()
-			if client.Ping(pingCtx, nil) == nil {
-				ctx.JSON(http.StatusOK, gin.H{"status": "MongoDB connection healthy"})
-			} else {
-				ctx.JSON(http.StatusInternalServerError, gin.H{"status": "MongoDB connection unhealthy"})
-			}
-		})
-		// Swagger documentation route
-		url := ginSwagger.URL("/swagger/doc.json") // The url pointing to API definition
-		router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
-	}
-
-	// --- API Routes (Protected by Clerk JWT Authentication) ---
-	api := router.Group("/api")
-	{
-		// Apply Clerk JWT authentication middleware to all /api routes
-		api.Use(middleware.AuthenticateClerkJWT())
-
-		// User Sync Route (Ensures user exists in DB after Clerk sign-in)
-		api.POST("/users/sync", userHandler.SyncUser)
-
-		// User Selection Routes (New)
-		api.POST("/selections", selectionHandler.CreateSelection)                 // Add a candidate/muse/ick
-		api.GET("/selections/:monthYear", selectionHandler.ListSelectionsByMonth) // List selections for a month (YYYY-MM)
-		api.PUT("/selections/:id", selectionHandler.UpdateSelection)              // Update a selection (e.g., change type, notes)
-		api.DELETE("/selections/:id", selectionHandler.DeleteSelection)           // Delete a selection
-
-		// Spotify Auth Routes (Need auth because they interact with user-specific data/tokens)
-		api.POST("/spotify/exchange-code", spotifyHandler.ExchangeCodeForToken) // Exchanges auth code for user tokens
-		api.POST("/spotify/refresh-token", spotifyHandler.RefreshAccessToken)   // Refreshes user's access token
-		// TODO: Consider if refresh token endpoint needs better security/design
-
-		// Playlist Routes
-		api.POST("/playlists", playlistHandler.CreatePlaylist)
-	}
-
-	log.Printf("🚀 Server starting on port %s", config.ServerPort)
-	if err := server.Run(":" + config.ServerPort); err != nil {
-		log.Fatal("❌ Server failed to start:", err)
+	exitCode := 0
+	select {
+	case <-signalCtx.Done():
+		logger.Info("shutdown signal received, draining requests")
+	case err := <-serverErr:
+		logger.Error("server failed", "error", err)
+		exitCode = 1
+	}
+	stopSignals()
+
+	// Fail readiness first and keep serving while load balancers notice, then drain in-flight requests
+	checker.SetShuttingDown()
+	if config.ShutdownReadinessDelay > 0 {
+		logger.Info("failing readiness before closing the listener", "delay", config.ShutdownReadinessDelay)
+		time.Sleep(config.ShutdownReadinessDelay)
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("error draining HTTP server", "error", err)
+		exitCode = 1
+	}
+	if err := backgroundWorkers.Stop(shutdownCtx); err != nil {
+		logger.Error("background workers did not stop in time", "error", err)
+		exitCode = 1
+	}
+	// Flush buffered spans before closing MongoDB so spans from the last requests are exported
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("error shutting down tracing", "error", err)
+	}
+	// MongoDB gets its own deadline: draining and the workers may have used up shutdownCtx
+	disconnectCtx, cancelDisconnect := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelDisconnect()
+	if err := client.Disconnect(disconnectCtx); err != nil {
+		logger.Error("error disconnecting from MongoDB", "error", err)
+	} else {
+		logger.Info("MongoDB connection closed")
+	}
+
+	logger.Info("server stopped")
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+}
+
+// printConfig writes the effective configuration to stdout and any validation errors to stderr,
+// returning the process exit code.
+func printConfig(config *initializers.Config) int {
+	if err := initializers.PrintConfig(os.Stdout, config); err != nil {
+		fmt.Fprintln(os.Stderr, "could not print configuration:", err)
+		return 1
+	}
+	if err := config.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "configuration is invalid:\n%v\n", err)
+		return 1
+	}
+	fmt.Fprintln(os.Stderr, "configuration is valid")
+	return 0
+}
+
+// printOpenAPI writes the OpenAPI 3 spec to stdout as indented JSON and returns the process exit code.
+func printOpenAPI() int {
+	doc, err := openapi.Load()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not load OpenAPI spec:", err)
+		return 1
+	}
+	out, err := json.MarshalIndent(doc, "", "    ")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not encode OpenAPI spec:", err)
+		return 1
 	}
+	fmt.Println(string(out))
+	return 0
 }
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/handlers"
+	"github.com/seven7een/museick/museick-backend/internal/health"
+	"github.com/seven7een/museick/museick-backend/internal/openapi"
+)
+
+// TestRoutesMatchOpenAPI fails when a route is added, removed or moved without regenerating the spec (`make swag`).
+func TestRoutesMatchOpenAPI(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	next := func(c *gin.Context) { c.Next() }
+	router := newRouter(routeHandlers{
+		user:      handlers.NewUserHandler(nil, logger),
+		spotify:   handlers.NewSpotifyHandler(nil, nil, logger),
+		selection: handlers.NewSelectionHandler(nil, logger),
+		playlist:  handlers.NewPlaylistHandler(nil, logger),
+		journal:   handlers.NewJournalHandler(nil, logger),
+		artist:    handlers.NewArtistHandler(nil, time.Hour, logger),
+		media:     handlers.NewMediaHandler(nil, logger),
+		stats:     handlers.NewStatsHandler(nil, logger),
+		health:    handlers.NewHealthHandler(health.NewChecker(time.Second), logger),
+	}, routeMiddleware{
+		publicRateLimit:   next,
+		authenticate:      next,
+		apiRateLimit:      next,
+		spotifyRateLimit:  next,
+		openAPIValidation: next,
+		idempotency:       next,
+	})
+
+	doc, err := openapi3.NewLoader().LoadFromFile("docs/openapi3.json")
+	if err != nil {
+		t.Fatalf("loading docs/openapi3.json: %v", err)
+	}
+	for _, problem := range openapi.CheckRoutes(doc, router.Routes()) {
+		t.Errorf("route and docs/openapi3.json disagree: %s", problem)
+	}
+
+	// docs/openapi3.json is generated from the embedded Swagger document, so the two must describe the same routes
+	embedded, err := openapi.Load()
+	if err != nil {
+		t.Fatalf("loading embedded spec: %v", err)
+	}
+	for _, problem := range openapi.CheckRoutes(embedded, router.Routes()) {
+		t.Errorf("route and embedded Swagger document disagree: %s", problem)
+	}
+}
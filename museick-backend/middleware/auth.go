@@ -2,14 +2,16 @@ package middleware
 
 import (
 	"errors"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
 	"strings"
 
 	"github.com/clerkinc/clerk-sdk-go/clerk"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/seven7een/museick/museick-backend/initializers"
+	"github.com/seven7een/museick/museick-backend/internal/logging"
 )
 
 // ClerkClientKey is the key used to store the Clerk client in the Gin context.
@@ -22,17 +24,19 @@ const ClerkClaimsKey = "clerkClaims"
 const ClerkUserIDKey = "user_sub"
 
 // SetupClerk initializes the Clerk client and adds it to the context.
-func SetupClerk() gin.HandlerFunc {
+func SetupClerk(logger *slog.Logger) gin.HandlerFunc {
 	config := initializers.GetConfig()
 	if config.ClerkSecretKey == "" {
-		log.Fatal("❌ Clerk Secret Key not configured in environment variables (CLERK_SECRET_KEY)")
+		logger.Error("clerk secret key not configured in environment variables (CLERK_SECRET_KEY)")
+		os.Exit(1)
 	}
 
 	client, err := clerk.NewClient(config.ClerkSecretKey)
 	if err != nil {
-		log.Fatalf("❌ Failed to initialize Clerk client: %v", err)
+		logger.Error("failed to initialize clerk client", "error", err)
+		os.Exit(1)
 	}
-	log.Println("✅ Clerk client initialized successfully.")
+	logger.Info("clerk client initialized")
 
 	return func(c *gin.Context) {
 		c.Set(ClerkClientKey, client)
@@ -41,41 +45,43 @@ func SetupClerk() gin.HandlerFunc {
 }
 
 // AuthenticateClerkJWT validates the Clerk JWT from the Authorization header.
-func AuthenticateClerkJWT() gin.HandlerFunc {
+// On success the user's sub is stored in the Gin context and in the request context,
+// so log lines written further down the chain are tagged with it.
+func AuthenticateClerkJWT(logger *slog.Logger) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		ctx := c.Request.Context()
 		clerkClientValue, exists := c.Get(ClerkClientKey)
 		if !exists {
-			log.Println("❌ Clerk client not found in context. Ensure SetupClerk middleware runs first.")
+			logger.ErrorContext(ctx, "clerk client not found in context, ensure SetupClerk middleware runs first")
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Server configuration error"})
 			return
 		}
 
 		client, ok := clerkClientValue.(clerk.Client)
 		if !ok {
-			log.Println("❌ Invalid Clerk client type in context.")
+			logger.ErrorContext(ctx, "invalid clerk client type in context")
 			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Server configuration error"})
 			return
 		}
 
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			log.Println("❌ No Authorization header present")
+			logger.DebugContext(ctx, "no authorization header present")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header required"})
 			return
 		}
 
 		parts := strings.Split(authHeader, " ")
 		if len(parts) != 2 || strings.ToLower(parts[0]) != "bearer" {
-			log.Printf("❌ Invalid Authorization header format: %s", authHeader)
+			logger.DebugContext(ctx, "invalid authorization header format")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header format must be Bearer {token}"})
 			return
 		}
 		sessionToken := parts[1]
-		log.Printf("🔍 Attempting to verify token: %s...", sessionToken[:10]) // Show first 10 chars for debugging
 
 		sessClaims, err := client.VerifyToken(sessionToken)
 		if err != nil {
-			log.Printf("⚠️ Clerk token verification failed: %v\n", err)
+			logger.WarnContext(ctx, "clerk token verification failed", "error", err)
 			if errors.Is(err, jwt.ErrTokenExpired) {
 				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Token has expired"})
 			} else {
@@ -86,14 +92,15 @@ func AuthenticateClerkJWT() gin.HandlerFunc {
 
 		userID := sessClaims.Subject
 		if userID == "" {
-			log.Println("❌ Valid Clerk token is missing 'sub' (Subject) claim.")
+			logger.WarnContext(ctx, "valid clerk token is missing 'sub' (Subject) claim")
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token claims"})
 			return
 		}
 
-		log.Printf("✅ Successfully authenticated user: %s", userID)
 		c.Set(ClerkClaimsKey, sessClaims)
 		c.Set(ClerkUserIDKey, userID)
+		c.Request = c.Request.WithContext(logging.WithUserSub(ctx, userID))
+		logger.DebugContext(c.Request.Context(), "authenticated user")
 		c.Next()
 	}
 }
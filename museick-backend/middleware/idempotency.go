@@ -0,0 +1,184 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// IdempotencyKeyHeader is the request header carrying the client's idempotency key.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to "true" on responses replayed from a stored result.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// Responses larger than this are not stored; the key is released so a retry runs the request again.
+const maxIdempotentResponseBytes = 1 << 20
+
+// idempotencyResponseWriter copies the response body so it can be stored after the handler finishes.
+type idempotencyResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *idempotencyResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *idempotencyResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST, PUT and DELETE requests that carry an Idempotency-Key safe to retry.
+// The first request with a key runs normally and its response is stored for ttl; a retry with the same
+// method, path and body gets the stored response, a concurrent retry gets 409, and reusing the key for a
+// different request gets 422. 5xx responses are not stored so the client can retry them.
+// The first request holds the key for lease and its context is cancelled when the lease runs out; if it dies
+// without storing or releasing the key, a retry after the lease runs the request again instead of getting 409
+// until ttl, and the first request can no longer store its response.
+// Keys are scoped per user, so this must run after AuthenticateClerkJWT.
+func Idempotency(idempotencyDAO dao.IdempotencyDAO, ttl, lease time.Duration, logger *slog.Logger) gin.HandlerFunc {
+	logger = logger.With("component", "idempotency")
+	return func(c *gin.Context) {
+		switch c.Request.Method {
+		case http.MethodPost, http.MethodPut, http.MethodDelete:
+		default:
+			c.Next()
+			return
+		}
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if !validIdempotencyKey(key) {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key must be 1-255 printable ASCII characters"})
+			return
+		}
+
+		ctx := c.Request.Context()
+		userSub := c.GetString(ClerkUserIDKey)
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Could not read request body"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(c.Request.Method, c.Request.URL.RequestURI(), body)
+		now := time.Now().UTC()
+		token := primitive.NewObjectID()
+		existing, err := idempotencyDAO.Reserve(ctx, &models.IdempotencyRecord{
+			UserSub:     userSub,
+			Key:         key,
+			Token:       token,
+			Fingerprint: fingerprint,
+			State:       models.IdempotencyInProgress,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+			LockedUntil: now.Add(lease),
+		})
+		if err != nil {
+			// Running the request without the guarantee could repeat side effects the client is trying to avoid
+			c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "Idempotency store unavailable, retry later"})
+			return
+		}
+		if existing != nil {
+			replayIdempotentResponse(c, existing, fingerprint, logger)
+			return
+		}
+
+		writer := &idempotencyResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		stored := false
+		// Store or release even if the client disconnected or a handler panicked
+		storeCtx := context.WithoutCancel(ctx)
+		defer func() {
+			if !stored {
+				if err := idempotencyDAO.Release(storeCtx, userSub, key, token); err != nil {
+					logger.ErrorContext(storeCtx, "could not release idempotency key", "error", err)
+				}
+			}
+		}()
+
+		// Past the lease a retry may take the key over, so the handler must not keep running side effects
+		leaseCtx, cancel := context.WithDeadline(ctx, now.Add(lease))
+		defer cancel()
+		c.Request = c.Request.WithContext(leaseCtx)
+
+		c.Next()
+
+		status := writer.Status()
+		if status >= http.StatusInternalServerError || writer.body.Len() > maxIdempotentResponseBytes {
+			return
+		}
+		err = idempotencyDAO.Complete(storeCtx, userSub, key, token, status, writer.Header().Get("Content-Type"), writer.body.Bytes())
+		if errors.Is(err, dao.ErrIdempotencyLeaseLost) {
+			logger.WarnContext(storeCtx, "request outlived its idempotency lease, response not stored", "status", status)
+			stored = true // The key belongs to another request now; there is nothing of ours to release
+			return
+		}
+		if err != nil {
+			logger.ErrorContext(storeCtx, "could not store idempotent response", "error", err)
+			return
+		}
+		stored = true
+	}
+}
+
+func replayIdempotentResponse(c *gin.Context, record *models.IdempotencyRecord, fingerprint string, logger *slog.Logger) {
+	ctx := c.Request.Context()
+	switch {
+	case record.Fingerprint != fingerprint:
+		logger.WarnContext(ctx, "idempotency key reused for a different request")
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used for a different request"})
+	case record.State != models.IdempotencyCompleted:
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "A request with this Idempotency-Key is still being processed"})
+	default:
+		logger.InfoContext(ctx, "replaying idempotent response", "status", record.Status)
+		c.Header(IdempotentReplayedHeader, "true")
+		contentType := record.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.Data(record.Status, contentType, record.Body)
+		c.Abort()
+	}
+}
+
+// requestFingerprint identifies a request by method, path with query, and body.
+func requestFingerprint(method, uri string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(method))
+	h.Write([]byte{0})
+	h.Write([]byte(uri))
+	h.Write([]byte{0})
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func validIdempotencyKey(key string) bool {
+	if len(key) == 0 || len(key) > 255 {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < 0x21 || key[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,261 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/dao"
+	"github.com/seven7een/museick/museick-backend/internal/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// fakeIdempotencyDAO keeps records in memory with the same reservation, takeover and token rules as the MongoDB DAO.
+type fakeIdempotencyDAO struct {
+	records    map[string]*models.IdempotencyRecord // By user and key
+	reserveErr error
+}
+
+func newFakeIdempotencyDAO() *fakeIdempotencyDAO {
+	return &fakeIdempotencyDAO{records: map[string]*models.IdempotencyRecord{}}
+}
+
+func (f *fakeIdempotencyDAO) Reserve(ctx context.Context, record *models.IdempotencyRecord) (*models.IdempotencyRecord, error) {
+	if f.reserveErr != nil {
+		return nil, f.reserveErr
+	}
+	id := record.UserSub + "|" + record.Key
+	existing, found := f.records[id]
+	if !found {
+		stored := *record
+		f.records[id] = &stored
+		return nil, nil
+	}
+	if existing.Fingerprint == record.Fingerprint && existing.State == models.IdempotencyInProgress && existing.LockedUntil.Before(record.CreatedAt) {
+		existing.Token, existing.CreatedAt, existing.ExpiresAt, existing.LockedUntil = record.Token, record.CreatedAt, record.ExpiresAt, record.LockedUntil
+		return nil, nil
+	}
+	copied := *existing
+	return &copied, nil
+}
+
+func (f *fakeIdempotencyDAO) Complete(ctx context.Context, userSub, key string, token primitive.ObjectID, status int, contentType string, body []byte) error {
+	record, found := f.records[userSub+"|"+key]
+	if !found || record.Token != token || record.State != models.IdempotencyInProgress {
+		return dao.ErrIdempotencyLeaseLost
+	}
+	record.State, record.Status, record.ContentType, record.Body = models.IdempotencyCompleted, status, contentType, body
+	record.LockedUntil = time.Time{}
+	return nil
+}
+
+func (f *fakeIdempotencyDAO) Release(ctx context.Context, userSub, key string, token primitive.ObjectID) error {
+	id := userSub + "|" + key
+	if record, found := f.records[id]; found && record.Token == token && record.State == models.IdempotencyInProgress {
+		delete(f.records, id)
+	}
+	return nil
+}
+
+func (f *fakeIdempotencyDAO) DeleteByUser(ctx context.Context, userSub string) (int64, error) {
+	return 0, nil
+}
+
+func TestIdempotency(t *testing.T) {
+	const (
+		path = "/api/selections"
+		body = `{"spotify_item_id":"t1"}`
+	)
+	fingerprint := requestFingerprint(http.MethodPost, path, []byte(body))
+	now := time.Now().UTC()
+	otherToken := primitive.NewObjectID()
+	record := func(state string, fingerprint string, lockedUntil time.Time) *models.IdempotencyRecord {
+		return &models.IdempotencyRecord{
+			UserSub: "user", Key: "k1", Token: otherToken, Fingerprint: fingerprint, State: state,
+			Status: http.StatusCreated, ContentType: "application/json", Body: []byte(`{"stored":true}`),
+			CreatedAt: now.Add(-time.Hour), ExpiresAt: now.Add(time.Hour), LockedUntil: lockedUntil,
+		}
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		key        string
+		existing   *models.IdempotencyRecord
+		reserveErr error
+		// handler runs as the route; nil responds 201 with a small JSON body
+		handler      func(c *gin.Context, f *fakeIdempotencyDAO)
+		wantStatus   int
+		wantBody     string
+		wantHandler  bool
+		wantReplayed bool
+		wantStored   string // State of the record afterwards, or "" if none should remain
+		wantToken    *primitive.ObjectID
+	}{
+		{name: "no key", method: http.MethodPost, wantStatus: http.StatusCreated, wantHandler: true},
+		{name: "GET ignores the key", method: http.MethodGet, key: "k1", wantStatus: http.StatusCreated, wantHandler: true},
+		{name: "invalid key", method: http.MethodPost, key: "has space", wantStatus: http.StatusBadRequest},
+		{
+			name: "first request is stored", method: http.MethodPost, key: "k1",
+			wantStatus: http.StatusCreated, wantHandler: true, wantStored: models.IdempotencyCompleted,
+		},
+		{
+			name: "retry replays the stored response", method: http.MethodPost, key: "k1",
+			existing:   record(models.IdempotencyCompleted, fingerprint, time.Time{}),
+			wantStatus: http.StatusCreated, wantBody: `{"stored":true}`, wantReplayed: true, wantStored: models.IdempotencyCompleted,
+		},
+		{
+			name: "key reused for a different request", method: http.MethodPost, key: "k1",
+			existing:   record(models.IdempotencyCompleted, "other", time.Time{}),
+			wantStatus: http.StatusUnprocessableEntity, wantStored: models.IdempotencyCompleted,
+		},
+		{
+			name: "concurrent retry while the lease holds", method: http.MethodPost, key: "k1",
+			existing:   record(models.IdempotencyInProgress, fingerprint, now.Add(time.Minute)),
+			wantStatus: http.StatusConflict, wantStored: models.IdempotencyInProgress, wantToken: &otherToken,
+		},
+		{
+			name: "abandoned reservation is taken over", method: http.MethodPost, key: "k1",
+			existing:   record(models.IdempotencyInProgress, fingerprint, now.Add(-time.Minute)),
+			wantStatus: http.StatusCreated, wantHandler: true, wantStored: models.IdempotencyCompleted,
+		},
+		{
+			name: "expired lease for a different request is not taken over", method: http.MethodPost, key: "k1",
+			existing:   record(models.IdempotencyInProgress, "other", now.Add(-time.Minute)),
+			wantStatus: http.StatusUnprocessableEntity, wantStored: models.IdempotencyInProgress, wantToken: &otherToken,
+		},
+		{
+			name: "server errors are not stored", method: http.MethodPost, key: "k1",
+			handler:    func(c *gin.Context, f *fakeIdempotencyDAO) { c.JSON(http.StatusBadGateway, gin.H{"error": "spotify"}) },
+			wantStatus: http.StatusBadGateway, wantHandler: true,
+		},
+		{
+			name: "client errors are stored", method: http.MethodPost, key: "k1",
+			handler:    func(c *gin.Context, f *fakeIdempotencyDAO) { c.JSON(http.StatusBadRequest, gin.H{"error": "bad"}) },
+			wantStatus: http.StatusBadRequest, wantHandler: true, wantStored: models.IdempotencyCompleted,
+		},
+		{
+			name: "oversize responses are not stored", method: http.MethodPost, key: "k1",
+			handler: func(c *gin.Context, f *fakeIdempotencyDAO) {
+				c.Data(http.StatusOK, "text/plain", []byte(strings.Repeat("x", maxIdempotentResponseBytes+1)))
+			},
+			wantStatus: http.StatusOK, wantHandler: true,
+		},
+		{
+			name: "store unavailable", method: http.MethodPost, key: "k1", reserveErr: errors.New("mongo down"),
+			wantStatus: http.StatusServiceUnavailable,
+		},
+		{
+			name: "request that outlived its lease leaves the new owner alone", method: http.MethodPost, key: "k1",
+			handler: func(c *gin.Context, f *fakeIdempotencyDAO) {
+				// A retry takes the key over while this request is still running
+				f.records["user|k1"].Token = otherToken
+				c.JSON(http.StatusCreated, gin.H{"id": "late"})
+			},
+			wantStatus: http.StatusCreated, wantHandler: true, wantStored: models.IdempotencyInProgress, wantToken: &otherToken,
+		},
+		{
+			name: "failed request that outlived its lease leaves the new owner alone", method: http.MethodPost, key: "k1",
+			handler: func(c *gin.Context, f *fakeIdempotencyDAO) {
+				f.records["user|k1"].Token = otherToken
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "late"})
+			},
+			wantStatus: http.StatusInternalServerError, wantHandler: true, wantStored: models.IdempotencyInProgress, wantToken: &otherToken,
+		},
+	}
+
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newFakeIdempotencyDAO()
+			f.reserveErr = tt.reserveErr
+			if tt.existing != nil {
+				f.records["user|k1"] = tt.existing
+			}
+			handlerCalled := false
+			router := gin.New()
+			router.Use(func(c *gin.Context) { c.Set(ClerkUserIDKey, "user") })
+			router.Use(Idempotency(f, time.Hour, time.Minute, logger))
+			router.Handle(tt.method, path, func(c *gin.Context) {
+				handlerCalled = true
+				if tt.handler != nil {
+					tt.handler(c, f)
+					return
+				}
+				c.JSON(http.StatusCreated, gin.H{"id": "new"})
+			})
+
+			req := httptest.NewRequest(tt.method, path, strings.NewReader(body))
+			if tt.key != "" {
+				req.Header.Set(IdempotencyKeyHeader, tt.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %s, want %s", w.Body.String(), tt.wantBody)
+			}
+			if handlerCalled != tt.wantHandler {
+				t.Errorf("handler called = %v, want %v", handlerCalled, tt.wantHandler)
+			}
+			if replayed := w.Header().Get(IdempotentReplayedHeader) == "true"; replayed != tt.wantReplayed {
+				t.Errorf("replayed = %v, want %v", replayed, tt.wantReplayed)
+			}
+			stored, found := f.records["user|k1"]
+			switch {
+			case tt.wantStored == "" && found:
+				t.Errorf("record left in state %s, want none", stored.State)
+			case tt.wantStored != "" && !found:
+				t.Errorf("no record left, want one in state %s", tt.wantStored)
+			case found && stored.State != tt.wantStored:
+				t.Errorf("record state = %s, want %s", stored.State, tt.wantStored)
+			case found && tt.wantToken != nil && stored.Token != *tt.wantToken:
+				t.Errorf("record token = %s, want %s", stored.Token.Hex(), tt.wantToken.Hex())
+			}
+			if found && tt.wantStored == models.IdempotencyCompleted && tt.wantHandler && !strings.Contains(string(stored.Body), w.Body.String()) {
+				t.Errorf("stored body = %s, want the response %s", stored.Body, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIdempotencyBoundsHandlerByLease(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	lease := 50 * time.Millisecond
+	var deadline time.Time
+	var hasDeadline bool
+	var ctxErr error
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Set(ClerkUserIDKey, "user") })
+	router.Use(Idempotency(newFakeIdempotencyDAO(), time.Hour, lease, logger))
+	router.POST("/slow", func(c *gin.Context) {
+		deadline, hasDeadline = c.Request.Context().Deadline()
+		<-c.Request.Context().Done()
+		ctxErr = c.Request.Context().Err()
+		c.Status(http.StatusNoContent)
+	})
+
+	start := time.Now()
+	req := httptest.NewRequest(http.MethodPost, "/slow", nil)
+	req.Header.Set(IdempotencyKeyHeader, "k1")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	if !hasDeadline || deadline.After(start.Add(lease+time.Second)) {
+		t.Errorf("handler deadline = %v (set %v), want within the %s lease", deadline, hasDeadline, lease)
+	}
+	if !errors.Is(ctxErr, context.DeadlineExceeded) {
+		t.Errorf("handler context error = %v, want %v", ctxErr, context.DeadlineExceeded)
+	}
+}
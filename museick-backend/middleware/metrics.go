@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+)
+
+// Metrics records request counts, latency and in-flight requests for Prometheus.
+// Routes are labelled by their gin pattern (e.g. /api/selections/:id) to keep label cardinality bounded.
+func Metrics() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		done := metrics.HTTPRequestStarted()
+
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		done(c.Request.Method, route, c.Writer.Status(), time.Since(start))
+	}
+}
@@ -0,0 +1,114 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/gin-gonic/gin"
+)
+
+// contractResponseWriter copies the response body so it can be checked against the spec after the handler finishes.
+type contractResponseWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *contractResponseWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *contractResponseWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// OpenAPIValidation rejects requests whose parameters or body don't match the OpenAPI document with 400.
+// With validateResponses set (the test profile), responses are checked too; the response has already been
+// sent by then, so a mismatch is logged as an error for the test run to surface rather than changing it.
+// Requests for routes the document doesn't describe pass through untouched; the startup route check reports those.
+// Authentication is left to AuthenticateClerkJWT, so this should run after it.
+func OpenAPIValidation(doc *openapi3.T, validateResponses bool, logger *slog.Logger) (gin.HandlerFunc, error) {
+	logger = logger.With("component", "openapi_validation")
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, err
+	}
+	options := &openapi3filter.Options{
+		AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		MultiError:         true,
+	}
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		route, pathParams, err := router.FindRoute(c.Request)
+		if err != nil {
+			if !errors.Is(err, routers.ErrPathNotFound) && !errors.Is(err, routers.ErrMethodNotAllowed) {
+				logger.WarnContext(ctx, "could not match request to the OpenAPI spec", "error", err)
+			}
+			c.Next()
+			return
+		}
+
+		requestInput := &openapi3filter.RequestValidationInput{
+			Request:    c.Request,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    options,
+		}
+		if err := openapi3filter.ValidateRequest(ctx, requestInput); err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + validationMessage(err)})
+			return
+		}
+
+		if !validateResponses {
+			c.Next()
+			return
+		}
+		writer := &contractResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+
+		responseInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: requestInput,
+			Status:                 writer.Status(),
+			Header:                 writer.Header(),
+			Body:                   io.NopCloser(bytes.NewReader(writer.body.Bytes())),
+			Options:                options,
+		}
+		if err := openapi3filter.ValidateResponse(ctx, responseInput); err != nil {
+			logger.ErrorContext(ctx, "response does not match the OpenAPI spec",
+				"method", c.Request.Method, "route", c.FullPath(), "status", writer.Status(), "error", validationMessage(err))
+		}
+	}, nil
+}
+
+// validationMessage flattens kin-openapi's (possibly multiple) validation errors into one line.
+func validationMessage(err error) string {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		messages := make([]string, 0, len(multi))
+		for _, e := range multi {
+			messages = append(messages, validationMessage(e))
+		}
+		return strings.Join(messages, "; ")
+	}
+	var requestErr *openapi3filter.RequestError
+	if errors.As(err, &requestErr) && requestErr.Err != nil {
+		if requestErr.Parameter != nil {
+			return "parameter " + requestErr.Parameter.Name + " in " + requestErr.Parameter.In + ": " + validationMessage(requestErr.Err)
+		}
+		if requestErr.RequestBody != nil {
+			return "request body: " + validationMessage(requestErr.Err)
+		}
+	}
+	return err.Error()
+}
@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+	"github.com/seven7een/museick/museick-backend/internal/ratelimit"
+)
+
+// RateLimitKeyFunc returns the identity a request is limited by.
+type RateLimitKeyFunc func(c *gin.Context) string
+
+// KeyByUser limits by the authenticated Clerk user, falling back to the client IP.
+// Must run after AuthenticateClerkJWT.
+func KeyByUser(c *gin.Context) string {
+	if sub := c.GetString(ClerkUserIDKey); sub != "" {
+		return "user:" + sub
+	}
+	return KeyByIP(c)
+}
+
+// KeyByIP limits by client IP, as resolved by gin: X-Forwarded-For only counts from TRUSTED_PROXIES.
+func KeyByIP(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// RateLimit enforces a token bucket per key within a named route group.
+// Denied requests get 429 with Retry-After. If the store fails the request is let through,
+// since an outage of the limiter's storage shouldn't take the API down with it.
+func RateLimit(store ratelimit.Store, group string, limit ratelimit.Limit, keyFunc RateLimitKeyFunc, logger *slog.Logger) gin.HandlerFunc {
+	if !limit.Enabled() {
+		return func(c *gin.Context) { c.Next() }
+	}
+	logger = logger.With("component", "rate_limit", "group", group)
+	limitHeader := strconv.Itoa(limit.Burst)
+
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		res, err := store.Take(ctx, group+":"+keyFunc(c), limit)
+		if err != nil {
+			logger.ErrorContext(ctx, "rate limit store failed, allowing request", "error", err)
+			c.Next()
+			return
+		}
+
+		c.Header("X-RateLimit-Limit", limitHeader)
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		if !res.Allowed {
+			retryAfter := int(math.Ceil(res.RetryAfter.Seconds()))
+			if retryAfter < 1 {
+				retryAfter = 1
+			}
+			metrics.RecordRateLimited(group)
+			logger.WarnContext(ctx, "rate limit exceeded", "retry_after_seconds", retryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "Rate limit exceeded, retry later"})
+			return
+		}
+		c.Next()
+	}
+}
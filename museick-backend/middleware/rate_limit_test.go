@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/ratelimit"
+)
+
+type failingStore struct{}
+
+func (failingStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("mongo down")
+}
+
+func TestRateLimit(t *testing.T) {
+	slow := ratelimit.Limit{Rate: 0.01, Burst: 2} // Two requests, then one every 100s
+	tests := []struct {
+		name       string
+		store      ratelimit.Store
+		limit      ratelimit.Limit
+		users      []string // One request per entry, by Clerk user ("" for anonymous)
+		wantStatus []int
+		wantRetry  string // Retry-After on the last response
+	}{
+		{"limit per user", ratelimit.NewMemoryStore(), slow, []string{"u1", "u1", "u1"}, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, "100"},
+		{"users have separate buckets", ratelimit.NewMemoryStore(), slow, []string{"u1", "u1", "u2"}, []int{http.StatusOK, http.StatusOK, http.StatusOK}, ""},
+		{"anonymous requests share the IP's bucket", ratelimit.NewMemoryStore(), slow, []string{"", "", ""}, []int{http.StatusOK, http.StatusOK, http.StatusTooManyRequests}, "100"},
+		{"limiting off", ratelimit.NewMemoryStore(), ratelimit.Limit{}, []string{"u1", "u1", "u1"}, []int{http.StatusOK, http.StatusOK, http.StatusOK}, ""},
+		{"store failure lets requests through", failingStore{}, slow, []string{"u1", "u1", "u1"}, []int{http.StatusOK, http.StatusOK, http.StatusOK}, ""},
+	}
+
+	gin.SetMode(gin.TestMode)
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(func(c *gin.Context) {
+				if user := c.GetHeader("X-Test-User"); user != "" {
+					c.Set(ClerkUserIDKey, user)
+				}
+			})
+			router.Use(RateLimit(tt.store, "api", tt.limit, KeyByUser, logger))
+			router.GET("/api/selections", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			var w *httptest.ResponseRecorder
+			for i, user := range tt.users {
+				req := httptest.NewRequest(http.MethodGet, "/api/selections", nil)
+				req.Header.Set("X-Test-User", user)
+				w = httptest.NewRecorder()
+				router.ServeHTTP(w, req)
+				if w.Code != tt.wantStatus[i] {
+					t.Errorf("request %d: status = %d, want %d", i, w.Code, tt.wantStatus[i])
+				}
+			}
+			if got := w.Header().Get("Retry-After"); got != tt.wantRetry {
+				t.Errorf("Retry-After = %q, want %q", got, tt.wantRetry)
+			}
+			if tt.wantRetry != "" && w.Header().Get("X-RateLimit-Limit") != "2" {
+				t.Errorf("X-RateLimit-Limit = %q, want 2", w.Header().Get("X-RateLimit-Limit"))
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/seven7een/museick/museick-backend/initializers"
+	"github.com/seven7een/museick/museick-backend/internal/logging"
+	"github.com/seven7een/museick/museick-backend/internal/migrations"
+)
+
+const migrateUsage = `Usage: museick-backend migrate <command> [flags]
+
+Commands:
+  status            List migrations and whether each has been applied
+  up [-to N]        Apply pending migrations (up to and including version N)
+  down [-steps N]   Roll back the last N applied migrations (default 1)
+`
+
+// runMigrateCommand implements `museick-backend migrate` and returns the process exit code.
+func runMigrateCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	flags := flag.NewFlagSet("migrate "+args[0], flag.ContinueOnError)
+	to := flags.Int("to", 0, "apply migrations up to and including this version (0 = all)")
+	steps := flags.Int("steps", 1, "number of migrations to roll back")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	config := initializers.GetConfig()
+	logger, err := logging.New(logging.Options{Level: config.LogLevel, Format: config.LogFormat})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "could not configure logging:", err)
+		return 1
+	}
+	client, err := initializers.NewMongoConnection(config)
+	if err != nil {
+		logger.Error("could not connect to MongoDB", "error", err)
+		return 1
+	}
+	defer func() {
+		disconnectCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = client.Disconnect(disconnectCtx)
+	}()
+
+	migrator, err := migrations.NewMigrator(client.Database(config.MongoDBName), migrations.All(), logger)
+	if err != nil {
+		logger.Error("invalid migration set", "error", err)
+		return 1
+	}
+	ctx := context.Background()
+
+	switch args[0] {
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			logger.Error("could not read migration status", "error", err)
+			return 1
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tREQUIRED\tREVERSIBLE\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.Applied {
+				appliedAt = s.AppliedAt.Format(time.RFC3339)
+			}
+			name := s.Name
+			if s.Unknown {
+				name += " (unknown to this version)"
+			}
+			if s.AppliedAs != "" {
+				name += fmt.Sprintf(" (applied as %s)", s.AppliedAs)
+			}
+			fmt.Fprintf(w, "%04d\t%s\t%t\t%t\t%s\n", s.Version, name, s.Required, s.Reversible, appliedAt)
+		}
+		w.Flush()
+	case "up":
+		applied, err := migrator.Up(ctx, *to)
+		for _, m := range applied {
+			fmt.Println("applied", m)
+		}
+		if err != nil {
+			logger.Error("migration failed", "error", err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("no pending migrations")
+		}
+	case "down":
+		if *steps < 1 {
+			fmt.Fprintln(os.Stderr, "-steps must be at least 1")
+			return 2
+		}
+		rolledBack, err := migrator.Down(ctx, *steps)
+		for _, m := range rolledBack {
+			fmt.Println("rolled back", m)
+		}
+		if err != nil {
+			logger.Error("rollback failed", "error", err)
+			return 1
+		}
+	default:
+		fmt.Fprint(os.Stderr, migrateUsage)
+		return 2
+	}
+	return 0
+}
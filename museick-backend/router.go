@@ -0,0 +1,110 @@
+package main
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/seven7een/museick/museick-backend/internal/handlers"
+	"github.com/seven7een/museick/museick-backend/internal/metrics"
+
+	swaggerFiles "github.com/swaggo/files"
+	ginSwagger "github.com/swaggo/gin-swagger"
+)
+
+// routeHandlers are the handlers served by the router.
+type routeHandlers struct {
+	user      *handlers.UserHandler
+	spotify   *handlers.SpotifyHandler
+	selection *handlers.SelectionHandler
+	playlist  *handlers.PlaylistHandler
+	journal   *handlers.JournalHandler
+	artist    *handlers.ArtistHandler
+	media     *handlers.MediaHandler
+	stats     *handlers.StatsHandler
+	health    *handlers.HealthHandler
+}
+
+// routeMiddleware is the middleware the router applies. Keeping it separate from the handlers lets the
+// router be built without MongoDB, Clerk or Spotify, as the route contract test does.
+type routeMiddleware struct {
+	global            []gin.HandlerFunc // Applied to every route, in order
+	publicRateLimit   gin.HandlerFunc   // Per IP, on public routes
+	authenticate      gin.HandlerFunc   // Clerk JWT, on /api
+	apiRateLimit      gin.HandlerFunc   // Per user, on /api (after authentication)
+	spotifyRateLimit  gin.HandlerFunc   // Per user, on /api routes that call Spotify
+	openAPIValidation gin.HandlerFunc
+	idempotency       gin.HandlerFunc
+}
+
+// newRouter registers every route on a new engine.
+func newRouter(h routeHandlers, mw routeMiddleware) *gin.Engine {
+	engine := gin.New() // Logging and recovery middleware come in through mw.global
+	engine.Use(mw.global...)
+
+	// --- Public Routes ---
+	router := engine.Group("/")
+	// Probes and metrics are scraped often from a single address, so they are not rate limited
+	public := router.Group("", mw.publicRateLimit)
+	{
+		public.GET("/", handlers.Welcome)
+		public.GET("/ping", handlers.Ping)
+		router.GET("/healthz", h.health.Liveness)   // Liveness: process is up
+		router.GET("/readyz", h.health.Readiness)   // Readiness: MongoDB, indexes and Spotify reachable
+		router.GET("/db_health", h.health.DBHealth) // Deprecated, use /readyz
+		// Prometheus metrics (HTTP, MongoDB commands, Spotify calls, item cache)
+		router.GET("/metrics", gin.WrapH(metrics.Handler()))
+		// Swagger documentation route
+		url := ginSwagger.URL("/swagger/doc.json") // The url pointing to API definition
+		public.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
+		// Mirrored album and artist images, public so share pages can embed them (?size=&format=)
+		public.GET("/media/:hash", h.media.GetMedia)
+	}
+
+	// --- API Routes (Protected by Clerk JWT Authentication) ---
+	api := router.Group("/api")
+	{
+		// Apply Clerk JWT authentication middleware to all /api routes
+		api.Use(mw.authenticate)
+		// Per-user limit, so it must come after authentication
+		api.Use(mw.apiRateLimit)
+		// Reject requests that don't match the spec before they can be stored under an Idempotency-Key
+		api.Use(mw.openAPIValidation)
+		// Retries of POST/PUT/DELETE with the same Idempotency-Key replay the first response
+		api.Use(mw.idempotency)
+		spotifyRateLimit := mw.spotifyRateLimit
+
+		// User Sync Route (Ensures user exists in DB after Clerk sign-in)
+		api.POST("/users/sync", h.user.SyncUser)
+
+		// User Selection Routes (New)
+		api.POST("/selections", spotifyRateLimit, h.selection.CreateSelection)       // Add a candidate/muse/ick
+		api.POST("/selections/batch", spotifyRateLimit, h.selection.BatchSelections) // Apply several creates/updates/deletes at once
+		api.POST("/selections/carry-over", h.selection.CarryOverSelections)          // Copy candidates from one month to another
+		api.GET("/selections/:monthYear", h.selection.ListSelectionsByMonth)         // List selections for a month (YYYY-MM)
+		api.PUT("/selections/:id", h.selection.UpdateSelection)                      // Update a selection (e.g., change type, notes)
+		api.DELETE("/selections/:id", h.selection.DeleteSelection)                   // Delete a selection
+
+		// Journal Routes
+		api.GET("/journal/search", h.journal.SearchJournal)              // Full-text search over notes and item names (?q=&year=)
+		api.GET("/journal/tags", h.journal.ListMoodTags)                 // List mood tags with counts
+		api.GET("/journal/tags/:tag", h.journal.ListSelectionsByMoodTag) // List selections carrying a mood tag (?year=)
+
+		// Stats Routes
+		api.GET("/stats/audio-features/:year", h.stats.GetAudioFeatureStats)    // Muse vs Ick audio feature distributions (?selected_only=)
+		api.GET("/stats/release-eras/:year", h.stats.GetReleaseEraStats)        // Picks by release decade/year, new vs catalog (?selected_only=)
+		api.GET("/stats/genres/:year", spotifyRateLimit, h.stats.GetGenreStats) // Genre mix by month, top artists (?selected_only=)
+		api.GET("/stats/compare", h.stats.GetYearComparison)                    // Cross-year comparison (?years=2024,2025&selected_only=)
+		api.GET("/stats/retrospective", h.stats.GetRetrospective)               // Comparison of every year with picks (?selected_only=)
+
+		// Artist Routes (served from the cache, synced from Spotify when missing or stale)
+		api.GET("/artists/:id", spotifyRateLimit, h.artist.GetArtist) // Artist with related artists and top tracks (?market=)
+
+		// Spotify Auth Routes (Need auth because they interact with user-specific data/tokens)
+		api.POST("/spotify/exchange-code", spotifyRateLimit, h.spotify.ExchangeCodeForToken) // Exchanges auth code for user tokens
+		api.POST("/spotify/refresh-token", spotifyRateLimit, h.spotify.RefreshAccessToken)   // Refreshes user's access token
+		// TODO: Consider if refresh token endpoint needs better security/design
+
+		// Playlist Routes
+		api.POST("/playlists", spotifyRateLimit, h.playlist.CreatePlaylist)
+	}
+
+	return engine
+}
@@ -1,7 +1,24 @@
-import { SpotifyGridItem } from '@/types/spotify.types';
+import { SpotifyGridItem, SpotifyImage } from '@/types/spotify.types';
 import { GridMode, GridItemType } from '@/types/spotify.types';
+import { ArtistDetails, GenreTimeline, MoodAnalytics, ReleaseEras, YearComparison } from '@/types/museick.types';
 
 const BASE_URL = '/api';
+const MEDIA_URL = '/media';
+
+/**
+ * Returns the URL to display an image from: the backend's mirrored copy when it has one, otherwise Spotify's.
+ * A size asks for a square thumbnail, optionally as WebP.
+ */
+export const mediaUrl = (image: SpotifyImage, size?: 64 | 160 | 300, format?: 'jpeg' | 'webp'): string => {
+    if (!image.mirror_hash) return image.url;
+    const params = new URLSearchParams();
+    if (size) {
+        params.set('size', String(size));
+        if (format) params.set('format', format);
+    }
+    const query = params.toString();
+    return `${MEDIA_URL}/${image.mirror_hash}${query ? `?${query}` : ''}`;
+};
 
 let getTokenFunction: (() => Promise<string | null>) | null = null;
 
@@ -183,3 +200,58 @@ export const saveUserSelection = async (
         }
     );
 };
+
+/**
+ * Gets an artist with its related artists and top tracks from the backend cache,
+ * which only calls Spotify when the cached data is missing or stale.
+ */
+export const getArtistDetails = async (artistId: string, market?: string): Promise<ArtistDetails> => {
+    const query = market ? `?market=${encodeURIComponent(market)}` : '';
+    return _fetchBackendApi<ArtistDetails>(`/artists/${encodeURIComponent(artistId)}${query}`);
+};
+
+/**
+ * Compares the audio features of the user's Muses and Icks in a year.
+ * @param selectedOnly Only count the monthly picks, not candidates.
+ */
+export const getMoodAnalytics = async (year: number, selectedOnly = false): Promise<MoodAnalytics> => {
+    const query = selectedOnly ? '?selected_only=true' : '';
+    return _fetchBackendApi<MoodAnalytics>(`/stats/audio-features/${year}${query}`);
+};
+
+/**
+ * Breaks down the tracks and albums the user picked in a year by release decade and year.
+ * @param selectedOnly Only count the monthly picks, not candidates.
+ */
+export const getReleaseEras = async (year: number, selectedOnly = false): Promise<ReleaseEras> => {
+    const query = selectedOnly ? '?selected_only=true' : '';
+    return _fetchBackendApi<ReleaseEras>(`/stats/release-eras/${year}${query}`);
+};
+
+/**
+ * Gets the genre mix of the user's picks month by month, with their most picked artists.
+ * @param selectedOnly Only count the monthly picks, not candidates.
+ */
+export const getGenreTimeline = async (year: number, selectedOnly = false): Promise<GenreTimeline> => {
+    const query = selectedOnly ? '?selected_only=true' : '';
+    return _fetchBackendApi<GenreTimeline>(`/stats/genres/${year}${query}`);
+};
+
+/**
+ * Compares the user's picks across several years.
+ * @param selectedOnly Only count the monthly picks, not candidates.
+ */
+export const compareYears = async (years: number[], selectedOnly = false): Promise<YearComparison> => {
+    const params = new URLSearchParams({ years: years.join(',') });
+    if (selectedOnly) params.set('selected_only', 'true');
+    return _fetchBackendApi<YearComparison>(`/stats/compare?${params.toString()}`);
+};
+
+/**
+ * Compares every year the user has picks in.
+ * @param selectedOnly Only count the monthly picks, not candidates.
+ */
+export const getRetrospective = async (selectedOnly = false): Promise<YearComparison> => {
+    const query = selectedOnly ? '?selected_only=true' : '';
+    return _fetchBackendApi<YearComparison>(`/stats/retrospective${query}`);
+};
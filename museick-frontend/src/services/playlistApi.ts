@@ -3,6 +3,10 @@ import { fetchBackendApi } from '@/features/api/backendApi';
 interface CreatePlaylistResponse {
   message: string;
   url: string;
+  market: string; // Market track availability was checked for
+  added: number;
+  substituted: { spotify_item_id: string; added_id: string }[]; // Added as Spotify's relinked copy
+  skipped: { spotify_item_id: string; reason: string }[];
 }
 
 export const createYearlyPlaylist = async (
@@ -23,4 +23,150 @@ export interface UserSelection {
   //   type: GridItemType;
   //   selectionId?: string;
   //   selectionRole?: SelectionRole;
-}
\ No newline at end of file
+}
+// Matches backend models.SpotifyArtist (cached artist)
+export interface CachedArtist {
+  spotify_id: string;
+  name: string;
+  uri: string;
+  external_urls: Record<string, string>;
+  genres?: string[];
+  images?: { url: string; height: number | null; width: number | null; mirror_hash?: string }[];
+  popularity?: number;
+  followers?: { href: string | null; total: number };
+  last_fetched_at: string; // ISO Date string
+}
+
+// Matches backend models.ArtistDetailsResponse (GET /api/artists/:id)
+export interface ArtistDetails {
+  artist: CachedArtist;
+  related_artists: CachedArtist[];
+  top_tracks: any[]; // backend models.SpotifyTrack
+  market: string;
+}
+
+// Matches backend models.AudioFeatures names
+export type AudioFeatureName = 'valence' | 'energy' | 'danceability' | 'tempo' | 'acousticness';
+
+// Matches backend models.FeatureDistribution
+export interface FeatureDistribution {
+  mean: number;
+  median: number;
+  std_dev: number;
+  min: number;
+  max: number;
+  histogram: { from: number; to: number; count: number }[];
+}
+
+// Matches backend models.MoodGroupStats
+export interface MoodGroupStats {
+  tracks: number;
+  with_features: number;
+  features: Partial<Record<AudioFeatureName, FeatureDistribution>>;
+}
+
+// Matches backend models.MoodAnalyticsResponse (GET /api/stats/audio-features/:year)
+export interface MoodAnalytics {
+  year: number;
+  selected_only: boolean;
+  muses: MoodGroupStats;
+  icks: MoodGroupStats;
+  differences: Partial<Record<AudioFeatureName, number>>; // Muse mean minus Ick mean
+}
+
+// Matches backend models.ReleaseBucket
+export interface ReleaseBucket {
+  label: string; // "1990s" or "1994"
+  start: number;
+  muses: number;
+  icks: number;
+}
+
+// Matches backend models.ReleaseEraPick
+export interface ReleaseEraPick {
+  selection_id: string;
+  spotify_item_id: string;
+  item_type: 'track' | 'album';
+  item_name: string;
+  artist_names: string[];
+  month_year: string;
+  selection_role: SelectionRole;
+  release_date: string; // YYYY, YYYY-MM or YYYY-MM-DD
+  release_date_precision: 'year' | 'month' | 'day';
+}
+
+// Matches backend models.ReleaseEraResponse (GET /api/stats/release-eras/:year)
+export interface ReleaseEras {
+  year: number;
+  selected_only: boolean;
+  picks: number;
+  with_release_date: number;
+  by_decade: ReleaseBucket[];
+  by_release_year: ReleaseBucket[];
+  new_vs_catalog: {
+    window_months: number;
+    new_releases: number;
+    catalog: number;
+    undetermined: number;
+    median_gap_months?: number;
+  };
+  oldest_muse?: ReleaseEraPick;
+  newest_muse?: ReleaseEraPick;
+}
+
+// Matches backend models.GenreShare
+export interface GenreShare {
+  genre: string;
+  weight: number; // Each pick's weight of 1 is split across its genres
+  share: number;
+}
+
+// Matches backend models.ArtistRollup
+export interface ArtistRollup {
+  spotify_id: string;
+  name: string;
+  genres: string[];
+  picks: number;
+  muses: number;
+  icks: number;
+  by_item_type: Partial<Record<'track' | 'album' | 'artist', number>>;
+}
+
+// Matches backend models.GenreTimelineResponse (GET /api/stats/genres/:year)
+export interface GenreTimeline {
+  year: number;
+  selected_only: boolean;
+  picks: number;
+  unresolved: number;
+  months: { month_year: string; picks: number; with_genres: number; genres: GenreShare[] }[];
+  top_genres: GenreShare[];
+  top_artists: ArtistRollup[];
+  muse_and_ick: ArtistRollup[];
+}
+
+// Matches backend models.CrossYearItem
+export interface CrossYearItem {
+  spotify_item_id: string;
+  item_type: 'track' | 'album' | 'artist';
+  item_name: string;
+  artist_names: string[];
+}
+
+// Matches backend models.RoleChange
+export interface RoleChange extends CrossYearItem {
+  from_month: string;
+  to_month: string;
+}
+
+// Matches backend models.YearComparisonResponse (GET /api/stats/compare, GET /api/stats/retrospective)
+export interface YearComparison {
+  years: number[];
+  selected_only: boolean;
+  summaries: { year: number; picks: number; muses: number; icks: number; top_genres: GenreShare[] }[];
+  recurring_artists: { spotify_id: string; name: string; years: number[]; picks: number; muses: number; icks: number }[];
+  genre_drift: { genre: string; shares: { year: number; share: number }[]; change: number }[];
+  repeat_muses: (CrossYearItem & { years: number[] })[];
+  redemptions: RoleChange[]; // Icks that became Muses
+  falls_from_grace: RoleChange[]; // Muses that became Icks
+  streaks: (CrossYearItem & { role: 'muse' | 'ick'; from: string; to: string; months: number })[];
+}
@@ -7,6 +7,7 @@ export interface SpotifyImage {
     url: string;
     height?: number; // Optional, but often included
     width?: number;  // Optional, but often included
+    mirror_hash?: string; // Set on images from the backend cache once mirrored; see mediaUrl
   }
 
   export interface SpotifyExternalUrls {
@@ -33,6 +33,12 @@ export default defineConfig({
         // No rewrite needed if backend expects /api prefix
         // rewrite: (path: string) => path.replace(/^\/api/, ''),
       },
+      // Images mirrored by the backend, e.g. /media/<hash>?size=300
+      '/media/': {
+        target: apiTarget,
+        changeOrigin: true,
+        secure: false,
+      },
       // Example proxy for a different backend service if needed
       '/health/': {
         target: apiTarget, // Assuming health check is on the same backend